@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"k8s.io/klog/v2/klogr"
+	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/profiler"
 	"kpt.dev/configsync/pkg/util/log"
 	"kpt.dev/configsync/pkg/webhook"
@@ -38,6 +39,7 @@ var (
 	healthProbeBindAddress  string
 	gracefulShutdownTimeout time.Duration
 	cacheSyncTimeout        time.Duration
+	auditOnly               bool
 )
 
 func main() {
@@ -46,12 +48,29 @@ func main() {
 	flag.StringVar(&healthProbeBindAddress, "health-probe-bind-addr", fmt.Sprintf(":%d", configuration.HealthProbePort), "The address the healthz & readyz probes bind to.")
 	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", configuration.GracefulShutdownTimeout, "The duration of time to wait while shutting down for all controllers to stop.")
 	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", configuration.CacheSyncTimeout, "The duration of time to wait while informers synchronize.")
+	flag.BoolVar(&auditOnly, "audit-only", false, "Admit requests modifying managed resources instead of denying them, and record them as Events, audit logs and metrics.")
 
 	log.Setup()
 
 	profiler.Service()
 	ctrl.SetLogger(klogr.New())
 
+	// Register the OpenCensus views and the OC Agent exporter
+	if err := metrics.RegisterAdmissionWebhookMetricsViews(); err != nil {
+		setupLog.Error(err, "unable to register OpenCensus views")
+		os.Exit(1)
+	}
+	oce, err := metrics.RegisterOCAgentExporter(configuration.ShortName)
+	if err != nil {
+		setupLog.Error(err, "unable to register the OC Agent exporter")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := oce.Stop(); err != nil {
+			setupLog.Error(err, "unable to stop the OC Agent exporter")
+		}
+	}()
+
 	setupLog.Info("starting manager")
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Port:    configuration.ContainerPort,
@@ -107,7 +126,7 @@ func main() {
 		<-certDone
 
 		setupLog.Info("registering validator for webhook")
-		if err := webhook.AddValidator(mgr); err != nil {
+		if err := webhook.AddValidator(mgr, auditOnly); err != nil {
 			setupLog.Error(err, "unable to register validator for webhook")
 			os.Exit(1)
 		}
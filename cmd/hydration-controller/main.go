@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"os"
 	"strings"
@@ -30,6 +31,8 @@ import (
 	"kpt.dev/configsync/pkg/profiler"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/reconcilermanager/controllers"
+	"kpt.dev/configsync/pkg/tracing"
+	"kpt.dev/configsync/pkg/util"
 	"kpt.dev/configsync/pkg/util/log"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -70,6 +73,18 @@ var (
 
 	reconcilerName = flag.String("reconciler-name", os.Getenv(reconcilermanager.ReconcilerNameKey),
 		"Name of the reconciler Deployment.")
+
+	kustomizeConfig = flag.String("kustomize-config", os.Getenv(reconcilermanager.KustomizeConfig),
+		"JSON encoded restrictions on the remote sources loaded by the kustomizations in the source.")
+
+	kustomizeCacheDir = flag.String("kustomize-cache", "kustomize-cache",
+		"the name of the directory under --repo-root where the remote bases pinned to a commit are cached.")
+
+	renderCacheDir = flag.String("render-cache-dir", os.Getenv(reconcilermanager.RenderCacheDir),
+		"the absolute path to the render cache shared by the hydration-controllers of the RootSyncs, or of the RepoSyncs in the same namespace. The render cache is disabled if empty.")
+
+	traceSamplingFraction = flag.Float64("trace-sampling-fraction", util.EnvFloat(reconcilermanager.TraceSamplingFraction, 0),
+		"The probability of sampling the trace spans of the rendering. Tracing is disabled if 0.")
 )
 
 func main() {
@@ -94,6 +109,9 @@ func main() {
 		}
 	}()
 
+	// Export the trace spans with the OC Agent exporter
+	tracing.Register(oce, *traceSamplingFraction)
+
 	absRepoRootDir, err := cmpath.AbsoluteOS(*repoRootDir)
 	if err != nil {
 		klog.Fatalf("--repo-root must be an absolute path: %v", err)
@@ -109,6 +127,13 @@ func main() {
 	dir := strings.TrimPrefix(*syncDir, "/")
 	relSyncDir := cmpath.RelativeOS(dir)
 
+	var kustomize v1beta1.KustomizeConfig
+	if *kustomizeConfig != "" {
+		if err := json.Unmarshal([]byte(*kustomizeConfig), &kustomize); err != nil {
+			klog.Fatalf("--kustomize-config must be a JSON encoded Kustomize configuration: %v", err)
+		}
+	}
+
 	hydrator := &hydrate.Hydrator{
 		DonePath:        absDonePath,
 		SourceType:      v1beta1.SourceType(*sourceType),
@@ -120,6 +145,12 @@ func main() {
 		PollingPeriod:   *pollingPeriod,
 		RehydratePeriod: *rehydratePeriod,
 		ReconcilerName:  *reconcilerName,
+		Kustomize: hydrate.KustomizeOptions{
+			DisableRemoteBases: kustomize.DisableRemoteBases,
+			AllowedRemoteURLs:  kustomize.AllowedRemoteURLs,
+			CacheDir:           absRepoRootDir.Join(cmpath.RelativeSlash(*kustomizeCacheDir)).OSPath(),
+		},
+		RenderCacheDir: *renderCacheDir,
 	}
 
 	hydrator.Run(context.Background())
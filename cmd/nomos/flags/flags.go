@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/cobra"
 	"kpt.dev/configsync/pkg/client/restconfig"
 	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/reader"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 )
 
@@ -78,6 +79,24 @@ var (
 
 	// APIServerTimeout specifies the timeout for requests to the cluster API servers
 	APIServerTimeout = restconfig.DefaultTimeout
+
+	// JsonnetLibPaths are the directories, relative to Path, which are searched
+	// for imported Jsonnet libraries.
+	JsonnetLibPaths []string
+
+	// JsonnetExtVars are the external variables passed to the Jsonnet files.
+	JsonnetExtVars map[string]string
+
+	// Variables are the values of the cluster variables ConfigMap substituted
+	// in the declared objects.
+	Variables map[string]string
+
+	// DisableRemoteBases rejects kustomizations that load remote bases.
+	DisableRemoteBases bool
+
+	// AllowedRemoteURLs are the URL prefixes the remote bases and Helm chart
+	// repositories of the kustomizations must match.
+	AllowedRemoteURLs []string
 )
 
 // AddContexts adds the --contexts flag.
@@ -126,3 +145,33 @@ func AddOutputFormat(cmd *cobra.Command) {
 func AddAPIServerTimeout(cmd *cobra.Command) {
 	cmd.Flags().DurationVar(&APIServerTimeout, "api-server-timeout", restconfig.DefaultTimeout, fmt.Sprintf("Client-side timeout for talking to the API server; defaults to %s", restconfig.DefaultTimeout))
 }
+
+// AddJsonnet adds the --jsonnet-lib-path and --jsonnet-ext-var flags.
+func AddJsonnet(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&JsonnetLibPaths, "jsonnet-lib-path", nil,
+		`Accepts a comma-separated list of directories, relative to the root directory, which are searched for imported Jsonnet libraries.`)
+	cmd.Flags().StringToStringVar(&JsonnetExtVars, "jsonnet-ext-var", nil,
+		`Accepts a comma-separated list of key=value external variables passed to the Jsonnet files.`)
+}
+
+// AddVariables adds the --variables flag.
+func AddVariables(cmd *cobra.Command) {
+	cmd.Flags().StringToStringVar(&Variables, "variables", nil,
+		`Accepts a comma-separated list of key=value cluster variables, which are substituted for the ${vars.<key>} references in the declared objects.`)
+}
+
+// AddKustomize adds the --disable-remote-bases and --allowed-remote-url flags.
+func AddKustomize(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&DisableRemoteBases, "disable-remote-bases", false,
+		`If true, rejects kustomizations that load remote resources, bases or components.`)
+	cmd.Flags().StringSliceVar(&AllowedRemoteURLs, "allowed-remote-url", nil,
+		`Accepts a comma-separated list of URL prefixes. If set, every remote base and Helm chart repository referenced by the kustomizations must match one of them.`)
+}
+
+// FileReader returns the reader of the source files configured by the flags.
+func FileReader() *reader.File {
+	return &reader.File{
+		JsonnetLibPaths: JsonnetLibPaths,
+		JsonnetExtVars:  JsonnetExtVars,
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gc contains the logic for the nomos gc CLI command.
+package gc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/discovery"
+	"kpt.dev/configsync/cmd/nomos/flags"
+	"kpt.dev/configsync/cmd/nomos/util"
+	"kpt.dev/configsync/pkg/client/restconfig"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/protection"
+	utildiscovery "kpt.dev/configsync/pkg/util/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var (
+	policy string
+	dryRun bool
+)
+
+func init() {
+	flags.AddAPIServerTimeout(Cmd)
+	Cmd.Flags().StringVar(&policy, "policy", string(PolicyReport),
+		fmt.Sprintf("What to do with the orphaned objects. Accepts %q, %q and %q.", PolicyReport, PolicyUnmanage, PolicyDelete))
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"If enabled, only prints what the policy would do with the orphaned objects.")
+}
+
+// Cmd is the Cobra object representing the gc command.
+var Cmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Finds and collects the objects left behind by deleted RootSyncs, RepoSyncs and inventories.",
+	Long: `Finds and collects the objects left behind by deleted RootSyncs, RepoSyncs and inventories.
+
+An object is orphaned when its configsync.gke.io/manager annotation refers to a RootSync or RepoSync,
+or its config.k8s.io/owning-inventory annotation refers to an inventory, which no longer exists on the
+cluster of the current context, and none of the owners it refers to exists. This happens when a
+RootSync or RepoSync is deleted with the Orphan deletion propagation policy, or when a reconciler is
+removed in the middle of an apply.
+
+The orphaned objects are reported with their missing owners. With the unmanage policy, their Config
+Sync metadata is removed, so they stay on the cluster as unmanaged objects. With the delete policy,
+they are deleted, except the objects which deletion propagation would orphan: the protected objects,
+and the objects with the detach lifecycle annotation or the orphan deletion-policy annotation, which
+are unmanaged instead.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Don't show usage on error, as argument validation passed.
+		cmd.SilenceUsage = true
+
+		p := Policy(policy)
+		if p != PolicyReport && p != PolicyUnmanage && p != PolicyDelete {
+			return fmt.Errorf("unsupported policy %q, must be %q, %q or %q", policy, PolicyReport, PolicyUnmanage, PolicyDelete)
+		}
+
+		cfg, err := restconfig.NewRestConfig(flags.APIServerTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to create rest config: %w", err)
+		}
+		mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create mapper: %w", err)
+		}
+		c, err := client.New(cfg, client.Options{
+			Scheme: core.Scheme,
+			Mapper: mapper,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create discovery client: %w", err)
+		}
+
+		resourceLists, discoveryErr := utildiscovery.GetResources(dc)
+		if discoveryErr != nil {
+			return discoveryErr
+		}
+		o, err := listOwners(cmd.Context(), c)
+		if err != nil {
+			return err
+		}
+		orphans, listErrs := findOrphans(cmd.Context(), c, o, listableKinds(resourceLists))
+		if listErrs != nil {
+			// The other kinds are still checked.
+			util.PrintErrOrDie(errors.Wrap(listErrs, "some objects were not checked"))
+		}
+
+		rules, ruleErrs := protection.ReadRules(cmd.Context(), c)
+		if ruleErrs != nil && p == PolicyDelete {
+			// Deleting objects which may be protected is not safe.
+			return ruleErrs
+		}
+		if rules == nil {
+			rules = protection.DefaultRules()
+		}
+		plan(orphans, p, rules)
+		printOrphans(os.Stdout, orphans)
+
+		if dryRun || p == PolicyReport {
+			return nil
+		}
+		collected, collectErrs := collect(cmd.Context(), c, orphans)
+		if collectErrs != nil {
+			return collectErrs
+		}
+		fmt.Printf("Collected %d orphaned objects.\n", collected)
+		return nil
+	},
+}
+
+// printOrphans writes a table of the orphaned objects, with their missing
+// owners and the action of the policy.
+func printOrphans(w io.Writer, orphans []Orphan) {
+	if len(orphans) == 0 {
+		fmt.Fprintln(w, "No orphaned objects found.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAMESPACE\tNAME\tACTION\tREASON")
+	for _, o := range orphans {
+		reason := strings.Join(o.Reasons, "; ")
+		if o.Note != "" {
+			reason += " (" + o.Note + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			o.Object.GroupVersionKind().GroupKind(), o.Object.GetNamespace(), o.Object.GetName(), o.Action, reason)
+	}
+	_ = tw.Flush()
+}
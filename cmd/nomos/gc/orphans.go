@@ -0,0 +1,360 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/lifecycle"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/protection"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Policy decides what happens to the orphaned objects.
+type Policy string
+
+const (
+	// PolicyReport only reports the orphaned objects.
+	PolicyReport = Policy("report")
+	// PolicyUnmanage removes the Config Sync metadata from the orphaned
+	// objects, which leaves them on the cluster as unmanaged objects.
+	PolicyUnmanage = Policy("unmanage")
+	// PolicyDelete deletes the orphaned objects. The objects which are
+	// protected, or which Config Sync would orphan on deletion, are unmanaged
+	// instead.
+	PolicyDelete = Policy("delete")
+)
+
+// Action is what is done with an orphaned object.
+type Action string
+
+const (
+	// ActionNone leaves the object as is.
+	ActionNone = Action("none")
+	// ActionUnmanage removes the Config Sync metadata from the object.
+	ActionUnmanage = Action("unmanage")
+	// ActionDelete deletes the object.
+	ActionDelete = Action("delete")
+)
+
+// Orphan is a live object which refers to a RootSync, RepoSync or inventory
+// that no longer exists.
+type Orphan struct {
+	// Object is the live object.
+	Object *unstructured.Unstructured
+	// Reasons lists the missing owners of the object.
+	Reasons []string
+	// Action is what the policy does with the object.
+	Action Action
+	// Note explains why the action differs from the policy, if it does.
+	Note string
+}
+
+// owners holds the RootSyncs, RepoSyncs and inventories which exist on the
+// cluster.
+type owners struct {
+	// managers holds the values of the manager annotation of the objects
+	// managed by the existing RootSyncs and RepoSyncs.
+	managers map[string]bool
+	// inventories holds the IDs of the existing inventories.
+	inventories map[string]bool
+}
+
+// listOwners lists the existing RootSyncs, RepoSyncs and inventories. Both the
+// ResourceGroup and the ConfigMap inventories are listed. The owners whose
+// kind is not served by the cluster do not exist.
+func listOwners(ctx context.Context, c client.Reader) (owners, error) {
+	o := owners{
+		managers:    make(map[string]bool),
+		inventories: make(map[string]bool),
+	}
+
+	rsList := &v1beta1.RootSyncList{}
+	if err := c.List(ctx, rsList); ignoreNoMatch(err) != nil {
+		return o, fmt.Errorf("failed to list RootSyncs: %w", err)
+	}
+	for _, rs := range rsList.Items {
+		o.managers[declared.ResourceManager(declared.RootReconciler, rs.Name)] = true
+	}
+
+	rsyncList := &v1beta1.RepoSyncList{}
+	if err := c.List(ctx, rsyncList); ignoreNoMatch(err) != nil {
+		return o, fmt.Errorf("failed to list RepoSyncs: %w", err)
+	}
+	for _, rs := range rsyncList.Items {
+		o.managers[declared.ResourceManager(declared.Scope(rs.Namespace), rs.Name)] = true
+	}
+
+	rgList := &unstructured.UnstructuredList{}
+	rgList.SetGroupVersionKind(kinds.ResourceGroup().GroupVersion().WithKind(kinds.ResourceGroup().Kind + "List"))
+	if err := c.List(ctx, rgList, client.HasLabels{common.InventoryLabel}); ignoreNoMatch(err) != nil {
+		return o, fmt.Errorf("failed to list ResourceGroups: %w", err)
+	}
+	for _, rg := range rgList.Items {
+		o.inventories[rg.GetLabels()[common.InventoryLabel]] = true
+	}
+
+	cmList := &unstructured.UnstructuredList{}
+	cmList.SetGroupVersionKind(kinds.ConfigMap().GroupVersion().WithKind(kinds.ConfigMap().Kind + "List"))
+	if err := c.List(ctx, cmList, client.HasLabels{common.InventoryLabel}); err != nil {
+		return o, fmt.Errorf("failed to list ConfigMap inventories: %w", err)
+	}
+	for _, cm := range cmList.Items {
+		o.inventories[cm.GetLabels()[common.InventoryLabel]] = true
+	}
+	return o, nil
+}
+
+// ownersOf looks up the RootSync or RepoSync and the inventory which the given
+// object refers to, and returns the ones which exist.
+func ownersOf(ctx context.Context, c client.Reader, obj client.Object) (owners, error) {
+	o := owners{
+		managers:    make(map[string]bool),
+		inventories: make(map[string]bool),
+	}
+
+	if manager := core.GetAnnotation(obj, metadata.ResourceManagerKey); manager != "" {
+		var rs client.Object = &v1beta1.RepoSync{}
+		if declared.IsRootManager(manager) {
+			rs = &v1beta1.RootSync{}
+		}
+		err := c.Get(ctx, managerKey(manager), rs)
+		switch {
+		case err == nil:
+			o.managers[manager] = true
+		case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		default:
+			return o, fmt.Errorf("failed to get the %s %s: %w", managerKind(manager), managerKey(manager), err)
+		}
+	}
+
+	if id := core.GetAnnotation(obj, metadata.OwningInventoryKey); id != "" {
+		for _, gvk := range []schema.GroupVersionKind{kinds.ResourceGroup(), kinds.ConfigMap()} {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := c.List(ctx, list, client.MatchingLabels{common.InventoryLabel: id}); ignoreNoMatch(err) != nil {
+				return o, fmt.Errorf("failed to list %s inventories: %w", gvk.Kind, err)
+			}
+			if len(list.Items) > 0 {
+				o.inventories[id] = true
+				break
+			}
+		}
+	}
+	return o, nil
+}
+
+// ignoreNoMatch returns nil if the error is caused by a kind which is not
+// served by the cluster.
+func ignoreNoMatch(err error) error {
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	return err
+}
+
+// orphanReasons returns the missing owners which the given object refers to.
+// An object is only orphaned if it refers to an owner, and if all the owners
+// it refers to are missing: an existing owner keeps managing the object, and
+// fixes the other references.
+func (o owners) orphanReasons(obj client.Object) []string {
+	var reasons []string
+	if manager := core.GetAnnotation(obj, metadata.ResourceManagerKey); manager != "" {
+		if o.managers[manager] {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s %s not found", managerKind(manager), managerKey(manager)))
+	}
+	if id := core.GetAnnotation(obj, metadata.OwningInventoryKey); id != "" {
+		if o.inventories[id] {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("inventory %q not found", id))
+	}
+	return reasons
+}
+
+// managerKind returns the kind of the RootSync or RepoSync which is the given
+// manager.
+func managerKind(manager string) string {
+	if declared.IsRootManager(manager) {
+		return kinds.RootSyncV1Beta1().Kind
+	}
+	return kinds.RepoSyncV1Beta1().Kind
+}
+
+// managerKey returns the namespace and name of the RootSync or RepoSync which
+// is the given manager.
+func managerKey(manager string) client.ObjectKey {
+	scope, name := declared.ManagerScopeAndName(manager)
+	if scope == declared.RootReconciler {
+		return client.ObjectKey{Namespace: configmanagement.ControllerNamespace, Name: name}
+	}
+	return client.ObjectKey{Namespace: string(scope), Name: name}
+}
+
+// listableKinds returns one version of each kind served by the cluster which
+// can be listed, in a consistent order. Events are left out, since they are
+// never managed.
+func listableKinds(resourceLists []*metav1.APIResourceList) []schema.GroupVersionKind {
+	seen := make(map[schema.GroupKind]bool)
+	var gvks []schema.GroupVersionKind
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !hasVerb(r, "list") {
+				// Subresources can not be listed.
+				continue
+			}
+			gk := schema.GroupKind{Group: gv.Group, Kind: r.Kind}
+			if seen[gk] || gk.Kind == "Event" {
+				continue
+			}
+			seen[gk] = true
+			gvks = append(gvks, gv.WithKind(r.Kind))
+		}
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+	return gvks
+}
+
+// hasVerb returns true if the resource supports the given verb.
+func hasVerb(r metav1.APIResource, verb string) bool {
+	for _, v := range r.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// findOrphans lists the objects of the given kinds, and returns the orphaned
+// ones. The kinds which fail to be listed are reported as errors and skipped.
+func findOrphans(ctx context.Context, c client.Reader, o owners, gvks []schema.GroupVersionKind) ([]Orphan, status.MultiError) {
+	var orphans []Orphan
+	var errs status.MultiError
+	for _, gvk := range gvks {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list); err != nil {
+			if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+				continue
+			}
+			errs = status.Append(errs, status.APIServerErrorf(err, "failed to list %s", gvk.GroupKind()))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if reasons := o.orphanReasons(obj); len(reasons) > 0 {
+				obj.SetGroupVersionKind(gvk)
+				orphans = append(orphans, Orphan{Object: obj, Reasons: reasons})
+			}
+		}
+	}
+	return orphans, errs
+}
+
+// plan decides the action on each orphaned object under the given policy.
+// Like deletion propagation, the delete policy unmanages the objects which
+// are protected, which have the detach lifecycle annotation, or whose
+// deletion-policy annotation orphans them.
+func plan(orphans []Orphan, policy Policy, rules protection.Rules) {
+	for i := range orphans {
+		o := &orphans[i]
+		switch policy {
+		case PolicyUnmanage:
+			o.Action = ActionUnmanage
+		case PolicyDelete:
+			o.Action = ActionDelete
+			if rule, protected := rules.Match(o.Object); protected {
+				o.Action = ActionUnmanage
+				o.Note = fmt.Sprintf("protected by rule %q", rule)
+			} else if lifecycle.HasPreventDeletion(o.Object) {
+				o.Action = ActionUnmanage
+				o.Note = "lifecycle annotation prevents deletion"
+			} else if metadata.DeletionPolicy(core.GetAnnotation(o.Object, metadata.DeletionPolicyAnnotationKey)) == metadata.DeletionPolicyOrphan {
+				o.Action = ActionUnmanage
+				o.Note = "deletion-policy annotation orphans the object"
+			}
+		default:
+			o.Action = ActionNone
+		}
+	}
+}
+
+// collect performs the planned action on each orphaned object, and returns
+// the number of collected objects.
+//
+// A RootSync, RepoSync or inventory may have been created since the owners
+// were listed, so the owners of each object are looked up again just before
+// the action, and the objects which are no longer orphaned are skipped. The
+// action only applies to the object as it was listed: the update conflicts,
+// and the deletion is preconditioned on its UID and resourceVersion, if the
+// object has changed since. The objects which no longer exist are ignored.
+func collect(ctx context.Context, c client.Client, orphans []Orphan) (int, status.MultiError) {
+	var errs status.MultiError
+	collected := 0
+	for _, o := range orphans {
+		if o.Action == ActionNone {
+			continue
+		}
+		current, err := ownersOf(ctx, c, o.Object)
+		if err != nil {
+			errs = status.Append(errs, status.APIServerError(err, "failed to look up the owners of the orphaned object", o.Object))
+			continue
+		}
+		if len(current.orphanReasons(o.Object)) == 0 {
+			continue
+		}
+		switch o.Action {
+		case ActionUnmanage:
+			if metadata.RemoveConfigSyncMetadata(o.Object) {
+				err = c.Update(ctx, o.Object)
+			}
+		case ActionDelete:
+			uid, rv := o.Object.GetUID(), o.Object.GetResourceVersion()
+			err = c.Delete(ctx, o.Object, client.PropagationPolicy(metav1.DeletePropagationBackground),
+				client.Preconditions{UID: &uid, ResourceVersion: &rv})
+		}
+		switch {
+		case err == nil:
+			collected++
+		case !apierrors.IsNotFound(err):
+			errs = status.Append(errs, status.APIServerError(err, fmt.Sprintf("failed to %s the orphaned object", o.Action), o.Object))
+		}
+	}
+	return collected, errs
+}
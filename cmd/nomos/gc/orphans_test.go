@@ -0,0 +1,317 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/protection"
+	"kpt.dev/configsync/pkg/resourcegroup"
+	syncertestfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	resourcegroupv1alpha1 "kpt.dev/resourcegroup/apis/kpt.dev/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func managedBy(manager, inventory string) core.MetaMutator {
+	return func(o client.Object) {
+		if manager != "" {
+			core.SetAnnotation(o, metadata.ResourceManagerKey, manager)
+			core.SetAnnotation(o, metadata.ResourceManagementKey, metadata.ResourceManagementEnabled)
+		}
+		if inventory != "" {
+			core.SetAnnotation(o, metadata.OwningInventoryKey, inventory)
+		}
+	}
+}
+
+func newClient(t *testing.T, objs ...client.Object) *syncertestfake.Client {
+	t.Helper()
+	s := runtime.NewScheme()
+	if err := appsv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := resourcegroupv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return syncertestfake.NewClient(t, s, objs...)
+}
+
+func TestFindOrphans(t *testing.T) {
+	rootManager := declared.ResourceManager(declared.RootReconciler, "root-sync")
+	otherRootManager := declared.ResourceManager(declared.RootReconciler, "deleted-sync")
+	repoManager := declared.ResourceManager(declared.Scope("bookstore"), configsync.RepoSyncName)
+
+	c := newClient(t,
+		fake.RootSyncObjectV1Beta1("root-sync"),
+		resourcegroup.Unstructured("root-sync", configsync.ControllerNamespace, "config-management-system_root-sync"),
+		fake.ConfigMapObject(core.Name("kpt-inventory"), core.Namespace("bookstore"),
+			core.Label("cli-utils.sigs.k8s.io/inventory-id", "kpt-id")),
+		// Managed by an existing RootSync.
+		fake.DeploymentObject(core.Name("managed"), core.Namespace("bookstore"),
+			managedBy(rootManager, "config-management-system_root-sync")),
+		// Owned by an existing inventory.
+		fake.DeploymentObject(core.Name("kpt"), core.Namespace("bookstore"),
+			managedBy("", "kpt-id")),
+		// Managed by a deleted RootSync, but owned by an existing inventory.
+		fake.DeploymentObject(core.Name("moving"), core.Namespace("bookstore"),
+			managedBy(otherRootManager, "config-management-system_root-sync")),
+		// Managed by a deleted RootSync.
+		fake.DeploymentObject(core.Name("orphan"), core.Namespace("bookstore"),
+			managedBy(otherRootManager, "config-management-system_deleted-sync")),
+		// Managed by a deleted RepoSync.
+		fake.ConfigMapObject(core.Name("repo-orphan"), core.Namespace("bookstore"),
+			managedBy(repoManager, "")),
+		// Not managed.
+		fake.ConfigMapObject(core.Name("unmanaged"), core.Namespace("bookstore")),
+	)
+
+	o, err := listOwners(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphans, errs := findOrphans(context.Background(), c, o, []schema.GroupVersionKind{kinds.ConfigMap(), kinds.Deployment()})
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	got := make(map[string][]string)
+	for _, orphan := range orphans {
+		got[orphan.Object.GetName()] = orphan.Reasons
+	}
+	want := map[string][]string{
+		"orphan": {
+			`RootSync config-management-system/deleted-sync not found`,
+			`inventory "config-management-system_deleted-sync" not found`,
+		},
+		"repo-orphan": {
+			`RepoSync bookstore/repo-sync not found`,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestListableKinds(t *testing.T) {
+	resourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Verbs: []string{"get", "list"}},
+				{Name: "events", Kind: "Event", Verbs: []string{"get", "list"}},
+				{Name: "pods/log", Kind: "Pod", Verbs: []string{"get"}},
+				{Name: "bindings", Kind: "Binding", Verbs: []string{"create"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Verbs: []string{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Verbs: []string{"get", "list"}},
+			},
+		},
+	}
+	want := []schema.GroupVersionKind{kinds.ConfigMap(), kinds.Deployment()}
+	if diff := cmp.Diff(want, listableKinds(resourceLists)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPlanAndCollect(t *testing.T) {
+	manager := declared.ResourceManager(declared.RootReconciler, "deleted-sync")
+	testCases := []struct {
+		name       string
+		policy     Policy
+		obj        client.Object
+		wantAction Action
+		wantExists bool
+	}{
+		{
+			name:       "report",
+			policy:     PolicyReport,
+			obj:        fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, "")),
+			wantAction: ActionNone,
+			wantExists: true,
+		},
+		{
+			name:       "unmanage",
+			policy:     PolicyUnmanage,
+			obj:        fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, "")),
+			wantAction: ActionUnmanage,
+			wantExists: true,
+		},
+		{
+			name:       "delete",
+			policy:     PolicyDelete,
+			obj:        fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, "")),
+			wantAction: ActionDelete,
+		},
+		{
+			name:   "delete unmanages the objects with the orphan deletion policy",
+			policy: PolicyDelete,
+			obj: fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, ""),
+				core.Annotation(metadata.DeletionPolicyAnnotationKey, string(metadata.DeletionPolicyOrphan))),
+			wantAction: ActionUnmanage,
+			wantExists: true,
+		},
+		{
+			name:   "delete unmanages the objects with the detach lifecycle annotation",
+			policy: PolicyDelete,
+			obj: fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, ""),
+				core.Annotation("client.lifecycle.config.k8s.io/deletion", "detach")),
+			wantAction: ActionUnmanage,
+			wantExists: true,
+		},
+		{
+			name:       "delete unmanages the protected objects",
+			policy:     PolicyDelete,
+			obj:        fake.NamespaceObject("kube-system", managedBy(manager, "")),
+			wantAction: ActionUnmanage,
+			wantExists: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newClient(t, tc.obj)
+			o, err := listOwners(context.Background(), c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gvk := tc.obj.GetObjectKind().GroupVersionKind()
+			orphans, errs := findOrphans(context.Background(), c, o, []schema.GroupVersionKind{gvk})
+			if errs != nil {
+				t.Fatal(errs)
+			}
+			if len(orphans) != 1 {
+				t.Fatalf("got %d orphans, want 1", len(orphans))
+			}
+
+			plan(orphans, tc.policy, protection.DefaultRules())
+			if orphans[0].Action != tc.wantAction {
+				t.Errorf("got action %q, want %q", orphans[0].Action, tc.wantAction)
+			}
+			if _, errs := collect(context.Background(), c, orphans); errs != nil {
+				t.Fatal(errs)
+			}
+
+			live, found := c.Objects[core.IDOf(tc.obj)]
+			if found != tc.wantExists {
+				t.Fatalf("got exists %t, want %t", found, tc.wantExists)
+			}
+			if !found {
+				return
+			}
+			managed := metadata.HasConfigSyncMetadata(live)
+			if wantManaged := tc.wantAction == ActionNone; managed != wantManaged {
+				t.Errorf("got Config Sync metadata %t, want %t", managed, wantManaged)
+			}
+		})
+	}
+}
+
+func TestCollectRechecksOwners(t *testing.T) {
+	manager := declared.ResourceManager(declared.RootReconciler, "root-sync")
+	inventory := "config-management-system_root-sync"
+	testCases := []struct {
+		name string
+		obj  client.Object
+		// created is created after the orphans are found.
+		created       client.Object
+		wantCollected int
+		wantErr       bool
+	}{
+		{
+			name:          "orphaned object is deleted",
+			obj:           fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, inventory)),
+			wantCollected: 1,
+		},
+		{
+			name:    "object of a recreated RootSync is kept",
+			obj:     fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, inventory)),
+			created: fake.RootSyncObjectV1Beta1("root-sync"),
+		},
+		{
+			name:    "object of a recreated inventory is kept",
+			obj:     fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy("", inventory)),
+			created: resourcegroup.Unstructured("root-sync", configsync.ControllerNamespace, inventory),
+		},
+		{
+			name: "object changed since it was found is kept",
+			obj:  fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, inventory)),
+			created: fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), managedBy(manager, inventory),
+				core.Annotation("changed", "true")),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClient(t, tc.obj)
+			o, err := listOwners(ctx, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gvk := tc.obj.GetObjectKind().GroupVersionKind()
+			orphans, errs := findOrphans(ctx, c, o, []schema.GroupVersionKind{gvk})
+			if errs != nil {
+				t.Fatal(errs)
+			}
+			if len(orphans) != 1 {
+				t.Fatalf("got %d orphans, want 1", len(orphans))
+			}
+			plan(orphans, PolicyDelete, protection.DefaultRules())
+
+			if tc.created != nil {
+				if core.IDOf(tc.created) == core.IDOf(tc.obj) {
+					if err := c.Update(ctx, tc.created); err != nil {
+						t.Fatal(err)
+					}
+				} else if err := c.Create(ctx, tc.created); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			collected, errs := collect(ctx, c, orphans)
+			if (errs != nil) != tc.wantErr {
+				t.Errorf("got collect() = %v, want error %t", errs, tc.wantErr)
+			}
+			if collected != tc.wantCollected {
+				t.Errorf("got %d collected objects, want %d", collected, tc.wantCollected)
+			}
+			if _, found := c.Objects[core.IDOf(tc.obj)]; found != (tc.wantCollected == 0) {
+				t.Errorf("got exists %t, want %t", found, tc.wantCollected == 0)
+			}
+		})
+	}
+}
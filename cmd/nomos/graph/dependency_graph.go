@@ -0,0 +1,299 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"errors"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+	cligraph "sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/object/mutation"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/ordering"
+)
+
+// EdgeType is the reason why an object is applied after another object.
+type EdgeType string
+
+const (
+	// DependsOnEdge is an explicit dependency declared with the
+	// config.kubernetes.io/depends-on annotation.
+	DependsOnEdge = EdgeType("depends-on")
+	// ApplyTimeMutationEdge is an explicit dependency declared with the
+	// config.kubernetes.io/apply-time-mutation annotation.
+	ApplyTimeMutationEdge = EdgeType("apply-time-mutation")
+	// CRDEdge is the dependency of a custom resource on its
+	// CustomResourceDefinition.
+	CRDEdge = EdgeType("crd")
+	// NamespaceEdge is the dependency of a namespaced object on its Namespace.
+	NamespaceEdge = EdgeType("namespace")
+	// WebhookEdge is the ordering of the webhook configurations within an apply
+	// group: MutatingWebhookConfigurations are applied before most kinds, and
+	// ValidatingWebhookConfigurations after every other kind.
+	WebhookEdge = EdgeType("webhook")
+)
+
+// Node is an object of the dependency graph.
+type Node struct {
+	// ID is the identifier of the object in the inventory format.
+	ID        string `json:"id"`
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// SourcePath is the path of the file declaring the object.
+	SourcePath string `json:"sourcePath,omitempty"`
+	// Wave is the index of the group of objects applied together, in apply
+	// order. It is -1 for the objects which cannot be ordered because of a
+	// dependency cycle.
+	Wave int `json:"wave"`
+	// Status is the live status of the object, if requested.
+	Status string `json:"status,omitempty"`
+}
+
+// Edge is a dependency of the From object on the To object, which means that
+// the From object is applied after the To object, and pruned before it.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Type EdgeType `json:"type"`
+	// Cyclic is true if the edge is part of a dependency cycle.
+	Cyclic bool `json:"cyclic,omitempty"`
+}
+
+// Graph is the dependency graph of the objects declared for a cluster.
+type Graph struct {
+	Cluster string `json:"cluster"`
+	Nodes   []Node `json:"nodes"`
+	Edges   []Edge `json:"edges"`
+}
+
+// buildGraph returns the dependency graph of the given objects, using the same
+// rules as the applier. Invalid dependencies and dependency cycles are
+// returned as errors with the paths of the involved objects.
+func buildGraph(cluster string, fileObjects []ast.FileObject) (*Graph, status.MultiError) {
+	objs := make(object.UnstructuredSet, len(fileObjects))
+	ids := make(object.ObjMetadataSet, len(fileObjects))
+	paths := make(map[object.ObjMetadata]string, len(fileObjects))
+	for i, fo := range fileObjects {
+		objs[i] = fo.Unstructured
+		ids[i] = object.UnstructuredToObjMetadata(fo.Unstructured)
+		paths[ids[i]] = fo.SlashPath()
+	}
+
+	var errs status.MultiError
+	depGraph, err := cligraph.DependencyGraph(objs)
+	if err != nil {
+		errs = appendPathErrors(errs, err, paths)
+	}
+	waves, err := depGraph.Sort()
+	cyclic := make(map[cligraph.Edge]bool)
+	if err != nil {
+		errs = appendPathErrors(errs, err, paths)
+		var cycleErr cligraph.CyclicDependencyError
+		if errors.As(err, &cycleErr) {
+			for _, e := range cycleErr.Edges {
+				cyclic[e] = true
+			}
+		}
+	}
+
+	g := &Graph{Cluster: cluster}
+	waveOf := make(map[object.ObjMetadata]int, len(ids))
+	for i, wave := range waves {
+		for _, id := range wave {
+			waveOf[id] = i
+		}
+	}
+	for _, id := range ids {
+		wave, found := waveOf[id]
+		if !found {
+			wave = -1
+		}
+		g.Nodes = append(g.Nodes, Node{
+			ID:         id.String(),
+			Group:      id.GroupKind.Group,
+			Kind:       id.GroupKind.Kind,
+			Namespace:  id.Namespace,
+			Name:       id.Name,
+			SourcePath: paths[id],
+			Wave:       wave,
+		})
+	}
+
+	for _, e := range typedEdges(objs, ids, waves) {
+		g.Edges = append(g.Edges, Edge{
+			From:   e.From.String(),
+			To:     e.To.String(),
+			Type:   e.Type,
+			Cyclic: cyclic[cligraph.Edge{From: e.From, To: e.To}],
+		})
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Wave != g.Nodes[j].Wave {
+			return g.Nodes[i].Wave < g.Nodes[j].Wave
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		if g.Edges[i].To != g.Edges[j].To {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].Type < g.Edges[j].Type
+	})
+	return g, errs
+}
+
+// appendPathErrors appends the errors of the cli-utils dependency graph as
+// errors with the paths of the objects they apply to.
+func appendPathErrors(errs status.MultiError, err error, paths map[object.ObjMetadata]string) status.MultiError {
+	for _, e := range multierror.Unwrap(err) {
+		var slashPaths []string
+		var validationErr *validation.Error
+		if errors.As(e, &validationErr) {
+			for _, id := range validationErr.Identifiers() {
+				if p, found := paths[id]; found {
+					slashPaths = append(slashPaths, p)
+				}
+			}
+		}
+		errs = status.Append(errs, status.PathWrapError(e, slashPaths...))
+	}
+	return errs
+}
+
+// typedEdge is an edge of the dependency graph with the reason for it.
+type typedEdge struct {
+	From object.ObjMetadata
+	To   object.ObjMetadata
+	Type EdgeType
+}
+
+// typedEdges returns the edges added to the dependency graph by the cli-utils
+// applier, labeled with the reason for them, and the webhook ordering edges
+// within each apply wave. Invalid dependencies are skipped, since they are
+// reported by the cli-utils dependency graph.
+func typedEdges(objs object.UnstructuredSet, ids object.ObjMetadataSet, waves []object.ObjMetadataSet) []typedEdge {
+	var edges []typedEdge
+	seen := make(map[typedEdge]bool)
+	add := func(e typedEdge) {
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+
+	crds := make(map[schema.GroupKind]object.ObjMetadata)
+	namespaces := make(map[string]object.ObjMetadata)
+	for i, u := range objs {
+		if object.IsCRD(u) {
+			if gk, found := object.GetCRDGroupKind(u); found {
+				crds[gk] = ids[i]
+			}
+		}
+		if object.IsKindNamespace(u) {
+			namespaces[u.GetName()] = ids[i]
+		}
+	}
+
+	for i, u := range objs {
+		from := ids[i]
+		if to, found := crds[u.GroupVersionKind().GroupKind()]; found {
+			add(typedEdge{From: from, To: to, Type: CRDEdge})
+		}
+		if object.IsNamespaced(u) {
+			if to, found := namespaces[u.GetNamespace()]; found {
+				add(typedEdge{From: from, To: to, Type: NamespaceEdge})
+			}
+		}
+		for _, to := range dependsOn(u) {
+			if ids.Contains(to) {
+				add(typedEdge{From: from, To: to, Type: DependsOnEdge})
+			}
+		}
+		for _, to := range mutationSources(u) {
+			if ids.Contains(to) {
+				add(typedEdge{From: from, To: to, Type: ApplyTimeMutationEdge})
+			}
+		}
+	}
+
+	for _, wave := range waves {
+		for _, webhook := range wave {
+			switch webhook.GroupKind {
+			case mutatingWebhookGK:
+				for _, id := range wave {
+					if ordering.IsLessThan(webhook.GroupKind, id.GroupKind) {
+						add(typedEdge{From: id, To: webhook, Type: WebhookEdge})
+					}
+				}
+			case validatingWebhookGK:
+				for _, id := range wave {
+					if ordering.IsLessThan(id.GroupKind, webhook.GroupKind) {
+						add(typedEdge{From: webhook, To: id, Type: WebhookEdge})
+					}
+				}
+			}
+		}
+	}
+	return edges
+}
+
+var (
+	mutatingWebhookGK   = schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}
+	validatingWebhookGK = schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}
+)
+
+// dependsOn returns the objects referenced by the depends-on annotation of the
+// object, ignoring an invalid annotation.
+func dependsOn(u *unstructured.Unstructured) []object.ObjMetadata {
+	if !dependson.HasAnnotation(u) {
+		return nil
+	}
+	deps, err := dependson.ReadAnnotation(u)
+	if err != nil {
+		return nil
+	}
+	return deps
+}
+
+// mutationSources returns the source objects referenced by the
+// apply-time-mutation annotation of the object, ignoring an invalid
+// annotation.
+func mutationSources(u *unstructured.Unstructured) []object.ObjMetadata {
+	if !mutation.HasAnnotation(u) {
+		return nil
+	}
+	subs, err := mutation.ReadAnnotation(u)
+	if err != nil {
+		return nil
+	}
+	var result []object.ObjMetadata
+	for _, sub := range subs {
+		result = append(result, sub.SourceRef.ToObjMetadata())
+	}
+	return result
+}
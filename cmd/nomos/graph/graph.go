@@ -0,0 +1,216 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph contains the logic for the nomos graph CLI command.
+package graph
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"kpt.dev/configsync/cmd/nomos/flags"
+	nomosparse "kpt.dev/configsync/cmd/nomos/parse"
+	"kpt.dev/configsync/cmd/nomos/util"
+	"kpt.dev/configsync/pkg/client/restconfig"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/hydrate"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var (
+	graphFormat string
+	outPath     string
+	liveStatus  bool
+)
+
+func init() {
+	flags.AddClusters(Cmd)
+	flags.AddPath(Cmd)
+	flags.AddSkipAPIServerCheck(Cmd)
+	flags.AddSourceFormat(Cmd)
+	flags.AddAPIServerTimeout(Cmd)
+	flags.AddJsonnet(Cmd)
+	flags.AddVariables(Cmd)
+	flags.AddKustomize(Cmd)
+	Cmd.Flags().StringVar(&graphFormat, "format", formatDOT,
+		fmt.Sprintf("Output format of the graph. Accepts %q and %q.", formatDOT, formatJSON))
+	Cmd.Flags().StringVar(&outPath, "output", "",
+		"File to write the graph to. Writes to stdout if unset.")
+	Cmd.Flags().BoolVar(&liveStatus, "live-status", false,
+		"If enabled, annotate each object with its status on the cluster of the current context.")
+}
+
+// Cmd is the Cobra object representing the graph command.
+var Cmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Prints the dependency graph which orders the apply and prune of the local repository.",
+	Long: `Prints the dependency graph which orders the apply and prune of the local repository.
+
+The graph holds one node per object declared for each cluster, and an edge from each object to every
+object it is applied after: explicit depends-on and apply-time-mutation dependencies, custom resources
+to their CustomResourceDefinition, namespaced objects to their Namespace, and the ordering of the
+webhook configurations. Dependency cycles are reported as errors with the paths of the involved files,
+and their edges are marked in the graph.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Don't show usage on error, as argument validation passed.
+		cmd.SilenceUsage = true
+
+		if graphFormat != formatDOT && graphFormat != formatJSON {
+			return fmt.Errorf("unsupported format %q, must be %q or %q", graphFormat, formatDOT, formatJSON)
+		}
+
+		sourceFormat := filesystem.SourceFormat(flags.SourceFormat)
+		if sourceFormat == "" {
+			sourceFormat = filesystem.SourceFormatHierarchy
+		}
+		rootDir, needsHydrate, err := hydrate.ValidateHydrateFlags(sourceFormat)
+		if err != nil {
+			return err
+		}
+
+		if needsHydrate {
+			// update rootDir to point to the hydrated output for further processing.
+			if rootDir, err = hydrate.ValidateAndRunKustomize(rootDir.OSPath()); err != nil {
+				return err
+			}
+			// delete the hydrated output directory in the end.
+			defer func() {
+				_ = os.RemoveAll(rootDir.OSPath())
+			}()
+		}
+
+		files, err := nomosparse.FindFiles(rootDir)
+		if err != nil {
+			return err
+		}
+
+		parser := filesystem.NewParser(flags.FileReader())
+
+		options, err := hydrate.ValidateOptions(cmd.Context(), rootDir, flags.APIServerTimeout)
+		if err != nil {
+			return err
+		}
+		options.Variables = flags.Variables
+
+		if sourceFormat == filesystem.SourceFormatHierarchy {
+			files = filesystem.FilterHierarchyFiles(rootDir, files)
+		}
+
+		filePaths := reader.FilePaths{
+			RootDir:   rootDir,
+			PolicyDir: cmpath.RelativeOS(rootDir.OSPath()),
+			Files:     files,
+		}
+
+		var c client.Client
+		if liveStatus {
+			if c, err = liveClient(); err != nil {
+				return err
+			}
+		}
+
+		var graphs []*Graph
+		encounteredError := false
+		hydrate.ForEachCluster(parser, options, sourceFormat, filePaths, func(clusterName string, fileObjects []ast.FileObject, err status.MultiError) {
+			clusterEnabled := flags.AllClusters()
+			for _, cluster := range flags.Clusters {
+				if clusterName == cluster {
+					clusterEnabled = true
+				}
+			}
+			if !clusterEnabled {
+				return
+			}
+			if clusterName == "" {
+				clusterName = nomosparse.UnregisteredCluster
+			}
+
+			if err != nil {
+				util.PrintErrOrDie(errors.Wrapf(err, "errors for Cluster %q", clusterName))
+				encounteredError = true
+				if status.HasBlockingErrors(err) {
+					return
+				}
+			}
+
+			g, graphErrs := buildGraph(clusterName, fileObjects)
+			if graphErrs != nil {
+				util.PrintErrOrDie(errors.Wrapf(graphErrs, "dependency errors for Cluster %q", clusterName))
+				encounteredError = true
+			}
+			if c != nil {
+				if err := addLiveStatus(cmd.Context(), c, g); err != nil {
+					util.PrintErrOrDie(errors.Wrapf(err, "failed to read the live status for Cluster %q", clusterName))
+					encounteredError = true
+				}
+			}
+			graphs = append(graphs, g)
+		})
+
+		if err := printGraphs(graphs); err != nil {
+			return err
+		}
+
+		if encounteredError {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// printGraphs writes the graphs in the requested format to the output file, or
+// to stdout.
+func printGraphs(graphs []*Graph) error {
+	var w io.Writer = os.Stdout
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		w = f
+	}
+	if graphFormat == formatJSON {
+		return writeJSON(w, graphs)
+	}
+	return writeDOT(w, graphs)
+}
+
+// liveClient returns a client for the cluster of the current context.
+func liveClient() (client.Client, error) {
+	cfg, err := restconfig.NewRestConfig(flags.APIServerTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rest config: %w", err)
+	}
+	mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mapper: %w", err)
+	}
+	return client.New(cfg, client.Options{
+		Scheme: core.Scheme,
+		Mapper: mapper,
+	})
+}
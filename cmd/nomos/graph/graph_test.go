@@ -0,0 +1,148 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+var (
+	anvilGVK   = schema.GroupVersionKind{Group: "acme.com", Version: "v1", Kind: "Anvil"}
+	webhookGVK = schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"}
+)
+
+func anvilCRD() ast.FileObject {
+	crd := fake.UnstructuredAtPath(kinds.CustomResourceDefinitionV1(), "cluster/anvil-crd.yaml", core.Name("anvils.acme.com"))
+	_ = unstructured.SetNestedField(crd.Object, "acme.com", "spec", "group")
+	_ = unstructured.SetNestedField(crd.Object, "Anvil", "spec", "names", "kind")
+	return crd
+}
+
+func TestBuildGraph(t *testing.T) {
+	objs := []ast.FileObject{
+		fake.Namespace("namespaces/shipping"),
+		anvilCRD(),
+		fake.UnstructuredAtPath(anvilGVK, "namespaces/shipping/anvil.yaml",
+			core.Name("heavy"), core.Namespace("shipping"),
+			core.Annotation(dependson.Annotation, "/namespaces/shipping/ConfigMap/config")),
+		fake.UnstructuredAtPath(kinds.ConfigMap(), "namespaces/shipping/config.yaml", core.Name("config"), core.Namespace("shipping")),
+		fake.UnstructuredAtPath(webhookGVK, "cluster/webhook.yaml", core.Name("anvil-checks")),
+	}
+
+	got, errs := buildGraph("defaultcluster", objs)
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	want := &Graph{
+		Cluster: "defaultcluster",
+		Nodes: []Node{
+			{ID: "_anvil-checks_admissionregistration.k8s.io_ValidatingWebhookConfiguration", Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration", Name: "anvil-checks", SourcePath: "cluster/webhook.yaml", Wave: 0},
+			{ID: "_anvils.acme.com_apiextensions.k8s.io_CustomResourceDefinition", Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition", Name: "anvils.acme.com", SourcePath: "cluster/anvil-crd.yaml", Wave: 0},
+			{ID: "_shipping__Namespace", Kind: "Namespace", Name: "shipping", SourcePath: "namespaces/shipping/namespace.yaml", Wave: 0},
+			{ID: "shipping_config__ConfigMap", Kind: "ConfigMap", Namespace: "shipping", Name: "config", SourcePath: "namespaces/shipping/config.yaml", Wave: 1},
+			{ID: "shipping_heavy_acme.com_Anvil", Group: "acme.com", Kind: "Anvil", Namespace: "shipping", Name: "heavy", SourcePath: "namespaces/shipping/anvil.yaml", Wave: 2},
+		},
+		Edges: []Edge{
+			{From: "_anvil-checks_admissionregistration.k8s.io_ValidatingWebhookConfiguration", To: "_anvils.acme.com_apiextensions.k8s.io_CustomResourceDefinition", Type: WebhookEdge},
+			{From: "_anvil-checks_admissionregistration.k8s.io_ValidatingWebhookConfiguration", To: "_shipping__Namespace", Type: WebhookEdge},
+			{From: "shipping_config__ConfigMap", To: "_shipping__Namespace", Type: NamespaceEdge},
+			{From: "shipping_heavy_acme.com_Anvil", To: "_anvils.acme.com_apiextensions.k8s.io_CustomResourceDefinition", Type: CRDEdge},
+			{From: "shipping_heavy_acme.com_Anvil", To: "_shipping__Namespace", Type: NamespaceEdge},
+			{From: "shipping_heavy_acme.com_Anvil", To: "shipping_config__ConfigMap", Type: DependsOnEdge},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBuildGraph_Cycle(t *testing.T) {
+	objs := []ast.FileObject{
+		fake.UnstructuredAtPath(kinds.ConfigMap(), "namespaces/foo/a.yaml", core.Name("a"), core.Namespace("foo"),
+			core.Annotation(dependson.Annotation, "/namespaces/foo/ConfigMap/b")),
+		fake.UnstructuredAtPath(kinds.ConfigMap(), "namespaces/foo/b.yaml", core.Name("b"), core.Namespace("foo"),
+			core.Annotation(dependson.Annotation, "/namespaces/foo/ConfigMap/a")),
+		fake.UnstructuredAtPath(kinds.ConfigMap(), "namespaces/foo/c.yaml", core.Name("c"), core.Namespace("foo")),
+	}
+
+	got, errs := buildGraph("defaultcluster", objs)
+	if errs == nil {
+		t.Fatal("got no error, want cyclic dependency error")
+	}
+	msg := errs.Error()
+	for _, want := range []string{
+		"cyclic dependency: /namespaces/foo/ConfigMap/a -> /namespaces/foo/ConfigMap/b; /namespaces/foo/ConfigMap/b -> /namespaces/foo/ConfigMap/a",
+		"namespaces/foo/a.yaml",
+		"namespaces/foo/b.yaml",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("got error %q, want it to contain %q", msg, want)
+		}
+	}
+
+	for _, n := range got.Nodes {
+		wantWave := -1
+		if n.Name == "c" {
+			wantWave = 0
+		}
+		if n.Wave != wantWave {
+			t.Errorf("got wave %d for %s, want %d", n.Wave, n.ID, wantWave)
+		}
+	}
+	for _, e := range got.Edges {
+		if !e.Cyclic {
+			t.Errorf("got edge %s -> %s not cyclic, want cyclic", e.From, e.To)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := &Graph{
+		Cluster: "defaultcluster",
+		Nodes: []Node{
+			{ID: "_foo__Namespace", Kind: "Namespace", Name: "foo", SourcePath: "namespaces/foo/namespace.yaml", Status: "Current"},
+			{ID: "foo_a__ConfigMap", Kind: "ConfigMap", Namespace: "foo", Name: "a", SourcePath: "namespaces/foo/a.yaml"},
+		},
+		Edges: []Edge{
+			{From: "foo_a__ConfigMap", To: "_foo__Namespace", Type: NamespaceEdge, Cyclic: true},
+		},
+	}
+	var b bytes.Buffer
+	if err := writeDOT(&b, []*Graph{g}); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "defaultcluster" {
+  node [shape=box];
+  "_foo__Namespace" [label="Namespace/foo\nnamespaces/foo/namespace.yaml\nstatus: Current", style=filled, fillcolor=palegreen];
+  "foo_a__ConfigMap" [label="ConfigMap/a\nnamespace: foo\nnamespaces/foo/a.yaml"];
+  "foo_a__ConfigMap" -> "_foo__Namespace" [label="namespace", color=red];
+}
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Error(diff)
+	}
+}
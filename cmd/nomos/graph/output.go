@@ -0,0 +1,147 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/health"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// formatDOT prints the graphs in the Graphviz DOT language.
+	formatDOT = "dot"
+	// formatJSON prints the graphs as a JSON list.
+	formatJSON = "json"
+)
+
+// notFoundStatus is the live status of the objects which do not exist.
+const notFoundStatus = "NotFound"
+
+// statusColors are the fill colors of the nodes in DOT by live status.
+var statusColors = map[string]string{
+	kstatus.CurrentStatus.String():     "palegreen",
+	kstatus.InProgressStatus.String():  "lightyellow",
+	kstatus.FailedStatus.String():      "lightpink",
+	kstatus.TerminatingStatus.String(): "lightyellow",
+	notFoundStatus:                     "lightgray",
+}
+
+// writeJSON prints the graphs as an indented JSON list.
+func writeJSON(w io.Writer, graphs []*Graph) error {
+	data, err := json.MarshalIndent(graphs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeDOT prints one DOT digraph per cluster. The edges point from the
+// dependent object to its dependency, and the edges of dependency cycles are
+// colored red.
+func writeDOT(w io.Writer, graphs []*Graph) error {
+	var sb strings.Builder
+	for _, g := range graphs {
+		fmt.Fprintf(&sb, "digraph %s {\n", quote(g.Cluster))
+		sb.WriteString("  node [shape=box];\n")
+		for _, n := range g.Nodes {
+			label := fmt.Sprintf("%s/%s", n.Kind, n.Name)
+			if n.Namespace != "" {
+				label += "\nnamespace: " + n.Namespace
+			}
+			if n.SourcePath != "" {
+				label += "\n" + n.SourcePath
+			}
+			if n.Status != "" {
+				label += "\nstatus: " + n.Status
+			}
+			attrs := []string{"label=" + quote(label)}
+			if color, found := statusColors[n.Status]; found {
+				attrs = append(attrs, "style=filled", "fillcolor="+color)
+			}
+			fmt.Fprintf(&sb, "  %s [%s];\n", quote(n.ID), strings.Join(attrs, ", "))
+		}
+		for _, e := range g.Edges {
+			attrs := []string{"label=" + quote(string(e.Type))}
+			if e.Type == WebhookEdge {
+				attrs = append(attrs, "style=dashed")
+			}
+			if e.Cyclic {
+				attrs = append(attrs, "color=red")
+			}
+			fmt.Fprintf(&sb, "  %s -> %s [%s];\n", quote(e.From), quote(e.To), strings.Join(attrs, ", "))
+		}
+		sb.WriteString("}\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// quote returns the string as a DOT quoted ID.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// addLiveStatus sets the status of the nodes from the state of the objects on
+// the cluster, using the health check rules of the cluster for the kinds which
+// have one and kstatus for the others.
+func addLiveStatus(ctx context.Context, c client.Client, g *Graph) error {
+	rules, errs := health.ReadRules(ctx, c)
+	if errs != nil {
+		return errs
+	}
+	for i, n := range g.Nodes {
+		g.Nodes[i].Status = nodeStatus(ctx, c, rules, n)
+	}
+	return nil
+}
+
+// nodeStatus returns the status of the object of the node, or Unknown if it
+// cannot be computed.
+func nodeStatus(ctx context.Context, c client.Client, rules health.Rules, n Node) string {
+	mapping, err := c.RESTMapper().RESTMapping(schema.GroupKind{Group: n.Group, Kind: n.Kind})
+	if err != nil {
+		return kstatus.UnknownStatus.String()
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: n.Namespace, Name: n.Name}, u); err != nil {
+		if apierrors.IsNotFound(err) {
+			return notFoundStatus
+		}
+		return kstatus.UnknownStatus.String()
+	}
+	result, found, err := rules.Compute(u)
+	if !found {
+		result, err = kstatus.Compute(u)
+	}
+	if err != nil {
+		return kstatus.UnknownStatus.String()
+	}
+	return result.Status.String()
+}
@@ -15,6 +15,7 @@
 package hydrate
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/pkg/errors"
@@ -27,6 +28,7 @@ import (
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/status"
 )
 
@@ -42,6 +44,9 @@ func init() {
 	flags.AddSourceFormat(Cmd)
 	flags.AddOutputFormat(Cmd)
 	flags.AddAPIServerTimeout(Cmd)
+	flags.AddJsonnet(Cmd)
+	flags.AddVariables(Cmd)
+	flags.AddKustomize(Cmd)
 	Cmd.Flags().BoolVar(&flat, "flat", false,
 		`If enabled, print all output to a single file`)
 	Cmd.Flags().StringVar(&outPath, "output", flags.DefaultHydrationOutput,
@@ -96,12 +101,13 @@ which you could kubectl apply -fR to the cluster, or have Config Sync sync to th
 			return err
 		}
 
-		parser := filesystem.NewParser(&reader.File{})
+		parser := filesystem.NewParser(flags.FileReader())
 
 		options, err := hydrate.ValidateOptions(cmd.Context(), rootDir, flags.APIServerTimeout)
 		if err != nil {
 			return err
 		}
+		options.Variables = flags.Variables
 
 		if sourceFormat == filesystem.SourceFormatHierarchy {
 			files = filesystem.FilterHierarchyFiles(rootDir, files)
@@ -141,6 +147,7 @@ which you could kubectl apply -fR to the cluster, or have Config Sync sync to th
 				}
 			}
 
+			printSubstitutions(clusterName, fileObjects)
 			allObjects = append(allObjects, fileObjects...)
 		})
 
@@ -162,3 +169,18 @@ which you could kubectl apply -fR to the cluster, or have Config Sync sync to th
 		return nil
 	},
 }
+
+// printSubstitutions prints the fields of the hydrated objects which had
+// cluster variables substituted, since the annotation recording them is removed
+// from the output.
+func printSubstitutions(clusterName string, fileObjects []ast.FileObject) {
+	if clusterName == "" {
+		clusterName = nomosparse.UnregisteredCluster
+	}
+	for _, obj := range fileObjects {
+		if fields, found := obj.GetAnnotations()[metadata.SubstitutedFieldsAnnotationKey]; found {
+			fmt.Printf("Cluster %q: substituted variables in %s %q from %s: %s\n",
+				clusterName, obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), obj.SlashPath(), fields)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 )
 
@@ -26,6 +27,7 @@ import (
 // we may not be dealing with a git repository. ONLY FOR USE IN THE CLI.
 //
 // Tries git first, and falls back to using `find` if git does not work.
+// Files excluded by .configsyncignore files are left out.
 //
 // Guaranteed to return the same files as ListFiles in git repo with no
 // uncommitted changes (see tests for findFiles)
@@ -44,5 +46,6 @@ func FindFiles(dir cmpath.Absolute) ([]cmpath.Absolute, error) {
 		}
 		result = append(result, p)
 	}
-	return result, nil
+	result, _, err = filesystem.FilterIgnoredFiles(dir, result)
+	return result, err
 }
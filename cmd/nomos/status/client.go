@@ -44,6 +44,7 @@ import (
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/client/restconfig"
 	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/health"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -162,9 +163,27 @@ func (c *ClusterClient) clusterStatus(ctx context.Context, cluster, namespace st
 	} else {
 		c.monoRepoClusterStatus(ctx, cs)
 	}
+	if resourceStatus {
+		c.resourceHealth(ctx, cs)
+	}
 	return cs
 }
 
+// resourceHealth updates the status of the managed resources which have a
+// health check rule in the health checks ConfigMap.
+func (c *ClusterClient) resourceHealth(ctx context.Context, cs *ClusterState) {
+	rules, errs := health.ReadRules(ctx, c.Client)
+	if errs != nil {
+		klog.Warningf("Failed to read the health check rules: %v", errs)
+	}
+	if len(rules) == 0 {
+		return
+	}
+	for _, repo := range cs.repos {
+		applyHealthChecks(ctx, c.Client, rules, repo.resources)
+	}
+}
+
 // monoRepoClusterStatus populates the given ClusterState with the sync status of
 // the mono repo on the ClusterClient's cluster.
 func (c *ClusterClient) monoRepoClusterStatus(ctx context.Context, cs *ClusterState) {
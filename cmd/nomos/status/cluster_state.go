@@ -83,7 +83,9 @@ type RepoState struct {
 	errors            []string
 	// errorSummary summarizes the `errors` field.
 	errorSummary *v1beta1.ErrorSummary
-	resources    []resourceState
+	// changeSummary summarizes the changes made by the commit being synced.
+	changeSummary *v1beta1.ChangeSummary
+	resources     []resourceState
 }
 
 func (r *RepoState) printRows(writer io.Writer) {
@@ -94,6 +96,11 @@ func (r *RepoState) printRows(writer io.Writer) {
 		fmt.Fprintf(writer, "%s%s\t%s\t\n", util.Indent, r.status, r.commit)
 	}
 
+	if r.changeSummary != nil {
+		fmt.Fprintf(writer, "%sChanges: %d to create, %d to update, %d to prune\n", util.Indent,
+			r.changeSummary.CreateCount, r.changeSummary.UpdateCount, r.changeSummary.PruneCount)
+	}
+
 	if r.errorSummary != nil && r.errorSummary.TotalCount > 0 {
 		if r.errorSummary.Truncated {
 			fmt.Fprintf(writer, "%sTotalErrorCount: %d, ErrorTruncated: %v, ErrorCountAfterTruncation: %d\n", util.Indent,
@@ -311,6 +318,7 @@ func namespaceRepoStatus(rs *v1beta1.RepoSync, rg *unstructured.Unstructured, sy
 		helm:       reposync.GetHelmBase(rs.Spec.Helm),
 		commit:     emptyCommit,
 	}
+	repostate.changeSummary = rs.Status.Sync.ChangeSummary
 
 	stalledCondition := reposync.GetCondition(rs.Status.Conditions, v1beta1.RepoSyncStalled)
 	reconcilingCondition := reposync.GetCondition(rs.Status.Conditions, v1beta1.RepoSyncReconciling)
@@ -413,6 +421,7 @@ func RootRepoStatus(rs *v1beta1.RootSync, rg *unstructured.Unstructured, syncing
 		helm:       rootsync.GetHelmBase(rs.Spec.Helm),
 		commit:     emptyCommit,
 	}
+	repostate.changeSummary = rs.Status.Sync.ChangeSummary
 	stalledCondition := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncStalled)
 	reconcilingCondition := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncReconciling)
 	syncingCondition := rootsync.GetCondition(rs.Status.Conditions, v1beta1.RootSyncSyncing)
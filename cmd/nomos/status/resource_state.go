@@ -15,11 +15,16 @@
 package status
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/health"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
@@ -118,3 +123,32 @@ func checkConflict(states []resourceState) []resourceState {
 
 	return states
 }
+
+// applyHealthChecks replaces the status of the resources which have a health
+// check rule with the health computed from their current state on the
+// cluster. The status of a resource is kept if it cannot be read.
+func applyHealthChecks(ctx context.Context, c client.Client, rules health.Rules, states []resourceState) {
+	for i, s := range states {
+		gk := schema.GroupKind{Group: s.Group, Kind: s.Kind}
+		if _, found := rules[gk]; !found || s.Status == "Conflict" {
+			continue
+		}
+		mapping, err := c.RESTMapper().RESTMapping(gk)
+		if err != nil {
+			klog.Warningf("Failed to check the health of %s: %v", s, err)
+			continue
+		}
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(mapping.GroupVersionKind)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: s.Name}, u); err != nil {
+			klog.Warningf("Failed to check the health of %s: %v", s, err)
+			continue
+		}
+		result, _, err := rules.Compute(u)
+		if err != nil {
+			klog.Warningf("Failed to check the health of %s: %v", s, err)
+			continue
+		}
+		states[i].Status = result.Status.String()
+	}
+}
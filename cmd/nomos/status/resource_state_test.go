@@ -15,10 +15,18 @@
 package status
 
 import (
+	"context"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/health"
+	syncertestfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
 )
 
 func TestResourceState(t *testing.T) {
@@ -84,3 +92,34 @@ func TestResourceState(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestApplyHealthChecks(t *testing.T) {
+	running := fake.PodObject("running", nil, core.Namespace("bookstore"))
+	running.Status.Phase = corev1.PodRunning
+	pending := fake.PodObject("pending", nil, core.Namespace("bookstore"))
+	pending.Status.Phase = corev1.PodPending
+	c := syncertestfake.NewClient(t, runtime.NewScheme(), running, pending)
+
+	rules := health.Rules{
+		schema.GroupKind{Kind: "Pod"}: {Current: `self.status.phase == "Running"`},
+	}
+	states := []resourceState{
+		{Kind: "Pod", Namespace: "bookstore", Name: "running", Status: "Unknown"},
+		{Kind: "Pod", Namespace: "bookstore", Name: "pending", Status: "Current"},
+		{Kind: "Pod", Namespace: "bookstore", Name: "missing", Status: "NotFound"},
+		{Kind: "Pod", Namespace: "bookstore", Name: "conflict", Status: "Conflict"},
+		{Kind: "Service", Namespace: "bookstore", Name: "test", Status: "Current"},
+	}
+	applyHealthChecks(context.Background(), c, rules, states)
+
+	want := []resourceState{
+		{Kind: "Pod", Namespace: "bookstore", Name: "running", Status: "Current"},
+		{Kind: "Pod", Namespace: "bookstore", Name: "pending", Status: "InProgress"},
+		{Kind: "Pod", Namespace: "bookstore", Name: "missing", Status: "NotFound"},
+		{Kind: "Pod", Namespace: "bookstore", Name: "conflict", Status: "Conflict"},
+		{Kind: "Service", Namespace: "bookstore", Name: "test", Status: "Current"},
+	}
+	if diff := cmp.Diff(want, states); diff != "" {
+		t.Error(diff)
+	}
+}
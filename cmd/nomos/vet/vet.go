@@ -35,6 +35,9 @@ func init() {
 	flags.AddSourceFormat(Cmd)
 	flags.AddOutputFormat(Cmd)
 	flags.AddAPIServerTimeout(Cmd)
+	flags.AddJsonnet(Cmd)
+	flags.AddVariables(Cmd)
+	flags.AddKustomize(Cmd)
 	Cmd.Flags().StringVar(&namespaceValue, "namespace", "",
 		fmt.Sprintf(
 			"If set, validate the repository as a Namespace Repo with the provided name. Automatically sets --source-format=%s",
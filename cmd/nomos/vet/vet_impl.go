@@ -80,12 +80,13 @@ func runVet(ctx context.Context, namespace string, sourceFormat filesystem.Sourc
 		return err
 	}
 
-	parser := filesystem.NewParser(&reader.File{})
+	parser := filesystem.NewParser(flags.FileReader())
 
 	options, err := hydrate.ValidateOptions(ctx, rootDir, apiServerTimeout)
 	if err != nil {
 		return err
 	}
+	options.Variables = flags.Variables
 
 	switch sourceFormat {
 	case filesystem.SourceFormatHierarchy:
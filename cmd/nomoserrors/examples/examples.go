@@ -22,9 +22,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"kpt.dev/configsync/pkg/api/configmanagement"
 	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/health"
 	"kpt.dev/configsync/pkg/importer/analyzer/hnc"
 	"kpt.dev/configsync/pkg/importer/analyzer/transform/selectors"
 	"kpt.dev/configsync/pkg/importer/analyzer/validation"
@@ -40,11 +42,14 @@ import (
 	"kpt.dev/configsync/pkg/kinds"
 	csmetadata "kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/parse"
+	"kpt.dev/configsync/pkg/protection"
+	"kpt.dev/configsync/pkg/reposync"
 	"kpt.dev/configsync/pkg/status"
 	"kpt.dev/configsync/pkg/syncer/client"
 	"kpt.dev/configsync/pkg/syncer/reconcile"
 	"kpt.dev/configsync/pkg/testing/fake"
 	"kpt.dev/configsync/pkg/util/clusterconfig"
+	"kpt.dev/configsync/pkg/validate/raw/hydrate"
 	"kpt.dev/configsync/pkg/validate/raw/validate"
 	"kpt.dev/configsync/pkg/vet"
 	"kpt.dev/configsync/pkg/webhook/configuration"
@@ -322,6 +327,34 @@ func Generate() AllExamples {
 	// 1069
 	result.add(validate.SelfReconcileError(fake.RootSyncV1Beta1(configsync.RootSyncName)))
 
+	// 1070
+	result.add(validate.InvalidIgnoreDifferencesAnnotationError(fake.Deployment("namespaces/foo"),
+		errors.New("field /metadata/name cannot be ignored")))
+
+	// 1071
+	result.add(reader.EncryptedFileError(cmpath.RelativeSlash("namespaces/foo/secret.yaml"), "SOPS"))
+	result.add(reader.DecryptionError(cmpath.RelativeSlash("namespaces/foo/secret.yaml.age"), "age",
+		errors.New("no identity matched any of the recipients")))
+
+	// 1072
+	result.add(hydrate.UndefinedVariableError(fake.Deployment("namespaces/foo"), "cluster.labels.region"))
+
+	// 1073
+	result.add(validate.InvalidSecretFromAnnotationError(fake.Deployment("namespaces/foo"),
+		errors.New("only Secrets may reference secret data")))
+
+	// 1074
+	result.add(validate.InvalidDeletionPolicyAnnotationError(fake.PersistentVolumeObject(
+		core.Annotation(csmetadata.DeletionPolicyAnnotationKey, "Orphan"))))
+
+	// 1075
+	result.add(validate.InvalidAdoptionAnnotationError(fake.RoleObject(
+		core.Annotation(csmetadata.AdoptionAnnotationKey, "IfUnmanaged"))))
+
+	// 1076
+	result.add(validate.InvalidDelegationAnnotationError(fake.Deployment("namespaces/foo"),
+		errors.New("only RepoSyncs may delegate namespaces")))
+
 	// 2001
 	result.add(status.PathWrapError(errors.New("error creating directory"), "namespaces/foo"))
 
@@ -372,6 +405,34 @@ func Generate() AllExamples {
 	// 2015
 	result.add(status.InternalHydrationError(errors.New("internal rendering error"), "internal rendering error"))
 
+	// 2016
+	result.add(applier.PruneBudgetExceededError(applier.PruneBudget{MaxObjects: 1}, "abc123", 10,
+		[]core.ID{core.IDOf(fake.Role(core.Name("foo"), core.Namespace("bar"))), core.IDOf(fake.Role(core.Name("baz"), core.Namespace("bar")))},
+		configsync.RootSyncKind))
+
+	// 2017
+	result.add(status.FieldManagerConflictError(fake.Deployment("namespaces/foo"),
+		[]v1beta1.FieldManagerConflict{{FieldManager: "kubectl-client-side-apply", Fields: []string{".spec.replicas"}}}))
+
+	// 2018
+	result.add(health.InvalidHealthCheckError("Widget.example.com", errors.New("the current expression must be set")))
+
+	// 2019
+	result.add(protection.InvalidProtectionRuleError("keep", errors.New("the kind must be set with the group \"example.com\"")))
+
+	// 2020
+	result.add(status.ProtectedResourceError(fake.NamespaceObject("kube-system"), "default:kube-system"))
+
+	// 2021
+	result.add(status.AdoptionPreventedError(fake.Role(), "ifUnmanaged", "helm:default/example"))
+
+	// 2022
+	result.add(reposync.UndefinedTemplateVariableError(fake.RepoSyncTemplateObject("tenants"), "shop", []string{"namespace.labels.team"}))
+
+	// 2023
+	result.add(status.ForcedFieldManagerConflictError(fake.Deployment("namespaces/foo"),
+		[]v1beta1.FieldManagerConflict{{FieldManager: "kubectl-client-side-apply", Fields: []string{".spec.replicas"}}}))
+
 	// 9998
 	result.add(status.InternalError("we made a mistake"))
 
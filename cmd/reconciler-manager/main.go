@@ -21,6 +21,7 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -33,6 +34,7 @@ import (
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/reconcilermanager/controllers"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -48,6 +50,18 @@ var (
 		controllers.PollingPeriod(reconcilermanager.HydrationPollingPeriod, configsync.DefaultHydrationPollingPeriod),
 		"Period of time between checking the filesystem for source updates to render.")
 
+	renderCacheClaim = flag.String("render-cache-claim", "",
+		"Name of the PersistentVolumeClaim in the config-management-system namespace, which holds the render cache shared by the hydration-controllers. The RootSyncs, and the RepoSyncs of each namespace, only share a directory of the claim. The render cache is disabled if empty.")
+
+	renderCacheSize = flag.String("render-cache-size", "1Gi",
+		"Storage requested by the render cache PersistentVolumeClaim, if it does not exist yet.")
+
+	renderCacheStorageClass = flag.String("render-cache-storage-class", "",
+		"StorageClass of the render cache PersistentVolumeClaim, if it does not exist yet. It must support the ReadWriteMany access mode.")
+
+	traceSamplingFraction = flag.Float64("trace-sampling-fraction", 0,
+		"The probability of sampling the trace spans of the reconcilers and the hydration-controllers. Tracing is disabled if 0.")
+
 	setupLog = ctrl.Log.WithName("setup")
 )
 
@@ -83,7 +97,25 @@ func main() {
 	}
 	watchFleetMembership := fleetMembershipCRDExists(dynamicClient, mgr.GetRESTMapper())
 
-	repoSync := controllers.NewRepoSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, mgr.GetClient(), dynamicClient,
+	if *renderCacheClaim != "" {
+		size, err := resource.ParseQuantity(*renderCacheSize)
+		if err != nil {
+			setupLog.Error(err, "invalid --render-cache-size")
+			os.Exit(1)
+		}
+		// The manager's client reads from its cache, which is not started yet.
+		c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			setupLog.Error(err, "failed to build client")
+			os.Exit(1)
+		}
+		if err := controllers.EnsureRenderCacheClaim(context.Background(), c, *renderCacheClaim, *renderCacheStorageClass, size); err != nil {
+			setupLog.Error(err, "failed to provision the render cache")
+			os.Exit(1)
+		}
+	}
+
+	repoSync := controllers.NewRepoSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, *renderCacheClaim, *traceSamplingFraction, mgr.GetClient(), dynamicClient,
 		ctrl.Log.WithName("controllers").WithName(configsync.RepoSyncKind),
 		mgr.GetScheme())
 	if err := repoSync.SetupWithManager(mgr, watchFleetMembership); err != nil {
@@ -91,7 +123,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	rootSync := controllers.NewRootSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, mgr.GetClient(), dynamicClient,
+	rootSync := controllers.NewRootSyncReconciler(*clusterName, *reconcilerPollingPeriod, *hydrationPollingPeriod, *renderCacheClaim, *traceSamplingFraction, mgr.GetClient(), dynamicClient,
 		ctrl.Log.WithName("controllers").WithName(configsync.RootSyncKind),
 		mgr.GetScheme())
 	if err := rootSync.SetupWithManager(mgr, watchFleetMembership); err != nil {
@@ -99,6 +131,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	repoSyncTemplate := controllers.NewRepoSyncTemplateReconciler(mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(configsync.RepoSyncTemplateKind),
+		mgr.GetScheme())
+	if err := repoSyncTemplate.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", configsync.RepoSyncTemplateKind)
+		os.Exit(1)
+	}
+
 	otel := controllers.NewOtelReconciler(*clusterName, mgr.GetClient(),
 		ctrl.Log.WithName("controllers").WithName("Otel"),
 		mgr.GetScheme())
@@ -32,6 +32,8 @@ import (
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/reconcilermanager/controllers"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/tracing"
+	"kpt.dev/configsync/pkg/util"
 	"kpt.dev/configsync/pkg/util/log"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -90,6 +92,32 @@ var (
 
 	apiServerTimeout = flag.String("api-server-timeout", os.Getenv(reconcilermanager.APIServerTimeout), "The client-side timeout for requests to the API server")
 
+	ignoreDifferences = flag.String("ignore-differences", os.Getenv(reconcilermanager.IgnoreDifferences),
+		"JSON encoded list of rules selecting fields of declared objects which are owned by another controller")
+
+	pruneBudgetMaxObjects = flag.Int("prune-budget-max-objects", util.EnvInt(reconcilermanager.PruneBudgetMaxObjects, 0),
+		"The maximum number of objects which may be pruned by a single commit without acknowledgment. 0 means no limit.")
+	pruneBudgetMaxPercentage = flag.Int("prune-budget-max-percentage", util.EnvInt(reconcilermanager.PruneBudgetMaxPercentage, 0),
+		"The maximum percentage of the inventory which may be pruned by a single commit without acknowledgment. 0 means no limit.")
+
+	conflictPolicy = flag.String("conflict-policy", os.Getenv(reconcilermanager.ConflictPolicy),
+		"JSON encoded policy deciding which fields owned by other field managers are taken over")
+
+	scopeNamespaces = flag.String("scope-namespaces", os.Getenv(reconcilermanager.ScopeNamespaces),
+		"Comma-separated list of the other namespaces which a namespace reconciler may sync objects to")
+
+	adoptionPolicy = flag.String("adoption-policy", os.Getenv(reconcilermanager.AdoptionPolicy),
+		"Policy deciding whether declared objects which already exist are adopted: never, ifUnmanaged or always")
+
+	jsonnetConfig = flag.String("jsonnet-config", os.Getenv(reconcilermanager.JsonnetConfig),
+		"JSON encoded configuration of the evaluation of the Jsonnet files in the source")
+
+	notificationConfig = flag.String("notification-config", os.Getenv(reconcilermanager.NotificationConfig),
+		"JSON encoded list of the HTTP endpoints to which the notifications of the sync results are posted")
+
+	traceSamplingFraction = flag.Float64("trace-sampling-fraction", util.EnvFloat(reconcilermanager.TraceSamplingFraction, 0),
+		"The probability of sampling the trace spans of the reconciliation. Tracing is disabled if 0.")
+
 	debug = flag.Bool("debug", false,
 		"Enable debug mode, panicking in many scenarios where normally an InternalError would be logged. "+
 			"Do not use in production.")
@@ -139,6 +167,9 @@ func main() {
 		}
 	}()
 
+	// Export the trace spans with the OC Agent exporter
+	tracing.Register(oce, *traceSamplingFraction)
+
 	absRepoRoot, err := cmpath.AbsoluteOS(*repoRootDir)
 	if err != nil {
 		klog.Fatalf("%s must be an absolute path: %v", flags.repoRootDir, err)
@@ -160,29 +191,45 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	var namespaces []string
+	if *scopeNamespaces != "" {
+		namespaces = strings.Split(*scopeNamespaces, ",")
+	}
+
 	opts := reconciler.Options{
-		ClusterName:             *clusterName,
-		FightDetectionThreshold: *fightDetectionThreshold,
-		NumWorkers:              *workers,
-		ReconcilerScope:         declared.Scope(*scope),
-		ResyncPeriod:            *resyncPeriod,
-		PollingPeriod:           *pollingPeriod,
-		RetryPeriod:             configsync.DefaultReconcilerRetryPeriod,
-		StatusUpdatePeriod:      configsync.DefaultReconcilerSyncStatusUpdatePeriod,
-		SourceRoot:              absSourceDir,
-		RepoRoot:                absRepoRoot,
-		HydratedRoot:            *hydratedRootDir,
-		HydratedLink:            *hydratedLinkDir,
-		SourceRev:               *sourceRev,
-		SourceBranch:            *sourceBranch,
-		SourceType:              v1beta1.SourceType(*sourceType),
-		SourceRepo:              *sourceRepo,
-		SyncDir:                 relSyncDir,
-		SyncName:                *syncName,
-		ReconcilerName:          *reconcilerName,
-		StatusMode:              *statusMode,
-		ReconcileTimeout:        *reconcileTimeout,
-		APIServerTimeout:        *apiServerTimeout,
+		ClusterName:              *clusterName,
+		FightDetectionThreshold:  *fightDetectionThreshold,
+		NumWorkers:               *workers,
+		ReconcilerScope:          declared.Scope(*scope),
+		ScopeNamespaces:          namespaces,
+		ResyncPeriod:             *resyncPeriod,
+		PollingPeriod:            *pollingPeriod,
+		RetryPeriod:              configsync.DefaultReconcilerRetryPeriod,
+		StatusUpdatePeriod:       configsync.DefaultReconcilerSyncStatusUpdatePeriod,
+		SourceRoot:               absSourceDir,
+		RepoRoot:                 absRepoRoot,
+		HydratedRoot:             *hydratedRootDir,
+		HydratedLink:             *hydratedLinkDir,
+		SourceRev:                *sourceRev,
+		SourceBranch:             *sourceBranch,
+		SourceType:               v1beta1.SourceType(*sourceType),
+		SourceRepo:               *sourceRepo,
+		SyncDir:                  relSyncDir,
+		SyncName:                 *syncName,
+		ReconcilerName:           *reconcilerName,
+		StatusMode:               *statusMode,
+		ReconcileTimeout:         *reconcileTimeout,
+		APIServerTimeout:         *apiServerTimeout,
+		IgnoreDifferences:        *ignoreDifferences,
+		PruneBudgetMaxObjects:    *pruneBudgetMaxObjects,
+		PruneBudgetMaxPercentage: *pruneBudgetMaxPercentage,
+		ConflictPolicy:           *conflictPolicy,
+		AdoptionPolicy:           *adoptionPolicy,
+		JsonnetConfig:            *jsonnetConfig,
+		NotificationConfig:       *notificationConfig,
+		// The key is not a flag, so it does not show in the command line.
+		NotificationHMACKey: os.Getenv(reconcilermanager.NotificationHMACKey),
+		DecryptionAgeKey:    os.Getenv(reconcilermanager.DecryptionAgeKey),
 	}
 
 	if declared.Scope(*scope) == declared.RootReconciler {
@@ -192,12 +239,20 @@ func main() {
 			format = filesystem.SourceFormatHierarchy
 		}
 
+		if len(namespaces) > 0 {
+			klog.Fatalf("Flag scope-namespaces and Environment variable %q must not be passed to a Root reconciler",
+				reconcilermanager.ScopeNamespaces)
+		}
+
 		klog.Info("Starting reconciler for: root")
 		opts.RootOptions = &reconciler.RootOptions{
 			SourceFormat: format,
 		}
 	} else {
 		klog.Infof("Starting reconciler for: %s", *scope)
+		if len(namespaces) > 0 {
+			klog.Infof("Managing the other namespaces: %s", *scopeNamespaces)
+		}
 
 		if *sourceFormat != "" {
 			klog.Fatalf("Flag %s and Environment variable%q must not be passed to a Namespace reconciler",
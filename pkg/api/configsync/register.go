@@ -40,6 +40,8 @@ const (
 	RepoSyncKind = "RepoSync"
 	// RootSyncKind is the kind of the RepoSync resource.
 	RootSyncKind = "RootSync"
+	// RepoSyncTemplateKind is the kind of the RepoSyncTemplate resource.
+	RepoSyncTemplateKind = "RepoSyncTemplate"
 )
 
 const (
@@ -0,0 +1,50 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+const (
+	// ConflictModeForce takes ownership of all conflicting fields.
+	ConflictModeForce = "Force"
+	// ConflictModeReport refuses to take ownership of conflicting fields, unless
+	// forced by the ConflictPolicy, and reports the conflicts.
+	ConflictModeReport = "Report"
+)
+
+// ConflictPolicy configures how Config Sync resolves server-side apply
+// conflicts with other field managers.
+type ConflictPolicy struct {
+	// mode is either "Force" or "Report".
+	// In Force mode, Config Sync takes ownership of all conflicting fields,
+	// and reports them in the sync status as non-blocking errors.
+	// In Report mode, Config Sync refuses to take ownership of conflicting
+	// fields, unless forced by forceFieldManagers or forceFields, and reports
+	// the conflicts in the sync status.
+	// Default: Force.
+	// +kubebuilder:validation:Enum=Force;Report
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// forceFieldManagers is a list of field managers from which Config Sync
+	// always takes ownership of conflicting fields, even in Report mode.
+	// +optional
+	ForceFieldManagers []string `json:"forceFieldManagers,omitempty"`
+
+	// forceFields is a list of fields which Config Sync always takes ownership
+	// of, even in Report mode, in the server-side apply notation used by
+	// fieldConflicts, e.g. ".spec.replicas".
+	// Conflicts on these fields and their children are forced.
+	// +optional
+	ForceFields []string `json:"forceFields,omitempty"`
+}
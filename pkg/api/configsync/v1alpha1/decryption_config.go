@@ -0,0 +1,26 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// DecryptionConfig configures how the encrypted files in the source are
+// decrypted.
+type DecryptionConfig struct {
+	// secretRef refers to a Secret in the namespace of the RootSync or RepoSync
+	// with the key "age.agekey", which holds the age identities decrypting the
+	// SOPS encrypted documents and the age encrypted files in the source.
+	// Encrypted files are rejected if it is unset.
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
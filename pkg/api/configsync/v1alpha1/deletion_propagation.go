@@ -0,0 +1,60 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// DeletionPropagation configures which managed objects are deleted when the
+// RootSync or RepoSync is deleted with deletion propagation enabled.
+type DeletionPropagation struct {
+	// orphan lists the kinds of the managed objects which are orphaned instead
+	// of deleted, such as PersistentVolumeClaims or Namespaces. An object with
+	// the `configsync.gke.io/deletion-policy: delete` annotation is deleted
+	// anyway.
+	// +optional
+	Orphan []DeletionOrphanRule `json:"orphan,omitempty"`
+}
+
+// DeletionOrphanRule selects the kind of the managed objects which are
+// orphaned instead of deleted.
+type DeletionOrphanRule struct {
+	// group is the API group of the objects.
+	// Leave empty to match objects in the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// kind is the kind of the objects.
+	Kind string `json:"kind"`
+}
+
+// DeletionPreview lists the managed resources which deletion propagation would
+// delete and orphan if the RootSync or RepoSync was deleted. The lists of
+// resources are capped, while the counts are not.
+type DeletionPreview struct {
+	// deleteCount is the number of managed resources which would be deleted.
+	// +optional
+	DeleteCount int `json:"deleteCount,omitempty"`
+	// orphanCount is the number of managed resources which would be orphaned.
+	// +optional
+	OrphanCount int `json:"orphanCount,omitempty"`
+	// delete lists the resources which would be deleted.
+	// +optional
+	Delete []ResourceRef `json:"delete,omitempty"`
+	// orphan lists the resources which would be orphaned.
+	// +optional
+	Orphan []ResourceRef `json:"orphan,omitempty"`
+	// truncated indicates whether the lists of resources do not include all
+	// the managed resources.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// IgnoreDifference selects fields of matching objects which Config Sync
+// neither applies nor reverts, because another controller legitimately owns
+// them (e.g. spec.replicas managed by a HorizontalPodAutoscaler).
+type IgnoreDifference struct {
+	// group is the API group of the objects to match.
+	// Leave empty to match objects in the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// kind is the kind of the objects to match.
+	Kind string `json:"kind"`
+
+	// name is the name of the object to match.
+	// If empty, all objects of the kind are matched.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// namespace is the namespace of the objects to match.
+	// If empty, objects in all namespaces are matched.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// jsonPointers is a list of RFC 6901 JSON pointers to ignored fields,
+	// e.g. "/spec/replicas".
+	// +optional
+	JSONPointers []string `json:"jsonPointers,omitempty"`
+
+	// jsonPaths is a list of dot-notation JSONPath expressions to ignored
+	// fields, e.g. ".spec.replicas" or
+	// ".metadata.annotations['sidecar.istio.io/status']".
+	// +optional
+	JSONPaths []string `json:"jsonPaths,omitempty"`
+}
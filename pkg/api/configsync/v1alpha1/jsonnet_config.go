@@ -0,0 +1,29 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// JsonnetConfig configures how the Jsonnet files in the source are evaluated.
+type JsonnetConfig struct {
+	// libPaths is a list of directories, relative to the sync directory, which
+	// are searched for imported Jsonnet libraries.
+	// +optional
+	LibPaths []string `json:"libPaths,omitempty"`
+
+	// extVars is a map of external variables passed to the Jsonnet files,
+	// which read them with std.extVar. The clusterName variable is always set
+	// to the name of the cluster.
+	// +optional
+	ExtVars map[string]string `json:"extVars,omitempty"`
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// KustomizeConfig restricts the remote sources the kustomizations in the
+// source may load when they are rendered.
+type KustomizeConfig struct {
+	// disableRemoteBases rejects kustomizations that load remote resources,
+	// bases or components, e.g. a Git repository URL.
+	// +optional
+	DisableRemoteBases bool `json:"disableRemoteBases,omitempty"`
+
+	// allowedRemoteURLs is a list of URL prefixes, e.g.
+	// "github.com/example/configs" or "https://charts.example.com". When it is
+	// set, every remote base and Helm chart repository referenced by the
+	// kustomizations must match one of the prefixes.
+	// +optional
+	AllowedRemoteURLs []string `json:"allowedRemoteURLs,omitempty"`
+}
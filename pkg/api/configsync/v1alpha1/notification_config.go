@@ -0,0 +1,59 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// The sync results of which notifications are sent.
+const (
+	// NotificationSyncSucceeded is sent when a commit is synced without errors.
+	NotificationSyncSucceeded = "SyncSucceeded"
+	// NotificationSyncFailed is sent when the sync of a commit fails.
+	NotificationSyncFailed = "SyncFailed"
+	// NotificationStalled is sent when a commit cannot be synced because it
+	// cannot be fetched, read or rendered.
+	NotificationStalled = "Stalled"
+	// NotificationConflict is sent when a managed object is declared in the
+	// source of another reconciler.
+	NotificationConflict = "Conflict"
+	// NotificationRollback is sent when a commit which was synced before is
+	// synced again in place of a later commit.
+	NotificationRollback = "Rollback"
+)
+
+// NotificationConfig configures the notifications of the sync results, which
+// the reconciler posts as CloudEvents to HTTP endpoints.
+type NotificationConfig struct {
+	// endpoints are the HTTP endpoints to which the notifications are posted.
+	// +optional
+	Endpoints []NotificationEndpoint `json:"endpoints,omitempty"`
+
+	// secretRef refers to a Secret in the namespace of the RootSync or RepoSync
+	// with the key "hmacKey". When it is set, the body of every notification is
+	// signed with HMAC-SHA256 and the signature is sent in the
+	// X-Config-Sync-Signature header, like "sha256=<hex digest>".
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+// NotificationEndpoint is an HTTP endpoint to which notifications are posted.
+type NotificationEndpoint struct {
+	// url is the HTTP or HTTPS URL to which the notifications are posted.
+	URL string `json:"url"`
+
+	// events filters the sync results which are posted to the endpoint, among
+	// SyncSucceeded, SyncFailed, Stalled, Conflict and Rollback. All of them are
+	// posted if it is empty.
+	// +optional
+	Events []string `json:"events,omitempty"`
+}
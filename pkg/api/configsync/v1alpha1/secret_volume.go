@@ -0,0 +1,29 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretVolume configures the volume mounted in the root reconciler, whose
+// files are referenced by the `file:` references of the
+// configsync.gke.io/secret-from annotation.
+type SecretVolume struct {
+	// csi is the CSI volume mounted read-only under /etc/config-sync/secrets
+	// in the reconciler container, such as a SecretProviderClass of the
+	// Secrets Store CSI driver in the config-management-system namespace.
+	CSI corev1.CSIVolumeSource `json:"csi"`
+}
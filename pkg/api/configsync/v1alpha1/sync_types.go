@@ -49,6 +49,12 @@ type Status struct {
 	// source of truth to the cluster.
 	// +optional
 	Sync SyncStatus `json:"sync,omitempty"`
+
+	// deletionPreview lists the managed resources which deletion propagation
+	// would delete and orphan. It is only set while the
+	// `configsync.gke.io/deletion-propagation-policy` annotation is DryRun.
+	// +optional
+	DeletionPreview *DeletionPreview `json:"deletionPreview,omitempty"`
 }
 
 // SourceStatus describes the source status of a source-of-truth.
@@ -155,6 +161,16 @@ type SyncStatus struct {
 	// errorSummary summarizes the errors encountered during the process of syncing the resources.
 	// +optional
 	ErrorSummary *ErrorSummary `json:"errorSummary,omitempty"`
+
+	// changeSummary summarizes the changes to the declared resources made by
+	// the commit being synced, compared to the previously synced commit.
+	// +optional
+	ChangeSummary *ChangeSummary `json:"changeSummary,omitempty"`
+
+	// adoptions lists the most recent pre-existing resources adopted by the
+	// RootSync or RepoSync, oldest first, when an adoption policy is set.
+	// +optional
+	Adoptions []Adoption `json:"adoptions,omitempty"`
 }
 
 // GitStatus describes the status of a Git source of truth.
@@ -208,6 +224,22 @@ type ConfigSyncError struct {
 	// errorResources describes the resources associated with this error, if any.
 	// +optional
 	Resources []ResourceRef `json:"errorResources,omitempty"`
+
+	// fieldConflicts describes the fields of the resources associated with this
+	// error which are owned by other field managers, if any.
+	// +optional
+	FieldConflicts []FieldManagerConflict `json:"fieldConflicts,omitempty"`
+}
+
+// FieldManagerConflict describes fields of a managed resource which are owned
+// by another field manager with a conflicting value.
+type FieldManagerConflict struct {
+	// fieldManager is the name of the field manager which owns the fields.
+	FieldManager string `json:"fieldManager"`
+
+	// fields are the paths of the conflicting fields, in server-side apply
+	// notation, e.g. ".spec.replicas".
+	Fields []string `json:"fields"`
 }
 
 // ErrorSummary summarizes the errors encountered.
@@ -224,6 +256,39 @@ type ErrorSummary struct {
 	ErrorCountAfterTruncation int `json:"errorCountAfterTruncation,omitempty"`
 }
 
+// ChangeSummary summarizes the changes to the declared resources made by a
+// commit. The lists of resources are capped, while the counts are not.
+type ChangeSummary struct {
+	// commit is the hash of the source of truth the changes were computed for.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+	// createCount is the number of resources declared by the commit which were
+	// not previously declared.
+	// +optional
+	CreateCount int `json:"createCount,omitempty"`
+	// updateCount is the number of resources whose declaration was changed by
+	// the commit.
+	// +optional
+	UpdateCount int `json:"updateCount,omitempty"`
+	// pruneCount is the number of previously declared resources which are no
+	// longer declared by the commit.
+	// +optional
+	PruneCount int `json:"pruneCount,omitempty"`
+	// create lists the resources to be created.
+	// +optional
+	Create []ResourceRef `json:"create,omitempty"`
+	// update lists the resources to be updated.
+	// +optional
+	Update []ResourceRef `json:"update,omitempty"`
+	// prune lists the resources to be pruned.
+	// +optional
+	Prune []ResourceRef `json:"prune,omitempty"`
+	// truncated indicates whether the lists of resources do not include all
+	// the changed resources.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
 // ResourceRef contains the identification bits of a single managed resource.
 type ResourceRef struct {
 	// sourcePath is the repo-relative slash path to where the config is defined.
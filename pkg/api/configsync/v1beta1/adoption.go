@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+const (
+	// AdoptionNever refuses to apply declared objects which already exist on
+	// the cluster and are not managed by the RootSync or RepoSync.
+	AdoptionNever = "never"
+	// AdoptionIfUnmanaged adopts pre-existing objects, unless they are managed
+	// by Helm, another RootSync or RepoSync, or another inventory.
+	AdoptionIfUnmanaged = "ifUnmanaged"
+	// AdoptionAlways adopts pre-existing objects, whatever manages them, and
+	// removes the ownership metadata of their previous manager.
+	AdoptionAlways = "always"
+)
+
+// Adoption records a pre-existing resource which was adopted by the
+// RootSync or RepoSync.
+type Adoption struct {
+	// resource is the adopted resource.
+	Resource ResourceRef `json:"resource"`
+
+	// previousManager identifies what managed the resource before it was
+	// adopted, e.g. "helm:<namespace>/<release>", "configsync:<manager>",
+	// "inventory:<id>" or "kubectl". Empty if the resource was unmanaged.
+	// +optional
+	PreviousManager string `json:"previousManager,omitempty"`
+
+	// removedMetadata lists the labels and annotations of the previous
+	// manager which were removed from the resource when it was adopted.
+	// +optional
+	RemovedMetadata []string `json:"removedMetadata,omitempty"`
+
+	// commit is the hash of the source of truth being synced when the
+	// resource was adopted.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// time is when the resource was adopted.
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
+}
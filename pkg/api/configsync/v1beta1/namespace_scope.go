@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// NamespaceScope lists the namespaces, in addition to its own namespace, which
+// a RepoSync may sync objects to. The namespace reconciler is bound to the
+// `configsync.gke.io:ns-reconciler-scope` ClusterRole in each of them, and a
+// single reconciler Deployment and inventory serve all of them.
+type NamespaceScope struct {
+	// names is a list of namespaces in the scope.
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// selector selects namespaces in the scope by their labels.
+	// An empty selector selects no namespace.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+// PruneBudget limits how many managed objects a single commit may prune.
+// When a commit would prune more objects than the budget allows, the
+// reconciler refuses to prune, reports an error listing the objects, and waits
+// for the prune to be acknowledged by setting the
+// `configsync.gke.io/prune-budget-ack` annotation on the RootSync or RepoSync
+// to the hash of that commit.
+type PruneBudget struct {
+	// maxObjects is the maximum number of objects which may be pruned by a
+	// single commit. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxObjects int64 `json:"maxObjects,omitempty"`
+
+	// maxPercentage is the maximum percentage of the objects in the inventory
+	// which may be pruned by a single commit. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxPercentage int32 `json:"maxPercentage,omitempty"`
+}
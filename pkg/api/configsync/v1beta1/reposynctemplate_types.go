@@ -0,0 +1,120 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="RepoSync",type="string",JSONPath=".spec.repoSyncName"
+// +kubebuilder:printcolumn:name="Namespaces",type="integer",JSONPath=".status.namespaceCount"
+// +kubebuilder:printcolumn:name="Errors",type="integer",JSONPath=".status.errorSummary.totalCount"
+
+// RepoSyncTemplate is the Schema for the reposynctemplates API. It stamps out
+// a RepoSync, with its RBAC and Secrets, in every Namespace matching its
+// namespace selector.
+type RepoSyncTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec RepoSyncTemplateSpec `json:"spec,omitempty"`
+	// +optional
+	Status RepoSyncTemplateStatus `json:"status,omitempty"`
+}
+
+// RepoSyncTemplateSpec defines the desired state of a RepoSyncTemplate.
+type RepoSyncTemplateSpec struct {
+	// namespaceSelector selects the Namespaces in which a RepoSync is created.
+	// An empty selector selects no Namespace.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// repoSyncName is the name of the RepoSync created in each selected
+	// Namespace. Default: repo-sync.
+	// +kubebuilder:default:=repo-sync
+	// +optional
+	RepoSyncName string `json:"repoSyncName,omitempty"`
+
+	// template is the spec of the RepoSync created in each selected
+	// Namespace. The references to `${namespace.name}`,
+	// `${namespace.labels.<key>}` and `${namespace.annotations.<key>}` in its
+	// string values are replaced by the name, labels and annotations of the
+	// Namespace. A reference preceded by an extra `$` is escaped.
+	Template RepoSyncSpec `json:"template"`
+
+	// clusterRole is the name of the ClusterRole granted to the namespace
+	// reconciler of each created RepoSync in its Namespace, with a RoleBinding.
+	// Default: no permissions are granted besides the base permissions of
+	// namespace reconcilers.
+	// +optional
+	ClusterRole string `json:"clusterRole,omitempty"`
+
+	// secrets are the Secrets copied from the config-management-system
+	// namespace to each selected Namespace, such as the authentication Secret
+	// referenced by the template.
+	// +optional
+	Secrets []RepoSyncTemplateSecret `json:"secrets,omitempty"`
+}
+
+// RepoSyncTemplateSecret is a Secret copied to each selected Namespace.
+type RepoSyncTemplateSecret struct {
+	// name is the name of the Secret in the selected Namespaces.
+	Name string `json:"name"`
+
+	// sourceName is the name of the Secret in the config-management-system
+	// namespace whose type and data are copied. Default: the name.
+	// +optional
+	SourceName string `json:"sourceName,omitempty"`
+}
+
+// RepoSyncTemplateStatus defines the observed state of a RepoSyncTemplate.
+type RepoSyncTemplateStatus struct {
+	// observedGeneration is the most recent generation observed for the
+	// RepoSyncTemplate.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// namespaces are the selected Namespaces in which the RepoSync is
+	// created, sorted by name.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// namespaceCount is the number of selected Namespaces.
+	// +optional
+	NamespaceCount int `json:"namespaceCount,omitempty"`
+
+	// errors are the errors which occurred while creating the RepoSyncs, their
+	// RBAC and Secrets.
+	// +optional
+	Errors []ConfigSyncError `json:"errors,omitempty"`
+
+	// errorSummary summarizes the errors.
+	// +optional
+	ErrorSummary *ErrorSummary `json:"errorSummary,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepoSyncTemplateList contains a list of RepoSyncTemplate
+type RepoSyncTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepoSyncTemplate `json:"items"`
+}
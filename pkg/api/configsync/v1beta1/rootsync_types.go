@@ -77,6 +77,69 @@ type RootSyncSpec struct {
 	// +nullable
 	// +optional
 	Override *OverrideSpec `json:"override,omitempty"`
+
+	// ignoreDifferences is a list of rules selecting fields of declared
+	// objects which are owned by another controller. Matching fields are
+	// stripped from the declared objects before they are applied, so Config Sync
+	// neither sets them, reverts drift on them, nor protects them in the
+	// admission webhook.
+	// +optional
+	IgnoreDifferences []IgnoreDifference `json:"ignoreDifferences,omitempty"`
+
+	// pruneBudget limits how many managed objects a single commit may prune,
+	// to protect against accidental mass deletion.
+	// +optional
+	PruneBudget *PruneBudget `json:"pruneBudget,omitempty"`
+
+	// conflictPolicy configures how Config Sync resolves server-side apply
+	// conflicts with other field managers, such as kubectl, Helm or operators.
+	// When unset, Config Sync takes ownership of all conflicting fields without
+	// reporting them.
+	// +optional
+	ConflictPolicy *ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// jsonnet configures how the Jsonnet files in the source are evaluated.
+	// +optional
+	Jsonnet *JsonnetConfig `json:"jsonnet,omitempty"`
+
+	// decryption configures the keys which decrypt the SOPS and age encrypted
+	// files in the source.
+	// +optional
+	Decryption *DecryptionConfig `json:"decryption,omitempty"`
+
+	// secretVolume configures the volume of the files referenced by the
+	// `file:` references of the configsync.gke.io/secret-from annotation.
+	// The `file:` references fail to resolve if it is unset.
+	// +optional
+	SecretVolume *SecretVolume `json:"secretVolume,omitempty"`
+
+	// kustomize restricts the remote sources the kustomizations in the source
+	// may load when they are rendered.
+	// +optional
+	Kustomize *KustomizeConfig `json:"kustomize,omitempty"`
+
+	// notifications configures the HTTP endpoints to which the results of the
+	// sync are posted as CloudEvents.
+	// +optional
+	Notifications *NotificationConfig `json:"notifications,omitempty"`
+
+	// deletionPropagation configures which managed objects are orphaned
+	// instead of deleted when deletion propagation is enabled.
+	// +optional
+	DeletionPropagation *DeletionPropagation `json:"deletionPropagation,omitempty"`
+
+	// adoption decides whether declared objects which already exist on the
+	// cluster, and are not yet managed by this RootSync, are adopted.
+	// "never" refuses to apply them. "ifUnmanaged" adopts them unless they
+	// are managed by Helm, another RootSync or RepoSync, or another inventory.
+	// "always" adopts them and removes the ownership metadata of their
+	// previous manager. The configsync.gke.io/adoption annotation overrides
+	// this policy for a single object.
+	// Default: the pre-existing objects are adopted without being checked or
+	// reported.
+	// +kubebuilder:validation:Enum=never;ifUnmanaged;always
+	// +optional
+	Adoption string `json:"adoption,omitempty"`
 }
 
 // RootSyncStatus defines the observed state of RootSync
@@ -0,0 +1,269 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/diff"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxAdoptions is the maximum number of adoptions remembered by the
+// supervisor and reported in the sync status. The oldest are dropped first.
+const maxAdoptions = 50
+
+// ParseAdoptionPolicy validates the spec.adoption field of a RootSync or
+// RepoSync, as passed to the reconciler by the reconciler-manager.
+func ParseAdoptionPolicy(s string) (metadata.AdoptionPolicy, error) {
+	switch policy := metadata.AdoptionPolicy(s); policy {
+	case "", metadata.AdoptionPolicyNever, metadata.AdoptionPolicyIfUnmanaged, metadata.AdoptionPolicyAlways:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("adoption must be %q, %q or %q, got %q",
+			metadata.AdoptionPolicyNever, metadata.AdoptionPolicyIfUnmanaged, metadata.AdoptionPolicyAlways, s)
+	}
+}
+
+// adoption is a pre-existing object which is adopted by the next apply.
+type adoption struct {
+	// policy is the effective adoption policy of the object.
+	policy metadata.AdoptionPolicy
+	// previousManager is the manager of the object before it is adopted, as
+	// described by diff.PreviousManager.
+	previousManager string
+	// labels and annotations are the keys of the ownership metadata of the
+	// previous manager, which are removed once the object is adopted.
+	labels, annotations []string
+	// uid is the UID of the live object, which the adoption Event refers to.
+	uid types.UID
+}
+
+// checkAdoptions looks up the live objects of the declared objects which have
+// an adoption policy and are not in the inventory yet. The objects which the
+// policy prevents adopting are reported and excluded from the returned
+// objects. The other pre-existing objects are returned as adoptions, by id.
+//
+// Without an adoption policy, neither the spec.adoption field nor the
+// adoption annotation, no object is looked up, and all objects are adopted.
+// Returns an error if the inventory cannot be read.
+func (a *supervisor) checkAdoptions(ctx context.Context, objs []client.Object) ([]client.Object, map[object.ObjMetadata]adoption, status.MultiError, error) {
+	var checked []client.Object
+	for _, obj := range objs {
+		if diff.EffectiveAdoptionPolicy(obj, a.adoptionPolicy) != "" {
+			checked = append(checked, obj)
+		}
+	}
+	if len(checked) == 0 {
+		return objs, nil, nil, nil
+	}
+
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	inInventory := make(map[object.ObjMetadata]bool, len(invObjs))
+	for _, id := range invObjs {
+		inInventory[id] = true
+	}
+
+	var errs status.MultiError
+	rejected := make(map[object.ObjMetadata]bool)
+	adoptions := make(map[object.ObjMetadata]adoption)
+	for _, obj := range checked {
+		id := ObjMetaFromObject(obj)
+		if inInventory[id] {
+			continue
+		}
+		live, err := a.getLiveObject(ctx, id)
+		if err != nil {
+			errs = status.Append(errs, ErrorForResource(err, core.IDOf(obj)))
+			rejected[id] = true
+			continue
+		}
+		if live == nil || !diff.NeedsAdoption(a.scope(), a.syncName, obj, live) {
+			continue
+		}
+		policy := diff.EffectiveAdoptionPolicy(obj, a.adoptionPolicy)
+		previousManager, managed := diff.PreviousManager(a.scope(), a.syncName, obj, live)
+		if !diff.CanAdopt(policy, managed) {
+			errs = status.Append(errs, status.AdoptionPreventedError(obj, string(policy), previousManager))
+			rejected[id] = true
+			continue
+		}
+		if policy == metadata.AdoptionPolicyAlways {
+			// The applier refuses to adopt objects of another inventory,
+			// unless their owning-inventory annotation is removed first.
+			if err := a.removeOwningInventory(ctx, obj, live); err != nil {
+				errs = status.Append(errs, ErrorForResource(err, core.IDOf(obj)))
+				rejected[id] = true
+				continue
+			}
+		}
+		adopted := adoption{policy: policy, previousManager: previousManager, uid: live.GetUID()}
+		if policy == metadata.AdoptionPolicyAlways && diff.IsHelmManaged(live) {
+			adopted.labels, adopted.annotations = helmMetadata(obj, live)
+		}
+		adoptions[id] = adopted
+	}
+	if len(rejected) == 0 {
+		return objs, adoptions, errs, nil
+	}
+	var result []client.Object
+	for _, obj := range objs {
+		if !rejected[ObjMetaFromObject(obj)] {
+			result = append(result, obj)
+		}
+	}
+	return result, adoptions, errs, nil
+}
+
+// removeOwningInventory removes the owning-inventory annotation of the live
+// object, if it is owned by another inventory.
+func (a *supervisor) removeOwningInventory(ctx context.Context, decl client.Object, live *unstructured.Unstructured) error {
+	inventoryID := core.GetAnnotation(live, metadata.OwningInventoryKey)
+	if inventoryID == "" || inventoryID == a.inventory.ID() {
+		return nil
+	}
+	klog.Infof("Removing the owning-inventory annotation %q of %v to adopt it", inventoryID, core.GKNN(decl))
+	return a.removeMetadata(ctx, live, nil, []string{metadata.OwningInventoryKey})
+}
+
+// recordAdoption removes the ownership metadata of the previous manager from
+// the adopted object, if any, and records the adoption in the status and with
+// an Event.
+func (a *supervisor) recordAdoption(ctx context.Context, obj *unstructured.Unstructured, adopted adoption, commit string) status.Error {
+	var removed []string
+	if len(adopted.labels) > 0 || len(adopted.annotations) > 0 {
+		if err := a.removeMetadata(ctx, obj, adopted.labels, adopted.annotations); err != nil {
+			return ErrorForResource(fmt.Errorf("failed to remove the ownership metadata of the previous manager: %w", err), core.IDOf(obj))
+		}
+		removed = append(removed, adopted.labels...)
+		removed = append(removed, adopted.annotations...)
+	}
+	klog.Infof("Adopted %v, previously managed by %q", core.GKNN(obj), adopted.previousManager)
+	live := obj.DeepCopy()
+	live.SetUID(adopted.uid)
+	a.clientSet.Events.Adopted(ctx, live, adopted.previousManager)
+
+	gvk := obj.GroupVersionKind()
+	a.addAdoption(v1beta1.Adoption{
+		Resource: v1beta1.ResourceRef{
+			SourcePath: status.GetSourceAnnotation(obj),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+			GVK: metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+		},
+		PreviousManager: adopted.previousManager,
+		RemovedMetadata: removed,
+		Commit:          commit,
+		Time:            metav1.Now(),
+	})
+	return nil
+}
+
+// helmMetadata returns the keys of the Helm ownership labels and annotations
+// of the live object, except those which are declared.
+func helmMetadata(decl client.Object, live *unstructured.Unstructured) (labels, annotations []string) {
+	if _, isDeclared := decl.GetLabels()[metadata.ManagedByKey]; !isDeclared &&
+		core.GetLabel(live, metadata.ManagedByKey) == metadata.HelmManagedByValue {
+		labels = append(labels, metadata.ManagedByKey)
+	}
+	for _, key := range []string{metadata.HelmReleaseNameAnnotationKey, metadata.HelmReleaseNamespaceAnnotationKey} {
+		_, isDeclared := decl.GetAnnotations()[key]
+		if _, found := live.GetAnnotations()[key]; found && !isDeclared {
+			annotations = append(annotations, key)
+		}
+	}
+	return labels, annotations
+}
+
+// removeMetadata removes the given labels and annotations from the live
+// object with a JSON merge patch, as they are owned by other field managers.
+func (a *supervisor) removeMetadata(ctx context.Context, obj *unstructured.Unstructured, labels, annotations []string) error {
+	meta := make(map[string]interface{})
+	if len(labels) > 0 {
+		removed := make(map[string]interface{})
+		for _, key := range labels {
+			removed[key] = nil
+		}
+		meta["labels"] = removed
+	}
+	if len(annotations) > 0 {
+		removed := make(map[string]interface{})
+		for _, key := range annotations {
+			removed[key] = nil
+		}
+		meta["annotations"] = removed
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": meta})
+	if err != nil {
+		return err
+	}
+	target := &unstructured.Unstructured{}
+	target.SetGroupVersionKind(obj.GroupVersionKind())
+	target.SetName(obj.GetName())
+	target.SetNamespace(obj.GetNamespace())
+	return a.clientSet.Client.Patch(ctx, target, client.RawPatch(types.MergePatchType, patch))
+}
+
+// addAdoption remembers an adoption, dropping the oldest adoptions beyond
+// maxAdoptions.
+func (a *supervisor) addAdoption(record v1beta1.Adoption) {
+	a.adoptionMux.Lock()
+	defer a.adoptionMux.Unlock()
+	a.adoptions = append(a.adoptions, record)
+	if len(a.adoptions) > maxAdoptions {
+		a.adoptions = a.adoptions[len(a.adoptions)-maxAdoptions:]
+	}
+}
+
+// Adoptions returns the pre-existing objects adopted since the supervisor
+// was started, oldest first.
+// Adoptions implements the Applier interface.
+func (a *supervisor) Adoptions() []v1beta1.Adoption {
+	a.adoptionMux.RLock()
+	defer a.adoptionMux.RUnlock()
+	var result []v1beta1.Adoption
+	for _, record := range a.adoptions {
+		result = append(result, *record.DeepCopy())
+	}
+	return result
+}
+
+// scope returns the scope of the reconciler of the supervisor.
+func (a *supervisor) scope() declared.Scope {
+	if a.syncKind == configsync.RootSyncKind {
+		return declared.RootReconciler
+	}
+	return declared.Scope(a.syncNamespace)
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestParseAdoptionPolicy(t *testing.T) {
+	for _, s := range []string{"", "never", "ifUnmanaged", "always"} {
+		policy, err := ParseAdoptionPolicy(s)
+		require.NoError(t, err)
+		assert.Equal(t, metadata.AdoptionPolicy(s), policy)
+	}
+	_, err := ParseAdoptionPolicy("IfUnmanaged")
+	assert.Error(t, err)
+}
+
+func TestAdoptions(t *testing.T) {
+	ns := core.Namespace("test-namespace")
+	helm := []core.MetaMutator{
+		ns,
+		core.Label(metadata.ManagedByKey, metadata.HelmManagedByValue),
+		core.Annotation(metadata.HelmReleaseNameAnnotationKey, "example"),
+		core.Annotation(metadata.HelmReleaseNamespaceAnnotationKey, "test-namespace"),
+	}
+	created := fake.ConfigMapObject(ns, core.Name("created"))
+	inventoried := fake.ConfigMapObject(ns, core.Name("inventoried"))
+	kubectl := fake.ConfigMapObject(ns, core.Name("kubectl"), core.Annotation(corev1.LastAppliedConfigAnnotation, "{}"))
+	helmRejected := fake.ConfigMapObject(append(helm, core.Name("helm-rejected"))...)
+	helmAdopted := fake.ConfigMapObject(append(helm, core.Name("helm-adopted"))...)
+	neverAdopted := fake.ConfigMapObject(ns, core.Name("never"))
+
+	fakeClient := testingfake.NewClient(t, core.Scheme, inventoried, kubectl, helmRejected, helmAdopted, neverAdopted)
+	cs := &ClientSet{
+		Client:    fakeClient,
+		Mapper:    fakeClient.RESTMapper(),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{ObjMetaFromObject(inventoried)}),
+	}
+	s, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{AdoptionPolicy: metadata.AdoptionPolicyIfUnmanaged})
+	require.NoError(t, err)
+	a := s.(*supervisor)
+
+	// The declared objects, with their adoption annotations.
+	declared := []client.Object{
+		created,
+		inventoried,
+		kubectl,
+		helmRejected,
+		fake.ConfigMapObject(ns, core.Name("helm-adopted"), core.Annotation(metadata.AdoptionAnnotationKey, "always")),
+		fake.ConfigMapObject(ns, core.Name("never"), core.Annotation(metadata.AdoptionAnnotationKey, "never")),
+	}
+	objs, adoptions, errs, err := a.checkAdoptions(context.Background(), declared)
+	require.NoError(t, err)
+
+	var applied []string
+	for _, obj := range objs {
+		applied = append(applied, obj.GetName())
+	}
+	assert.Equal(t, []string{"created", "inventoried", "kubectl", "helm-adopted"}, applied)
+	assert.Equal(t, map[object.ObjMetadata]adoption{
+		ObjMetaFromObject(kubectl): {
+			policy:          metadata.AdoptionPolicyIfUnmanaged,
+			previousManager: "kubectl",
+			uid:             "1",
+		},
+		ObjMetaFromObject(helmAdopted): {
+			policy:          metadata.AdoptionPolicyAlways,
+			previousManager: "helm:test-namespace/example",
+			labels:          []string{metadata.ManagedByKey},
+			annotations:     []string{metadata.HelmReleaseNameAnnotationKey, metadata.HelmReleaseNamespaceAnnotationKey},
+			uid:             "1",
+		},
+	}, adoptions)
+	var codes []string
+	for _, err := range errs.Errors() {
+		codes = append(codes, err.(status.Error).Code())
+	}
+	assert.Equal(t, []string{status.AdoptionPreventedErrorCode, status.AdoptionPreventedErrorCode}, codes)
+
+	// Record the adoption of the Helm object, once applied.
+	u, err := toUnstructured([]client.Object{objs[3]})
+	require.NoError(t, err)
+	require.NoError(t, a.recordAdoption(context.Background(), u[0], adoptions[ObjMetaFromObject(helmAdopted)], "abc123"))
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(helmAdopted.GroupVersionKind())
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(helmAdopted), live))
+	assert.Empty(t, live.GetLabels())
+	assert.NotContains(t, live.GetAnnotations(), metadata.HelmReleaseNameAnnotationKey)
+	assert.NotContains(t, live.GetAnnotations(), metadata.HelmReleaseNamespaceAnnotationKey)
+
+	records := a.Adoptions()
+	require.Len(t, records, 1)
+	assert.Equal(t, "helm-adopted", records[0].Resource.Name)
+	assert.Equal(t, "helm:test-namespace/example", records[0].PreviousManager)
+	assert.Equal(t, []string{metadata.ManagedByKey, metadata.HelmReleaseNameAnnotationKey, metadata.HelmReleaseNamespaceAnnotationKey}, records[0].RemovedMetadata)
+	assert.Equal(t, "abc123", records[0].Commit)
+}
@@ -30,18 +30,18 @@ import (
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/api/configmanagement"
 	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier/stats"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
-	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/metadata"
 	m "kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/resourcegroup"
 	"kpt.dev/configsync/pkg/status"
-	"kpt.dev/configsync/pkg/syncer/differ"
 	"kpt.dev/configsync/pkg/syncer/metrics"
 	"kpt.dev/configsync/pkg/util"
 	nomosutil "kpt.dev/configsync/pkg/util"
+	"kpt.dev/configsync/pkg/util/fieldmanager"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/apply"
 	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
@@ -69,10 +69,24 @@ var (
 type Applier interface {
 	// Apply creates, updates, or prunes all managed resources, depending on
 	// the new desired resource objects.
+	// The commit is the source commit the desired resource objects were read
+	// from, which is used to acknowledge prunes exceeding the prune budget.
 	// Returns the set of GVKs which were successfully applied and any errors.
 	// This is called by the reconciler when changes are detected in the
 	// source of truth (git, OCI, helm) and periodically.
-	Apply(ctx context.Context, desiredResources []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError)
+	Apply(ctx context.Context, desiredResources []client.Object, commit string) (map[schema.GroupVersionKind]struct{}, status.MultiError)
+	// SecretReferencesChanged returns true if the secret data referenced by
+	// the last applied objects changed since they were applied, so they need
+	// to be applied again.
+	SecretReferencesChanged(ctx context.Context) bool
+	// PruneBlocked returns the managed objects which applying the desired
+	// resource objects of the commit would prune, but whose prune is blocked
+	// by the prune budget until the commit is acknowledged.
+	PruneBlocked(ctx context.Context, desiredResources []client.Object, commit string) []core.ID
+	// Adoptions returns the most recent pre-existing objects adopted by
+	// Apply, oldest first. Adoptions are only checked and recorded for
+	// objects with an adoption policy.
+	Adoptions() []v1beta1.Adoption
 	// Errors returns the errors encountered during apply.
 	// This method may be called while Destroy is running, to get the set of
 	// errors encounted so far.
@@ -82,11 +96,18 @@ type Applier interface {
 // Destroyer is a bulk client for deleting all the managed resource objects
 // tracked in a single ResourceGroup inventory.
 type Destroyer interface {
-	// Destroy deletes all managed resources.
+	// Destroy deletes all managed resources, except the resources orphaned
+	// by the options, the protected resources, and the resources with a
+	// deletion-policy annotation which orphans them.
 	// Returns any errors encountered while destorying.
 	// This is called by the reconciler finalizer when deletion propagation is
 	// enabled.
-	Destroy(ctx context.Context) status.MultiError
+	Destroy(ctx context.Context, opts DestroyOptions) status.MultiError
+	// PlanDestroy returns the managed resources which Destroy would delete
+	// and orphan with the given options, without changing anything.
+	// This is called by the reconciler finalizer when deletion propagation is
+	// in dry-run mode.
+	PlanDestroy(ctx context.Context, opts DestroyOptions) (DestroyPlan, status.MultiError)
 	// Errors returns the errors encountered during destroy.
 	// This method may be called while Destroy is running, to get the set of
 	// errors encounted so far.
@@ -123,6 +144,27 @@ type supervisor struct {
 	syncNamespace string
 	// reconcileTimeout controls the reconcile and prune timeout
 	reconcileTimeout time.Duration
+	// pruneBudget limits how many objects a single commit may prune
+	pruneBudget PruneBudget
+	// conflictPolicy decides which fields owned by other field managers
+	// are taken over
+	conflictPolicy fieldmanager.ConflictPolicy
+	// adoptionPolicy decides whether pre-existing objects are adopted, unless
+	// overridden by the adoption annotation of an object
+	adoptionPolicy metadata.AdoptionPolicy
+	// secretResolver resolves the secret references of the applied objects
+	secretResolver *SecretResolver
+	// secretRefObjs are the last applied objects which reference secret data,
+	// as declared
+	secretRefObjs []*unstructured.Unstructured
+	// secretRefsDigest is the digest of the secret data resolved for
+	// secretRefObjs
+	secretRefsDigest string
+
+	// adoptionMux prevents concurrent modifications to the adoptions
+	adoptionMux sync.RWMutex
+	// adoptions are the most recent objects adopted by Apply, oldest first
+	adoptions []v1beta1.Adoption
 
 	// execMux prevents concurrent Apply/Destroy calls
 	execMux sync.Mutex
@@ -137,18 +179,30 @@ var _ Applier = &supervisor{}
 var _ Destroyer = &supervisor{}
 var _ Supervisor = &supervisor{}
 
+// SupervisorOptions configures how a Supervisor applies and prunes objects.
+type SupervisorOptions struct {
+	// PruneBudget limits how many objects a commit may prune without
+	// acknowledgment.
+	PruneBudget PruneBudget
+	// ConflictPolicy decides which field managers lose the fields in conflict
+	// with the applied objects.
+	ConflictPolicy fieldmanager.ConflictPolicy
+	// AdoptionPolicy decides which pre-existing objects may be adopted.
+	AdoptionPolicy metadata.AdoptionPolicy
+}
+
 // NewSupervisor constructs either a cluster-level or namespace-level Supervisor,
 // based on the specified scope.
-func NewSupervisor(cs *ClientSet, scope declared.Scope, syncName string, reconcileTimeout time.Duration) (Supervisor, error) {
+func NewSupervisor(cs *ClientSet, scope declared.Scope, syncName string, reconcileTimeout time.Duration, opts SupervisorOptions) (Supervisor, error) {
 	if scope == declared.RootReconciler {
-		return NewRootSupervisor(cs, syncName, reconcileTimeout)
+		return NewRootSupervisor(cs, syncName, reconcileTimeout, opts)
 	}
-	return NewNamespaceSupervisor(cs, scope, syncName, reconcileTimeout)
+	return NewNamespaceSupervisor(cs, scope, syncName, reconcileTimeout, opts)
 }
 
 // NewNamespaceSupervisor constructs a Supervisor that can manage resource
 // objects in a single namespace.
-func NewNamespaceSupervisor(cs *ClientSet, namespace declared.Scope, syncName string, reconcileTimeout time.Duration) (Supervisor, error) {
+func NewNamespaceSupervisor(cs *ClientSet, namespace declared.Scope, syncName string, reconcileTimeout time.Duration, opts SupervisorOptions) (Supervisor, error) {
 	syncKind := configsync.RepoSyncKind
 	invObj := newInventoryUnstructured(syncKind, syncName, string(namespace), cs.StatusMode)
 	// If the ResourceGroup object exists, annotate the status mode on the
@@ -170,6 +224,10 @@ func NewNamespaceSupervisor(cs *ClientSet, namespace declared.Scope, syncName st
 		syncName:         syncName,
 		syncNamespace:    string(namespace),
 		reconcileTimeout: reconcileTimeout,
+		pruneBudget:      opts.PruneBudget,
+		conflictPolicy:   opts.ConflictPolicy,
+		adoptionPolicy:   opts.AdoptionPolicy,
+		secretResolver:   NewSecretResolver(cs.Client, SecretMountDir),
 	}
 	klog.V(4).Infof("Namespace Supervisor %s/%s is initialized", namespace, syncName)
 	return a, nil
@@ -177,7 +235,7 @@ func NewNamespaceSupervisor(cs *ClientSet, namespace declared.Scope, syncName st
 
 // NewRootSupervisor constructs a Supervisor that can manage both cluster-level
 // and namespace-level resource objects in a single cluster.
-func NewRootSupervisor(cs *ClientSet, syncName string, reconcileTimeout time.Duration) (Supervisor, error) {
+func NewRootSupervisor(cs *ClientSet, syncName string, reconcileTimeout time.Duration, opts SupervisorOptions) (Supervisor, error) {
 	syncKind := configsync.RootSyncKind
 	u := newInventoryUnstructured(syncKind, syncName, configmanagement.ControllerNamespace, cs.StatusMode)
 	// If the ResourceGroup object exists, annotate the status mode on the
@@ -199,6 +257,10 @@ func NewRootSupervisor(cs *ClientSet, syncName string, reconcileTimeout time.Dur
 		syncName:         syncName,
 		syncNamespace:    string(configmanagement.ControllerNamespace),
 		reconcileTimeout: reconcileTimeout,
+		pruneBudget:      opts.PruneBudget,
+		conflictPolicy:   opts.ConflictPolicy,
+		adoptionPolicy:   opts.AdoptionPolicy,
+		secretResolver:   NewSecretResolver(cs.Client, SecretMountDir),
 	}
 	klog.V(4).Infof("Root Supervisor %s is initialized and synced with the API server", syncName)
 	return a, nil
@@ -381,17 +443,17 @@ func (a *supervisor) processDeleteEvent(ctx context.Context, e event.DeleteEvent
 // handleDeleteSkippedEvent translates from prune skip or delete skip event into
 // a resource error.
 func (a *supervisor) handleDeleteSkippedEvent(ctx context.Context, eventType event.Type, obj *unstructured.Unstructured, id core.ID, err error) status.Error {
-	// Disable protected namespaces that were removed from the desired object set.
-	if isNamespace(obj) && differ.SpecialNamespaces[obj.GetName()] {
+	// Disable protected objects that were removed from the desired object set.
+	if rule, protected := a.clientSet.Protection.Protects(obj); protected {
 		// the `client.lifecycle.config.k8s.io/deletion: detach` annotation is not a part of the Config Sync metadata, and will not be removed here.
 		err := a.disableObject(ctx, obj)
 		handleMetrics(ctx, "unmanage", err, id.WithVersion(""))
 		if err != nil {
-			errorMsg := "failed to remove the Config Sync metadata from %v (which is a special namespace): %v"
-			klog.Errorf(errorMsg, id, err)
-			return applierErrorBuilder.Wrap(fmt.Errorf(errorMsg, id, err)).Build()
+			errorMsg := "failed to remove the Config Sync metadata from %v (which is protected by the rule %q): %v"
+			klog.Errorf(errorMsg, id, rule, err)
+			return applierErrorBuilder.Wrap(fmt.Errorf(errorMsg, id, rule, err)).Build()
 		}
-		klog.V(4).Infof("removed the Config Sync metadata from %v (which is a special namespace)", id)
+		klog.V(4).Infof("removed the Config Sync metadata from %v (which is protected by the rule %q)", id, rule)
 	}
 
 	var depErr *filter.DependencyPreventedActuationError
@@ -436,10 +498,6 @@ func (a *supervisor) handleDeleteSkippedEvent(ctx context.Context, eventType eve
 	return SkipErrorForResource(err, id, actuation.ActuationStrategyDelete)
 }
 
-func isNamespace(obj *unstructured.Unstructured) bool {
-	return obj.GetObjectKind().GroupVersionKind().GroupKind() == kinds.Namespace().GroupKind()
-}
-
 func handleMetrics(ctx context.Context, operation string, err error, gvk schema.GroupVersionKind) {
 	// TODO capture the apply duration in the kpt apply library.
 	start := time.Now()
@@ -468,7 +526,7 @@ func (a *supervisor) checkInventoryObjectSize(ctx context.Context, c client.Clie
 }
 
 // applyInner triggers a kpt live apply library call to apply a set of resources.
-func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
+func (a *supervisor) applyInner(ctx context.Context, objs []client.Object, commit string) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
 	a.checkInventoryObjectSize(ctx, a.clientSet.Client)
 
 	s := stats.NewSyncStats()
@@ -488,6 +546,18 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 			Succeeded: disabledCount,
 		}
 	}
+	// Skip the pre-existing objects which the adoption policy prevents
+	// adopting. The adopted objects are recorded once they are applied.
+	enabledObjs, adoptions, adoptionErrs, err := a.checkAdoptions(ctx, enabledObjs)
+	if err != nil {
+		a.addError(Error(fmt.Errorf("failed to check the pre-existing objects against the adoption policy: %w", err)))
+		return nil, a.Errors()
+	}
+	if adoptionErrs != nil {
+		for _, err := range adoptionErrs.Errors() {
+			a.addError(err)
+		}
+	}
 	klog.Infof("%v objects to be applied: %v", len(enabledObjs), core.GKNNs(enabledObjs))
 	resources, err := toUnstructured(enabledObjs)
 	if err != nil {
@@ -495,12 +565,22 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 		return nil, a.Errors()
 	}
 
+	resources, skippedSecretRefs := a.resolveSecretReferences(ctx, resources)
+
+	a.resolveFieldConflicts(ctx, resources)
+
 	unknownTypeResources := make(map[core.ID]struct{})
+	declaredObjs := make(map[object.ObjMetadata]*unstructured.Unstructured, len(resources))
+	for _, r := range resources {
+		declaredObjs[object.UnstructuredToObjMetadata(r)] = r
+	}
 	options := apply.ApplierOptions{
 		ServerSideOptions: common.ServerSideOptions{
 			ServerSideApply: true,
-			ForceConflicts:  true,
-			FieldManager:    configsync.FieldManager,
+			// Conflicts are only reported when the conflict policy does not
+			// force ownership of all conflicting fields.
+			ForceConflicts: a.conflictPolicy.ForceAll(),
+			FieldManager:   configsync.FieldManager,
 		},
 		InventoryPolicy: a.policy,
 		// Leaving ReconcileTimeout and PruneTimeout unset may cause a WaitTask to wait forever.
@@ -519,10 +599,62 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 		PrunePropagationPolicy: metav1.DeletePropagationBackground,
 	}
 
+	// Objects which are not applied are dropped from the inventory when
+	// pruning is disabled, unless they are retained.
+	var retained object.ObjMetadataSet
+	retainUnapplied := false
+
+	// Unmanage the protected objects which are no longer declared, instead of
+	// pruning them. Refuse to prune if some of them may still be pruned.
+	if a.clientSet.Protection != nil && !a.unmanageProtectedObjects(ctx, enabledObjs) {
+		klog.Warning("Pruning disabled: some protected objects could not be unmanaged")
+		options.NoPrune = true
+		retainUnapplied = true
+	}
+
+	// Refuse to prune if the commit prunes more objects than allowed by the
+	// prune budget, but still apply the desired objects. The objects which
+	// are not pruned stay in the inventory, so that the budget still applies
+	// to them until the commit is acknowledged.
+	if toPrune, err := a.checkPruneBudget(ctx, enabledObjs, commit); err != nil {
+		klog.Warningf("Pruning disabled: %v", err)
+		a.addError(err)
+		options.NoPrune = true
+		retained = retained.Union(toPrune)
+	}
+	if skippedSecretRefs {
+		klog.Warning("Pruning disabled: some objects with unresolved secret references were skipped")
+		options.NoPrune = true
+		retainUnapplied = true
+	}
+	if retainUnapplied {
+		unapplied, err := a.unappliedInventory(resources)
+		if err != nil {
+			a.addError(Error(err))
+			return nil, a.Errors()
+		}
+		retained = retained.Union(unapplied)
+	}
+
 	// Reset shared mapper before each apply to invalidate the discovery cache.
 	// This allows for picking up CRD changes.
 	meta.MaybeResetRESTMapper(a.clientSet.Mapper)
 
+	// Re-read the health check rules before each apply to pick up rule
+	// changes. Rules declared in the source are used once they are applied.
+	if a.clientSet.HealthChecks != nil {
+		if errs := a.clientSet.HealthChecks.Refresh(ctx, a.clientSet.Client); errs != nil {
+			for _, err := range errs.Errors() {
+				a.addError(err)
+			}
+		}
+	}
+
+	a.retainInventory(retained)
+	defer a.retainInventory(nil)
+
+	spans := newTaskGroupSpans(ctx, commit)
+	defer spans.end()
 	events := a.clientSet.KptApplier.Run(ctx, a.inventory, object.UnstructuredSet(resources), options)
 	for e := range events {
 		switch e.Type {
@@ -532,6 +664,7 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 			}
 		case event.ActionGroupType:
 			klog.Info(e.ActionGroupEvent)
+			spans.handle(e.ActionGroupEvent)
 		case event.ErrorType:
 			klog.Info(e.ErrorEvent)
 			if util.IsRequestTooLargeError(e.ErrorEvent.Err) {
@@ -556,7 +689,25 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 			} else {
 				klog.V(1).Info(e.ApplyEvent)
 			}
-			a.addError(processApplyEvent(ctx, e.ApplyEvent, s.ApplyEvent, objStatusMap, unknownTypeResources))
+			err := processApplyEvent(ctx, e.ApplyEvent, s.ApplyEvent, objStatusMap, unknownTypeResources)
+			if err != nil && err.Code() == status.ManagementConflictErrorCode && e.ApplyEvent.Resource != nil {
+				// The applier does not report the conflicting manager, nor the
+				// live object, so the Event is only recorded on the RootSync or
+				// RepoSync. The remediator records it on the live object.
+				a.clientSet.Events.ManagementConflict(ctx, e.ApplyEvent.Resource, "")
+			}
+			if e.ApplyEvent.Status == event.ApplyFailed && fieldmanager.IsApplyConflict(e.ApplyEvent.Error) {
+				if obj, found := declaredObjs[e.ApplyEvent.Identifier]; found {
+					err = a.handleApplyConflict(ctx, obj, e.ApplyEvent.Error)
+				}
+			}
+			applied := err == nil && e.ApplyEvent.Status == event.ApplySuccessful
+			if adopted, found := adoptions[e.ApplyEvent.Identifier]; found && applied {
+				if obj, found := declaredObjs[e.ApplyEvent.Identifier]; found {
+					err = a.recordAdoption(ctx, obj, adopted, commit)
+				}
+			}
+			a.addError(err)
 		case event.PruneType:
 			if e.PruneEvent.Error != nil {
 				klog.Info(e.PruneEvent)
@@ -578,6 +729,10 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 		gvks[resource.GetObjectKind().GroupVersionKind()] = struct{}{}
 	}
 
+	if pruned := s.PruneEvent.EventByOp[event.PruneSuccessful]; pruned > 0 {
+		a.clientSet.Events.Pruned(ctx, commit, pruned)
+	}
+
 	errs := a.Errors()
 	if errs == nil {
 		klog.V(4).Infof("Apply completed without error: all resources are up to date.")
@@ -591,6 +746,93 @@ func (a *supervisor) applyInner(ctx context.Context, objs []client.Object) (map[
 	return gvks, errs
 }
 
+// unappliedInventory returns the objects of the inventory which are not in the
+// applied resources. They must be retained in the inventory when pruning is
+// disabled, or they are dropped from it while they keep the Config Sync
+// metadata, and are never pruned afterwards.
+func (a *supervisor) unappliedInventory(resources []*unstructured.Unstructured) (object.ObjMetadataSet, error) {
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return nil, err
+	}
+	applied := make([]client.Object, len(resources))
+	for i, r := range resources {
+		applied[i] = r
+	}
+	return removeFrom(invObjs, applied), nil
+}
+
+// retainInventory keeps the given objects in the inventory when it is replaced
+// by the next apply, although they are not applied and not pruned.
+func (a *supervisor) retainInventory(objs object.ObjMetadataSet) {
+	if r, ok := a.clientSet.InvClient.(inventoryRetainer); ok {
+		r.Retain(objs)
+	}
+}
+
+// resolveSecretReferences resolves the secret references of the given objects
+// just before they are applied. An object whose reference fails to resolve
+// keeps the secret data of its live object, or is skipped if it does not exist,
+// and the failure is recorded as an error for that object. Returns true if an
+// object was skipped without knowing whether it exists, in which case pruning
+// must be disabled so that the object is not pruned.
+func (a *supervisor) resolveSecretReferences(ctx context.Context, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, bool) {
+	var refObjs []*unstructured.Unstructured
+	for _, r := range resources {
+		if hasSecretReference(r) {
+			refObjs = append(refObjs, r.DeepCopy())
+		}
+	}
+	a.secretRefObjs = refObjs
+	if len(refObjs) == 0 {
+		a.secretRefsDigest = ""
+		return resources, false
+	}
+	a.secretRefsDigest = a.secretResolver.digest(ctx, refObjs)
+
+	var result []*unstructured.Unstructured
+	skipped := false
+	for _, r := range resources {
+		if !hasSecretReference(r) {
+			result = append(result, r)
+			continue
+		}
+		resolved, err := a.secretResolver.Resolve(ctx, r)
+		if err == nil {
+			result = append(result, resolved)
+			continue
+		}
+		id := core.IDOf(r)
+		a.addError(ErrorForResource(fmt.Errorf("unable to resolve the secret reference: %w", err), id))
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(r.GroupVersionKind())
+		switch getErr := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(r), live); {
+		case getErr == nil:
+			kept := r.DeepCopy()
+			if data, found, _ := unstructured.NestedMap(live.Object, "data"); found {
+				kept.Object["data"] = data
+			}
+			result = append(result, kept)
+		case apierrors.IsNotFound(getErr):
+			klog.Warningf("Skipping apply of %v until its secret reference resolves", id)
+		default:
+			klog.Warningf("Skipping apply of %v until its secret reference resolves: %v", id, getErr)
+			skipped = true
+		}
+	}
+	return result, skipped
+}
+
+// SecretReferencesChanged implements the Applier interface.
+func (a *supervisor) SecretReferencesChanged(ctx context.Context) bool {
+	a.execMux.Lock()
+	defer a.execMux.Unlock()
+	if len(a.secretRefObjs) == 0 {
+		return false
+	}
+	return a.secretResolver.digest(ctx, a.secretRefObjs) != a.secretRefsDigest
+}
+
 // Errors returns the errors encountered during the last apply or current apply
 // if still running.
 // Errors implements the Applier and Destroyer interfaces.
@@ -622,7 +864,7 @@ func (a *supervisor) invalidateErrors() {
 }
 
 // destroyInner triggers a kpt live destroy library call to destroy a set of resources.
-func (a *supervisor) destroyInner(ctx context.Context) status.MultiError {
+func (a *supervisor) destroyInner(ctx context.Context, opts DestroyOptions) status.MultiError {
 	s := stats.NewSyncStats()
 	objStatusMap := make(ObjectStatusMap)
 
@@ -643,6 +885,16 @@ func (a *supervisor) destroyInner(ctx context.Context) status.MultiError {
 	// This allows for picking up CRD changes.
 	meta.MaybeResetRESTMapper(a.clientSet.Mapper)
 
+	// Orphan the protected objects and the objects selected by the options
+	// instead of deleting them. Refuse to destroy if some of them may still be
+	// deleted.
+	if !a.orphanObjects(ctx, opts) {
+		klog.Warning("Destroy skipped: some orphaned objects could not be unmanaged")
+		return a.Errors()
+	}
+
+	spans := newTaskGroupSpans(ctx, "")
+	defer spans.end()
 	events := a.clientSet.KptDestroyer.Run(ctx, a.inventory, options)
 	for e := range events {
 		switch e.Type {
@@ -652,6 +904,7 @@ func (a *supervisor) destroyInner(ctx context.Context) status.MultiError {
 			}
 		case event.ActionGroupType:
 			klog.Info(e.ActionGroupEvent)
+			spans.handle(e.ActionGroupEvent)
 		case event.ErrorType:
 			klog.Info(e.ErrorEvent)
 			if util.IsRequestTooLargeError(e.ErrorEvent.Err) {
@@ -697,7 +950,7 @@ func (a *supervisor) destroyInner(ctx context.Context) status.MultiError {
 
 // Apply all managed resource objects and return any errors.
 // Apply implements the Applier interface.
-func (a *supervisor) Apply(ctx context.Context, desiredResource []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
+func (a *supervisor) Apply(ctx context.Context, desiredResource []client.Object, commit string) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
 	a.execMux.Lock()
 	defer a.execMux.Unlock()
 
@@ -705,12 +958,13 @@ func (a *supervisor) Apply(ctx context.Context, desiredResource []client.Object)
 	// but for now, invalidate all errors until they recur.
 	// TODO: improve error cache invalidation to make rsync status more stable
 	a.invalidateErrors()
-	return a.applyInner(ctx, desiredResource)
+	return a.applyInner(ctx, desiredResource, commit)
 }
 
-// Destroy all managed resource objects and return any errors.
+// Destroy all managed resource objects, except the orphaned objects, and
+// return any errors.
 // Destroy implements the Destroyer interface.
-func (a *supervisor) Destroy(ctx context.Context) status.MultiError {
+func (a *supervisor) Destroy(ctx context.Context, opts DestroyOptions) status.MultiError {
 	a.execMux.Lock()
 	defer a.execMux.Unlock()
 
@@ -718,7 +972,7 @@ func (a *supervisor) Destroy(ctx context.Context) status.MultiError {
 	// but for now, invalidate all errors until they recur.
 	// TODO: improve error cache invalidation to make rsync status more stable
 	a.invalidateErrors()
-	return a.destroyInner(ctx)
+	return a.destroyInner(ctx, opts)
 }
 
 // newInventoryUnstructured creates an inventory object as an unstructured.
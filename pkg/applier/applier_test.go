@@ -251,10 +251,10 @@ func TestApply(t *testing.T) {
 				// TODO: Add tests to cover disabling objects
 				// TODO: Add tests to cover status mode
 			}
-			applier, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute)
+			applier, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{})
 			require.NoError(t, err)
 
-			gvks, errs := applier.Apply(context.Background(), objs)
+			gvks, errs := applier.Apply(context.Background(), objs, "abc123")
 			testutil.AssertEqual(t, tc.gvks, gvks)
 
 			if tc.multiErr == nil {
@@ -23,9 +23,15 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/cmd/util"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/events"
+	"kpt.dev/configsync/pkg/health"
+	"kpt.dev/configsync/pkg/protection"
 	"sigs.k8s.io/cli-utils/pkg/apply"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/statusreaders"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -52,10 +58,18 @@ type ClientSet struct {
 	DynamicClient dynamic.Interface
 	Mapper        meta.RESTMapper
 	StatusMode    string
+	// HealthChecks computes the status of the objects with a health check
+	// rule while the applier waits for the applied objects to reconcile.
+	HealthChecks *health.StatusReader
+	// Events records the management conflicts and the pruned objects.
+	Events *events.Recorder
+	// Protection unmanages the protected objects instead of pruning or
+	// deleting them.
+	Protection *protection.Protector
 }
 
-// NewClientSet constructs a new ClientSet.
-func NewClientSet(c client.Client, configFlags *genericclioptions.ConfigFlags, statusMode string) (*ClientSet, error) {
+// NewClientSet constructs a new ClientSet for a reconciler of the given scope.
+func NewClientSet(c client.Client, configFlags *genericclioptions.ConfigFlags, statusMode string, scope declared.Scope) (*ClientSet, error) {
 	matchVersionKubeConfigFlags := util.NewMatchVersionFlags(configFlags)
 	f := util.NewFactory(matchVersionKubeConfigFlags)
 
@@ -67,15 +81,35 @@ func NewClientSet(c client.Client, configFlags *genericclioptions.ConfigFlags, s
 		klog.Infof("Disabled status reporting")
 		statusPolicy = inventory.StatusPolicyNone
 	}
-	invClient, err := inventory.NewClient(f, live.WrapInventoryObj,
+	clusterInvClient, err := inventory.NewClient(f, live.WrapInventoryObj,
 		live.InvToUnstructuredFunc, statusPolicy, live.ResourceGroupGVK)
 	if err != nil {
 		return nil, err
 	}
+	invClient := &retainingInventoryClient{Client: clusterInvClient}
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	// The health check rules take precedence over the default status readers.
+	healthChecks := health.NewStatusReader(mapper)
+	statusWatcher := watcher.NewDefaultStatusWatcher(dynamicClient, mapper)
+	statusWatcher.StatusReader = statusreaders.NewStatusReader(mapper, healthChecks)
+	var kptStatusWatcher watcher.StatusWatcher = statusWatcher
+	if scope != declared.RootReconciler {
+		kptStatusWatcher = namespaceStatusWatcher{StatusWatcher: statusWatcher}
+	}
 
 	applier, err := apply.NewApplierBuilder().
 		WithInventoryClient(invClient).
 		WithFactory(f).
+		WithStatusWatcher(kptStatusWatcher).
 		Build()
 	if err != nil {
 		return nil, err
@@ -84,20 +118,12 @@ func NewClientSet(c client.Client, configFlags *genericclioptions.ConfigFlags, s
 	destroyer, err := apply.NewDestroyerBuilder().
 		WithInventoryClient(invClient).
 		WithFactory(f).
+		WithStatusWatcher(kptStatusWatcher).
 		Build()
 	if err != nil {
 		return nil, err
 	}
 
-	dynamicClient, err := f.DynamicClient()
-	if err != nil {
-		return nil, err
-	}
-	mapper, err := f.ToRESTMapper()
-	if err != nil {
-		return nil, err
-	}
-
 	return &ClientSet{
 		KptApplier:    applier,
 		KptDestroyer:  destroyer,
@@ -106,5 +132,7 @@ func NewClientSet(c client.Client, configFlags *genericclioptions.ConfigFlags, s
 		DynamicClient: dynamicClient,
 		Mapper:        mapper,
 		StatusMode:    statusMode,
+		HealthChecks:  healthChecks,
+		Protection:    protection.NewProtector(),
 	}, nil
 }
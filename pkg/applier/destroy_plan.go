@@ -0,0 +1,155 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/lifecycle"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DestroyOptions selects the managed objects which Destroy orphans instead of
+// deleting.
+type DestroyOptions struct {
+	// OrphanKinds are the kinds of the managed objects which are orphaned,
+	// unless they have the `configsync.gke.io/deletion-policy: delete`
+	// annotation.
+	OrphanKinds []schema.GroupKind
+}
+
+// NewDestroyOptions returns the DestroyOptions configured by the
+// spec.deletionPropagation of a RootSync or RepoSync.
+func NewDestroyOptions(dp *v1beta1.DeletionPropagation) DestroyOptions {
+	var opts DestroyOptions
+	if dp == nil {
+		return opts
+	}
+	for _, rule := range dp.Orphan {
+		opts.OrphanKinds = append(opts.OrphanKinds, schema.GroupKind{Group: rule.Group, Kind: rule.Kind})
+	}
+	return opts
+}
+
+// orphans returns true if the kind of the given object is orphaned.
+func (o DestroyOptions) orphans(obj client.Object) bool {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	for _, orphanGK := range o.OrphanKinds {
+		if orphanGK == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// DestroyPlan lists the live managed objects which Destroy deletes and
+// orphans.
+type DestroyPlan struct {
+	// Delete are the objects which are deleted.
+	Delete []client.Object
+	// Orphan are the objects which are orphaned instead of deleted. They are
+	// removed from the inventory and their Config Sync metadata is removed.
+	Orphan []client.Object
+	// protectedBy are the names of the protection rules of the orphaned
+	// objects, at the same index, or empty for the objects which are not
+	// protected.
+	protectedBy []string
+}
+
+// PlanDestroy returns the managed objects which Destroy would delete and
+// orphan with the given options, without changing anything.
+// PlanDestroy implements the Destroyer interface.
+func (a *supervisor) PlanDestroy(ctx context.Context, opts DestroyOptions) (DestroyPlan, status.MultiError) {
+	if a.clientSet.Protection != nil {
+		if errs := a.clientSet.Protection.Refresh(ctx, a.clientSet.Client); errs != nil {
+			if !protectionRulesRead(errs) {
+				// The objects protected by the unread rules are unknown.
+				return DestroyPlan{}, errs
+			}
+			klog.Warningf("Failed to read some protection rules: %v", errs)
+		}
+	}
+	plan, err := a.planDestroy(ctx, opts)
+	if err != nil {
+		return plan, Error(err)
+	}
+	return plan, nil
+}
+
+// planDestroy splits the live objects of the inventory into the objects to
+// delete and the objects to orphan. An object is orphaned if it is protected
+// by the protection rules, if it has the detach lifecycle annotation, or if
+// either its deletion-policy annotation or its kind orphans it.
+func (a *supervisor) planDestroy(ctx context.Context, opts DestroyOptions) (DestroyPlan, error) {
+	var plan DestroyPlan
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return plan, err
+	}
+	for _, id := range invObjs {
+		u, err := a.getLiveObject(ctx, id)
+		if err != nil {
+			return plan, err
+		}
+		if u == nil {
+			// Already deleted.
+			continue
+		}
+		if rule, protected := a.clientSet.Protection.Protects(u); protected {
+			plan.Orphan = append(plan.Orphan, u)
+			plan.protectedBy = append(plan.protectedBy, rule)
+			continue
+		}
+		policy := metadata.DeletionPolicy(core.GetAnnotation(u, metadata.DeletionPolicyAnnotationKey))
+		switch {
+		case lifecycle.HasPreventDeletion(u), policy == metadata.DeletionPolicyOrphan:
+			plan.Orphan = append(plan.Orphan, u)
+			plan.protectedBy = append(plan.protectedBy, "")
+		case policy != metadata.DeletionPolicyDelete && opts.orphans(u):
+			plan.Orphan = append(plan.Orphan, u)
+			plan.protectedBy = append(plan.protectedBy, "")
+		default:
+			plan.Delete = append(plan.Delete, u)
+		}
+	}
+	return plan, nil
+}
+
+// orphanObjects unmanages the objects which Destroy orphans instead of
+// deleting, so that the destroyer does not delete them.
+//
+// Returns false if some orphaned objects may still be in the inventory.
+func (a *supervisor) orphanObjects(ctx context.Context, opts DestroyOptions) bool {
+	if !a.refreshProtection(ctx) {
+		return false
+	}
+	plan, err := a.planDestroy(ctx, opts)
+	if err != nil {
+		a.addError(Error(fmt.Errorf("failed to plan the deletion of the managed objects: %w", err)))
+		return false
+	}
+	if len(plan.Orphan) == 0 {
+		return true
+	}
+	klog.Infof("%v objects to be orphaned instead of deleted: %v", len(plan.Orphan), core.GKNNs(plan.Orphan))
+	return a.unmanageObjects(ctx, plan.Orphan, plan.protectedBy)
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/protection"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNewDestroyOptions(t *testing.T) {
+	require.Equal(t, DestroyOptions{}, NewDestroyOptions(nil))
+	require.Equal(t, DestroyOptions{
+		OrphanKinds: []schema.GroupKind{
+			kinds.Namespace().GroupKind(),
+			{Group: "apps", Kind: "Deployment"},
+		},
+	}, NewDestroyOptions(&v1beta1.DeletionPropagation{
+		Orphan: []v1beta1.DeletionOrphanRule{
+			{Kind: "Namespace"},
+			{Group: "apps", Kind: "Deployment"},
+		},
+	}))
+}
+
+func TestPlanDestroy(t *testing.T) {
+	bookstore := fake.NamespaceObject("bookstore")
+	deleted := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("deleted"))
+	kept := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("kept"), core.Label("example.com/keep", "true"))
+	detached := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("detached"),
+		core.Annotation(common.LifecycleDeleteAnnotation, common.PreventDeletion))
+	orphaned := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("orphaned"),
+		core.Annotation(metadata.DeletionPolicyAnnotationKey, string(metadata.DeletionPolicyOrphan)))
+	forced := fake.NamespaceObject("forced",
+		core.Annotation(metadata.DeletionPolicyAnnotationKey, string(metadata.DeletionPolicyDelete)))
+	missing := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("missing"))
+
+	fakeClient := testingfake.NewClient(t, core.Scheme, bookstore, deleted, kept, detached, orphaned, forced)
+	p := protection.NewProtector()
+	p.SetRules(protection.Rules{
+		"keep": {LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/keep": "true"}}},
+	})
+	var ids object.ObjMetadataSet
+	for _, obj := range []client.Object{bookstore, deleted, kept, detached, orphaned, forced, missing} {
+		ids = append(ids, ObjMetaFromObject(obj))
+	}
+	cs := &ClientSet{
+		Client:     fakeClient,
+		Mapper:     fakeClient.RESTMapper(),
+		InvClient:  inventory.NewFakeClient(ids),
+		Protection: p,
+	}
+	applier, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{})
+	require.NoError(t, err)
+
+	opts := DestroyOptions{OrphanKinds: []schema.GroupKind{kinds.Namespace().GroupKind()}}
+	plan, err := applier.(*supervisor).planDestroy(context.Background(), opts)
+	require.NoError(t, err)
+
+	ids = nil
+	for _, obj := range plan.Delete {
+		ids = append(ids, ObjMetaFromObject(obj))
+	}
+	require.Equal(t, object.ObjMetadataSet{ObjMetaFromObject(deleted), ObjMetaFromObject(forced)}, ids)
+	ids = nil
+	for _, obj := range plan.Orphan {
+		ids = append(ids, ObjMetaFromObject(obj))
+	}
+	require.Equal(t, object.ObjMetadataSet{
+		ObjMetaFromObject(bookstore),
+		ObjMetaFromObject(kept),
+		ObjMetaFromObject(detached),
+		ObjMetaFromObject(orphaned),
+	}, ids)
+	require.Equal(t, []string{"", "keep", "", ""}, plan.protectedBy)
+}
@@ -190,14 +190,15 @@ func TestDestroy(t *testing.T) {
 			fakeClient := testingfake.NewClient(t, core.Scheme)
 			cs := &ClientSet{
 				KptDestroyer: newFakeKptDestroyer(tc.events),
+				InvClient:    inventory.NewFakeClient(nil),
 				Client:       fakeClient,
 				// TODO: Add tests to cover disabling objects
 				// TODO: Add tests to cover status mode
 			}
-			destroyer, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute)
+			destroyer, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{})
 			require.NoError(t, err)
 
-			errs := destroyer.Destroy(context.Background())
+			errs := destroyer.Destroy(context.Background(), DestroyOptions{})
 			testutil.AssertEqual(t, tc.multiErr, errs)
 		})
 	}
@@ -0,0 +1,133 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/util/fieldmanager"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object/mutation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveFieldConflicts computes the fields of the existing objects which
+// conflict with other field managers, with a server-side apply dry-run, and
+// takes ownership of them before the objects are applied, if the conflict
+// policy forces ownership of all of them.
+//
+// The cli-utils applier forces ownership of the conflicting fields for all the
+// objects or for none of them, so it only forces ownership when the policy
+// forces all the conflicts. The objects whose conflicts are all forced by the
+// policy are applied with ForceOwnership beforehand, and are then applied by
+// cli-utils without conflict, which records them in the inventory. The other
+// objects fail to apply, and handleApplyConflict reports their conflicts.
+//
+// The forced conflicts are reported as non-blocking errors, so that the fields
+// fought over with other field managers are visible in the sync status. The
+// objects whose dry-run fails for another reason are left to the cli-utils
+// applier, which reports the error if the apply fails as well.
+//
+// Nothing is done without a conflict policy, since the cli-utils applier takes
+// ownership of all the conflicting fields.
+func (a *supervisor) resolveFieldConflicts(ctx context.Context, objs []*unstructured.Unstructured) {
+	if !a.conflictPolicy.Configured() {
+		return
+	}
+	for _, obj := range objs {
+		// Apply-time mutations are only computed by the cli-utils applier.
+		if mutation.HasAnnotation(obj) {
+			continue
+		}
+		id := core.IDOf(obj)
+		// Run a server-side apply dry-run without forcing ownership, so the
+		// API server reports the conflicting fields.
+		err := a.clientSet.Client.Patch(ctx, obj.DeepCopy(), client.Apply, client.FieldOwner(configsync.FieldManager), client.DryRunAll)
+		if err == nil {
+			continue
+		}
+		if !fieldmanager.IsApplyConflict(err) {
+			if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				klog.V(3).Infof("Failed to dry-run apply %v to compute conflicting fields: %v", id, err)
+			}
+			continue
+		}
+		conflicts := fieldmanager.ConflictsFromError(err)
+		if len(conflicts) == 0 || len(a.conflictPolicy.Unresolved(conflicts)) > 0 {
+			continue
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+			klog.Warningf("Failed to get %v to take ownership of conflicting fields: %v", id, err)
+			continue
+		}
+		// Leave the objects of other inventories to the inventory policy of
+		// the cli-utils applier.
+		owner := core.GetAnnotation(live, inventory.OwningInventoryKey)
+		if owner != "" && owner != a.inventory.ID() && a.policy != inventory.PolicyAdoptAll {
+			continue
+		}
+		// The cli-utils applier takes ownership of them.
+		if a.conflictPolicy.ForceAll() {
+			a.addError(status.ForcedFieldManagerConflictError(obj, conflicts.ByManager()))
+			continue
+		}
+
+		klog.Infof("Taking ownership of conflicting fields of %v: %s", id, conflicts)
+		forced := obj.DeepCopy()
+		// Keep the owning-inventory annotation set by the cli-utils applier.
+		if owner == a.inventory.ID() {
+			core.SetAnnotation(forced, inventory.OwningInventoryKey, owner)
+		}
+		if err := a.clientSet.Client.Patch(ctx, forced, client.Apply, client.FieldOwner(configsync.FieldManager), client.ForceOwnership); err != nil {
+			klog.Warningf("Failed to take ownership of conflicting fields of %v: %v", id, err)
+			continue
+		}
+		a.addError(status.ForcedFieldManagerConflictError(obj, conflicts.ByManager()))
+	}
+}
+
+// handleApplyConflict reports a server-side apply conflict between the
+// declared object and other field managers, as a FieldManagerConflictError
+// listing the fields which the conflict policy does not force ownership of,
+// and the field managers which own them.
+func (a *supervisor) handleApplyConflict(ctx context.Context, obj *unstructured.Unstructured, applyErr error) status.Error {
+	id := core.IDOf(obj)
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := a.clientSet.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		klog.Warningf("Failed to get %v to compute conflicting fields: %v", id, err)
+		return ErrorForResource(applyErr, id)
+	}
+	conflicts, err := fieldmanager.ConflictsFromManagedFields(live, obj, configsync.FieldManager)
+	if err != nil {
+		return ErrorForResource(err, id)
+	}
+	if unresolved := a.conflictPolicy.Unresolved(conflicts); len(unresolved) > 0 {
+		return status.FieldManagerConflictError(obj, unresolved.ByManager())
+	}
+	// The conflicts appeared after resolveFieldConflicts, and are resolved by
+	// the next apply.
+	return ErrorForResource(applyErr, id)
+}
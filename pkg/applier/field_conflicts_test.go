@@ -0,0 +1,165 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/util/fieldmanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dryRunClient returns the given errors for the server-side apply dry-runs,
+// as the fake client neither tracks the field managers nor honors dry-runs.
+type dryRunClient struct {
+	client.Client
+	errs map[string]error
+	// dryRuns counts the server-side apply dry-runs.
+	dryRuns int
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	if len(patchOpts.DryRun) > 0 {
+		c.dryRuns++
+		return c.errs[obj.GetName()]
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func applyConflictError(manager, field string) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusConflict,
+		Reason: metav1.StatusReasonConflict,
+		Details: &metav1.StatusDetails{
+			Causes: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: fmt.Sprintf("conflict with %q", manager),
+				Field:   field,
+			}},
+		},
+		Message: "Apply failed with 1 conflict",
+	}}
+}
+
+func TestResolveFieldConflicts(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      *v1beta1.ConflictPolicy
+		wantData    map[string]string
+		wantForced  []string
+		wantDryRuns int
+	}{
+		{
+			name: "report mode forces the conflicts of the force field managers",
+			policy: &v1beta1.ConflictPolicy{
+				Mode:               v1beta1.ConflictModeReport,
+				ForceFieldManagers: []string{"kubectl"},
+			},
+			wantData:    map[string]string{"forced": "declared", "reported": "live", "failed": "live"},
+			wantForced:  []string{"forced"},
+			wantDryRuns: 4,
+		},
+		{
+			name:        "force mode leaves the conflicts to the cli-utils applier",
+			policy:      &v1beta1.ConflictPolicy{Mode: v1beta1.ConflictModeForce},
+			wantData:    map[string]string{"forced": "live", "reported": "live", "failed": "live"},
+			wantForced:  []string{"forced", "reported"},
+			wantDryRuns: 4,
+		},
+		{
+			name:     "no conflict policy skips the dry-runs",
+			wantData: map[string]string{"forced": "live", "reported": "live", "failed": "live"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var live []client.Object
+			for _, name := range []string{"forced", "reported", "failed"} {
+				cm := fake.ConfigMapObject(core.Namespace("test-namespace"), core.Name(name))
+				cm.Data = map[string]string{"key": "live"}
+				live = append(live, cm)
+			}
+			fakeClient := testingfake.NewClient(t, core.Scheme, live...)
+			policy, err := fieldmanager.NewConflictPolicy(tc.policy)
+			require.NoError(t, err)
+			dryRuns := &dryRunClient{
+				Client: fakeClient,
+				errs: map[string]error{
+					"forced":   applyConflictError("kubectl", ".data.key"),
+					"reported": applyConflictError("other", ".data.key"),
+					"failed":   apierrors.NewInternalError(errors.New("webhook unavailable")),
+				},
+			}
+			cs := &ClientSet{
+				Client: dryRuns,
+				Mapper: fakeClient.RESTMapper(),
+			}
+			s, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{ConflictPolicy: policy})
+			require.NoError(t, err)
+
+			var objs []*unstructured.Unstructured
+			for _, name := range []string{"forced", "reported", "failed", "created"} {
+				cm := fake.ConfigMapObject(core.Namespace("test-namespace"), core.Name(name))
+				cm.Data = map[string]string{"key": "declared"}
+				u, err := toUnstructured([]client.Object{cm})
+				require.NoError(t, err)
+				objs = append(objs, u...)
+			}
+			sup := s.(*supervisor)
+			sup.resolveFieldConflicts(context.Background(), objs)
+			require.Equal(t, tc.wantDryRuns, dryRuns.dryRuns)
+
+			for name, want := range tc.wantData {
+				cm := &corev1.ConfigMap{}
+				require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "test-namespace", Name: name}, cm))
+				require.Equal(t, want, cm.Data["key"], name)
+			}
+			// Objects which do not exist have no conflict, and are left to the
+			// cli-utils applier.
+			err = fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "test-namespace", Name: "created"}, &corev1.ConfigMap{})
+			require.True(t, apierrors.IsNotFound(err), "got %v", err)
+
+			// The forced conflicts are reported as non-blocking errors.
+			var forced []string
+			if errs := sup.Errors(); errs != nil {
+				for _, err := range errs.Errors() {
+					require.Equal(t, status.ForcedFieldManagerConflictErrorCode, err.Code())
+					forced = append(forced, err.ToCSE().Resources[0].Name)
+				}
+			}
+			require.Equal(t, tc.wantForced, forced)
+			require.False(t, status.HasBlockingErrors(sup.Errors()))
+		})
+	}
+}
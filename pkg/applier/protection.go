@@ -0,0 +1,171 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/protection"
+	"kpt.dev/configsync/pkg/status"
+	nomosutil "kpt.dev/configsync/pkg/util"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// unmanageProtectedObjects unmanages the objects of the inventory which are
+// not in the declared objects and are protected by the protection rules, so
+// that they are neither pruned nor deleted. Each unmanaged object is recorded
+// with an Event and a status warning.
+//
+// Returns false if some protected objects may still be in the inventory.
+func (a *supervisor) unmanageProtectedObjects(ctx context.Context, declared []client.Object) bool {
+	if !a.refreshProtection(ctx) {
+		return false
+	}
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		a.addError(Error(err))
+		return false
+	}
+	objs, rules, err := a.protectedObjects(ctx, removeFrom(invObjs, declared))
+	if err != nil {
+		a.addError(Error(err))
+		return false
+	}
+	if len(objs) == 0 {
+		return true
+	}
+
+	klog.Infof("%v protected objects to be unmanaged instead of deleted: %v", len(objs), core.GKNNs(objs))
+	return a.unmanageObjects(ctx, objs, rules)
+}
+
+// refreshProtection re-reads the protection rules to pick up rule changes. The
+// invalid rules are reported, and the other rules are still used.
+//
+// Returns false if the protection rules could not be read.
+func (a *supervisor) refreshProtection(ctx context.Context) bool {
+	if a.clientSet.Protection == nil {
+		return true
+	}
+	errs := a.clientSet.Protection.Refresh(ctx, a.clientSet.Client)
+	if errs != nil {
+		for _, err := range errs.Errors() {
+			a.addError(err)
+		}
+	}
+	return protectionRulesRead(errs)
+}
+
+// protectionRulesRead returns false if the errors of a protection rules refresh
+// include failing to read the rules, rather than only invalid rules.
+func protectionRulesRead(errs status.MultiError) bool {
+	if errs == nil {
+		return true
+	}
+	for _, err := range errs.Errors() {
+		if err.Code() != protection.InvalidProtectionRuleErrorCode {
+			return false
+		}
+	}
+	return true
+}
+
+// unmanageObjects removes the given objects from the inventory, and then
+// removes their Config Sync metadata. The objects protected by a rule, whose
+// name is at the same index of rules, are recorded with an Event and a status
+// warning.
+//
+// Returns false if the objects may still be in the inventory.
+func (a *supervisor) unmanageObjects(ctx context.Context, objs []client.Object, rules []string) bool {
+	if err := a.removeFromInventory(a.inventory, objs); err != nil {
+		if nomosutil.IsRequestTooLargeError(err) {
+			a.addError(largeResourceGroupError(err, idFromInventory(a.inventory)))
+		} else {
+			a.addError(Error(err))
+		}
+		return false
+	}
+	// The objects are no longer in the inventory, so they are not deleted
+	// even if their Config Sync metadata cannot be removed.
+	for i, obj := range objs {
+		err := a.disableObject(ctx, obj)
+		handleMetrics(ctx, "unmanage", err, obj.GetObjectKind().GroupVersionKind())
+		if err != nil {
+			klog.Warningf("failed to disable object %v", core.IDOf(obj))
+			a.addError(Error(err))
+			continue
+		}
+		klog.V(4).Infof("disabled object %v", core.IDOf(obj))
+		if rules[i] != "" {
+			a.clientSet.Events.DeletionSkipped(ctx, obj, rules[i])
+			a.addError(status.ProtectedResourceError(obj, rules[i]))
+		}
+	}
+	return true
+}
+
+// protectedObjects returns the live objects of the given identities which are
+// protected by the protection rules, with the names of the protecting rules.
+// The objects which no longer exist are ignored.
+func (a *supervisor) protectedObjects(ctx context.Context, ids []object.ObjMetadata) ([]client.Object, []string, error) {
+	rules := a.clientSet.Protection.Rules()
+	var objs []client.Object
+	var names []string
+	for _, id := range ids {
+		if !rules.SelectsID(id.GroupKind, id.Namespace, id.Name) {
+			continue
+		}
+		u, err := a.getLiveObject(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if u == nil {
+			continue
+		}
+		if name, protected := rules.Match(u); protected {
+			objs = append(objs, u)
+			names = append(names, name)
+		}
+	}
+	return objs, names, nil
+}
+
+// getLiveObject returns the live object of the given identity, or nil if it
+// does not exist.
+func (a *supervisor) getLiveObject(ctx context.Context, id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	mapping, err := a.clientSet.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The object cannot exist without its type.
+			return nil, nil
+		}
+		return nil, err
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := a.clientSet.Client.Get(ctx, client.ObjectKey{Namespace: id.Namespace, Name: id.Name}, u); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
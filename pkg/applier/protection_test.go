@@ -0,0 +1,66 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/protection"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestProtectedObjects(t *testing.T) {
+	kubeSystem := fake.NamespaceObject("kube-system")
+	bookstore := fake.NamespaceObject("bookstore")
+	kept := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("kept"), core.Label("example.com/keep", "true"))
+	other := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("other"))
+	missing := fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("missing"), core.Label("example.com/keep", "true"))
+
+	fakeClient := testingfake.NewClient(t, core.Scheme, kubeSystem, bookstore, kept, other)
+	p := protection.NewProtector()
+	p.SetRules(protection.Rules{
+		"default:kube-system": {Kind: "Namespace", Name: "kube-system"},
+		"keep":                {LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/keep": "true"}}},
+	})
+	cs := &ClientSet{
+		Client:     fakeClient,
+		Mapper:     fakeClient.RESTMapper(),
+		Protection: p,
+	}
+	applier, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{})
+	require.NoError(t, err)
+
+	var ids []object.ObjMetadata
+	for _, obj := range []client.Object{kubeSystem, bookstore, kept, other, missing} {
+		ids = append(ids, ObjMetaFromObject(obj))
+	}
+	objs, rules, err := applier.(*supervisor).protectedObjects(context.Background(), ids)
+	require.NoError(t, err)
+
+	var got []core.ID
+	for _, obj := range objs {
+		got = append(got, core.IDOf(obj))
+	}
+	require.Equal(t, []core.ID{core.IDOf(kubeSystem), core.IDOf(kept)}, got)
+	require.Equal(t, []string{"default:kube-system", "keep"}, rules)
+}
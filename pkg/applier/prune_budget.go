@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PruneBudgetExceededErrorCode is the error code for a commit which would
+// prune more objects than allowed by the prune budget.
+const PruneBudgetExceededErrorCode = "2016"
+
+var pruneBudgetExceededErrorBuilder = status.NewErrorBuilder(PruneBudgetExceededErrorCode)
+
+// maxListedPrunes is the maximum number of objects to prune listed in a
+// PruneBudgetExceededError, to keep the error within the size of the status.
+const maxListedPrunes = 20
+
+// PruneBudget limits how many managed objects a single commit may prune
+// without explicit acknowledgment. A zero value disables the respective limit.
+type PruneBudget struct {
+	// MaxObjects is the maximum number of objects which may be pruned.
+	MaxObjects int
+	// MaxPercentage is the maximum percentage of the inventory which may be
+	// pruned.
+	MaxPercentage int
+}
+
+// Enabled returns true if any limit of the budget is set.
+func (b PruneBudget) Enabled() bool {
+	return b.MaxObjects > 0 || b.MaxPercentage > 0
+}
+
+// Exceeded returns true if pruning pruneCount of the inventoryCount objects in
+// the inventory exceeds the budget.
+func (b PruneBudget) Exceeded(pruneCount, inventoryCount int) bool {
+	if pruneCount == 0 {
+		return false
+	}
+	if b.MaxObjects > 0 && pruneCount > b.MaxObjects {
+		return true
+	}
+	return b.MaxPercentage > 0 && pruneCount*100 > b.MaxPercentage*inventoryCount
+}
+
+// String returns a human readable description of the budget.
+func (b PruneBudget) String() string {
+	var limits []string
+	if b.MaxObjects > 0 {
+		limits = append(limits, fmt.Sprintf("maxObjects=%d", b.MaxObjects))
+	}
+	if b.MaxPercentage > 0 {
+		limits = append(limits, fmt.Sprintf("maxPercentage=%d", b.MaxPercentage))
+	}
+	return strings.Join(limits, ", ")
+}
+
+// PruneBudgetExceededError reports that the given commit would prune more
+// objects than allowed by the prune budget, so pruning is blocked until the
+// commit is acknowledged with the prune-budget-ack annotation on the
+// RootSync or RepoSync.
+func PruneBudgetExceededError(budget PruneBudget, commit string, inventoryCount int, toPrune []core.ID, syncKind string) status.Error {
+	ids := make([]string, len(toPrune))
+	for i, id := range toPrune {
+		ids[i] = id.String()
+	}
+	sort.Strings(ids)
+	if len(ids) > maxListedPrunes {
+		ids = append(ids[:maxListedPrunes], fmt.Sprintf("... (%d more)", len(ids)-maxListedPrunes))
+	}
+	return pruneBudgetExceededErrorBuilder.
+		Sprintf("Commit %q would prune %d of %d managed objects, which exceeds the prune budget (%s). "+
+			"Pruning is blocked until the commit is acknowledged. "+
+			"If this is not a mistake, set the annotation %s=%s on the %s. Objects to prune: [%s]",
+			commit, len(toPrune), inventoryCount, budget, metadata.PruneBudgetAckAnnotationKey, commit, syncKind,
+			strings.Join(ids, ", ")).
+		Build()
+}
+
+// checkPruneBudget returns an error if applying the desired objects would
+// prune more objects than allowed by the prune budget, unless the prune has
+// been acknowledged for the given commit. When the budget is exceeded, it also
+// returns the objects which must not be pruned.
+func (a *supervisor) checkPruneBudget(ctx context.Context, objs []client.Object, commit string) (object.ObjMetadataSet, status.Error) {
+	if !a.pruneBudget.Enabled() {
+		return nil, nil
+	}
+	invObjs, err := a.clientSet.InvClient.GetClusterObjs(a.inventory)
+	if err != nil {
+		return nil, Error(err)
+	}
+	toPrune := removeFrom(invObjs, objs)
+	if !a.pruneBudget.Exceeded(len(toPrune), len(invObjs)) {
+		return nil, nil
+	}
+	acked, err := a.pruneAcknowledged(ctx, commit)
+	if err != nil {
+		return toPrune, Error(err)
+	}
+	if acked {
+		klog.Infof("Pruning %d of %d managed objects, exceeding the prune budget (%s), acknowledged for commit %q",
+			len(toPrune), len(invObjs), a.pruneBudget, commit)
+		return nil, nil
+	}
+	ids := make([]core.ID, len(toPrune))
+	for i, meta := range toPrune {
+		ids[i] = idFrom(meta)
+	}
+	return toPrune, PruneBudgetExceededError(a.pruneBudget, commit, len(invObjs), ids, a.syncKind)
+}
+
+// PruneBlocked implements the Applier interface.
+func (a *supervisor) PruneBlocked(ctx context.Context, objs []client.Object, commit string) []core.ID {
+	a.execMux.Lock()
+	defer a.execMux.Unlock()
+	enabledObjs, _ := partitionObjs(objs)
+	toPrune, err := a.checkPruneBudget(ctx, enabledObjs, commit)
+	if err == nil {
+		return nil
+	}
+	ids := make([]core.ID, len(toPrune))
+	for i, meta := range toPrune {
+		ids[i] = idFrom(meta)
+	}
+	return ids
+}
+
+// pruneAcknowledged returns true if the RootSync or RepoSync has the
+// prune-budget-ack annotation set to the given commit.
+func (a *supervisor) pruneAcknowledged(ctx context.Context, commit string) (bool, error) {
+	if commit == "" {
+		return false, nil
+	}
+	rs := &unstructured.Unstructured{}
+	if a.syncKind == configsync.RootSyncKind {
+		rs.SetGroupVersionKind(kinds.RootSyncV1Beta1())
+	} else {
+		rs.SetGroupVersionKind(kinds.RepoSyncV1Beta1())
+	}
+	if err := a.clientSet.Client.Get(ctx, client.ObjectKey{Namespace: a.syncNamespace, Name: a.syncName}, rs); err != nil {
+		return false, fmt.Errorf("failed to get %s %s/%s: %w", a.syncKind, a.syncNamespace, a.syncName, err)
+	}
+	return core.GetAnnotation(rs, metadata.PruneBudgetAckAnnotationKey) == commit, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPruneBudgetExceeded(t *testing.T) {
+	testCases := []struct {
+		name           string
+		budget         PruneBudget
+		pruneCount     int
+		inventoryCount int
+		want           bool
+	}{
+		{
+			name:           "disabled",
+			budget:         PruneBudget{},
+			pruneCount:     100,
+			inventoryCount: 100,
+			want:           false,
+		},
+		{
+			name:           "nothing to prune",
+			budget:         PruneBudget{MaxObjects: 1, MaxPercentage: 1},
+			pruneCount:     0,
+			inventoryCount: 100,
+			want:           false,
+		},
+		{
+			name:           "within max objects",
+			budget:         PruneBudget{MaxObjects: 10},
+			pruneCount:     10,
+			inventoryCount: 100,
+			want:           false,
+		},
+		{
+			name:           "exceeds max objects",
+			budget:         PruneBudget{MaxObjects: 10},
+			pruneCount:     11,
+			inventoryCount: 100,
+			want:           true,
+		},
+		{
+			name:           "within max percentage",
+			budget:         PruneBudget{MaxPercentage: 25},
+			pruneCount:     25,
+			inventoryCount: 100,
+			want:           false,
+		},
+		{
+			name:           "exceeds max percentage",
+			budget:         PruneBudget{MaxPercentage: 25},
+			pruneCount:     26,
+			inventoryCount: 100,
+			want:           true,
+		},
+		{
+			name:           "exceeds max percentage within max objects",
+			budget:         PruneBudget{MaxObjects: 10, MaxPercentage: 25},
+			pruneCount:     3,
+			inventoryCount: 10,
+			want:           true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.budget.Exceeded(tc.pruneCount, tc.inventoryCount)
+			if got != tc.want {
+				t.Errorf("%+v.Exceeded(%d, %d) = %t, want %t", tc.budget, tc.pruneCount, tc.inventoryCount, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckPruneBudget(t *testing.T) {
+	var inv object.ObjMetadataSet
+	var objs []client.Object
+	for i := 0; i < 10; i++ {
+		obj := fake.ConfigMapObject(core.Name(fmt.Sprintf("cm-%d", i)), core.Namespace("test-namespace"))
+		inv = append(inv, ObjMetaFromObject(obj))
+		objs = append(objs, obj)
+	}
+
+	testCases := []struct {
+		name    string
+		budget  PruneBudget
+		objs    []client.Object
+		ack     string
+		wantErr bool
+		// wantRetained is the number of objects which must not be pruned
+		wantRetained int
+	}{
+		{
+			name:   "budget disabled",
+			budget: PruneBudget{},
+			objs:   nil,
+		},
+		{
+			name:   "within budget",
+			budget: PruneBudget{MaxObjects: 2},
+			objs:   objs[2:],
+		},
+		{
+			name:         "exceeds budget",
+			budget:       PruneBudget{MaxObjects: 2},
+			objs:         objs[3:],
+			wantErr:      true,
+			wantRetained: 3,
+		},
+		{
+			name:         "exceeds budget, acknowledged for another commit",
+			budget:       PruneBudget{MaxPercentage: 50},
+			objs:         objs[6:],
+			ack:          "def456",
+			wantErr:      true,
+			wantRetained: 6,
+		},
+		{
+			name:   "exceeds budget, acknowledged",
+			budget: PruneBudget{MaxPercentage: 50},
+			objs:   objs[6:],
+			ack:    "abc123",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(kinds.RepoSyncV1Beta1())
+			u.SetNamespace("test-namespace")
+			u.SetName("rs")
+			if tc.ack != "" {
+				core.SetAnnotation(u, metadata.PruneBudgetAckAnnotationKey, tc.ack)
+			}
+
+			cs := &ClientSet{
+				InvClient: inventory.NewFakeClient(inv),
+				Client:    testingfake.NewClient(t, core.Scheme, u),
+			}
+			applier, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{PruneBudget: tc.budget})
+			require.NoError(t, err)
+
+			retained, budgetErr := applier.(*supervisor).checkPruneBudget(context.Background(), tc.objs, "abc123")
+			require.Len(t, retained, tc.wantRetained)
+			if tc.wantErr {
+				require.NotNil(t, budgetErr)
+				require.Equal(t, PruneBudgetExceededErrorCode, budgetErr.Code())
+			} else {
+				require.Nil(t, budgetErr)
+			}
+		})
+	}
+}
+
+func TestPruneBudgetExceededErrorTruncation(t *testing.T) {
+	var ids []core.ID
+	for i := 0; i < maxListedPrunes+5; i++ {
+		ids = append(ids, core.IDOf(fake.ConfigMapObject(core.Name(fmt.Sprintf("cm-%02d", i)), core.Namespace("test-namespace"))))
+	}
+	err := PruneBudgetExceededError(PruneBudget{MaxObjects: 1}, "abc123", 100, ids, "RepoSync")
+	require.Contains(t, err.Error(), "cm-19")
+	require.NotContains(t, err.Error(), "cm-20")
+	require.Contains(t, err.Error(), "... (5 more)")
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"sync"
+
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// inventoryRetainer is implemented by the inventory clients which can keep
+// objects in the inventory although they are neither applied nor pruned.
+type inventoryRetainer interface {
+	Retain(objs object.ObjMetadataSet)
+}
+
+// retainingInventoryClient is an inventory.Client which keeps the retained
+// objects in the inventory when it is replaced at the end of an apply.
+//
+// When pruning is disabled, cli-utils replaces the inventory with the applied
+// objects only, which orphans the objects which would have been pruned while
+// they keep the Config Sync metadata. Retaining them records them as skipped
+// deletes instead, so that they are pruned by a later apply.
+type retainingInventoryClient struct {
+	inventory.Client
+
+	mux      sync.Mutex
+	retained object.ObjMetadataSet
+}
+
+var _ inventory.Client = &retainingInventoryClient{}
+var _ inventoryRetainer = &retainingInventoryClient{}
+
+// Retain sets the objects to keep in the inventory when it is next replaced.
+func (c *retainingInventoryClient) Retain(objs object.ObjMetadataSet) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.retained = objs
+}
+
+// Replace replaces the set of objects stored in the inventory with the given
+// objects and the retained objects.
+func (c *retainingInventoryClient) Replace(inv inventory.Info, objs object.ObjMetadataSet, status []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
+	c.mux.Lock()
+	retained := c.retained
+	c.mux.Unlock()
+	objs, status = retainObjects(objs, status, retained)
+	return c.Client.Replace(inv, objs, status, dryRun)
+}
+
+// retainObjects adds the retained objects missing from objs to objs, with a
+// skipped delete status.
+func retainObjects(objs object.ObjMetadataSet, status []actuation.ObjectStatus, retained object.ObjMetadataSet) (object.ObjMetadataSet, []actuation.ObjectStatus) {
+	missing := retained.Diff(objs)
+	if len(missing) == 0 {
+		return objs, status
+	}
+	withStatus := make(map[object.ObjMetadata]bool, len(status))
+	for _, s := range status {
+		withStatus[inventory.ObjMetadataFromObjectReference(s.ObjectReference)] = true
+	}
+	for _, id := range missing {
+		if withStatus[id] {
+			continue
+		}
+		status = append(status, actuation.ObjectStatus{
+			ObjectReference: inventory.ObjectReferenceFromObjMetadata(id),
+			Strategy:        actuation.ActuationStrategyDelete,
+			Actuation:       actuation.ActuationSkipped,
+			Reconcile:       actuation.ReconcileSkipped,
+		})
+	}
+	return objs.Union(missing), status
+}
@@ -0,0 +1,182 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/protection"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+func TestRetainingInventoryClient(t *testing.T) {
+	applied := ObjMetaFromObject(fake.ConfigMapObject(core.Name("applied"), core.Namespace("test-namespace")))
+	kept := ObjMetaFromObject(fake.ConfigMapObject(core.Name("kept"), core.Namespace("test-namespace")))
+	pruned := ObjMetaFromObject(fake.ConfigMapObject(core.Name("pruned"), core.Namespace("test-namespace")))
+
+	fakeClient := inventory.NewFakeClient(object.ObjMetadataSet{applied, kept, pruned})
+	c := &retainingInventoryClient{Client: fakeClient}
+	appliedStatus := []actuation.ObjectStatus{{
+		ObjectReference: inventory.ObjectReferenceFromObjMetadata(applied),
+		Strategy:        actuation.ActuationStrategyApply,
+		Actuation:       actuation.ActuationSucceeded,
+	}}
+
+	c.Retain(object.ObjMetadataSet{kept})
+	require.NoError(t, c.Replace(nil, object.ObjMetadataSet{applied}, appliedStatus, common.DryRunNone))
+	objs, err := fakeClient.GetClusterObjs(nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, object.ObjMetadataSet{applied, kept}, objs)
+
+	_, status := retainObjects(object.ObjMetadataSet{applied}, appliedStatus, object.ObjMetadataSet{kept})
+	require.Equal(t, []actuation.ObjectStatus{appliedStatus[0], {
+		ObjectReference: inventory.ObjectReferenceFromObjMetadata(kept),
+		Strategy:        actuation.ActuationStrategyDelete,
+		Actuation:       actuation.ActuationSkipped,
+		Reconcile:       actuation.ReconcileSkipped,
+	}}, status)
+
+	c.Retain(nil)
+	require.NoError(t, c.Replace(nil, object.ObjMetadataSet{applied}, appliedStatus, common.DryRunNone))
+	objs, err = fakeClient.GetClusterObjs(nil)
+	require.NoError(t, err)
+	require.Equal(t, object.ObjMetadataSet{applied}, objs)
+}
+
+// invSetKptApplier replaces the inventory with the applied objects, as the
+// cli-utils applier does at the end of an apply, and records the options.
+type invSetKptApplier struct {
+	invClient inventory.Client
+	options   apply.ApplierOptions
+}
+
+var _ KptApplier = &invSetKptApplier{}
+
+func (a *invSetKptApplier) Run(_ context.Context, inv inventory.Info, objs object.UnstructuredSet, options apply.ApplierOptions) <-chan event.Event {
+	a.options = options
+	events := make(chan event.Event, 1)
+	if err := a.invClient.Replace(inv, object.UnstructuredSetToObjMetadataSet(objs), nil, common.DryRunNone); err != nil {
+		events <- formErrorEvent(err)
+	}
+	close(events)
+	return events
+}
+
+// unavailableClient fails to get any object of the given kind.
+type unavailableClient struct {
+	client.Client
+	gvk schema.GroupVersionKind
+}
+
+func (c unavailableClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if gvk, err := apiutil.GVKForObject(obj, core.Scheme); err == nil && gvk == c.gvk {
+		return errors.New("connection refused")
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestApplyRetainsUnappliedInventory(t *testing.T) {
+	applied := fake.ConfigMapObject(core.Name("applied"), core.Namespace("test-namespace"))
+	stale := fake.ConfigMapObject(core.Name("stale"), core.Namespace("test-namespace"))
+	secretRef := fake.SecretObject("creds", core.Namespace("test-namespace"),
+		core.Annotation(metadata.SecretFromAnnotationKey, "secret:missing"))
+	kubeSystem := fake.NamespaceObject("kube-system")
+
+	testCases := []struct {
+		name      string
+		inventory []client.Object
+		declared  []client.Object
+		// unavailable is the kind of the objects which cannot be read
+		unavailable schema.GroupVersionKind
+		protection  bool
+		// wantInventory are the objects in the inventory after the apply
+		wantInventory []client.Object
+	}{
+		{
+			name:          "skipped secret reference",
+			inventory:     []client.Object{applied, secretRef, stale},
+			declared:      []client.Object{applied, secretRef},
+			unavailable:   kinds.Secret(),
+			wantInventory: []client.Object{applied, secretRef, stale},
+		},
+		{
+			name:          "protected object not unmanaged",
+			inventory:     []client.Object{applied, kubeSystem, stale},
+			declared:      []client.Object{applied},
+			unavailable:   kinds.Namespace(),
+			protection:    true,
+			wantInventory: []client.Object{applied, kubeSystem, stale},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := &unstructured.Unstructured{}
+			rs.SetGroupVersionKind(kinds.RepoSyncV1Beta1())
+			rs.SetNamespace("test-namespace")
+			rs.SetName("rs")
+			fakeClient := testingfake.NewClient(t, core.Scheme, rs)
+
+			var inv object.ObjMetadataSet
+			for _, obj := range tc.inventory {
+				inv = append(inv, ObjMetaFromObject(obj))
+			}
+			fakeInvClient := inventory.NewFakeClient(inv)
+			invClient := &retainingInventoryClient{Client: fakeInvClient}
+			kptApplier := &invSetKptApplier{invClient: invClient}
+			cs := &ClientSet{
+				KptApplier: kptApplier,
+				InvClient:  invClient,
+				Client:     unavailableClient{Client: fakeClient, gvk: tc.unavailable},
+				Mapper:     fakeClient.RESTMapper(),
+			}
+			if tc.protection {
+				cs.Protection = protection.NewProtector()
+			}
+			applier, err := NewNamespaceSupervisor(cs, "test-namespace", "rs", 5*time.Minute, SupervisorOptions{})
+			require.NoError(t, err)
+
+			_, errs := applier.Apply(context.Background(), tc.declared, "abc123")
+			require.NotNil(t, errs)
+			require.True(t, kptApplier.options.NoPrune)
+
+			var want object.ObjMetadataSet
+			for _, obj := range tc.wantInventory {
+				want = append(want, ObjMetaFromObject(obj))
+			}
+			got, err := fakeInvClient.GetClusterObjs(nil)
+			require.NoError(t, err)
+			require.ElementsMatch(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	syncerreconcile "kpt.dev/configsync/pkg/syncer/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecretMountDir is the directory of the reconciler container which the files
+// referenced by `file:` secret references are mounted under, from the
+// spec.secretVolume of the RootSync.
+const SecretMountDir = "/etc/config-sync/secrets"
+
+// SecretResolver resolves the secret references of declared Secrets, so that
+// the source only contains the references and never the secret data.
+type SecretResolver struct {
+	client   client.Client
+	mountDir string
+}
+
+// NewSecretResolver returns a SecretResolver which reads the referenced
+// Secrets with the given client, and the referenced files under mountDir.
+func NewSecretResolver(c client.Client, mountDir string) *SecretResolver {
+	return &SecretResolver{client: c, mountDir: mountDir}
+}
+
+// hasSecretReference returns true if the object references secret data which
+// needs to be resolved before it is applied.
+func hasSecretReference(obj client.Object) bool {
+	_, found := obj.GetAnnotations()[metadata.SecretFromAnnotationKey]
+	return found
+}
+
+// Resolve returns a copy of the given object with the referenced secret data
+// added to its data. The object itself is never modified, so the secret data
+// does not leak into the declared resources. The returned error never contains
+// secret data.
+func (r *SecretResolver) Resolve(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	data, err := r.data(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	resolved := obj.DeepCopy()
+	for k, v := range data {
+		if err := unstructured.SetNestedField(resolved.Object, base64.StdEncoding.EncodeToString(v), "data", k); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// data returns the secret data referenced by the given object.
+func (r *SecretResolver) data(ctx context.Context, obj client.Object) (map[string][]byte, error) {
+	ref, err := declared.ParseSecretReference(obj.GetAnnotations()[metadata.SecretFromAnnotationKey])
+	if err != nil {
+		return nil, err
+	}
+	if ref.Path != "" {
+		return r.fileData(ref)
+	}
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = obj.GetNamespace()
+	}
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("unable to get the referenced Secret %s: %w", key, err)
+	}
+	return secret.Data, nil
+}
+
+// fileData reads the referenced file, or each regular file of the referenced
+// directory, keyed by their base names. Hidden files, such as the timestamped
+// directories of projected volumes, are skipped.
+func (r *SecretResolver) fileData(ref declared.SecretReference) (map[string][]byte, error) {
+	root, err := filepath.EvalSymlinks(r.mountDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the secrets directory: %w", err)
+	}
+	p, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(ref.Path)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the referenced file %s: %w", ref.Path, err)
+	}
+	if !withinDir(root, p) {
+		return nil, fmt.Errorf("the referenced file %s is outside of the secrets directory", ref.Path)
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the referenced file %s: %w", ref.Path, err)
+	}
+	if !info.IsDir() {
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the referenced file %s: %w", ref.Path, err)
+		}
+		return map[string][]byte{filepath.Base(ref.Path): content}, nil
+	}
+
+	entries, err := ioutil.ReadDir(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the referenced directory %s: %w", ref.Path, err)
+	}
+	data := make(map[string][]byte)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		f, err := filepath.EvalSymlinks(filepath.Join(p, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the referenced file %s/%s: %w", ref.Path, e.Name(), err)
+		}
+		if !withinDir(root, f) {
+			return nil, fmt.Errorf("the referenced file %s/%s is outside of the secrets directory", ref.Path, e.Name())
+		}
+		if info, err := os.Stat(f); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		content, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the referenced file %s/%s: %w", ref.Path, e.Name(), err)
+		}
+		data[e.Name()] = content
+	}
+	return data, nil
+}
+
+// withinDir returns true if path p is dir or one of its descendants.
+func withinDir(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// digest returns a digest of the secret data referenced by the given objects,
+// which changes whenever any of the data changes, or fails to resolve.
+func (r *SecretResolver) digest(ctx context.Context, objs []*unstructured.Unstructured) string {
+	h := sha256.New()
+	for _, obj := range objs {
+		fmt.Fprintf(h, "%s\n", core.IDOf(obj))
+		data, err := r.data(ctx, obj)
+		if err != nil {
+			fmt.Fprintf(h, "error: %v\n", err)
+			continue
+		}
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sum := sha256.Sum256(data[k])
+			fmt.Fprintf(h, "%s=%s\n", k, hex.EncodeToString(sum[:]))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// secretResolvingApplier resolves the secret references of the objects
+// created or updated by the remediator, so that it does not revert the
+// resolved secret data.
+type secretResolvingApplier struct {
+	syncerreconcile.Applier
+	resolver *SecretResolver
+}
+
+// WithSecretResolver wraps the given Applier so that the secret references of
+// the objects it creates or updates are resolved first.
+func WithSecretResolver(a syncerreconcile.Applier, r *SecretResolver) syncerreconcile.Applier {
+	return &secretResolvingApplier{Applier: a, resolver: r}
+}
+
+// Create implements syncerreconcile.Applier.
+func (a *secretResolvingApplier) Create(ctx context.Context, obj *unstructured.Unstructured) (bool, status.Error) {
+	if hasSecretReference(obj) {
+		resolved, err := a.resolver.Resolve(ctx, obj)
+		if err != nil {
+			return false, ErrorForResource(fmt.Errorf("unable to resolve the secret reference: %w", err), core.IDOf(obj))
+		}
+		obj = resolved
+	}
+	return a.Applier.Create(ctx, obj)
+}
+
+// Update implements syncerreconcile.Applier.
+func (a *secretResolvingApplier) Update(ctx context.Context, intendedState, currentState *unstructured.Unstructured) (bool, status.Error) {
+	if hasSecretReference(intendedState) {
+		resolved, err := a.resolver.Resolve(ctx, intendedState)
+		if err != nil {
+			return false, ErrorForResource(fmt.Errorf("unable to resolve the secret reference: %w", err), core.IDOf(intendedState))
+		}
+		intendedState = resolved
+	}
+	return a.Applier.Update(ctx, intendedState, currentState)
+}
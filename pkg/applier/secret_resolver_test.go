@@ -0,0 +1,149 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func declaredSecret(ref string) *unstructured.Unstructured {
+	u := fake.UnstructuredObject(corev1.SchemeGroupVersion.WithKind("Secret"),
+		core.Name("creds"), core.Namespace("bookstore"),
+		core.Annotation(metadata.SecretFromAnnotationKey, ref))
+	u.Object["data"] = map[string]interface{}{"user": "YWRtaW4="}
+	return u
+}
+
+func TestSecretResolver_Resolve(t *testing.T) {
+	mountDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mountDir, "db"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"db/password": "hunter2",
+		"db/.hidden":  "skipped",
+		"token":       "t0ken",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(mountDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := filepath.Join(t.TempDir(), "outside")
+	if err := ioutil.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(mountDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	vault := fake.SecretObject("vault-creds", core.Namespace("vault"))
+	vault.Data = map[string][]byte{"password": []byte("s3cret")}
+	local := fake.SecretObject("local-creds", core.Namespace("bookstore"))
+	local.Data = map[string][]byte{"password": []byte("l0cal")}
+	r := NewSecretResolver(testingfake.NewClient(t, core.Scheme, vault, local), mountDir)
+
+	testCases := []struct {
+		name     string
+		ref      string
+		wantData map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "Secret in another namespace",
+			ref:      "secret:vault/vault-creds",
+			wantData: map[string]interface{}{"user": "YWRtaW4=", "password": "czNjcmV0"},
+		},
+		{
+			name:     "Secret in the same namespace",
+			ref:      "secret:local-creds",
+			wantData: map[string]interface{}{"user": "YWRtaW4=", "password": "bDBjYWw="},
+		},
+		{
+			name:     "mounted file",
+			ref:      "file:token",
+			wantData: map[string]interface{}{"user": "YWRtaW4=", "token": "dDBrZW4="},
+		},
+		{
+			name:     "mounted directory",
+			ref:      "file:db",
+			wantData: map[string]interface{}{"user": "YWRtaW4=", "password": "aHVudGVyMg=="},
+		},
+		{
+			name:    "missing Secret",
+			ref:     "secret:vault/missing",
+			wantErr: true,
+		},
+		{
+			name:    "file linked outside of the secrets directory",
+			ref:     "file:escape",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := declaredSecret(tc.ref)
+			resolved, err := r.Resolve(context.Background(), obj)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("got Resolve() error nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got Resolve() error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tc.wantData, resolved.Object["data"]); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(declaredSecret(tc.ref), obj); diff != "" {
+				t.Errorf("Resolve() modified the declared object: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSecretResolver_Digest(t *testing.T) {
+	vault := fake.SecretObject("vault-creds", core.Namespace("vault"))
+	vault.Data = map[string][]byte{"password": []byte("s3cret")}
+	c := testingfake.NewClient(t, core.Scheme, vault)
+	r := NewSecretResolver(c, t.TempDir())
+	objs := []*unstructured.Unstructured{declaredSecret("secret:vault/vault-creds")}
+	ctx := context.Background()
+
+	before := r.digest(ctx, objs)
+	if got := r.digest(ctx, objs); got != before {
+		t.Errorf("got digest %s, want the unchanged digest %s", got, before)
+	}
+	vault.Data["password"] = []byte("rotated")
+	if err := c.Update(ctx, vault); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.digest(ctx, objs); got == before {
+		t.Error("got an unchanged digest after the referenced Secret changed")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// namespaceStatusWatcher watches the status of the objects in each of their
+// namespaces. By default, the objects of several namespaces are watched across
+// the cluster, which a namespace reconciler managing several namespaces is not
+// allowed to do.
+type namespaceStatusWatcher struct {
+	watcher.StatusWatcher
+}
+
+// Watch implements watcher.StatusWatcher.
+func (w namespaceStatusWatcher) Watch(ctx context.Context, ids object.ObjMetadataSet, opts watcher.Options) <-chan event.Event {
+	opts.RESTScopeStrategy = watcher.RESTScopeNamespace
+	return w.StatusWatcher.Watch(ctx, ids, opts)
+}
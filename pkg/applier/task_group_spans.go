@@ -0,0 +1,67 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"context"
+	"strings"
+
+	"go.opencensus.io/trace"
+	"kpt.dev/configsync/pkg/tracing"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+)
+
+// taskGroupSpans traces the task groups run by the applier, such as the
+// apply, wait and prune tasks of each wave, as children of the span in ctx.
+type taskGroupSpans struct {
+	ctx    context.Context
+	commit string
+	// spans are the spans of the started task groups, by group name.
+	spans map[string]*trace.Span
+}
+
+func newTaskGroupSpans(ctx context.Context, commit string) *taskGroupSpans {
+	return &taskGroupSpans{
+		ctx:    ctx,
+		commit: commit,
+		spans:  make(map[string]*trace.Span),
+	}
+}
+
+// handle starts the span of a task group when it starts, and ends it when it
+// finishes.
+func (t *taskGroupSpans) handle(e event.ActionGroupEvent) {
+	switch e.Status {
+	case event.Started:
+		name := "applier." + strings.ToLower(e.Action.String())
+		_, span := tracing.StartSpan(t.ctx, name, t.commit)
+		span.AddAttributes(trace.StringAttribute(tracing.TaskGroupKey, e.GroupName))
+		t.spans[e.GroupName] = span
+	case event.Finished:
+		if span, found := t.spans[e.GroupName]; found {
+			span.End()
+			delete(t.spans, e.GroupName)
+		}
+	}
+}
+
+// end ends the spans of the task groups which never finished, which happens
+// when the run is canceled.
+func (t *taskGroupSpans) end() {
+	for name, span := range t.spans {
+		span.End()
+		delete(t.spans, name)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ChangeSummary is the set of changes between the current resource
+// declarations and a new set of declarations. Each list is sorted by object ID.
+type ChangeSummary struct {
+	// Create holds the new declarations of objects which are not currently
+	// declared.
+	Create []*unstructured.Unstructured
+	// Update holds the new declarations of objects whose declaration changed.
+	Update []*unstructured.Unstructured
+	// Prune holds the current declarations of objects which are no longer
+	// declared.
+	Prune []*unstructured.Unstructured
+}
+
+// Empty returns true if there are no changes.
+func (cs ChangeSummary) Empty() bool {
+	return len(cs.Create) == 0 && len(cs.Update) == 0 && len(cs.Prune) == 0
+}
+
+// ChangeSummary computes the changes between the current resource
+// declarations and the given objects, without updating the declarations.
+// Returns false if the declarations have never been set, in which case there
+// is no baseline to compare to.
+func (r *Resources) ChangeSummary(objects []client.Object) (ChangeSummary, bool, status.Error) {
+	var summary ChangeSummary
+	previousSet := r.getObjectSet()
+	if previousSet == nil {
+		return summary, false, nil
+	}
+
+	seen := make(map[core.ID]bool, len(objects))
+	for _, obj := range objects {
+		if obj == nil {
+			continue
+		}
+		id := core.IDOf(obj)
+		seen[id] = true
+		u, err := reconcile.AsUnstructuredSanitized(obj)
+		if err != nil {
+			return summary, false, err
+		}
+		previous, found := previousSet[id]
+		switch {
+		case !found:
+			summary.Create = append(summary.Create, u)
+		case !declarationsEqual(previous, u):
+			summary.Update = append(summary.Update, u)
+		}
+	}
+	for id, obj := range previousSet {
+		if !seen[id] {
+			summary.Prune = append(summary.Prune, obj)
+		}
+	}
+
+	sortByID(summary.Create)
+	sortByID(summary.Update)
+	sortByID(summary.Prune)
+	return summary, true, nil
+}
+
+// declarationsEqual returns true if the two declarations of an object are
+// equal, ignoring the annotations which change with every commit.
+func declarationsEqual(previous, current *unstructured.Unstructured) bool {
+	previous = previous.DeepCopy()
+	current = current.DeepCopy()
+	core.RemoveAnnotations(previous, metadata.SyncTokenAnnotationKey)
+	core.RemoveAnnotations(current, metadata.SyncTokenAnnotationKey)
+	return equality.Semantic.DeepEqual(previous.Object, current.Object)
+}
+
+func sortByID(objs []*unstructured.Unstructured) {
+	sort.Slice(objs, func(i, j int) bool {
+		return core.IDOf(objs[i]).String() < core.IDOf(objs[j]).String()
+	})
+}
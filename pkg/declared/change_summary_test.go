@@ -0,0 +1,81 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestChangeSummary(t *testing.T) {
+	dr := Resources{}
+	if _, found, err := dr.ChangeSummary(testSet); err != nil || found {
+		t.Fatalf("got dr.ChangeSummary() = _, %t, %v, want _, false, nil before the first update", found, err)
+	}
+
+	kept := fake.RoleObject(core.Name("kept"), core.Namespace("bar"),
+		core.Annotation(metadata.SyncTokenAnnotationKey, "commit1"))
+	changed := fake.RoleObject(core.Name("changed"), core.Namespace("bar"))
+	pruned := fake.RoleObject(core.Name("pruned"), core.Namespace("bar"))
+	if _, err := dr.Update(context.Background(), []client.Object{kept, changed, pruned}); err != nil {
+		t.Fatal(err)
+	}
+
+	keptNewCommit := fake.RoleObject(core.Name("kept"), core.Namespace("bar"),
+		core.Annotation(metadata.SyncTokenAnnotationKey, "commit2"))
+	changedNewCommit := fake.RoleObject(core.Name("changed"), core.Namespace("bar"), core.Label("foo", "bar"))
+	created := fake.RoleObject(core.Name("created"), core.Namespace("bar"))
+
+	summary, found, err := dr.ChangeSummary([]client.Object{keptNewCommit, changedNewCommit, created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("got dr.ChangeSummary() found = false, want true")
+	}
+	want := map[string][]core.ID{
+		"create": {core.IDOf(created)},
+		"update": {core.IDOf(changedNewCommit)},
+		"prune":  {core.IDOf(pruned)},
+	}
+	got := map[string][]core.ID{
+		"create": idsOf(summary.Create),
+		"update": idsOf(summary.Update),
+		"prune":  idsOf(summary.Prune),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+
+	// Computing the summary must not update the declarations.
+	if _, found := dr.Get(core.IDOf(created)); found {
+		t.Errorf("got dr.Get(%v) found = true, want false", core.IDOf(created))
+	}
+}
+
+func idsOf(objs []*unstructured.Unstructured) []core.ID {
+	var ids []core.ID
+	for _, obj := range objs {
+		ids = append(ids, core.IDOf(obj))
+	}
+	return ids
+}
@@ -0,0 +1,348 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldPath is a parsed path to a field in an object. Each element is either a
+// map key or, when the parent is a list, a list index.
+type FieldPath []string
+
+// String returns the path in JSON pointer notation.
+func (p FieldPath) String() string {
+	var sb strings.Builder
+	for _, e := range p {
+		sb.WriteString("/")
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(e))
+	}
+	return sb.String()
+}
+
+// ParseFieldPath parses either an RFC 6901 JSON pointer (e.g.
+// "/spec/replicas") or a dot-notation JSONPath expression (e.g.
+// ".spec.replicas", "$.metadata.annotations['example.com/key']").
+func ParseFieldPath(s string) (FieldPath, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "/"):
+		return parseJSONPointer(s)
+	case strings.HasPrefix(s, "$."), strings.HasPrefix(s, "."):
+		return parseJSONPath(strings.TrimPrefix(s, "$"))
+	default:
+		return nil, fmt.Errorf("field path %q must be a JSON pointer starting with \"/\" or a JSONPath starting with \".\"", s)
+	}
+}
+
+func parseJSONPointer(s string) (FieldPath, error) {
+	var path FieldPath
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for _, e := range strings.Split(s[1:], "/") {
+		if e == "" {
+			return nil, fmt.Errorf("JSON pointer %q has an empty element", s)
+		}
+		path = append(path, unescape.Replace(e))
+	}
+	return path, nil
+}
+
+func parseJSONPath(s string) (FieldPath, error) {
+	var path FieldPath
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			j := i + 1
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("JSONPath %q has an empty element", s)
+			}
+			path = append(path, s[i+1:j])
+			i = j
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unterminated %q", s, "[")
+			}
+			e := s[i+1 : i+end]
+			if len(e) >= 2 && (e[0] == '\'' || e[0] == '"') && e[len(e)-1] == e[0] {
+				e = e[1 : len(e)-1]
+			} else if _, err := strconv.Atoi(e); err != nil {
+				return nil, fmt.Errorf("JSONPath %q: %q must be a quoted key or a list index", s, e)
+			}
+			if e == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty element", s)
+			}
+			path = append(path, e)
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("JSONPath %q: unexpected character %q", s, s[i])
+		}
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("JSONPath %q selects the whole object", s)
+	}
+	return path, nil
+}
+
+// protectedPaths are the fields which can never be ignored, since they
+// identify the object or carry the Config Sync metadata that manages it.
+var protectedPaths = []FieldPath{
+	{"apiVersion"},
+	{"kind"},
+	{"metadata", "name"},
+	{"metadata", "namespace"},
+}
+
+func validateFieldPath(p FieldPath) error {
+	for _, protected := range protectedPaths {
+		if hasPrefix(protected, p) {
+			return fmt.Errorf("field %s cannot be ignored", p)
+		}
+	}
+	if len(p) < 2 || p[0] != "metadata" || (p[1] != "annotations" && p[1] != "labels") {
+		return nil
+	}
+	// Ignoring the whole map would also ignore the Config Sync metadata in it.
+	if len(p) == 2 {
+		return fmt.Errorf("field %s cannot be ignored, ignore individual keys instead", p)
+	}
+	if metadata.IsConfigSyncAnnotationKey(p[2]) || metadata.IsConfigSyncLabelKey(p[2]) ||
+		hasProtectedKeyPrefix(p[2]) {
+		return fmt.Errorf("Config Sync metadata %s cannot be ignored", p)
+	}
+	return nil
+}
+
+// protectedKeyDomains are the domains of annotation and label keys which are
+// owned by Config Sync or by the cli-utils applier it uses.
+var protectedKeyDomains = []string{
+	configsync.GroupName,
+	configmanagement.GroupName,
+	"config.k8s.io",
+}
+
+// hasProtectedKeyPrefix returns true if the prefix of the given annotation or
+// label key is one of protectedKeyDomains or a subdomain of one.
+func hasProtectedKeyPrefix(k string) bool {
+	i := strings.IndexByte(k, '/')
+	if i < 0 {
+		return false
+	}
+	domain := k[:i]
+	for _, d := range protectedKeyDomains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrefix returns true if prefix is equal to or an ancestor of p, or if p
+// is an ancestor of prefix.
+func hasPrefix(p, prefix FieldPath) bool {
+	n := len(prefix)
+	if len(p) < n {
+		n = len(p)
+	}
+	for i := 0; i < n; i++ {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreRule selects fields of matching objects which are stripped from their
+// declared configuration.
+type IgnoreRule struct {
+	GroupKind schema.GroupKind
+	Name      string
+	Namespace string
+	Paths     []FieldPath
+}
+
+// Matches returns true if the rule applies to the given object.
+func (r IgnoreRule) Matches(obj client.Object) bool {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	return gk == r.GroupKind &&
+		(r.Name == "" || r.Name == obj.GetName()) &&
+		(r.Namespace == "" || r.Namespace == obj.GetNamespace())
+}
+
+// IgnoreRules is the set of RootSync/RepoSync level rules selecting fields
+// which Config Sync must neither apply nor revert.
+type IgnoreRules []IgnoreRule
+
+// NewIgnoreRules converts and validates the spec.ignoreDifferences field of a
+// RootSync or RepoSync.
+func NewIgnoreRules(diffs []v1beta1.IgnoreDifference) (IgnoreRules, error) {
+	var rules IgnoreRules
+	for i, d := range diffs {
+		if d.Kind == "" {
+			return nil, fmt.Errorf("ignoreDifferences[%d].kind must be specified", i)
+		}
+		if len(d.JSONPointers) == 0 && len(d.JSONPaths) == 0 {
+			return nil, fmt.Errorf("ignoreDifferences[%d] must specify jsonPointers or jsonPaths", i)
+		}
+		rule := IgnoreRule{
+			GroupKind: schema.GroupKind{Group: d.Group, Kind: d.Kind},
+			Name:      d.Name,
+			Namespace: d.Namespace,
+		}
+		for _, s := range append(append([]string{}, d.JSONPointers...), d.JSONPaths...) {
+			p, err := ParseFieldPath(s)
+			if err == nil {
+				err = validateFieldPath(p)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("ignoreDifferences[%d]: %w", i, err)
+			}
+			rule.Paths = append(rule.Paths, p)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParseIgnoreRules parses the JSON encoding of the spec.ignoreDifferences
+// field, as passed to the reconciler by the reconciler-manager.
+func ParseIgnoreRules(s string) (IgnoreRules, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var diffs []v1beta1.IgnoreDifference
+	if err := json.Unmarshal([]byte(s), &diffs); err != nil {
+		return nil, fmt.Errorf("unable to decode ignoreDifferences: %w", err)
+	}
+	return NewIgnoreRules(diffs)
+}
+
+// IgnoredFieldsFromAnnotation returns the fields listed in the
+// `configsync.gke.io/ignore-differences` annotation of the given object. The
+// annotation holds a comma or newline separated list of JSON pointers or
+// JSONPath expressions.
+func IgnoredFieldsFromAnnotation(obj client.Object) ([]FieldPath, error) {
+	value, found := obj.GetAnnotations()[metadata.IgnoreDifferencesAnnotationKey]
+	if !found {
+		return nil, nil
+	}
+	var paths []FieldPath
+	for _, s := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		p, err := ParseFieldPath(s)
+		if err == nil {
+			err = validateFieldPath(p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// IgnoredFields returns all the fields of the given object which are selected
+// by either the rules or the object's ignore-differences annotation.
+func (rs IgnoreRules) IgnoredFields(obj client.Object) ([]FieldPath, error) {
+	paths, err := IgnoredFieldsFromAnnotation(obj)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rs {
+		if r.Matches(obj) {
+			paths = append(paths, r.Paths...)
+		}
+	}
+	return paths, nil
+}
+
+// Strip removes all the ignored fields from the given object in place.
+// Fields which do not exist on the object are skipped.
+//
+// Note that omitting a field which Config Sync previously applied causes
+// server-side apply to remove the field, unless another field manager has
+// since taken ownership of it.
+func (rs IgnoreRules) Strip(u *unstructured.Unstructured) error {
+	paths, err := rs.IgnoredFields(u)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		RemoveField(u.Object, p)
+	}
+	return nil
+}
+
+// RemoveField removes the field at the given path from obj, returning true if
+// the field was found.
+func RemoveField(obj map[string]interface{}, path FieldPath) bool {
+	if len(path) == 0 {
+		return false
+	}
+	child, found := obj[path[0]]
+	if !found {
+		return false
+	}
+	if len(path) == 1 {
+		delete(obj, path[0])
+		return true
+	}
+	switch c := child.(type) {
+	case map[string]interface{}:
+		return RemoveField(c, path[1:])
+	case []interface{}:
+		list, removed := removeFromList(c, path[1:])
+		obj[path[0]] = list
+		return removed
+	default:
+		return false
+	}
+}
+
+func removeFromList(list []interface{}, path FieldPath) ([]interface{}, bool) {
+	idx, err := strconv.Atoi(path[0])
+	if err != nil || idx < 0 || idx >= len(list) {
+		return list, false
+	}
+	if len(path) == 1 {
+		return append(list[:idx:idx], list[idx+1:]...), true
+	}
+	switch c := list[idx].(type) {
+	case map[string]interface{}:
+		return list, RemoveField(c, path[1:])
+	case []interface{}:
+		var removed bool
+		list[idx], removed = removeFromList(c, path[1:])
+		return list, removed
+	default:
+		return list, false
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/metadata"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	testCases := []struct {
+		name    string
+		path    string
+		want    FieldPath
+		wantErr bool
+	}{
+		{
+			name: "JSON pointer",
+			path: "/spec/replicas",
+			want: FieldPath{"spec", "replicas"},
+		},
+		{
+			name: "JSON pointer with escaped characters",
+			path: "/metadata/annotations/sidecar.istio.io~1status",
+			want: FieldPath{"metadata", "annotations", "sidecar.istio.io/status"},
+		},
+		{
+			name: "JSONPath",
+			path: ".spec.replicas",
+			want: FieldPath{"spec", "replicas"},
+		},
+		{
+			name: "JSONPath with root and quoted key",
+			path: "$.metadata.annotations['sidecar.istio.io/status']",
+			want: FieldPath{"metadata", "annotations", "sidecar.istio.io/status"},
+		},
+		{
+			name: "JSONPath with list index",
+			path: ".webhooks[0].clientConfig.caBundle",
+			want: FieldPath{"webhooks", "0", "clientConfig", "caBundle"},
+		},
+		{
+			name:    "missing prefix",
+			path:    "spec.replicas",
+			wantErr: true,
+		},
+		{
+			name:    "empty JSON pointer element",
+			path:    "/spec//replicas",
+			wantErr: true,
+		},
+		{
+			name:    "JSONPath filter",
+			path:    ".spec.containers[?(@.name=='istio-proxy')]",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseFieldPath(tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseFieldPath(%q) = %v, want error", tc.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFieldPath(%q) got unexpected error: %v", tc.path, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestNewIgnoreRules(t *testing.T) {
+	testCases := []struct {
+		name    string
+		diffs   []v1beta1.IgnoreDifference
+		wantErr bool
+	}{
+		{
+			name: "valid rule",
+			diffs: []v1beta1.IgnoreDifference{{
+				Group:        "apps",
+				Kind:         "Deployment",
+				JSONPointers: []string{"/spec/replicas"},
+			}},
+		},
+		{
+			name: "missing kind",
+			diffs: []v1beta1.IgnoreDifference{{
+				JSONPointers: []string{"/spec/replicas"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "missing paths",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind: "Deployment",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "identity field",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:         "ConfigMap",
+				JSONPointers: []string{"/metadata/name"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "parent of identity field",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:      "ConfigMap",
+				JSONPaths: []string{".metadata"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "Config Sync annotation",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:      "ConfigMap",
+				JSONPaths: []string{".metadata.annotations['configsync.gke.io/manager']"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "all annotations",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:         "ConfigMap",
+				JSONPointers: []string{"/metadata/annotations"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "all labels",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:      "ConfigMap",
+				JSONPaths: []string{".metadata.labels"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unknown Config Sync annotation",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:         "ConfigMap",
+				JSONPointers: []string{"/metadata/annotations/configsync.gke.io~1future"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "cli-utils annotation",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:      "ConfigMap",
+				JSONPaths: []string{".metadata.annotations['config.k8s.io/depends-on']"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "cli-utils subdomain label",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:      "ConfigMap",
+				JSONPaths: []string{".metadata.labels['client.lifecycle.config.k8s.io/x']"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "user annotation",
+			diffs: []v1beta1.IgnoreDifference{{
+				Kind:      "ConfigMap",
+				JSONPaths: []string{".metadata.annotations['example.com/key']"},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewIgnoreRules(tc.diffs)
+			if tc.wantErr != (err != nil) {
+				t.Errorf("NewIgnoreRules() got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestIgnoreRulesStrip(t *testing.T) {
+	rules, err := NewIgnoreRules([]v1beta1.IgnoreDifference{
+		{
+			Group:        "apps",
+			Kind:         "Deployment",
+			JSONPointers: []string{"/spec/replicas"},
+		},
+		{
+			Group:     "admissionregistration.k8s.io",
+			Kind:      "ValidatingWebhookConfiguration",
+			Name:      "webhook",
+			JSONPaths: []string{".webhooks[0].clientConfig.caBundle"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		obj  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "rule matches",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "foo"},
+				"spec":       map[string]interface{}{"replicas": int64(3), "paused": true},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "foo"},
+				"spec":       map[string]interface{}{"paused": true},
+			},
+		},
+		{
+			name: "rule does not match kind",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "foo"},
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "foo"},
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+			},
+		},
+		{
+			name: "rule with name and list index",
+			obj: map[string]interface{}{
+				"apiVersion": "admissionregistration.k8s.io/v1",
+				"kind":       "ValidatingWebhookConfiguration",
+				"metadata":   map[string]interface{}{"name": "webhook"},
+				"webhooks": []interface{}{
+					map[string]interface{}{
+						"name":         "a.example.com",
+						"clientConfig": map[string]interface{}{"caBundle": "abc", "url": "https://example.com"},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "admissionregistration.k8s.io/v1",
+				"kind":       "ValidatingWebhookConfiguration",
+				"metadata":   map[string]interface{}{"name": "webhook"},
+				"webhooks": []interface{}{
+					map[string]interface{}{
+						"name":         "a.example.com",
+						"clientConfig": map[string]interface{}{"url": "https://example.com"},
+					},
+				},
+			},
+		},
+		{
+			name: "annotation",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "cm",
+					"annotations": map[string]interface{}{
+						metadata.IgnoreDifferencesAnnotationKey: "/data/generated, .metadata.labels['example.com/injected']",
+					},
+					"labels": map[string]interface{}{"example.com/injected": "true", "team": "a"},
+				},
+				"data": map[string]interface{}{"generated": "x", "declared": "y"},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "cm",
+					"annotations": map[string]interface{}{
+						metadata.IgnoreDifferencesAnnotationKey: "/data/generated, .metadata.labels['example.com/injected']",
+					},
+					"labels": map[string]interface{}{"team": "a"},
+				},
+				"data": map[string]interface{}{"declared": "y"},
+			},
+		},
+		{
+			name: "missing fields are skipped",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "foo"},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "foo"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: tc.obj}
+			if err := rules.Strip(u); err != nil {
+				t.Fatalf("Strip() got unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, u.Object); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRemoveFieldFromList(t *testing.T) {
+	obj := map[string]interface{}{
+		"items": []interface{}{"a", "b", "c"},
+	}
+	if !RemoveField(obj, FieldPath{"items", "1"}) {
+		t.Fatal("RemoveField() = false, want true")
+	}
+	want := map[string]interface{}{
+		"items": []interface{}{"a", "c"},
+	}
+	if diff := cmp.Diff(want, obj); diff != "" {
+		t.Error(diff)
+	}
+	if RemoveField(obj, FieldPath{"items", "5"}) {
+		t.Error("RemoveField() of out-of-range index = true, want false")
+	}
+}
@@ -39,6 +39,10 @@ type Resources struct {
 	// directly. The map should never be written to once it has been assigned to
 	// this reference; it should be treated as read-only from then on.
 	objectSet map[core.ID]*unstructured.Unstructured
+	// retained are the objects which are no longer declared, but which must
+	// not be deleted yet, since their prune is blocked by the prune budget.
+	// Like objectSet, the map is treated as read-only once assigned.
+	retained map[core.ID]bool
 }
 
 // Update performs an atomic update on the resource declaration set.
@@ -77,6 +81,27 @@ func (r *Resources) Update(ctx context.Context, objects []client.Object) ([]clie
 	return newObjects, nil
 }
 
+// Retain keeps the given objects from being deleted while they are not
+// declared, until the next call to Retain. It is called before the declared
+// objects are updated, so that the objects are never deleted in between.
+func (r *Resources) Retain(ids []core.ID) {
+	retained := make(map[core.ID]bool, len(ids))
+	for _, id := range ids {
+		retained[id] = true
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.retained = retained
+}
+
+// Retained returns true if the object must not be deleted while it is not
+// declared.
+func (r *Resources) Retained(id core.ID) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.retained[id]
+}
+
 // Get returns a copy of the resource declaration as read from Git
 func (r *Resources) Get(id core.ID) (*unstructured.Unstructured, bool) {
 	objSet := r.getObjectSet()
@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declared
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// secretReferencePrefix prefixes a reference to a Secret, as either
+	// `secret:<name>` or `secret:<namespace>/<name>`.
+	secretReferencePrefix = "secret:"
+	// fileReferencePrefix prefixes a reference to a file or directory mounted
+	// in the reconciler container, as `file:<path>`.
+	fileReferencePrefix = "file:"
+)
+
+// SecretReference is the parsed value of the secret-from annotation, which
+// points to the secret data of a declared Secret held outside of the source.
+// Exactly one of Name and Path is set.
+type SecretReference struct {
+	// Namespace is the namespace of the referenced Secret. It is empty if the
+	// Secret is in the same namespace as the declared Secret.
+	Namespace string
+	// Name is the name of the referenced Secret.
+	Name string
+	// Path is the slash-separated path of the referenced file or directory,
+	// relative to the directory the secrets are mounted in.
+	Path string
+}
+
+// String returns the reference in the format of the secret-from annotation.
+func (r SecretReference) String() string {
+	switch {
+	case r.Path != "":
+		return fileReferencePrefix + r.Path
+	case r.Namespace != "":
+		return secretReferencePrefix + r.Namespace + "/" + r.Name
+	default:
+		return secretReferencePrefix + r.Name
+	}
+}
+
+// ParseSecretReference parses the value of the secret-from annotation.
+func ParseSecretReference(value string) (SecretReference, error) {
+	switch {
+	case strings.HasPrefix(value, secretReferencePrefix):
+		parts := strings.Split(strings.TrimPrefix(value, secretReferencePrefix), "/")
+		for _, p := range parts {
+			if p == "" {
+				return SecretReference{}, fmt.Errorf("invalid Secret reference %q: want %s<name> or %s<namespace>/<name>", value, secretReferencePrefix, secretReferencePrefix)
+			}
+		}
+		switch len(parts) {
+		case 1:
+			return SecretReference{Name: parts[0]}, nil
+		case 2:
+			return SecretReference{Namespace: parts[0], Name: parts[1]}, nil
+		default:
+			return SecretReference{}, fmt.Errorf("invalid Secret reference %q: want %s<name> or %s<namespace>/<name>", value, secretReferencePrefix, secretReferencePrefix)
+		}
+	case strings.HasPrefix(value, fileReferencePrefix):
+		p := strings.TrimPrefix(value, fileReferencePrefix)
+		if p == "" || path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") {
+			return SecretReference{}, fmt.Errorf("invalid file reference %q: want %s<path> with a clean path relative to the secrets directory", value, fileReferencePrefix)
+		}
+		return SecretReference{Path: p}, nil
+	default:
+		return SecretReference{}, fmt.Errorf("invalid secret reference %q: want a %s or %s reference", value, secretReferencePrefix, fileReferencePrefix)
+	}
+}
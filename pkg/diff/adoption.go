@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EffectiveAdoptionPolicy returns the adoption policy of the declared object:
+// the value of its adoption annotation, if any, or the given default policy of
+// its RootSync or RepoSync otherwise.
+func EffectiveAdoptionPolicy(decl client.Object, defaultPolicy metadata.AdoptionPolicy) metadata.AdoptionPolicy {
+	if policy, found := decl.GetAnnotations()[metadata.AdoptionAnnotationKey]; found {
+		return metadata.AdoptionPolicy(policy)
+	}
+	return defaultPolicy
+}
+
+// NeedsAdoption returns true if the live object is not yet managed by the
+// given reconciler, so applying the declared object would adopt it.
+// An object is managed by the reconciler if either its manager annotation or
+// its owning-inventory annotation match the declared object.
+func NeedsAdoption(scope declared.Scope, syncName string, decl, actual client.Object) bool {
+	if IsManager(scope, syncName, actual) {
+		return false
+	}
+	inventoryID := core.GetAnnotation(decl, metadata.OwningInventoryKey)
+	return inventoryID == "" || core.GetAnnotation(actual, metadata.OwningInventoryKey) != inventoryID
+}
+
+// PreviousManager describes what manages the live object, other than the
+// given reconciler: "configsync:<manager>" for another RootSync or RepoSync,
+// "inventory:<id>" for another inventory, "helm:<namespace>/<release>" for a
+// Helm release, or "kubectl" for an object applied with kubectl.
+// Returns true if the previous manager prevents adopting the object with the
+// ifUnmanaged policy. Objects applied with kubectl are considered unmanaged.
+func PreviousManager(scope declared.Scope, syncName string, decl, actual client.Object) (string, bool) {
+	manager := core.GetAnnotation(actual, metadata.ResourceManagerKey)
+	if differ.ManagementEnabled(actual) && manager != "" && manager != declared.ResourceManager(scope, syncName) {
+		return "configsync:" + manager, true
+	}
+	inventoryID := core.GetAnnotation(actual, metadata.OwningInventoryKey)
+	if inventoryID != "" && inventoryID != core.GetAnnotation(decl, metadata.OwningInventoryKey) {
+		return "inventory:" + inventoryID, true
+	}
+	if IsHelmManaged(actual) {
+		return "helm:" + core.GetAnnotation(actual, metadata.HelmReleaseNamespaceAnnotationKey) +
+			"/" + core.GetAnnotation(actual, metadata.HelmReleaseNameAnnotationKey), true
+	}
+	if _, found := actual.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; found {
+		return "kubectl", false
+	}
+	return "", false
+}
+
+// IsHelmManaged returns true if the object has the ownership metadata of a
+// Helm release.
+func IsHelmManaged(obj client.Object) bool {
+	if core.GetLabel(obj, metadata.ManagedByKey) == metadata.HelmManagedByValue {
+		return true
+	}
+	_, found := obj.GetAnnotations()[metadata.HelmReleaseNameAnnotationKey]
+	return found
+}
+
+// CanAdopt returns true if the adoption policy allows adopting a pre-existing
+// object. The managed argument is whether the object has another manager, as
+// returned by PreviousManager.
+// An empty policy always allows adoption, for backwards compatibility.
+func CanAdopt(policy metadata.AdoptionPolicy, managed bool) bool {
+	switch policy {
+	case metadata.AdoptionPolicyNever:
+		return false
+	case metadata.AdoptionPolicyIfUnmanaged:
+		return !managed
+	default:
+		return true
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestNeedsAdoptionAndPreviousManager(t *testing.T) {
+	rootSync := declared.ResourceManager(declared.RootReconciler, configsync.RootSyncName)
+	otherSync := declared.ResourceManager(declared.RootReconciler, "other")
+	inventoryID := "config-management-system_root-sync"
+	resourceID := core.GKNN(fake.RoleObject())
+	decl := fake.RoleObject(
+		core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
+		core.Annotation(metadata.ResourceManagerKey, rootSync),
+		core.Annotation(metadata.OwningInventoryKey, inventoryID))
+
+	testCases := []struct {
+		name                string
+		opts                []core.MetaMutator
+		wantNeedsAdoption   bool
+		wantPreviousManager string
+		wantManaged         bool
+	}{
+		{
+			name:              "unmanaged",
+			wantNeedsAdoption: true,
+		},
+		{
+			name: "managed by the same RootSync",
+			opts: []core.MetaMutator{
+				core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
+				core.Annotation(metadata.ResourceManagerKey, rootSync),
+				core.Annotation(metadata.ResourceIDKey, resourceID),
+			},
+		},
+		{
+			name: "owned by the same inventory",
+			opts: []core.MetaMutator{
+				core.Annotation(metadata.OwningInventoryKey, inventoryID),
+			},
+		},
+		{
+			name: "applied with kubectl",
+			opts: []core.MetaMutator{
+				core.Annotation(corev1.LastAppliedConfigAnnotation, "{}"),
+			},
+			wantNeedsAdoption:   true,
+			wantPreviousManager: "kubectl",
+		},
+		{
+			name: "managed by Helm",
+			opts: []core.MetaMutator{
+				core.Label(metadata.ManagedByKey, metadata.HelmManagedByValue),
+				core.Annotation(metadata.HelmReleaseNameAnnotationKey, "example"),
+				core.Annotation(metadata.HelmReleaseNamespaceAnnotationKey, "default"),
+			},
+			wantNeedsAdoption:   true,
+			wantPreviousManager: "helm:default/example",
+			wantManaged:         true,
+		},
+		{
+			name: "managed by another RootSync",
+			opts: []core.MetaMutator{
+				core.Annotation(metadata.ResourceManagementKey, metadata.ResourceManagementEnabled),
+				core.Annotation(metadata.ResourceManagerKey, otherSync),
+				core.Annotation(metadata.ResourceIDKey, resourceID),
+				core.Annotation(metadata.OwningInventoryKey, "config-management-system_other"),
+			},
+			wantNeedsAdoption:   true,
+			wantPreviousManager: "configsync:" + otherSync,
+			wantManaged:         true,
+		},
+		{
+			name: "owned by another inventory",
+			opts: []core.MetaMutator{
+				core.Annotation(metadata.OwningInventoryKey, "default_example"),
+			},
+			wantNeedsAdoption:   true,
+			wantPreviousManager: "inventory:default_example",
+			wantManaged:         true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := fake.RoleObject(tc.opts...)
+			assert.Equal(t, tc.wantNeedsAdoption, NeedsAdoption(declared.RootReconciler, configsync.RootSyncName, decl, actual))
+			previousManager, managed := PreviousManager(declared.RootReconciler, configsync.RootSyncName, decl, actual)
+			assert.Equal(t, tc.wantPreviousManager, previousManager)
+			assert.Equal(t, tc.wantManaged, managed)
+		})
+	}
+}
+
+func TestEffectiveAdoptionPolicy(t *testing.T) {
+	assert.Equal(t, metadata.AdoptionPolicy(""), EffectiveAdoptionPolicy(fake.RoleObject(), ""))
+	assert.Equal(t, metadata.AdoptionPolicyNever, EffectiveAdoptionPolicy(fake.RoleObject(), metadata.AdoptionPolicyNever))
+	assert.Equal(t, metadata.AdoptionPolicyAlways, EffectiveAdoptionPolicy(
+		fake.RoleObject(core.Annotation(metadata.AdoptionAnnotationKey, "always")), metadata.AdoptionPolicyNever))
+}
+
+func TestCanAdopt(t *testing.T) {
+	testCases := []struct {
+		policy  metadata.AdoptionPolicy
+		managed bool
+		want    bool
+	}{
+		{policy: "", managed: false, want: true},
+		{policy: "", managed: true, want: true},
+		{policy: metadata.AdoptionPolicyNever, managed: false, want: false},
+		{policy: metadata.AdoptionPolicyNever, managed: true, want: false},
+		{policy: metadata.AdoptionPolicyIfUnmanaged, managed: false, want: true},
+		{policy: metadata.AdoptionPolicyIfUnmanaged, managed: true, want: false},
+		{policy: metadata.AdoptionPolicyAlways, managed: false, want: true},
+		{policy: metadata.AdoptionPolicyAlways, managed: true, want: true},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, CanAdopt(tc.policy, tc.managed), "CanAdopt(%q, %v)", tc.policy, tc.managed)
+	}
+}
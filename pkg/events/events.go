@@ -0,0 +1,272 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events records Kubernetes Events for the sync lifecycle on the
+// RootSync or RepoSync object of a reconciler, and on the managed objects, so
+// `kubectl describe` shows the history which the status fields overwrite.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The reasons of the Events.
+const (
+	ReasonSourceFetched      = "SourceFetched"
+	ReasonRenderingSucceeded = "RenderingSucceeded"
+	ReasonRenderingFailed    = "RenderingFailed"
+	ReasonSyncStarted        = "SyncStarted"
+	ReasonSyncCompleted      = "SyncCompleted"
+	ReasonSyncFailed         = "SyncFailed"
+	ReasonPruned             = "Pruned"
+	ReasonManagementConflict = "ManagementConflict"
+	ReasonDriftCorrected     = "DriftCorrected"
+	ReasonResourceFight      = "ResourceFight"
+	ReasonDeletionSkipped    = "DeletionSkipped"
+	ReasonAdopted            = "Adopted"
+)
+
+const (
+	// burstSize is the number of Events recorded on an object before they are
+	// rate limited.
+	burstSize = 25
+	// qps is the rate at which the Events recorded on an object refill the
+	// burst, one every minute.
+	qps = 1.0 / 60
+	// maxMessageLength is the length at which the messages are truncated.
+	maxMessageLength = 1024
+)
+
+// Recorder records the Events of the sync lifecycle. The methods of a nil
+// Recorder do nothing, so the components which record Events can run without
+// one.
+type Recorder struct {
+	recorder    record.EventRecorder
+	broadcaster record.EventBroadcaster
+	reader      client.Reader
+	syncKind    string
+	syncName    types.NamespacedName
+
+	mux sync.Mutex
+	// syncRef refers to the RootSync or RepoSync object once it has been read,
+	// as the Events must refer to its UID to be listed by `kubectl describe`.
+	syncRef *corev1.ObjectReference
+}
+
+// NewRecorder returns a Recorder of the Events of the RootSync or RepoSync
+// object syncKind/syncName, which it reads with reader. The Events are
+// reported by component and rate limited per object.
+func NewRecorder(cfg *rest.Config, reader client.Reader, syncKind string, syncName types.NamespacedName, component string) (*Recorder, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	broadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize: burstSize,
+		QPS:       qps,
+	})
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	r := newRecorder(broadcaster.NewRecorder(core.Scheme, corev1.EventSource{Component: component}), reader, syncKind, syncName)
+	r.broadcaster = broadcaster
+	return r, nil
+}
+
+func newRecorder(recorder record.EventRecorder, reader client.Reader, syncKind string, syncName types.NamespacedName) *Recorder {
+	return &Recorder{
+		recorder: recorder,
+		reader:   reader,
+		syncKind: syncKind,
+		syncName: syncName,
+	}
+}
+
+// Shutdown stops recording Events, after the pending Events are sent.
+func (r *Recorder) Shutdown() {
+	if r == nil || r.broadcaster == nil {
+		return
+	}
+	r.broadcaster.Shutdown()
+}
+
+// SourceFetched records that a new commit was fetched from the source.
+func (r *Recorder) SourceFetched(ctx context.Context, commit string) {
+	r.syncEventf(ctx, corev1.EventTypeNormal, ReasonSourceFetched, "Fetched commit %s", commit)
+}
+
+// RenderingSucceeded records that the commit was rendered.
+func (r *Recorder) RenderingSucceeded(ctx context.Context, commit string) {
+	r.syncEventf(ctx, corev1.EventTypeNormal, ReasonRenderingSucceeded, "Rendered commit %s", commit)
+}
+
+// RenderingFailed records that the rendering of the commit failed.
+func (r *Recorder) RenderingFailed(ctx context.Context, commit string, err error) {
+	r.syncEventf(ctx, corev1.EventTypeWarning, ReasonRenderingFailed, "Failed to render commit %s: %v", commit, err)
+}
+
+// SyncStarted records that the reconciler started to sync the commit.
+func (r *Recorder) SyncStarted(ctx context.Context, commit string) {
+	r.syncEventf(ctx, corev1.EventTypeNormal, ReasonSyncStarted, "Syncing commit %s", commit)
+}
+
+// SyncCompleted records that the commit was synced.
+func (r *Recorder) SyncCompleted(ctx context.Context, commit string) {
+	r.syncEventf(ctx, corev1.EventTypeNormal, ReasonSyncCompleted, "Synced commit %s", commit)
+}
+
+// SyncFailed records that the sync of the commit failed with errCount errors,
+// which are reported in the status.
+func (r *Recorder) SyncFailed(ctx context.Context, commit string, errCount int) {
+	if r == nil {
+		return
+	}
+	r.syncEventf(ctx, corev1.EventTypeWarning, ReasonSyncFailed,
+		"Failed to sync commit %s with %d error(s), see the status of the %s for details", commit, errCount, r.syncKind)
+}
+
+// Pruned records that the sync of the commit pruned count objects.
+func (r *Recorder) Pruned(ctx context.Context, commit string, count uint64) {
+	r.syncEventf(ctx, corev1.EventTypeNormal, ReasonPruned, "Pruned %d object(s) no longer declared in commit %s", count, commit)
+}
+
+// ManagementConflict records that obj is declared in the source of another
+// reconciler, on both obj and the RootSync or RepoSync.
+func (r *Recorder) ManagementConflict(ctx context.Context, obj client.Object, conflictingManager string) {
+	if conflictingManager == "" {
+		conflictingManager = "another reconciler"
+	}
+	r.objectEventf(obj, corev1.EventTypeWarning, ReasonManagementConflict,
+		"The object is declared in the sources of both %s and %s", r.manager(), conflictingManager)
+	r.syncEventf(ctx, corev1.EventTypeWarning, ReasonManagementConflict,
+		"Management conflict on %s with %s", core.GKNN(obj), conflictingManager)
+}
+
+// DriftCorrected records that the remediator corrected the drift of obj from
+// its declared state, with the operation: create, update or delete.
+func (r *Recorder) DriftCorrected(ctx context.Context, obj client.Object, operation string) {
+	r.objectEventf(obj, corev1.EventTypeNormal, ReasonDriftCorrected,
+		"Corrected the drift from the source of %s with %s", r.manager(), operation)
+}
+
+// ResourceFight records that obj is updated frequently, probably because the
+// reconciler is fighting with another controller over it, on both obj and the
+// RootSync or RepoSync.
+func (r *Recorder) ResourceFight(ctx context.Context, obj client.Object, operation string) {
+	r.objectEventf(obj, corev1.EventTypeWarning, ReasonResourceFight,
+		"%s is fighting with another controller over the object, on %s", r.manager(), operation)
+	r.syncEventf(ctx, corev1.EventTypeWarning, ReasonResourceFight,
+		"Fighting with another controller over %s, on %s", core.GKNN(obj), operation)
+}
+
+// DeletionSkipped records that obj was unmanaged instead of being pruned or
+// deleted because it is protected by the given rule, on both obj and the
+// RootSync or RepoSync.
+func (r *Recorder) DeletionSkipped(ctx context.Context, obj client.Object, rule string) {
+	r.objectEventf(obj, corev1.EventTypeWarning, ReasonDeletionSkipped,
+		"%s unmanaged the object instead of deleting it, as it is protected by the rule %q", r.manager(), rule)
+	r.syncEventf(ctx, corev1.EventTypeWarning, ReasonDeletionSkipped,
+		"Unmanaged %s instead of deleting it, as it is protected by the rule %q", core.GKNN(obj), rule)
+}
+
+// Adopted records that the pre-existing obj was adopted, on both obj and the
+// RootSync or RepoSync. The previous manager is empty for unmanaged objects.
+func (r *Recorder) Adopted(ctx context.Context, obj client.Object, previousManager string) {
+	if previousManager == "" {
+		previousManager = "nothing"
+	}
+	r.objectEventf(obj, corev1.EventTypeNormal, ReasonAdopted,
+		"%s adopted the object, previously managed by %s", r.manager(), previousManager)
+	r.syncEventf(ctx, corev1.EventTypeNormal, ReasonAdopted,
+		"Adopted %s, previously managed by %s", core.GKNN(obj), previousManager)
+}
+
+// manager describes the RootSync or RepoSync, like RootSync root-sync.
+func (r *Recorder) manager() string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", r.syncKind, r.syncName)
+}
+
+// objectEventf records an Event on a managed object. The object must be a live
+// object: objects without a UID, like declared objects, have no Event.
+func (r *Recorder) objectEventf(obj client.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if r == nil || obj == nil {
+		return
+	}
+	if obj.GetUID() == "" {
+		// Events are matched to their object by UID, which only live objects
+		// have.
+		klog.V(4).Infof("Skipping the Event %s on %s, which has no UID", reason, core.GKNN(obj))
+		return
+	}
+	r.recorder.Event(obj, eventType, reason, truncate(fmt.Sprintf(messageFmt, args...)))
+}
+
+// syncEventf records an Event on the RootSync or RepoSync object.
+func (r *Recorder) syncEventf(ctx context.Context, eventType, reason, messageFmt string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	ref := r.syncReference(ctx)
+	if ref == nil {
+		return
+	}
+	r.recorder.Event(ref, eventType, reason, truncate(fmt.Sprintf(messageFmt, args...)))
+}
+
+// syncReference returns the reference to the RootSync or RepoSync object, or
+// nil if it cannot be read.
+func (r *Recorder) syncReference(ctx context.Context) *corev1.ObjectReference {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.syncRef != nil {
+		return r.syncRef
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(r.syncKind))
+	if err := r.reader.Get(ctx, r.syncName, u); err != nil {
+		klog.Warningf("Failed to get the %s %s to record an Event: %v", r.syncKind, r.syncName, err)
+		return nil
+	}
+	r.syncRef = &corev1.ObjectReference{
+		APIVersion: u.GetAPIVersion(),
+		Kind:       u.GetKind(),
+		Namespace:  u.GetNamespace(),
+		Name:       u.GetName(),
+		UID:        u.GetUID(),
+	}
+	return r.syncRef
+}
+
+// truncate truncates the message of an Event to maxMessageLength.
+func truncate(message string) string {
+	if len(message) <= maxMessageLength {
+		return message
+	}
+	return message[:maxMessageLength-3] + "..."
+}
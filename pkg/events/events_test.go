@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeReader reads the objects by name. The fake Client of the syncer cannot
+// be used, as it depends on this package.
+type fakeReader map[types.NamespacedName]client.Object
+
+func newFakeReader(objs ...client.Object) fakeReader {
+	r := fakeReader{}
+	for _, o := range objs {
+		r[client.ObjectKeyFromObject(o)] = o
+	}
+	return r
+}
+
+func (r fakeReader) Get(_ context.Context, key client.ObjectKey, obj client.Object) error {
+	o, found := r[key]
+	if !found {
+		return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return err
+	}
+	u := obj.(*unstructured.Unstructured)
+	gvk := u.GroupVersionKind()
+	u.SetUnstructuredContent(content)
+	u.SetGroupVersionKind(gvk)
+	return nil
+}
+
+func (r fakeReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return nil
+}
+
+var rootSyncName = types.NamespacedName{Namespace: configsync.ControllerNamespace, Name: configsync.RootSyncName}
+
+func TestRecorder_SyncEvents(t *testing.T) {
+	rs := fake.RootSyncObjectV1Beta1(configsync.RootSyncName, core.UID("rs-uid"))
+	c := newFakeReader(rs)
+	fakeRecorder := record.NewFakeRecorder(10)
+	r := newRecorder(fakeRecorder, c, configsync.RootSyncKind, rootSyncName)
+
+	ctx := context.Background()
+	r.SourceFetched(ctx, "abc123")
+	r.RenderingFailed(ctx, "abc123", errors.New("kustomize build failed"))
+	r.SyncFailed(ctx, "abc123", 2)
+
+	want := []string{
+		"Normal SourceFetched Fetched commit abc123",
+		"Warning RenderingFailed Failed to render commit abc123: kustomize build failed",
+		"Warning SyncFailed Failed to sync commit abc123 with 2 error(s), see the status of the RootSync for details",
+	}
+	for _, w := range want {
+		if got := <-fakeRecorder.Events; got != w {
+			t.Errorf("got Event %q, want %q", got, w)
+		}
+	}
+	if r.syncRef == nil || r.syncRef.UID != "rs-uid" {
+		t.Errorf("got reference %v, want the UID of the RootSync", r.syncRef)
+	}
+}
+
+func TestRecorder_ObjectEvents(t *testing.T) {
+	rs := fake.RootSyncObjectV1Beta1(configsync.RootSyncName, core.UID("rs-uid"))
+	cm := fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"), core.UID("cm-uid"))
+	c := newFakeReader(rs, cm)
+	fakeRecorder := record.NewFakeRecorder(10)
+	r := newRecorder(fakeRecorder, c, configsync.RootSyncKind, rootSyncName)
+
+	ctx := context.Background()
+	r.DriftCorrected(ctx, cm, "update")
+	r.ManagementConflict(ctx, cm, "")
+
+	want := []string{
+		"Normal DriftCorrected Corrected the drift from the source of RootSync config-management-system/root-sync with update",
+		"Warning ManagementConflict The object is declared in the sources of both RootSync config-management-system/root-sync and another reconciler",
+		"Warning ManagementConflict Management conflict on _configmap_bookstore_cm with another reconciler",
+	}
+	for _, w := range want {
+		if got := <-fakeRecorder.Events; got != w {
+			t.Errorf("got Event %q, want %q", got, w)
+		}
+	}
+
+	// Declared objects have no UID, so they have no Event, and are not read.
+	declared := fake.ConfigMapObject(core.Name("cm"), core.Namespace("bookstore"))
+	r.DriftCorrected(ctx, declared, "update")
+	if len(fakeRecorder.Events) != 0 {
+		t.Errorf("got Event %q, want none", <-fakeRecorder.Events)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	r.SyncCompleted(context.Background(), "abc123")
+	r.SyncFailed(context.Background(), "abc123", 1)
+	r.DriftCorrected(context.Background(), fake.ConfigMapObject(), "create")
+	r.Shutdown()
+}
+
+func TestTruncate(t *testing.T) {
+	long := make([]byte, maxMessageLength+10)
+	for i := range long {
+		long[i] = 'a'
+	}
+	if got := truncate(string(long)); len(got) != maxMessageLength {
+		t.Errorf("got length %d, want %d", len(got), maxMessageLength)
+	}
+	if got := truncate("short"); got != "short" {
+		t.Errorf("got %q, want %q", got, "short")
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health computes the health of managed objects from user-defined
+// health check rules, for the kinds whose status does not follow the
+// conventions understood by kstatus.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/status"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigMapName is the name of the ConfigMap in the config-management-system
+// namespace which defines the health check rules. The ConfigMap may be
+// declared in the root repository or created directly on the cluster.
+//
+// Each key of the ConfigMap is the `<kind>.<group>` of the checked objects,
+// like `Widget.example.com`, or just the kind for the core group. Each value
+// is a YAML Rule.
+const ConfigMapName = "health-checks"
+
+// InvalidHealthCheckErrorCode is the error code for a health check rule which
+// cannot be parsed.
+const InvalidHealthCheckErrorCode = "2018"
+
+var invalidHealthCheckErrorBuilder = status.NewErrorBuilder(InvalidHealthCheckErrorCode)
+
+// InvalidHealthCheckError reports that the health check rule defined under the
+// given key of the health checks ConfigMap is invalid. The objects of that
+// kind fall back to the default kstatus health computation.
+func InvalidHealthCheckError(key string, err error) status.Error {
+	return invalidHealthCheckErrorBuilder.Wrap(err).Sprintf(
+		"invalid health check rule %q in the ConfigMap %s/%s; the default health computation is used for this kind instead",
+		key, configmanagement.ControllerNamespace, ConfigMapName).Build()
+}
+
+// Rule defines the health of the objects of a kind with CEL expressions
+// evaluated against the object, which is bound to the `self` variable, like
+// `self.status.phase == "Ready"` or
+// `self.status.conditions.exists(c, c.type == "Ready" && c.status == "True")`.
+//
+// An object is Failed if the Failed expression is true, otherwise InProgress if
+// the InProgress expression is true, otherwise Current if the Current
+// expression is true, and InProgress when no expression is true. An
+// expression which fails to evaluate, like when it refers to missing fields
+// without checking them with has(), is never true.
+type Rule struct {
+	// Current is the expression which is true once the object is healthy.
+	Current string `json:"current"`
+	// InProgress is an optional expression which is true while the object is
+	// still being reconciled, even if Current is true, such as when the status
+	// is stale.
+	InProgress string `json:"inProgress,omitempty"`
+	// Failed is an optional expression which is true if the object failed to
+	// reconcile.
+	Failed string `json:"failed,omitempty"`
+
+	// programs are the compiled expressions of the rule, by expression. The
+	// expressions which are not compiled yet are compiled when evaluated.
+	programs map[string]cel.Program
+}
+
+// selfVariable is the name of the CEL variable bound to the checked object.
+const selfVariable = "self"
+
+var (
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
+)
+
+// celEnv returns the CEL environment of the health check expressions.
+func celEnv() (*cel.Env, error) {
+	envOnce.Do(func() {
+		env, envErr = cel.NewEnv(cel.Declarations(decls.NewVar(selfVariable, decls.Dyn)))
+	})
+	return env, envErr
+}
+
+// compileExpression compiles the given CEL expression, which must evaluate to
+// a boolean.
+func compileExpression(expr string) (cel.Program, error) {
+	e, err := celEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := e.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile expression %q: %w", expr, issues.Err())
+	}
+	if !proto.Equal(ast.ResultType(), decls.Bool) && !proto.Equal(ast.ResultType(), decls.Dyn) {
+		return nil, fmt.Errorf("expression %q evaluates to %v, not a bool", expr, ast.ResultType())
+	}
+	return e.Program(ast)
+}
+
+// compile returns an error if the rule is missing the Current expression, or
+// if any of its expressions cannot be compiled. The compiled expressions are
+// kept in the rule.
+func (r *Rule) compile() error {
+	if r.Current == "" {
+		return fmt.Errorf("the current expression must be set")
+	}
+	r.programs = make(map[string]cel.Program, 3)
+	for _, expr := range []string{r.Current, r.InProgress, r.Failed} {
+		if expr == "" {
+			continue
+		}
+		program, err := compileExpression(expr)
+		if err != nil {
+			return err
+		}
+		r.programs[expr] = program
+	}
+	return nil
+}
+
+// Rules maps the checked kinds to their health check rule.
+type Rules map[schema.GroupKind]Rule
+
+// ParseRules parses the data of the health checks ConfigMap. The invalid rules
+// are reported as errors and left out of the returned Rules.
+func ParseRules(data map[string]string) (Rules, status.MultiError) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rules := make(Rules, len(data))
+	var errs status.MultiError
+	for _, k := range keys {
+		r := Rule{}
+		if err := yaml.UnmarshalStrict([]byte(data[k]), &r); err != nil {
+			errs = status.Append(errs, InvalidHealthCheckError(k, err))
+			continue
+		}
+		if err := r.compile(); err != nil {
+			errs = status.Append(errs, InvalidHealthCheckError(k, err))
+			continue
+		}
+		rules[schema.ParseGroupKind(k)] = r
+	}
+	return rules, errs
+}
+
+// ReadRules returns the health check rules of the health checks ConfigMap. No
+// rules are defined if the ConfigMap does not exist. Failing to read it,
+// including being forbidden to, is an error.
+func ReadRules(ctx context.Context, c client.Reader) (Rules, status.MultiError) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: configmanagement.ControllerNamespace, Name: ConfigMapName}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("No health check rules are read from ConfigMap %s: %v", key, err)
+			return nil, nil
+		}
+		return nil, status.APIServerError(err, "failed to get the health checks ConfigMap")
+	}
+	return ParseRules(cm.Data)
+}
+
+// Compute returns the health of the given object according to the rule of its
+// kind. It returns false if there is no rule for the kind of the object.
+func (r Rules) Compute(u *unstructured.Unstructured) (*kstatus.Result, bool, error) {
+	rule, found := r[u.GroupVersionKind().GroupKind()]
+	if !found {
+		return nil, false, nil
+	}
+	result, err := rule.Compute(u)
+	return result, true, err
+}
+
+// Compute returns the health of the given object according to the rule.
+func (r Rule) Compute(u *unstructured.Unstructured) (*kstatus.Result, error) {
+	checks := []struct {
+		expr   string
+		status kstatus.Status
+	}{
+		{r.Failed, kstatus.FailedStatus},
+		{r.InProgress, kstatus.InProgressStatus},
+		{r.Current, kstatus.CurrentStatus},
+	}
+	for _, c := range checks {
+		if c.expr == "" {
+			continue
+		}
+		matched, err := r.eval(u, c.expr)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			return &kstatus.Result{
+				Status:  c.status,
+				Message: fmt.Sprintf("health check %q is true", c.expr),
+			}, nil
+		}
+	}
+	return &kstatus.Result{
+		Status:  kstatus.InProgressStatus,
+		Message: fmt.Sprintf("health check %q is not true yet", r.Current),
+	}, nil
+}
+
+// eval returns true if the expression evaluates to the boolean true against
+// the object. It returns an error only if the expression cannot be compiled.
+func (r Rule) eval(u *unstructured.Unstructured, expr string) (bool, error) {
+	program, found := r.programs[expr]
+	if !found {
+		var err error
+		program, err = compileExpression(expr)
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate health check %q: %w", expr, err)
+		}
+	}
+	val, _, err := program.Eval(map[string]interface{}{selfVariable: u.Object})
+	if err != nil {
+		klog.V(4).Infof("Health check %q is not true for %s %s/%s: %v",
+			expr, u.GetKind(), u.GetNamespace(), u.GetName(), err)
+		return false, nil
+	}
+	return val == types.True, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+var widgetGK = schema.GroupKind{Group: "example.com", Kind: "Widget"}
+
+func widget(s map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":       "foo",
+			"namespace":  "bar",
+			"generation": int64(2),
+		},
+	}}
+	if s != nil {
+		u.Object["status"] = s
+	}
+	return u
+}
+
+func TestParseRules(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      map[string]string
+		want      Rules
+		wantError bool
+	}{
+		{
+			name: "valid rules",
+			data: map[string]string{
+				"Widget.example.com": `
+current: self.status.phase == "Ready"
+failed: self.status.phase == "Error"
+`,
+				"Pod": `current: self.status.phase == "Running"`,
+			},
+			want: Rules{
+				widgetGK:      {Current: `self.status.phase == "Ready"`, Failed: `self.status.phase == "Error"`},
+				{Kind: "Pod"}: {Current: `self.status.phase == "Running"`},
+			},
+		},
+		{
+			name: "missing current expression",
+			data: map[string]string{
+				"Widget.example.com": `failed: self.status.phase == "Error"`,
+				"Pod":                `current: self.status.phase == "Running"`,
+			},
+			want: Rules{
+				{Kind: "Pod"}: {Current: `self.status.phase == "Running"`},
+			},
+			wantError: true,
+		},
+		{
+			name: "unknown field",
+			data: map[string]string{
+				"Widget.example.com": `ready: self.status.phase == "Ready"`,
+			},
+			want:      Rules{},
+			wantError: true,
+		},
+		{
+			name: "invalid expression",
+			data: map[string]string{
+				"Widget.example.com": `current: ready widget`,
+			},
+			want:      Rules{},
+			wantError: true,
+		},
+		{
+			name: "unknown variable",
+			data: map[string]string{
+				"Widget.example.com": `current: widget.status.phase == "Ready"`,
+			},
+			want:      Rules{},
+			wantError: true,
+		},
+		{
+			name: "not a boolean expression",
+			data: map[string]string{
+				"Widget.example.com": `current: size("ready")`,
+			},
+			want:      Rules{},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, errs := ParseRules(tc.data)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(Rule{})); diff != "" {
+				t.Error(diff)
+			}
+			if tc.wantError {
+				if errs == nil {
+					t.Fatal("got no error, want error")
+				}
+				for _, err := range errs.Errors() {
+					if err.Code() != InvalidHealthCheckErrorCode {
+						t.Errorf("got error %v, want error code %s", err, InvalidHealthCheckErrorCode)
+					}
+				}
+			} else if errs != nil {
+				t.Errorf("got error %v, want nil", errs)
+			}
+		})
+	}
+}
+
+func TestRules_Compute(t *testing.T) {
+	rules := Rules{
+		widgetGK: {
+			Current:    `self.status.conditions.exists(c, c.type == "Ready" && c.status == "True")`,
+			InProgress: `self.status.observedGeneration != self.metadata.generation`,
+			Failed:     `has(self.status.phase) && self.status.phase == "Error"`,
+		},
+	}
+	ready := []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}}
+
+	testCases := []struct {
+		name      string
+		obj       *unstructured.Unstructured
+		want      kstatus.Status
+		wantFound bool
+	}{
+		{
+			name:      "no status",
+			obj:       widget(nil),
+			want:      kstatus.InProgressStatus,
+			wantFound: true,
+		},
+		{
+			name:      "current",
+			obj:       widget(map[string]interface{}{"observedGeneration": int64(2), "conditions": ready}),
+			want:      kstatus.CurrentStatus,
+			wantFound: true,
+		},
+		{
+			name:      "stale status",
+			obj:       widget(map[string]interface{}{"observedGeneration": int64(1), "conditions": ready}),
+			want:      kstatus.InProgressStatus,
+			wantFound: true,
+		},
+		{
+			name:      "failed",
+			obj:       widget(map[string]interface{}{"observedGeneration": int64(2), "conditions": ready, "phase": "Error"}),
+			want:      kstatus.FailedStatus,
+			wantFound: true,
+		},
+		{
+			name: "no rule",
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found, err := rules.Compute(tc.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tc.wantFound {
+				t.Fatalf("got found %t, want %t", found, tc.wantFound)
+			}
+			if !found {
+				return
+			}
+			if got.Status != tc.want {
+				t.Errorf("got status %s (%s), want %s", got.Status, got.Message, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/statusreaders"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StatusReader is a kstatus StatusReader which computes the status of the
+// objects with a health check rule. The rules are replaced with Refresh, so
+// the same StatusReader can be shared by the status watchers of every apply.
+type StatusReader struct {
+	mux   sync.RWMutex
+	rules Rules
+	// reader computes the status of the objects with the current rules.
+	reader engine.StatusReader
+}
+
+var _ engine.StatusReader = &StatusReader{}
+
+// NewStatusReader returns a StatusReader without any rule.
+func NewStatusReader(mapper meta.RESTMapper) *StatusReader {
+	r := &StatusReader{}
+	r.reader = statusreaders.NewGenericStatusReader(mapper, r.compute)
+	return r
+}
+
+// Refresh replaces the rules with the rules of the health checks ConfigMap.
+// The invalid rules are returned as errors, and the other rules are still
+// used. The current rules are kept if the ConfigMap cannot be read.
+func (r *StatusReader) Refresh(ctx context.Context, c client.Reader) status.MultiError {
+	rules, errs := ReadRules(ctx, c)
+	if rules == nil && errs != nil {
+		return errs
+	}
+	r.SetRules(rules)
+	return errs
+}
+
+// SetRules replaces the rules.
+func (r *StatusReader) SetRules(rules Rules) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.rules = rules
+}
+
+// Supports returns true if there is a rule for the given kind.
+func (r *StatusReader) Supports(gk schema.GroupKind) bool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	_, found := r.rules[gk]
+	return found
+}
+
+// ReadStatus implements engine.StatusReader.
+func (r *StatusReader) ReadStatus(ctx context.Context, reader engine.ClusterReader, id object.ObjMetadata) (*event.ResourceStatus, error) {
+	return r.reader.ReadStatus(ctx, reader, id)
+}
+
+// ReadStatusForObject implements engine.StatusReader.
+func (r *StatusReader) ReadStatusForObject(ctx context.Context, reader engine.ClusterReader, obj *unstructured.Unstructured) (*event.ResourceStatus, error) {
+	return r.reader.ReadStatusForObject(ctx, reader, obj)
+}
+
+// compute is the StatusFunc of the objects with a rule. It falls back to the
+// default kstatus computation if the rule was removed since Supports was
+// called.
+func (r *StatusReader) compute(u *unstructured.Unstructured) (*kstatus.Result, error) {
+	r.mux.RLock()
+	rules := r.rules
+	r.mux.RUnlock()
+	result, found, err := rules.Compute(u)
+	if !found {
+		return kstatus.Compute(u)
+	}
+	return result, err
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/core"
+	syncertestfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// forbiddenClient is forbidden to get any object.
+type forbiddenClient struct {
+	client.Client
+}
+
+func (c forbiddenClient) Get(_ context.Context, key client.ObjectKey, _ client.Object) error {
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, key.Name, nil)
+}
+
+func TestStatusReader_Refresh(t *testing.T) {
+	cm := fake.ConfigMapObject(core.Name(ConfigMapName), core.Namespace(configmanagement.ControllerNamespace))
+	cm.Data = map[string]string{
+		"Widget.example.com": `current: self.status.phase == "Ready"`,
+	}
+	c := syncertestfake.NewClient(t, runtime.NewScheme(), cm)
+
+	r := NewStatusReader(nil)
+	if r.Supports(widgetGK) {
+		t.Fatal("got Supports true before Refresh, want false")
+	}
+	if errs := r.Refresh(context.Background(), c); errs != nil {
+		t.Fatal(errs)
+	}
+	if !r.Supports(widgetGK) {
+		t.Fatal("got Supports false after Refresh, want true")
+	}
+
+	// The rules which can no longer be read are still used.
+	if errs := r.Refresh(context.Background(), forbiddenClient{c}); errs == nil {
+		t.Fatal("got no error when forbidden to read the ConfigMap, want error")
+	}
+	if !r.Supports(widgetGK) {
+		t.Fatal("got Supports false after a forbidden Refresh, want true")
+	}
+
+	got, err := r.compute(widget(map[string]interface{}{"phase": "Ready"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != kstatus.CurrentStatus {
+		t.Errorf("got status %s, want %s", got.Status, kstatus.CurrentStatus)
+	}
+
+	// Objects fall back to the default computation once their rule is removed.
+	r.SetRules(nil)
+	got, err = r.compute(widget(map[string]interface{}{"phase": "Pending"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != kstatus.CurrentStatus {
+		t.Errorf("got status %s, want %s", got.Status, kstatus.CurrentStatus)
+	}
+}
@@ -22,9 +22,11 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
@@ -32,6 +34,7 @@ import (
 	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/tracing"
 )
 
 const (
@@ -41,6 +44,8 @@ const (
 	DoneFile = "done"
 	// ErrorFile is the file name of the hydration errors.
 	ErrorFile = "error.json"
+	// renderSpan is the name of the trace span of rendering a commit.
+	renderSpan = "hydrate.render"
 )
 
 // Hydrator runs the hydration process.
@@ -65,6 +70,12 @@ type Hydrator struct {
 	RehydratePeriod time.Duration
 	// ReconcilerName is the name of the reconciler.
 	ReconcilerName string
+	// Kustomize restricts the remote sources of the kustomizations and
+	// configures the cache of the remote bases.
+	Kustomize KustomizeOptions
+	// RenderCacheDir is the directory of the render cache shared by the
+	// hydration-controllers. The render cache is disabled if it is empty.
+	RenderCacheDir string
 }
 
 // Run runs the hydration process periodically.
@@ -86,7 +97,7 @@ func (h *Hydrator) Run(ctx context.Context) {
 			if err != nil {
 				klog.Errorf("failed to get the commit hash and sync directory from the source directory %s: %v", absSourceDir.OSPath(), err)
 			} else {
-				h.rehydrateOnError(commit, syncDir.OSPath())
+				h.rehydrateOnError(ctx, commit, syncDir.OSPath())
 			}
 			rehydrateTimer.Reset(h.RehydratePeriod) // Schedule rehydrate attempt
 		case <-runTimer.C:
@@ -97,22 +108,24 @@ func (h *Hydrator) Run(ctx context.Context) {
 				// If the commit has been processed before, regardless of success or failure,
 				// skip the hydration to avoid repeated execution.
 				// The rehydrate ticker will retry on the failed commit.
+				_, span := tracing.StartSpan(ctx, renderSpan, commit)
 				hydrateErr := h.hydrate(commit, syncDir.OSPath())
-				if err := h.complete(commit, hydrateErr); err != nil {
+				if err := h.complete(commit, span.SpanContext(), hydrateErr); err != nil {
 					klog.Errorf("failed to complete the rendering execution for commit %q: %v", commit, err)
 				}
+				tracing.EndSpan(span, hydrateErr)
 			}
 			runTimer.Reset(h.PollingPeriod) // Schedule re-run attempt
 		}
 	}
 }
 
-// runHydrate runs `kustomize build` on the source configs.
+// runHydrate renders the kustomization of the source configs.
 func (h *Hydrator) runHydrate(sourceCommit, syncDir string) HydrationError {
 	newHydratedDir := h.HydratedRoot.Join(cmpath.RelativeOS(sourceCommit))
 	dest := newHydratedDir.Join(h.SyncDir).OSPath()
 
-	if err := kustomizeBuild(syncDir, dest, true); err != nil {
+	if err := h.render(syncDir, dest); err != nil {
 		return err
 	}
 	if err := updateSymlink(h.HydratedRoot.OSPath(), h.HydratedLink, newHydratedDir.OSPath()); err != nil {
@@ -153,7 +166,7 @@ func (h *Hydrator) hydrate(sourceCommit, syncDir string) HydrationError {
 }
 
 // rehydrateOnError retries the hydration on errors.
-func (h *Hydrator) rehydrateOnError(sourceCommit, syncDir string) {
+func (h *Hydrator) rehydrateOnError(ctx context.Context, sourceCommit, syncDir string) {
 	errorFile := h.HydratedRoot.Join(cmpath.RelativeSlash(ErrorFile))
 	if _, err := os.Stat(errorFile.OSPath()); err != nil {
 		if !os.IsNotExist(err) {
@@ -162,10 +175,12 @@ func (h *Hydrator) rehydrateOnError(sourceCommit, syncDir string) {
 		return
 	}
 	klog.Infof("retry rendering commit %s", sourceCommit)
+	_, span := tracing.StartSpan(ctx, renderSpan, sourceCommit)
 	hydrationErr := h.runHydrate(sourceCommit, syncDir)
-	if err := h.complete(sourceCommit, hydrationErr); err != nil {
+	if err := h.complete(sourceCommit, span.SpanContext(), hydrationErr); err != nil {
 		klog.Errorf("failed to complete the re-rendering execution for commit %q: %v", sourceCommit, err)
 	}
+	tracing.EndSpan(span, hydrationErr)
 }
 
 // updateSymlink updates the symbolic link to the hydrated directory.
@@ -203,7 +218,10 @@ func updateSymlink(hydratedRoot, link, newDir string) error {
 
 // complete marks the hydration process is done with a done file under the /repo directory
 // and reset the error file (create, update or delete).
-func (h *Hydrator) complete(commit string, hydrationErr HydrationError) error {
+// The done file holds the commit on its first line, followed by the trace
+// context of the rendering span if it is sampled, so the reconciler can link
+// its spans to it.
+func (h *Hydrator) complete(commit string, sc trace.SpanContext, hydrationErr HydrationError) error {
 	errorPath := h.HydratedRoot.Join(cmpath.RelativeSlash(ErrorFile)).OSPath()
 	var err error
 	if hydrationErr == nil {
@@ -218,7 +236,11 @@ func (h *Hydrator) complete(commit string, hydrationErr HydrationError) error {
 	if err != nil {
 		return errors.Wrapf(err, "unable to create done file: %s", h.DonePath.OSPath())
 	}
-	if _, err = done.WriteString(commit); err != nil {
+	content := commit
+	if sc.IsSampled() {
+		content += "\n" + tracing.FormatSpanContext(sc)
+	}
+	if _, err = done.WriteString(content); err != nil {
 		return errors.Wrapf(err, "unable to write to commit hash to the done file: %s", h.DonePath)
 	}
 	if err := done.Close(); err != nil {
@@ -228,21 +250,36 @@ func (h *Hydrator) complete(commit string, hydrationErr HydrationError) error {
 }
 
 // DoneCommit extracts the commit hash from the done file if exists.
-// It returns the commit hash if exists, otherwise, returns an empty string.
-// If it fails to extract the commit hash for various errors, we only log a warning,
-// and wait for the next hydration loop to retry the hydration.
 func DoneCommit(donePath string) string {
+	commit, _ := readDoneFile(donePath)
+	return commit
+}
+
+// DoneSpanContext extracts the trace context of the rendering span from the
+// done file. It returns false if the done file does not exist or the rendering
+// span was not sampled.
+func DoneSpanContext(donePath string) (trace.SpanContext, bool) {
+	_, traceParent := readDoneFile(donePath)
+	return tracing.ParseSpanContext(traceParent)
+}
+
+// readDoneFile returns the commit and the trace context in the done file.
+func readDoneFile(donePath string) (string, string) {
 	if _, err := os.Stat(donePath); err == nil {
-		commit, err := ioutil.ReadFile(donePath)
+		content, err := ioutil.ReadFile(donePath)
 		if err != nil {
 			klog.Warningf("unable to read the done file %s: %v", donePath, err)
-			return ""
+			return "", ""
+		}
+		lines := strings.SplitN(string(content), "\n", 2)
+		if len(lines) == 1 {
+			return lines[0], ""
 		}
-		return string(commit)
+		return lines[0], lines[1]
 	} else if !os.IsNotExist(err) {
 		klog.Warningf("unable to check the status of the done file %s: %v", donePath, err)
 	}
-	return ""
+	return "", ""
 }
 
 // exportError writes the error content to the error file.
@@ -0,0 +1,75 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestHydrator_CompleteDoneFile(t *testing.T) {
+	sampled := trace.SpanContext{
+		TraceID:      trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:       trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceOptions: 1,
+	}
+	notSampled := sampled
+	notSampled.TraceOptions = 0
+
+	testCases := []struct {
+		name     string
+		sc       trace.SpanContext
+		wantSpan bool
+	}{
+		{
+			name:     "sampled rendering span",
+			sc:       sampled,
+			wantSpan: true,
+		},
+		{
+			name: "rendering span not sampled",
+			sc:   notSampled,
+		},
+		{
+			name: "no rendering span",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			donePath := filepath.Join(dir, DoneFile)
+			h := &Hydrator{
+				DonePath:     cmpath.Absolute(donePath),
+				HydratedRoot: cmpath.Absolute(filepath.Join(dir, "hydrated")),
+			}
+			if err := h.complete("abc123", tc.sc, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := DoneCommit(donePath); got != "abc123" {
+				t.Errorf("DoneCommit() = %q, want %q", got, "abc123")
+			}
+			got, found := DoneSpanContext(donePath)
+			if found != tc.wantSpan {
+				t.Fatalf("DoneSpanContext() found = %t, want %t", found, tc.wantSpan)
+			}
+			if found && got != tc.sc {
+				t.Errorf("DoneSpanContext() = %v, want %v", got, tc.sc)
+			}
+		})
+	}
+}
@@ -33,6 +33,11 @@ func NewActionableError(e error) ActionableError {
 	return ActionableError{e}
 }
 
+// Unwrap returns the user actionable error.
+func (e ActionableError) Unwrap() error {
+	return e.error
+}
+
 // Code returns the user actionable error code.
 func (e ActionableError) Code() string {
 	return status.ActionableHydrationErrorCode
@@ -48,6 +53,11 @@ func NewInternalError(e error) InternalError {
 	return InternalError{e}
 }
 
+// Unwrap returns the internal error.
+func (e InternalError) Unwrap() error {
+	return e.error
+}
+
 // Code returns the internal error code.
 func (e InternalError) Code() string {
 	return status.InternalHydrationErrorCode
@@ -15,6 +15,8 @@
 package hydrate
 
 import (
+	"context"
+
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/analyzer/transform/selectors"
 	"kpt.dev/configsync/pkg/importer/filesystem"
@@ -58,9 +60,9 @@ func ForEachCluster(parser filesystem.ConfigParser, options validate.Options,
 	errs = status.Append(errs, err2)
 
 	if sourceFormat == filesystem.SourceFormatHierarchy {
-		defaultFileObjects, err2 = validate.Hierarchical(defaultFileObjects, options)
+		defaultFileObjects, err2 = validate.Hierarchical(context.Background(), defaultFileObjects, options)
 	} else {
-		defaultFileObjects, err2 = validate.Unstructured(defaultFileObjects, options)
+		defaultFileObjects, err2 = validate.Unstructured(context.Background(), defaultFileObjects, options)
 	}
 	errs = status.Append(errs, err2)
 
@@ -84,9 +86,9 @@ func ForEachCluster(parser filesystem.ConfigParser, options validate.Options,
 		fileObjects, errs := parser.Parse(filePaths)
 
 		if sourceFormat == filesystem.SourceFormatHierarchy {
-			fileObjects, err2 = validate.Hierarchical(fileObjects, options)
+			fileObjects, err2 = validate.Hierarchical(context.Background(), fileObjects, options)
 		} else {
-			fileObjects, err2 = validate.Unstructured(fileObjects, options)
+			fileObjects, err2 = validate.Unstructured(context.Background(), fileObjects, options)
 		}
 
 		errs = status.Append(errs, err2)
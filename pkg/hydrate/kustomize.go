@@ -0,0 +1,585 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// KustomizeOptions restricts the remote sources the kustomizations may load
+// and configures the cache of the remote bases.
+type KustomizeOptions struct {
+	// DisableRemoteBases rejects remote resources, bases and components.
+	DisableRemoteBases bool
+	// AllowedRemoteURLs are the URL prefixes every remote base and Helm chart
+	// repository must match. All remote URLs are allowed if it is empty.
+	AllowedRemoteURLs []string
+	// CacheDir is the directory where the remote bases pinned to a commit are
+	// cloned once and reused by later builds. Caching is disabled if it is empty.
+	CacheDir string
+}
+
+// KustomizationError is an error rendering a kustomization file.
+type KustomizationError struct {
+	// Path is the path of the failing kustomization file.
+	Path string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *KustomizationError) Error() string {
+	return fmt.Sprintf("failed to render the kustomization %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *KustomizationError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	commitRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// gitHosts are the hosts whose repository URLs kustomize accepts without a
+	// scheme or a .git suffix.
+	gitHosts = []string{"github.com/", "gitlab.com/", "bitbucket.org/"}
+)
+
+// build renders the kustomization in the input directory. It also returns
+// whether the rendering is reproducible from the source alone, which is not
+// the case if a remote base or Helm chart is not pinned to a version.
+func (o KustomizeOptions) build(input string) (resmap.ResMap, bool, error) {
+	root, err := resolvedDir(input)
+	if err != nil {
+		return nil, false, err
+	}
+	fSys := &kustomizeFS{
+		FileSystem: filesys.MakeFsOnDisk(),
+		opts:       o,
+		root:       root,
+		cached:     map[string]bool{},
+	}
+	if o.CacheDir != "" {
+		if err := os.MkdirAll(o.CacheDir, os.FileMode(0755)); err != nil {
+			return nil, false, errors.Wrapf(err, "unable to make directory: %s", o.CacheDir)
+		}
+		if fSys.cacheDir, err = resolvedDir(o.CacheDir); err != nil {
+			return nil, false, err
+		}
+	}
+
+	m, err := krusty.MakeKustomizer(kustomizerOptions()).Run(fSys, root)
+	if fSys.err != nil {
+		return nil, false, fSys.err
+	}
+	if err != nil {
+		return nil, false, &KustomizationError{Path: fSys.failedKustomization(err), Err: err}
+	}
+	fSys.pruneCache()
+	return m, !fSys.unpinned, nil
+}
+
+// kustomizerOptions returns the options of the in-process `kustomize build`.
+// Both the Helm chart inflator generator and the exec based Helm inflation
+// function are enabled, so either way of rendering Helm charts is supported.
+// They have no side-effect if no Helm chart is in the DRY configs.
+func kustomizerOptions() *krusty.Options {
+	opts := krusty.MakeDefaultOptions()
+	opts.PluginConfig = types.EnabledPluginConfig(types.BploUseStaticallyLinked)
+	opts.PluginConfig.FnpLoadingOptions.EnableExec = true
+	opts.PluginConfig.HelmConfig.Command = Helm
+	return opts
+}
+
+// writeKustomizeOutput writes each rendered resource to its own file in the
+// output directory, named the way `kustomize build --output` names them.
+func writeKustomizeOutput(output string, m resmap.ResMap) error {
+	byNamespace := m.GroupedByCurrentNamespace()
+	for namespace, resources := range byNamespace {
+		for _, r := range resources {
+			name := kustomizeOutputFileName(r)
+			if len(byNamespace) > 1 {
+				name = strings.ToLower(namespace) + "_" + name
+			}
+			if err := writeResource(filepath.Join(output, name), r); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range m.ClusterScoped() {
+		if err := writeResource(filepath.Join(output, kustomizeOutputFileName(r)), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func kustomizeOutputFileName(r *resource.Resource) string {
+	return strings.ToLower(r.GetGvk().StringWoEmptyField()) + "_" + strings.ToLower(r.GetName()) + ".yaml"
+}
+
+func writeResource(file string, r *resource.Resource) error {
+	out, err := r.AsYAML()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, out, os.FileMode(0644))
+}
+
+// kustomizeFS is the file system the kustomizations are built on. It reads
+// the files from disk, but before handing a kustomization file to kustomize it
+// checks the remote sources of the kustomization against the options, points
+// the remote bases pinned to a commit at their cached clones, and adds the
+// originAnnotations build metadata to the root kustomization.
+type kustomizeFS struct {
+	filesys.FileSystem
+	opts KustomizeOptions
+	// root is the directory of the root kustomization.
+	root string
+	// cacheDir is the resolved cache directory, empty if caching is disabled.
+	cacheDir string
+	// read are the kustomization files read by the build, in order.
+	read []string
+	// cached are the cache entries used by the build.
+	cached map[string]bool
+	// unpinned is whether a remote base or Helm chart is not pinned to a
+	// version.
+	unpinned bool
+	// err is the first kustomization rejected by the options.
+	err *KustomizationError
+}
+
+// ReadFile implements filesys.FileSystem.
+func (f *kustomizeFS) ReadFile(file string) ([]byte, error) {
+	b, err := f.FileSystem.ReadFile(file)
+	if err != nil || !hasKustomization(filepath.Base(file)) {
+		return b, err
+	}
+	f.read = append(f.read, file)
+	out, err := f.rewrite(file, b)
+	if err != nil {
+		if f.err == nil {
+			f.err = &KustomizationError{Path: f.displayPath(file), Err: err}
+		}
+		return nil, f.err
+	}
+	return out, nil
+}
+
+// rewrite returns the contents of the kustomization file to build.
+func (f *kustomizeFS) rewrite(file string, b []byte) ([]byte, error) {
+	node, err := yaml.Parse(string(b))
+	if err != nil {
+		// kustomize reports a clearer error for a malformed kustomization.
+		return b, nil
+	}
+	dir := filepath.Dir(file)
+	changed := false
+
+	for _, field := range []string{"resources", "bases", "components"} {
+		for _, entry := range scalars(node, field) {
+			if !isRemote(dir, entry.Value) {
+				continue
+			}
+			if f.opts.DisableRemoteBases {
+				return nil, errors.Errorf("remote base %q is not allowed because remote bases are disabled", entry.Value)
+			}
+			if !f.allowed(entry.Value) {
+				return nil, errors.Errorf("remote base %q does not match any of the allowed remote URLs %v", entry.Value, f.opts.AllowedRemoteURLs)
+			}
+			if _, _, _, pinned := parseRemoteBase(entry.Value); !pinned {
+				f.unpinned = true
+			}
+			local, err := f.cache(dir, entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			if local != "" {
+				entry.Value = local
+				changed = true
+			}
+		}
+	}
+
+	for _, chart := range []struct{ field, repo string }{
+		{field: "helmCharts", repo: "repo"},
+		{field: "helmChartInflationGenerator", repo: "chartRepoUrl"},
+	} {
+		charts := node.Field(chart.field)
+		if charts == nil {
+			continue
+		}
+		elements, err := charts.Value.Elements()
+		if err != nil {
+			continue
+		}
+		for _, e := range elements {
+			repo := e.Field(chart.repo)
+			if repo == nil || repo.Value.YNode().Value == "" {
+				continue
+			}
+			if u := repo.Value.YNode().Value; !f.allowed(u) {
+				return nil, errors.Errorf("Helm chart repository %q does not match any of the allowed remote URLs %v", u, f.opts.AllowedRemoteURLs)
+			}
+			if version := e.Field("version"); version == nil || version.Value.YNode().Value == "" {
+				f.unpinned = true
+			}
+		}
+	}
+
+	if dir == f.root {
+		added, err := addOriginAnnotations(node)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || added
+	}
+
+	if !changed {
+		return b, nil
+	}
+	s, err := node.String()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// scalars returns the scalar entries of the list field.
+func scalars(node *yaml.RNode, field string) []*yaml.Node {
+	list := node.Field(field)
+	if list == nil || list.Value.YNode().Kind != yaml.SequenceNode {
+		return nil
+	}
+	var result []*yaml.Node
+	for _, entry := range list.Value.YNode().Content {
+		if entry.Kind == yaml.ScalarNode {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// addOriginAnnotations enables the originAnnotations build metadata, which
+// records the source file of each rendered resource. It returns whether the
+// kustomization was changed.
+func addOriginAnnotations(node *yaml.RNode) (bool, error) {
+	metadata, err := node.Pipe(yaml.LookupCreate(yaml.SequenceNode, "buildMetadata"))
+	if err != nil || metadata == nil || metadata.YNode().Kind != yaml.SequenceNode {
+		// Leave a malformed field for kustomize to report.
+		return false, nil
+	}
+	for _, option := range metadata.YNode().Content {
+		if option.Value == types.OriginAnnotations {
+			return false, nil
+		}
+	}
+	metadata.YNode().Content = append(metadata.YNode().Content, yaml.NewScalarRNode(types.OriginAnnotations).YNode())
+	return true, nil
+}
+
+// allowed returns whether the remote URL matches one of the allowed remote URLs.
+func (f *kustomizeFS) allowed(remote string) bool {
+	if len(f.opts.AllowedRemoteURLs) == 0 {
+		return true
+	}
+	u, ok := parseRemoteURL(remote)
+	if !ok {
+		return false
+	}
+	for _, a := range f.opts.AllowedRemoteURLs {
+		if prefix, ok := parseRemoteURL(a); ok && u.within(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cache clones the remote base into the cache if it is pinned to a commit and
+// returns the path of the clone relative to dir. It returns an empty path if
+// the remote base is not cached.
+func (f *kustomizeFS) cache(dir, remote string) (string, error) {
+	// Kustomize requires bases in a remote clone to stay within the clone, so
+	// only the local kustomizations and the cached ones point at the cache.
+	if f.cacheDir == "" || !(within(dir, f.root) || within(dir, f.cacheDir)) {
+		return "", nil
+	}
+	repo, subdir, commit, ok := parseRemoteBase(remote)
+	if !ok {
+		return "", nil
+	}
+	sum := sha256.Sum256([]byte(repo + "@" + commit))
+	key := hex.EncodeToString(sum[:])
+	clone := filepath.Join(f.cacheDir, key)
+	if _, err := os.Stat(clone); err != nil {
+		klog.Infof("Caching the remote base %s at commit %s", repo, commit)
+		if err := cloneCommit(repo, commit, clone); err != nil {
+			return "", errors.Wrapf(err, "unable to fetch the remote base %q", remote)
+		}
+	}
+	f.cached[key] = true
+	return filepath.Rel(dir, filepath.Join(clone, filepath.FromSlash(subdir)))
+}
+
+// pruneCache removes the cache entries the build did not use.
+func (f *kustomizeFS) pruneCache() {
+	if f.cacheDir == "" {
+		return
+	}
+	entries, err := ioutil.ReadDir(f.cacheDir)
+	if err != nil {
+		klog.Warningf("Unable to read the kustomize cache directory %s: %v", f.cacheDir, err)
+		return
+	}
+	for _, e := range entries {
+		if f.cached[e.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(f.cacheDir, e.Name())); err != nil {
+			klog.Warningf("Unable to remove the unused kustomize cache entry %s: %v", e.Name(), err)
+		}
+	}
+}
+
+// failedKustomization returns the kustomization file which most likely caused
+// the build error: the deepest kustomization whose directory the error
+// mentions, or the root kustomization.
+func (f *kustomizeFS) failedKustomization(err error) string {
+	msg := err.Error()
+	failed := ""
+	for _, file := range f.read {
+		if strings.Contains(msg, filepath.Dir(file)) && len(file) > len(failed) {
+			failed = file
+		}
+	}
+	if failed == "" {
+		if len(f.read) == 0 {
+			return f.displayPath(f.root)
+		}
+		failed = f.read[0]
+	}
+	return f.displayPath(failed)
+}
+
+// displayPath returns the path relative to the root kustomization directory
+// if the file is within it.
+func (f *kustomizeFS) displayPath(file string) string {
+	if within(file, f.root) {
+		if rel, err := filepath.Rel(f.root, file); err == nil {
+			return rel
+		}
+	}
+	return file
+}
+
+// isRemote returns whether the kustomization entry refers to a remote
+// resource, base or component rather than a local path.
+func isRemote(dir, entry string) bool {
+	if filepath.IsAbs(entry) {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(dir, entry)); err == nil {
+		return false
+	}
+	if strings.Contains(entry, "://") || strings.HasPrefix(entry, "git@") ||
+		strings.HasPrefix(entry, "git::") || strings.Contains(entry, ".git") ||
+		strings.Contains(entry, "?ref=") || strings.Contains(entry, "?version=") {
+		return true
+	}
+	for _, host := range gitHosts {
+		if strings.HasPrefix(entry, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteURL is a remote URL reduced to its host and path segments, so that the
+// different spellings of a repository URL compare equal.
+type remoteURL struct {
+	host     string
+	segments []string
+}
+
+// parseRemoteURL parses a remote base or Helm chart repository URL, ignoring
+// its scheme, user, query and .git suffixes. It returns false if the URL cannot
+// be parsed, or if its path has a ".." segment, since the path the Git host
+// resolves it to may escape the allowed prefixes.
+func parseRemoteURL(remote string) (remoteURL, bool) {
+	s := strings.TrimPrefix(remote, "git::")
+	if i := strings.Index(s, "?"); i >= 0 {
+		s = s[:i]
+	}
+	var host, p string
+	switch {
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return remoteURL{}, false
+		}
+		host, p = u.Host, u.Path
+	case strings.HasPrefix(s, "git@"):
+		s = strings.TrimPrefix(s, "git@")
+		i := strings.Index(s, ":")
+		if i < 0 {
+			return remoteURL{}, false
+		}
+		host, p = s[:i], s[i+1:]
+	default:
+		host = s
+		if i := strings.Index(s, "/"); i >= 0 {
+			host, p = s[:i], s[i:]
+		}
+		unescaped, err := url.PathUnescape(p)
+		if err != nil {
+			return remoteURL{}, false
+		}
+		p = unescaped
+	}
+	if host == "" {
+		return remoteURL{}, false
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return remoteURL{}, false
+		}
+	}
+	result := remoteURL{host: strings.ToLower(host)}
+	for _, segment := range strings.Split(path.Clean("/"+p), "/") {
+		if segment == "" {
+			continue
+		}
+		result.segments = append(result.segments, strings.TrimSuffix(segment, ".git"))
+	}
+	return result, true
+}
+
+// within returns whether the URL is the prefix URL or a path under it.
+func (u remoteURL) within(prefix remoteURL) bool {
+	if u.host != prefix.host || len(u.segments) < len(prefix.segments) {
+		return false
+	}
+	for i, segment := range prefix.segments {
+		if u.segments[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRemoteBase splits a remote base pinned to a commit into the URL of its
+// repository, the directory within the repository, and the commit. It returns
+// false if the remote base is not pinned to a commit or its repository cannot
+// be told apart from the directory.
+func parseRemoteBase(remote string) (repo, subdir, commit string, ok bool) {
+	u, query := remote, ""
+	if i := strings.Index(remote, "?"); i >= 0 {
+		u, query = remote[:i], remote[i+1:]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", "", false
+	}
+	commit = values.Get("ref")
+	if commit == "" {
+		commit = values.Get("version")
+	}
+	if !commitRegex.MatchString(commit) {
+		return "", "", "", false
+	}
+
+	u = strings.TrimPrefix(u, "git::")
+	start := 0
+	if i := strings.Index(u, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	switch {
+	case strings.Contains(u[start:], "//"):
+		i := start + strings.Index(u[start:], "//")
+		repo, subdir = u[:i], u[i+len("//"):]
+	case strings.Contains(u, ".git/"):
+		i := strings.Index(u, ".git/") + len(".git")
+		repo, subdir = u[:i], u[i+len("/"):]
+	case strings.HasSuffix(u, ".git"):
+		repo = u
+	default:
+		return "", "", "", false
+	}
+	subdir = path.Clean("/" + subdir)[1:]
+	if start == 0 && !strings.HasPrefix(repo, "git@") {
+		repo = "https://" + repo
+	}
+	return repo, subdir, commit, true
+}
+
+// cloneCommit fetches the commit of the repository into dest.
+func cloneCommit(repo, commit, dest string) error {
+	tmp, err := ioutil.TempDir(filepath.Dir(dest), ".clone-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", repo, commit},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmp
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errors.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// resolvedDir returns the absolute path of the directory with its symbolic
+// links resolved, which is how kustomize refers to the files it reads.
+func resolvedDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// within returns whether the path is the directory or is inside it.
+func within(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,303 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  key: value
+`
+
+func TestKustomizeBuild(t *testing.T) {
+	source := t.TempDir()
+	rootKustomization := "namespace: foo\nresources:\n- base\n"
+	writeFiles(t, source, map[string]string{
+		"kustomization.yaml":      rootKustomization,
+		"base/kustomization.yaml": "resources:\n- cm.yaml\n- ns.yaml\n",
+		"base/cm.yaml":            configMap,
+		"base/ns.yaml":            "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: foo\n",
+	})
+	output := filepath.Join(t.TempDir(), "out")
+
+	if _, err := kustomizeBuild(source, output, KustomizeOptions{}, false); err != nil {
+		t.Fatalf("kustomizeBuild() got error: %v", err)
+	}
+
+	cm, err := ioutil.ReadFile(filepath.Join(output, "v1_configmap_cm.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"namespace: foo", "config.kubernetes.io/origin", "path: base/cm.yaml"} {
+		if !strings.Contains(string(cm), want) {
+			t.Errorf("rendered ConfigMap does not contain %q:\n%s", want, cm)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(output, "v1_namespace_foo.yaml")); err != nil {
+		t.Errorf("rendered Namespace not found: %v", err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(source, "kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != rootKustomization {
+		t.Errorf("source kustomization changed to:\n%s", b)
+	}
+}
+
+func TestKustomizeBuild_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     KustomizeOptions
+		files    map[string]string
+		wantPath string
+		wantErr  string
+	}{
+		{
+			name: "remote base when remote bases are disabled",
+			opts: KustomizeOptions{DisableRemoteBases: true},
+			files: map[string]string{
+				"kustomization.yaml":      "resources:\n- base\n",
+				"base/kustomization.yaml": "resources:\n- github.com/example/configs//base?ref=v1\n",
+			},
+			wantPath: filepath.Join("base", "kustomization.yaml"),
+			wantErr:  "remote bases are disabled",
+		},
+		{
+			name: "remote base not in the allowed remote URLs",
+			opts: KustomizeOptions{AllowedRemoteURLs: []string{"https://github.com/example/allowed"}},
+			files: map[string]string{
+				"kustomization.yaml": "resources:\n- https://github.com/example/configs.git//base?ref=v1\n",
+			},
+			wantPath: "kustomization.yaml",
+			wantErr:  "does not match any of the allowed remote URLs",
+		},
+		{
+			name: "remote base escaping the allowed remote URLs",
+			opts: KustomizeOptions{AllowedRemoteURLs: []string{"https://github.com/allowed-org"}},
+			files: map[string]string{
+				"kustomization.yaml": "resources:\n- https://github.com/allowed-org/../evil/repo//base?ref=" + strings.Repeat("a", 40) + "\n",
+			},
+			wantPath: "kustomization.yaml",
+			wantErr:  "does not match any of the allowed remote URLs",
+		},
+		{
+			name: "Helm chart repository not in the allowed remote URLs",
+			opts: KustomizeOptions{AllowedRemoteURLs: []string{"github.com/example"}},
+			files: map[string]string{
+				"kustomization.yaml": "helmCharts:\n- name: chart\n  repo: https://charts.example.com\n",
+			},
+			wantPath: "kustomization.yaml",
+			wantErr:  `Helm chart repository "https://charts.example.com"`,
+		},
+		{
+			name: "missing resource in a base",
+			files: map[string]string{
+				"kustomization.yaml":      "resources:\n- base\n",
+				"base/kustomization.yaml": "resources:\n- missing.yaml\n",
+			},
+			wantPath: filepath.Join("base", "kustomization.yaml"),
+			wantErr:  "missing.yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := t.TempDir()
+			writeFiles(t, source, tc.files)
+			output := filepath.Join(t.TempDir(), "out")
+
+			_, err := kustomizeBuild(source, output, tc.opts, false)
+			if err == nil {
+				t.Fatal("kustomizeBuild() got no error")
+			}
+			var kErr *KustomizationError
+			if !errors.As(err, &kErr) {
+				t.Fatalf("kustomizeBuild() got error %v, want a KustomizationError", err)
+			}
+			if kErr.Path != tc.wantPath {
+				t.Errorf("got failing kustomization %q, want %q", kErr.Path, tc.wantPath)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %q, want it to contain %q", err, tc.wantErr)
+			}
+			if _, err := os.Stat(output); !os.IsNotExist(err) {
+				t.Errorf("output directory was not deleted: %v", err)
+			}
+		})
+	}
+}
+
+func TestKustomizeBuild_CachesPinnedRemoteBases(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{
+		"base/kustomization.yaml": "resources:\n- cm.yaml\n",
+		"base/cm.yaml":            configMap,
+	})
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "base")
+	commit := git("rev-parse", "HEAD")
+
+	source := t.TempDir()
+	writeFiles(t, source, map[string]string{
+		"kustomization.yaml": "resources:\n- file://" + filepath.ToSlash(repo) + "//base?ref=" + commit + "\n",
+	})
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	opts := KustomizeOptions{CacheDir: cacheDir}
+	output := filepath.Join(t.TempDir(), "out")
+
+	if _, err := kustomizeBuild(source, output, opts, false); err != nil {
+		t.Fatalf("kustomizeBuild() got error: %v", err)
+	}
+	entries, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d cache entries, want 1", len(entries))
+	}
+
+	// The cached clone is used once the remote repository is gone.
+	if err := os.RemoveAll(repo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kustomizeBuild(source, output, opts, false); err != nil {
+		t.Fatalf("kustomizeBuild() with the cached remote base got error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "v1_configmap_cm.yaml")); err != nil {
+		t.Errorf("rendered ConfigMap not found: %v", err)
+	}
+}
+
+func TestKustomizeFS_Allowed(t *testing.T) {
+	f := &kustomizeFS{opts: KustomizeOptions{AllowedRemoteURLs: []string{
+		"https://github.com/allowed-org",
+		"charts.example.com/stable/",
+	}}}
+	testCases := []struct {
+		remote string
+		want   bool
+	}{
+		{remote: "https://github.com/allowed-org/repo//base?ref=v1", want: true},
+		{remote: "github.com/allowed-org/repo.git//base", want: true},
+		{remote: "git::https://user@GitHub.com/allowed-org/repo", want: true},
+		{remote: "git@github.com:allowed-org/repo.git", want: true},
+		{remote: "https://github.com/allowed-org", want: true},
+		{remote: "https://github.com//allowed-org/./repo", want: true},
+		{remote: "https://charts.example.com/stable", want: true},
+		{remote: "https://github.com/allowed-org/../evil/repo//base?ref=v1"},
+		{remote: "https://github.com/allowed-org/%2e%2e/evil/repo"},
+		{remote: "github.com/allowed-org/%2E%2E/evil/repo"},
+		{remote: "git@github.com:allowed-org/../evil/repo.git"},
+		{remote: "https://github.com/allowed-org-evil/repo"},
+		{remote: "https://github.com.evil.com/allowed-org/repo"},
+		{remote: "https://evil.com/github.com/allowed-org/repo"},
+		{remote: "https://charts.example.com/stable-evil"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.remote, func(t *testing.T) {
+			if got := f.allowed(tc.remote); got != tc.want {
+				t.Errorf("allowed(%q) got %t, want %t", tc.remote, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRemoteBase(t *testing.T) {
+	commit := strings.Repeat("a", 40)
+	testCases := []struct {
+		remote     string
+		wantRepo   string
+		wantSubdir string
+		wantOK     bool
+	}{
+		{
+			remote:     "github.com/example/configs//overlays/prod?ref=" + commit,
+			wantRepo:   "https://github.com/example/configs",
+			wantSubdir: "overlays/prod",
+			wantOK:     true,
+		},
+		{
+			remote:     "https://example.com/configs.git/base?version=" + commit,
+			wantRepo:   "https://example.com/configs.git",
+			wantSubdir: "base",
+			wantOK:     true,
+		},
+		{
+			remote:   "git@github.com:example/configs.git?ref=" + commit,
+			wantRepo: "git@github.com:example/configs.git",
+			wantOK:   true,
+		},
+		{
+			remote: "github.com/example/configs//base?ref=v1.0.0",
+		},
+		{
+			remote: "github.com/example/configs/base?ref=" + commit,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.remote, func(t *testing.T) {
+			repo, subdir, gotCommit, ok := parseRemoteBase(tc.remote)
+			if ok != tc.wantOK {
+				t.Fatalf("parseRemoteBase() got ok %t, want %t", ok, tc.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if repo != tc.wantRepo || subdir != tc.wantSubdir || gotCommit != commit {
+				t.Errorf("parseRemoteBase() = (%q, %q, %q), want (%q, %q, %q)", repo, subdir, gotCommit, tc.wantRepo, tc.wantSubdir, commit)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/kmetrics"
+)
+
+// renderCacheMaxAge is how long a render cache entry is kept after it was
+// last used.
+const renderCacheMaxAge = 7 * 24 * time.Hour
+
+var (
+	toolVersionsOnce sync.Once
+	toolVersionsKey  string
+)
+
+// toolVersions returns the versions of the kustomize API and the Helm binary
+// the configs are rendered with.
+func toolVersions() string {
+	toolVersionsOnce.Do(func() {
+		kustomizeVersion := "unknown"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, dep := range info.Deps {
+				if dep.Path == "sigs.k8s.io/kustomize/api" {
+					kustomizeVersion = dep.Version
+				}
+			}
+		}
+		helmVersion, err := getVersion(Helm)
+		if err != nil {
+			helmVersion = "none"
+		}
+		toolVersionsKey = fmt.Sprintf("kustomize/%s helm/%s", kustomizeVersion, helmVersion)
+	})
+	return toolVersionsKey
+}
+
+// renderCacheKey returns the content address of the rendered configs of the
+// sync directory: the digest of the source files, the sync directory within
+// the source, the tool versions and the rendering options.
+func renderCacheKey(sourceDir, syncDir string, opts KustomizeOptions) (string, error) {
+	sourceDigest, err := treeDigest(sourceDir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(sourceDir, syncDir)
+	if err != nil {
+		return "", err
+	}
+	// The cache directory of the remote bases does not change the output.
+	opts.CacheDir = ""
+	// Marshalling a struct with only bool and string fields never fails.
+	inputs, _ := json.Marshal(opts)
+	h := sha256.New()
+	for _, part := range []string{sourceDigest, filepath.ToSlash(rel), toolVersions(), string(inputs)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// treeDigest returns the digest of the paths and contents of the files under
+// dir, skipping the .git directories.
+func treeDigest(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Name() == ".git" {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "link %s %s\x00", filepath.ToSlash(rel), target)
+		case fi.Mode().IsRegular():
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			content := sha256.New()
+			_, err = io.Copy(content, f)
+			_ = f.Close()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "file %s %x\x00", filepath.ToSlash(rel), content.Sum(nil))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to compute the digest of %s", dir)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// render renders the sync directory to dest, reusing the rendered configs in
+// the render cache if a hydration-controller rendered the same source before.
+func (h *Hydrator) render(syncDir, dest string) HydrationError {
+	if h.RenderCacheDir == "" {
+		_, err := kustomizeBuild(syncDir, dest, h.Kustomize, true)
+		return err
+	}
+	key, err := renderCacheKey(sourceDirOf(syncDir, h.SyncDir.OSPath()), syncDir, h.Kustomize)
+	if err != nil {
+		klog.Warningf("Rendering without the render cache: %v", err)
+		_, err := kustomizeBuild(syncDir, dest, h.Kustomize, true)
+		return err
+	}
+	entry := filepath.Join(h.RenderCacheDir, key)
+	hit := loadRenderCache(entry, dest)
+	kmetrics.RecordRenderCacheLookup(context.Background(), hit)
+	if hit {
+		klog.Infof("Reused the rendered configs of %s from the render cache entry %s", syncDir, key)
+		return nil
+	}
+	reproducible, hydrationErr := kustomizeBuild(syncDir, dest, h.Kustomize, true)
+	if hydrationErr != nil {
+		return hydrationErr
+	}
+	if reproducible {
+		if err := storeRenderCache(h.RenderCacheDir, entry, dest); err != nil {
+			klog.Warningf("Unable to store the rendered configs of %s in the render cache: %v", syncDir, err)
+		}
+	}
+	return nil
+}
+
+// sourceDirOf returns the source directory the sync directory is in.
+func sourceDirOf(syncDir, relSyncDir string) string {
+	rel := filepath.Clean(relSyncDir)
+	if rel == "." {
+		return syncDir
+	}
+	return strings.TrimSuffix(filepath.Clean(syncDir), string(filepath.Separator)+rel)
+}
+
+// loadRenderCache copies the rendered configs of the cache entry to dest. It
+// returns false if the entry does not exist or cannot be copied.
+func loadRenderCache(entry, dest string) bool {
+	if _, err := os.Stat(entry); err != nil {
+		if !os.IsNotExist(err) {
+			klog.Warningf("Unable to check the render cache entry %s: %v", entry, err)
+		}
+		return false
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		klog.Warningf("Unable to remove the output directory %s: %v", dest, err)
+		return false
+	}
+	if err := copyDir(entry, dest); err != nil {
+		klog.Warningf("Unable to copy the render cache entry %s: %v", entry, err)
+		_ = os.RemoveAll(dest)
+		return false
+	}
+	now := time.Now()
+	if err := os.Chtimes(entry, now, now); err != nil {
+		klog.Warningf("Unable to mark the render cache entry %s as used: %v", entry, err)
+	}
+	return true
+}
+
+// storeRenderCache copies the rendered configs in src to the cache entry and
+// removes the entries which have not been used for renderCacheMaxAge.
+func storeRenderCache(cacheDir, entry, src string) error {
+	if err := os.MkdirAll(cacheDir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir(cacheDir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+	if err := copyDir(src, tmp); err != nil {
+		return err
+	}
+	// Another hydration-controller may have stored the same entry meanwhile,
+	// in which case the rename fails and its entry is kept.
+	if err := os.Rename(tmp, entry); err != nil {
+		if _, statErr := os.Stat(entry); statErr != nil {
+			return err
+		}
+	}
+	pruneRenderCache(cacheDir)
+	return nil
+}
+
+// pruneRenderCache removes the cache entries which have not been used for
+// renderCacheMaxAge.
+func pruneRenderCache(cacheDir string) {
+	entries, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		klog.Warningf("Unable to read the render cache directory %s: %v", cacheDir, err)
+		return
+	}
+	for _, e := range entries {
+		if time.Since(e.ModTime()) < renderCacheMaxAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(cacheDir, e.Name())); err != nil {
+			klog.Warningf("Unable to remove the render cache entry %s: %v", e.Name(), err)
+		}
+	}
+}
+
+// copyDir copies the directories and regular files under src to dst.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if fi.IsDir() {
+			return os.MkdirAll(target, os.FileMode(0755))
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, b, fi.Mode().Perm())
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestRenderCacheKey(t *testing.T) {
+	files := map[string]string{
+		"configs/kustomization.yaml": "resources:\n- cm.yaml\n",
+		"configs/cm.yaml":            configMap,
+	}
+	source1 := t.TempDir()
+	writeFiles(t, source1, files)
+	source2 := t.TempDir()
+	writeFiles(t, source2, files)
+
+	key := func(source string, opts KustomizeOptions) string {
+		t.Helper()
+		k, err := renderCacheKey(source, filepath.Join(source, "configs"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	base := key(source1, KustomizeOptions{})
+	if got := key(source2, KustomizeOptions{CacheDir: "/cache"}); got != base {
+		t.Errorf("the same source in another directory got key %s, want %s", got, base)
+	}
+	if got := key(source1, KustomizeOptions{DisableRemoteBases: true}); got == base {
+		t.Error("different rendering options got the same key")
+	}
+	writeFiles(t, source2, map[string]string{"configs/cm.yaml": strings.Replace(configMap, "value", "other", 1)})
+	if got := key(source2, KustomizeOptions{}); got == base {
+		t.Error("different source contents got the same key")
+	}
+}
+
+func TestHydrator_RenderCache(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	render := func(source string) string {
+		t.Helper()
+		h := &Hydrator{
+			SyncDir:        cmpath.RelativeSlash("configs"),
+			RenderCacheDir: cacheDir,
+		}
+		dest := filepath.Join(t.TempDir(), "hydrated")
+		if err := h.render(filepath.Join(source, "configs"), dest); err != nil {
+			t.Fatalf("render() got error: %v", err)
+		}
+		return dest
+	}
+	files := map[string]string{
+		"configs/kustomization.yaml": "resources:\n- cm.yaml\n",
+		"configs/cm.yaml":            configMap,
+	}
+
+	source1 := t.TempDir()
+	writeFiles(t, source1, files)
+	render(source1)
+	entries, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d render cache entries, want 1", len(entries))
+	}
+	// Mark the cache entry to tell a cache hit from a new rendering.
+	writeFiles(t, filepath.Join(cacheDir, entries[0].Name()), map[string]string{"marker": "cached"})
+
+	source2 := t.TempDir()
+	writeFiles(t, source2, files)
+	dest := render(source2)
+	if _, err := os.Stat(filepath.Join(dest, "marker")); err != nil {
+		t.Errorf("rendering the same source did not reuse the render cache: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "v1_configmap_cm.yaml")); err != nil {
+		t.Errorf("rendered ConfigMap not found: %v", err)
+	}
+
+	writeFiles(t, source2, map[string]string{"configs/cm.yaml": strings.Replace(configMap, "value", "other", 1)})
+	dest = render(source2)
+	if _, err := os.Stat(filepath.Join(dest, "marker")); !os.IsNotExist(err) {
+		t.Errorf("rendering a changed source reused the render cache: %v", err)
+	}
+}
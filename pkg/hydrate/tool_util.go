@@ -84,10 +84,16 @@ func hasKustomization(filename string) bool {
 }
 
 // hasKustomizeSubdir checks if there exists a kustomization config file in any
-// of the subdirectory under dir.
+// of the subdirectory under dir. Paths excluded by .configsyncignore files are
+// skipped.
 func hasKustomizeSubdir(dir string) (bool, error) {
 	found := false
-	err := filepath.Walk(dir,
+	abs, err := cmpath.AbsoluteOS(dir)
+	if err != nil {
+		return false, err
+	}
+	ignorer := filesystem.NewIgnorer(abs)
+	err = filepath.Walk(abs.OSPath(),
 		func(path string, fi os.FileInfo, err error) error {
 			if found {
 				return nil
@@ -95,6 +101,16 @@ func hasKustomizeSubdir(dir string) (bool, error) {
 			if err != nil {
 				return err
 			}
+			ignored, err := ignorer.Ignored(path, fi.IsDir())
+			if err != nil {
+				return err
+			}
+			if ignored {
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 			if fi.IsDir() {
 				return nil
 			}
@@ -124,35 +140,34 @@ func mustDeleteOutput(err error, output string) {
 	klog.Fatalf("Attempted to delete the output directory %s for %d times, but all failed. Exiting now...", output, retries)
 }
 
-// kustomizeBuild runs the 'kustomize build' command to render the configs.
-func kustomizeBuild(input, output string, sendMetrics bool) HydrationError {
-	// The `--enable-alpha-plugins` and `--enable-exec` flags are to support rendering
-	// Helm charts using the Helm inflation function.
-	// The `--enable-helm` flag is to enable use of the Helm chart inflator generator.
-	// We decided to enable all the flags so that both the Helm plugin and Helm
-	// inflation function are supported. This provides us with a fallback plan
-	// if the new Helm inflation function is having issues.
-	// It has no side-effect if no Helm chart in the DRY configs.
-	args := []string{"--enable-alpha-plugins", "--enable-exec", "--enable-helm", "--output", output}
-
+// kustomizeBuild renders the kustomization in the input directory in-process
+// with the kustomize API and writes the rendered configs to the output directory.
+// It returns whether the rendered configs are reproducible from the source.
+func kustomizeBuild(input, output string, opts KustomizeOptions, sendMetrics bool) (bool, HydrationError) {
 	if _, err := os.Stat(output); err == nil {
 		mustDeleteOutput(err, output)
 	}
 
 	fileMode := os.FileMode(0755)
 	if err := os.MkdirAll(output, fileMode); err != nil {
-		return NewInternalError(errors.Wrapf(err, "unable to make directory: %s", output))
+		return false, NewInternalError(errors.Wrapf(err, "unable to make directory: %s", output))
 	}
 
-	// run kustomize build with the wrapper library
-	out, err := kmetrics.RunKustomizeBuild(context.Background(), sendMetrics, input, args...)
+	start := time.Now()
+	m, reproducible, err := opts.build(input)
 	if err != nil {
-		kustomizeErr := errors.Wrapf(err, "failed to run kustomize build in %s, stdout: %s", input, out)
-		mustDeleteOutput(kustomizeErr, output)
-		return NewActionableError(kustomizeErr)
+		mustDeleteOutput(err, output)
+		return false, NewActionableError(err)
 	}
-
-	return nil
+	if err := writeKustomizeOutput(output, m); err != nil {
+		writeErr := errors.Wrapf(err, "unable to write the rendered configs to %s", output)
+		mustDeleteOutput(writeErr, output)
+		return false, NewInternalError(writeErr)
+	}
+	if sendMetrics {
+		kmetrics.RecordKustomizeBuild(context.Background(), input, m.Size(), time.Since(start))
+	}
+	return reproducible, nil
 }
 
 // validateTool checks if the hydration tool is installed and if the installed
@@ -194,17 +209,6 @@ func getVersion(tool string) (string, error) {
 	return version, nil
 }
 
-func validateKustomize() error {
-	version, err := getVersion(Kustomize)
-	if err != nil {
-		return errors.Errorf("Kustomization file is detected, but Kustomize is not installed: %v. Please install Kustomize and re-run the command.", err)
-	}
-	if err := validateTool(Kustomize, version, KustomizeVersion); err != nil {
-		fmt.Printf("WARNING: %v\n", err)
-	}
-	return nil
-}
-
 func validateHelm() error {
 	version, err := getVersion(Helm)
 	if err != nil {
@@ -218,15 +222,12 @@ func validateHelm() error {
 	return nil
 }
 
-// ValidateAndRunKustomize validates if the Kustomize and Helm binaries are supported.
-// If supported, it copies the source configs to a temp directory, run 'kustomize build',
-// save the output to another temp directory, and return the output path for further
-// parsing and validation.
+// ValidateAndRunKustomize validates if the Helm binary is supported.
+// If supported, it renders the source configs with the kustomize API under the
+// remote base restrictions of the flags, saves the output to a temp directory,
+// and returns the output path for further parsing and validation.
 func ValidateAndRunKustomize(sourcePath string) (cmpath.Absolute, error) {
 	var output cmpath.Absolute
-	if err := validateKustomize(); err != nil {
-		return output, err
-	}
 	if err := validateHelm(); err != nil {
 		return output, err
 	}
@@ -238,7 +239,11 @@ func ValidateAndRunKustomize(sourcePath string) (cmpath.Absolute, error) {
 		return output, err
 	}
 
-	if err := kustomizeBuild(sourcePath, tmpHydratedDir, false); err != nil {
+	opts := KustomizeOptions{
+		DisableRemoteBases: flags.DisableRemoteBases,
+		AllowedRemoteURLs:  flags.AllowedRemoteURLs,
+	}
+	if _, err := kustomizeBuild(sourcePath, tmpHydratedDir, opts, false); err != nil {
 		return output, errors.Wrapf(err, "unable to render the source configs in %s", sourcePath)
 	}
 
@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/monochromegane/go-gitignore"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+// IgnoreFileName is the name of the files listing the paths which Config Sync
+// should not read from the source, using the gitignore syntax. An ignore file
+// may appear in any directory, and its patterns are relative to that directory.
+const IgnoreFileName = ".configsyncignore"
+
+// Ignorer reports which paths under a root directory are excluded by the
+// .configsyncignore files in that directory tree.
+//
+// A path is ignored if it, or any of its parent directories, matches a pattern
+// in an ignore file of one of its parent directories. Negated patterns only
+// take effect within the ignore file that declares them.
+type Ignorer struct {
+	root string
+	// matchers caches the parsed ignore file of each directory, or nil if the
+	// directory has no ignore file.
+	matchers map[string]gitignore.IgnoreMatcher
+	// ignoredDirs caches whether each directory is ignored.
+	ignoredDirs map[string]bool
+}
+
+// NewIgnorer returns an Ignorer for the directory tree rooted at root.
+func NewIgnorer(root cmpath.Absolute) *Ignorer {
+	return &Ignorer{
+		root:        root.OSPath(),
+		matchers:    make(map[string]gitignore.IgnoreMatcher),
+		ignoredDirs: make(map[string]bool),
+	}
+}
+
+// Ignored returns true if path is excluded by an ignore file. Paths outside of
+// the root directory are never ignored.
+func (i *Ignorer) Ignored(path string, isDir bool) (bool, error) {
+	rel, err := filepath.Rel(i.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, nil
+	}
+	if isDir {
+		if ignored, found := i.ignoredDirs[path]; found {
+			return ignored, nil
+		}
+	}
+
+	ignored, err := i.matches(path, isDir)
+	if err != nil {
+		return false, err
+	}
+	if isDir {
+		i.ignoredDirs[path] = ignored
+	}
+	return ignored, nil
+}
+
+func (i *Ignorer) matches(path string, isDir bool) (bool, error) {
+	dir := filepath.Dir(path)
+	if dir != i.root {
+		ignored, err := i.Ignored(dir, true)
+		if err != nil || ignored {
+			return ignored, err
+		}
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		m, err := i.matcher(d)
+		if err != nil {
+			return false, err
+		}
+		if m != nil && m.Match(path, isDir) {
+			klog.V(4).Infof("Ignoring %s as listed in %s", path, filepath.Join(d, IgnoreFileName))
+			return true, nil
+		}
+		if d == i.root {
+			return false, nil
+		}
+	}
+}
+
+// matcher returns the parsed ignore file of dir, or nil if dir has none.
+func (i *Ignorer) matcher(dir string) (gitignore.IgnoreMatcher, error) {
+	if m, found := i.matchers[dir]; found {
+		return m, nil
+	}
+	file := filepath.Join(dir, IgnoreFileName)
+	m, err := gitignore.NewGitIgnore(file, dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "unable to read %s", file)
+		}
+	}
+	i.matchers[dir] = m
+	return m, nil
+}
+
+// FilterIgnoredFiles removes the files excluded by the ignore files under root.
+// It also returns the number of files removed.
+func FilterIgnoredFiles(root cmpath.Absolute, files []cmpath.Absolute) ([]cmpath.Absolute, int, error) {
+	ignorer := NewIgnorer(root)
+	var result []cmpath.Absolute
+	ignored := 0
+	for _, file := range files {
+		skip, err := ignorer.Ignored(file.OSPath(), false)
+		if err != nil {
+			return nil, 0, err
+		}
+		if skip {
+			ignored++
+			continue
+		}
+		result = append(result, file)
+	}
+	return result, ignored, nil
+}
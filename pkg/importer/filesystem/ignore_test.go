@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+)
+
+func TestFilterIgnoredFiles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ignoreFiles map[string]string
+		files       []string
+		want        []string
+		wantIgnored int
+	}{
+		{
+			name:  "no ignore files",
+			files: []string{"ns.yaml", "fixtures/cm.yaml"},
+			want:  []string{"ns.yaml", "fixtures/cm.yaml"},
+		},
+		{
+			name: "ignore directory",
+			ignoreFiles: map[string]string{
+				IgnoreFileName: "fixtures/\n",
+			},
+			files:       []string{"ns.yaml", "fixtures/cm.yaml", "fixtures/nested/cm.yaml"},
+			want:        []string{"ns.yaml"},
+			wantIgnored: 2,
+		},
+		{
+			name: "ignore glob with negation",
+			ignoreFiles: map[string]string{
+				IgnoreFileName: "# test data\n*.test.yaml\n!keep.test.yaml\n",
+			},
+			files:       []string{"ns.yaml", "a.test.yaml", "dir/b.test.yaml", "keep.test.yaml"},
+			want:        []string{"ns.yaml", "keep.test.yaml"},
+			wantIgnored: 2,
+		},
+		{
+			name: "nested ignore file is relative to its directory",
+			ignoreFiles: map[string]string{
+				filepath.Join("docs", IgnoreFileName): "/examples\n",
+			},
+			files:       []string{"examples/cm.yaml", "docs/examples/cm.yaml", "docs/cm.yaml"},
+			want:        []string{"examples/cm.yaml", "docs/cm.yaml"},
+			wantIgnored: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range tc.ignoreFiles {
+				writeFile(t, filepath.Join(dir, path), content)
+			}
+			var files []cmpath.Absolute
+			for _, f := range tc.files {
+				path := filepath.Join(dir, f)
+				writeFile(t, path, "")
+				file, err := cmpath.AbsoluteOS(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, file)
+			}
+			root, err := cmpath.AbsoluteOS(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotFiles, gotIgnored, err := FilterIgnoredFiles(root, files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range gotFiles {
+				rel, err := filepath.Rel(dir, f.OSPath())
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			sort.Strings(tc.want)
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+			if gotIgnored != tc.wantIgnored {
+				t.Errorf("got %d ignored files, want %d", gotIgnored, tc.wantIgnored)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,551 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/status"
+)
+
+const (
+	// ageFileExtension is the extension of the files encrypted with age.
+	ageFileExtension = ".age"
+
+	// sopsMetadataKey is the top-level key of the documents encrypted with
+	// SOPS, which holds the SOPS metadata.
+	sopsMetadataKey = "sops"
+
+	// sopsDefaultUnencryptedSuffix is the suffix of the keys whose values SOPS
+	// leaves unencrypted, unless the metadata sets another rule.
+	sopsDefaultUnencryptedSuffix = "_unencrypted"
+)
+
+// sopsMACOnlyEncryptedInitialization is written to the MAC before the values
+// when the metadata sets mac_only_encrypted, so that the MAC differs from the
+// one of all the values.
+var sopsMACOnlyEncryptedInitialization = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+// sopsValue matches the values encrypted with SOPS.
+var sopsValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// readSOPSContents returns the contents of the YAML or JSON file at path, and
+// true if its first document was encrypted with SOPS, which records its
+// metadata, including the MAC of the encrypted values, in a top-level sops
+// field.
+//
+// The file is checked before it is parsed as Kubernetes objects, since SOPS
+// may have encrypted their apiVersion and kind.
+func readSOPSContents(path string) ([]byte, bool) {
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml", ".json":
+	default:
+		return nil, false
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil || !bytes.Contains(contents, []byte(sopsMetadataKey)) {
+		// Read errors are reported when the file is parsed.
+		return nil, false
+	}
+	var document struct {
+		SOPS struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}
+	if err := yaml.NewDecoder(bytes.NewReader(contents)).Decode(&document); err != nil {
+		return nil, false
+	}
+	return contents, document.SOPS.MAC != ""
+}
+
+// readAgeFile decrypts the file encrypted with age, and reads the objects in
+// the decrypted content according to the extension preceding .age, e.g. the
+// YAML documents of secret.yaml.age.
+func (r *File) readAgeFile(rootDir cmpath.Absolute, policyDir cmpath.Relative, file cmpath.Absolute) ([]ast.FileObject, status.MultiError) {
+	if len(r.AgeIdentities) == 0 {
+		return nil, EncryptedFileError(file, "age")
+	}
+	contents, err := ioutil.ReadFile(file.OSPath())
+	if err != nil {
+		return nil, status.PathWrapError(err, file.OSPath())
+	}
+	plaintext, err := decryptAge(contents, r.AgeIdentities)
+	if err != nil {
+		return nil, DecryptionError(file, "age", err)
+	}
+	var unstructureds []*unstructured.Unstructured
+	switch filepath.Ext(strings.TrimSuffix(file.OSPath(), ageFileExtension)) {
+	case ".yml", ".yaml":
+		unstructureds, err = parseYAMLFile(plaintext)
+	case ".json":
+		unstructureds, err = parseJSONFile(plaintext)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, status.PathWrapError(err, file.OSPath())
+	}
+	return toAllFileObjects(unstructureds, rootDir, policyDir, file)
+}
+
+// readSOPSFile decrypts the contents of the YAML or JSON file whose documents
+// are encrypted with SOPS, and reads the objects in the decrypted documents.
+func (r *File) readSOPSFile(rootDir cmpath.Absolute, policyDir cmpath.Relative, file cmpath.Absolute, contents []byte) ([]ast.FileObject, status.MultiError) {
+	if len(r.AgeIdentities) == 0 {
+		return nil, EncryptedFileError(file, "SOPS")
+	}
+	plaintext, err := decryptSOPS(contents, r.AgeIdentities)
+	if err != nil {
+		return nil, DecryptionError(file, "SOPS", err)
+	}
+	// JSON is YAML, and the decrypted documents are encoded as YAML.
+	unstructureds, err := parseYAMLFile(plaintext)
+	if err != nil {
+		return nil, status.PathWrapError(err, file.OSPath())
+	}
+	return toAllFileObjects(unstructureds, rootDir, policyDir, file)
+}
+
+// decryptAge decrypts the contents of a file encrypted with age, in either the
+// binary or the ASCII armored format.
+func decryptAge(contents []byte, identities []age.Identity) ([]byte, error) {
+	var in io.Reader = bytes.NewReader(contents)
+	if bytes.HasPrefix(bytes.TrimSpace(contents), []byte(armor.Header)) {
+		in = armor.NewReader(bytes.NewReader(bytes.TrimSpace(contents)))
+	}
+	out, err := age.Decrypt(in, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(out)
+}
+
+// sopsMetadata is the part of the SOPS metadata needed to decrypt documents
+// whose data key is encrypted with age.
+type sopsMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	LastModified      string `yaml:"lastmodified"`
+	MAC               string `yaml:"mac"`
+	UnencryptedSuffix string `yaml:"unencrypted_suffix"`
+	EncryptedSuffix   string `yaml:"encrypted_suffix"`
+	UnencryptedRegex  string `yaml:"unencrypted_regex"`
+	EncryptedRegex    string `yaml:"encrypted_regex"`
+	MACOnlyEncrypted  bool   `yaml:"mac_only_encrypted"`
+	// The comment rules are not supported, since the comments are not part of
+	// the decoded documents.
+	UnencryptedCommentRegex string `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string `yaml:"encrypted_comment_regex"`
+}
+
+// decryptSOPS decrypts the documents of a YAML or JSON file encrypted with
+// SOPS, and returns them as YAML documents without their SOPS metadata.
+//
+// Like SOPS, the metadata is read from the first document, and the decryption
+// fails unless the MAC of all the documents matches the one in the metadata,
+// so that values cannot be added, removed or swapped in the source without the
+// data key.
+func decryptSOPS(contents []byte, identities []age.Identity) ([]byte, error) {
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		document := &yaml.Node{}
+		err := decoder.Decode(document)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		documents = append(documents, document)
+	}
+	if len(documents) == 0 {
+		return nil, errors.New("no SOPS metadata")
+	}
+
+	metadataNode := removeSOPSMetadata(documents[0].Content[0])
+	if metadataNode == nil {
+		return nil, errors.New("no SOPS metadata in the first document")
+	}
+	for _, document := range documents[1:] {
+		removeSOPSMetadata(document.Content[0])
+	}
+	var metadata sopsMetadata
+	if err := metadataNode.Decode(&metadata); err != nil {
+		return nil, errors.Wrap(err, "invalid SOPS metadata")
+	}
+
+	d, err := newSOPSDecryptor(metadata, identities)
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		if err := d.walk(document.Content[0], nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.verifyMAC(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for i, document := range documents {
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		encoded, err := yaml.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(encoded)
+	}
+	return out.Bytes(), nil
+}
+
+// removeSOPSMetadata removes the top-level SOPS metadata from the mapping, and
+// returns it, or nil if it has none.
+func removeSOPSMetadata(mapping *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == sopsMetadataKey {
+			value := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// sopsDecryptor decrypts the values of SOPS documents in place, and computes
+// their MAC.
+type sopsDecryptor struct {
+	metadata         sopsMetadata
+	dataKey          []byte
+	unencryptedRegex *regexp.Regexp
+	encryptedRegex   *regexp.Regexp
+	mac              hash.Hash
+}
+
+func newSOPSDecryptor(metadata sopsMetadata, identities []age.Identity) (*sopsDecryptor, error) {
+	d := &sopsDecryptor{
+		metadata: metadata,
+		mac:      sha512.New(),
+	}
+	if metadata.UnencryptedCommentRegex != "" || metadata.EncryptedCommentRegex != "" {
+		return nil, errors.New("unsupported unencrypted_comment_regex or encrypted_comment_regex")
+	}
+	if metadata.MACOnlyEncrypted {
+		d.mac.Write(sopsMACOnlyEncryptedInitialization)
+	}
+	if metadata.UnencryptedSuffix == "" && metadata.EncryptedSuffix == "" &&
+		metadata.UnencryptedRegex == "" && metadata.EncryptedRegex == "" {
+		d.metadata.UnencryptedSuffix = sopsDefaultUnencryptedSuffix
+	}
+	var err error
+	if metadata.UnencryptedRegex != "" {
+		if d.unencryptedRegex, err = regexp.Compile(metadata.UnencryptedRegex); err != nil {
+			return nil, errors.Wrap(err, "invalid unencrypted_regex")
+		}
+	}
+	if metadata.EncryptedRegex != "" {
+		if d.encryptedRegex, err = regexp.Compile(metadata.EncryptedRegex); err != nil {
+			return nil, errors.Wrap(err, "invalid encrypted_regex")
+		}
+	}
+	if d.dataKey, err = decryptDataKey(metadata, identities); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// decryptDataKey decrypts the data key of the SOPS documents with the first
+// age recipient matching one of the identities.
+func decryptDataKey(metadata sopsMetadata, identities []age.Identity) ([]byte, error) {
+	if len(metadata.Age) == 0 {
+		return nil, errors.New("the data key is not encrypted with age")
+	}
+	var lastErr error
+	for _, recipient := range metadata.Age {
+		out, err := age.Decrypt(armor.NewReader(strings.NewReader(recipient.Enc)), identities...)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		key, err := ioutil.ReadAll(out)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return key, nil
+	}
+	return nil, errors.Wrap(lastErr, "failed to decrypt the data key")
+}
+
+// walk decrypts the values of the node in place, in document order.
+//
+// The collections are encoded in the block style, since the YAML decoder of
+// the Kubernetes objects would read the flow style of JSON documents as JSON.
+func (d *sopsDecryptor) walk(node *yaml.Node, path []string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if err := d.walk(node.Content[i+1], append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		node.Style &^= yaml.FlowStyle
+		// SOPS encrypts the items of a sequence with the path of the sequence.
+		for _, item := range node.Content {
+			if err := d.walk(item, path); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return d.decryptScalar(node, path)
+	case yaml.AliasNode:
+		return errors.Errorf("unsupported alias at %q", strings.Join(path, "."))
+	}
+	return nil
+}
+
+// decryptScalar decrypts the scalar node in place, if it is encrypted, and
+// adds its plaintext to the MAC, unless only the encrypted values are
+// authenticated.
+func (d *sopsDecryptor) decryptScalar(node *yaml.Node, path []string) error {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	if value == nil {
+		// SOPS neither encrypts nor authenticates null values.
+		return nil
+	}
+	encrypted := d.isEncrypted(path)
+	if encrypted {
+		ciphertext, ok := value.(string)
+		if !ok {
+			return errors.Errorf("value at %q is not encrypted", strings.Join(path, "."))
+		}
+		var err error
+		value, err = d.decryptValue(ciphertext, strings.Join(path, ":")+":")
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt the value at %q", strings.Join(path, "."))
+		}
+		setScalar(node, value)
+	}
+	if d.metadata.MACOnlyEncrypted && !encrypted {
+		return nil
+	}
+	plaintext, err := sopsBytes(value)
+	if err != nil {
+		return errors.Wrapf(err, "value at %q", strings.Join(path, "."))
+	}
+	d.mac.Write(plaintext)
+	return nil
+}
+
+// isEncrypted returns true if SOPS encrypts the value at path, according to
+// the rules of the metadata.
+func (d *sopsDecryptor) isEncrypted(path []string) bool {
+	encrypted := true
+	if d.metadata.UnencryptedSuffix != "" {
+		for _, p := range path {
+			if strings.HasSuffix(p, d.metadata.UnencryptedSuffix) {
+				encrypted = false
+				break
+			}
+		}
+	}
+	if d.metadata.EncryptedSuffix != "" {
+		encrypted = false
+		for _, p := range path {
+			if strings.HasSuffix(p, d.metadata.EncryptedSuffix) {
+				encrypted = true
+				break
+			}
+		}
+	}
+	if d.unencryptedRegex != nil {
+		for _, p := range path {
+			if d.unencryptedRegex.MatchString(p) {
+				encrypted = false
+				break
+			}
+		}
+	}
+	if d.encryptedRegex != nil {
+		encrypted = false
+		for _, p := range path {
+			if d.encryptedRegex.MatchString(p) {
+				encrypted = true
+				break
+			}
+		}
+	}
+	return encrypted
+}
+
+// decryptValue decrypts a value encrypted with SOPS into a string, int,
+// float64, bool, time.Time or []byte, depending on its type.
+func (d *sopsDecryptor) decryptValue(ciphertext, additionalData string) (interface{}, error) {
+	if ciphertext == "" {
+		// SOPS does not encrypt empty strings.
+		return "", nil
+	}
+	matches := sopsValue.FindStringSubmatch(ciphertext)
+	if matches == nil {
+		return nil, errors.New("the value is not encrypted with SOPS")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		var err error
+		if parts[i], err = base64.StdEncoding.DecodeString(matches[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+	block, err := aes.NewCipher(d.dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, err
+	}
+	switch valueType := matches[4]; valueType {
+	case "str":
+		return string(plaintext), nil
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	case "bytes":
+		return plaintext, nil
+	case "time":
+		var t time.Time
+		err := t.UnmarshalText(plaintext)
+		return t, err
+	default:
+		return nil, errors.Errorf("unsupported type %q", valueType)
+	}
+}
+
+// verifyMAC returns an error unless the MAC of the decrypted values matches
+// the MAC in the metadata.
+func (d *sopsDecryptor) verifyMAC() error {
+	lastModified, err := time.Parse(time.RFC3339, d.metadata.LastModified)
+	if err != nil {
+		return errors.Wrap(err, "invalid lastmodified")
+	}
+	stored, err := d.decryptValue(d.metadata.MAC, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return errors.Wrap(err, "failed to decrypt the MAC")
+	}
+	storedMAC, ok := stored.(string)
+	computedMAC := fmt.Sprintf("%X", d.mac.Sum(nil))
+	if !ok || subtle.ConstantTimeCompare([]byte(storedMAC), []byte(computedMAC)) != 1 {
+		return errors.New("the MAC does not match the documents, which were modified after they were encrypted")
+	}
+	return nil
+}
+
+// setScalar replaces the value of the scalar node with the decrypted value.
+func setScalar(node *yaml.Node, value interface{}) {
+	node.Style = 0
+	switch v := value.(type) {
+	case string:
+		node.Tag = "!!str"
+		node.Value = v
+	case []byte:
+		node.Tag = "!!str"
+		node.Value = string(v)
+	case int:
+		node.Tag = "!!int"
+		node.Value = strconv.Itoa(v)
+	case float64:
+		node.Tag = "!!float"
+		node.Value = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		node.Tag = "!!bool"
+		node.Value = strconv.FormatBool(v)
+	case time.Time:
+		node.Tag = "!!timestamp"
+		node.Value = v.Format(time.RFC3339Nano)
+	}
+}
+
+// sopsBytes returns the bytes of a value which SOPS adds to the MAC.
+func sopsBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case int:
+		return []byte(strconv.Itoa(v)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case bool:
+		// SOPS capitalizes booleans, like Python.
+		if v {
+			return []byte("True"), nil
+		}
+		return []byte("False"), nil
+	case time.Time:
+		return v.MarshalText()
+	default:
+		return nil, errors.Errorf("unsupported type %T", value)
+	}
+}
+
+// ParseAgeIdentities parses the age identities, one per line, of the Secret
+// referenced by spec.decryption.secretRef.
+func ParseAgeIdentities(key string) ([]age.Identity, error) {
+	return age.ParseIdentities(strings.NewReader(key))
+}
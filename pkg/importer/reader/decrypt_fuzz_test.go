@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package reader
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fuzzAgeKey is the age identity the files in testdata/sops are encrypted for.
+const fuzzAgeKey = "AGE-SECRET-KEY-1RPMZZW2734628YKPYY3WKCM0SAH7HJD2CTT6NRKHS562K6KKXSCS85VYZ7"
+
+// addSeedFiles adds the files in testdata/sops matching the pattern to the
+// seed corpus.
+func addSeedFiles(f *testing.F, pattern string) {
+	f.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "sops", pattern))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(contents)
+	}
+}
+
+func FuzzDecryptSOPS(f *testing.F) {
+	addSeedFiles(f, "*.enc.*")
+	identities, err := ParseAgeIdentities(fuzzAgeKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, contents []byte) {
+		// Any contents must be decrypted or rejected without panicking.
+		plaintext, err := decryptSOPS(contents, identities)
+		if err != nil {
+			return
+		}
+		// The decrypted documents are read as YAML.
+		decoder := yaml.NewDecoder(bytes.NewReader(plaintext))
+		for {
+			var document interface{}
+			if err := decoder.Decode(&document); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("got invalid decrypted YAML: %v\n%s", err, plaintext)
+			}
+		}
+	})
+}
+
+func FuzzDecryptAge(f *testing.F) {
+	addSeedFiles(f, "*.age")
+	identities, err := ParseAgeIdentities(fuzzAgeKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, contents []byte) {
+		// Any contents must be decrypted or rejected without panicking.
+		_, _ = decryptAge(contents, identities)
+	})
+}
@@ -0,0 +1,495 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ft "kpt.dev/configsync/pkg/importer/filesystem/filesystemtest"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// The fixtures are encrypted for testAgeKey, and decrypt with the sops 3.10.2
+// and age 1.2.0 CLIs.
+const (
+	testAgeKey  = "AGE-SECRET-KEY-1RPMZZW2734628YKPYY3WKCM0SAH7HJD2CTT6NRKHS562K6KKXSCS85VYZ7"
+	otherAgeKey = "AGE-SECRET-KEY-1LASXHMZY8H9X82KF0TK2UAYS6Z2N4VQ22U8NNR6MTVYVWKTNRDSS9ZF46D"
+)
+
+// sopsTypedYAML is encrypted with the default rules, so every value but
+// spec.note_unencrypted is encrypted, including apiVersion and kind.
+const sopsTypedYAML = `apiVersion: ENC[AES256_GCM,data:w4HvGG/8hpOakUkT+Yk=,iv:gmsT4UGLEqvymJnEaY4L/7PnheWlvOmBnpwUQrULc/0=,tag:CB+++HgoatfALfXonwE/eA==,type:str]
+kind: ENC[AES256_GCM,data:uuL/570X+g==,iv:ALI0t5UWOGv7fOCfFabNI21qkT0iwRxm3BrjbFIwgCQ=,tag:spkkgZ8w7TgbUm3f3SowaA==,type:str]
+metadata:
+    name: ENC[AES256_GCM,data:QXSGrUk=,iv:Iwpb4xbxKOE8IpE+9PxG1iM1lbmSsBusrRQtgR5vCEg=,tag:mwHqD7nCxQp951BVXUjR5Q==,type:str]
+    namespace: ENC[AES256_GCM,data:hCku,iv:QjQ8ycxM/L5ywCB2IvSkiHWfCoJKVZvoIc4AmHhJ8pI=,tag:ShxgybAIzQlEJrpZsRJhaw==,type:str]
+spec:
+    replicas: ENC[AES256_GCM,data:CQ==,iv:TG5rfvtEP2ISiukWvrhhb429PzFv6z5+57FzdiZBfws=,tag:3yiK5EcbsB00B1+V7qMzPQ==,type:int]
+    ratio: ENC[AES256_GCM,data:V3TS,iv:wQ9ClvDs5YuRImn2dcMuEYG4OhvvM9cHOmO3lCBi7LA=,tag:UgrqVw48JL0WWoyhSG5tMw==,type:float]
+    paused: ENC[AES256_GCM,data:l4rvag==,iv:3WVA42buSnBbmlc+s8zSmtB6wB8GI4MX8pDm9nAjyJo=,tag:kStfgD9GiLHdcskhSMdJKw==,type:bool]
+    empty: ""
+    ports:
+        - ENC[AES256_GCM,data:v5s=,iv:vWsUzUn89KyOMa1o23TJ+6TC0t3ThxasulDI1/Ehr3Y=,tag:fMEmDve3qJMouevSx8w45Q==,type:int]
+        - ENC[AES256_GCM,data:DRar,iv:IRz+aeuEy17hSUs0s90Lsw0sAHqns8lIQpSS0uMWRJA=,tag:hhG0gkglh8R7U/4e2uxk4A==,type:int]
+    note_unencrypted: visible
+sops:
+    kms: []
+    gcp_kms: []
+    azure_kv: []
+    hc_vault: []
+    age:
+        - recipient: age104suv6e7k8st7a55wegfs4x5ykavpzp7mpdm65zzrh8aws0u7pss7ec9kq
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBrNlllci91anc3STJ0RHVE
+            NjJYOS9kRTB6SnJ1Sk5kQ09VU041bGNEL3dBCmxEL0tHTm5DZ2dUOEI4VXpMOVBR
+            VVFvR0ZWMitQZ3lSNDFNOVBsOHRqcTAKLS0tIGo1Vkx1QTFqV3dFclNIL0JWTW1L
+            bXN6SjN4WjF5ZXR4M3hJOE1COGtUK2MKB9JIYLRcBJpEvjxbxSvlLYdoKMlm5qRn
+            LjLdji5B/BjQrYCkti9Ac8QWFA9e3polDJoI8Lm4EjXF6WK2PGjIYQ==
+            -----END AGE ENCRYPTED FILE-----
+    lastmodified: "2026-10-18T19:38:27Z"
+    mac: ENC[AES256_GCM,data:7OYsxRNmuwPb8FkpmnZ7OizYM/FZEVaY6LaJYIAsrc64PS2uGq+FtYZmCBK36ruNRftDLfKTGHy3OVBxWKZ1hokYU4zfLNyZ2VsdHAapf3bY0np27H0iNFaCU4zFoR0Au36lg9QOXJG2zszGNcItEdfrGdamBvGZDkqAk53KL7E=,iv:tRY3yvY3rvsHPbKUjkeG4gkZ9mWNauywn/1q8cykmJs=,tag:oDIJd1nIgOUh2S5X0u0asw==,type:str]
+    pgp: []
+    unencrypted_suffix: _unencrypted
+    version: 3.7.3
+`
+
+// sopsMultiDocumentYAML has two documents sharing the SOPS metadata and MAC.
+const sopsMultiDocumentYAML = `apiVersion: ENC[AES256_GCM,data:8p8=,iv:1EwF6AL9JGr1n5Bz1AFr4DDg2r1/0tlbhgC7c7wv62s=,tag:BioPJ4VQ+2YsfLLypamQDA==,type:str]
+kind: ENC[AES256_GCM,data:CoBFN1t0GaCH,iv:yPdijGcw1G8KEP9LajZhrD/ddWdaeV332Oqyd+mVyx0=,tag:U58nVCjr6kWOzVMzI0+7VA==,type:str]
+metadata:
+    name: ENC[AES256_GCM,data:WE3KNtPvER8=,iv:kvPkbboc69dqZNzeD7SChuHpFE1YMOKD6ZQnYQQAb+s=,tag:u6UPwfzwCwva4BKFr+3CSg==,type:str]
+    namespace: ENC[AES256_GCM,data:f2ns,iv:BrQVRfmQ4iVmRa0zS9xvinCOtX+tuIVOLNpFPEtfSZo=,tag:DhkFayg0a+T0B1wqGJD6zQ==,type:str]
+data:
+    replicas: ENC[AES256_GCM,data:3A==,iv:y9g9CVd2rjoYv0BBaKPck9y/brx+O0DG11ASKDD63j0=,tag:6Y2ShEBK65rSx2VeJx4zRw==,type:str]
+    list_unencrypted: plain
+sops:
+    kms: []
+    gcp_kms: []
+    azure_kv: []
+    hc_vault: []
+    age:
+        - recipient: age104suv6e7k8st7a55wegfs4x5ykavpzp7mpdm65zzrh8aws0u7pss7ec9kq
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBUOVViWmdWemc4cm1RZjhw
+            aGFPN2xCSFp3R1puVFZQY1VMKy9hSjlqUmkwClJteThTWTVqc2lnaXB1bmdIVmZG
+            MnFEZVdCYlpORVR3Tm5kemdnTWQ2MEEKLS0tIHVhaytBOVl0UE44M1BTeVJtUTBM
+            LzJxaXRwMzVjaHZLU0NwRGVJMnBsRXMKl4kzVWmYgIDchWV7VaGHrD8s3QeqfLpT
+            NzClxNITYskB+Hlr7UaueiaH4hJ8UewiCEqgTd3SceO4iX5G2C9fwA==
+            -----END AGE ENCRYPTED FILE-----
+    lastmodified: "2026-10-18T19:37:53Z"
+    mac: ENC[AES256_GCM,data:cOlDwhiBIR0NETB5//TQ4UnsGuEtSe2Yv1nltyys99rcOwtv1b2q/Rya7mEk5GHyJP6Yik79rbzxqP7paUoSkErVnrjyyXknhTvu+Mzhcfa36DXc4eQGCyoHIi4RIQ8vV7Yftw0C8WMqsfbBduEcE/5QylMBq1Kelui+nLKg3XU=,iv:JK+pNJL+b1eiyerdXUQuXzV5U2j55+aX/ZT5aLFfyu4=,tag:xR4xOlW4s0LT02x+cl8c9w==,type:str]
+    pgp: []
+    unencrypted_suffix: _unencrypted
+    version: 3.7.3
+---
+apiVersion: ENC[AES256_GCM,data:IIg=,iv:WUG5BgedJcOs/9lF7Xbe2PbH1X7WqJqWLtQh8po63R0=,tag:zPIDRir0CPGI8U634TFyMA==,type:str]
+kind: ENC[AES256_GCM,data:6WPmj+b2,iv:ctjQPO+8/PurUzcMw9K1s0L31uM1P1amt+0HoLP/wWE=,tag:c5ubpKn0Th8q2f9sCCZPdg==,type:str]
+metadata:
+    name: ENC[AES256_GCM,data:6apiFcU=,iv:crFwSFtmksrsCN6ZqO93+biVOCWPIIIi/o6CBQDxvmY=,tag:K6vFcQlxAZvxZy0zSSE3Sg==,type:str]
+    namespace: ENC[AES256_GCM,data:tQgM,iv:xOmykYI8nqEcEu2kq2SqY5AubM7VA0epL99p43ogqI4=,tag:5firJQjoDWo66IVzMlaXgg==,type:str]
+stringData:
+    token: ENC[AES256_GCM,data:EJ8S,iv:ievl0LmQDxFyh0ENYr4YypcIvBKnmQruEEo07D8rFKg=,tag:7wQdStmaQUIneG+1GDNBfw==,type:str]
+    items_count: ENC[AES256_GCM,data:3g==,iv:qUIgMSVLUKebUmAharh/Z/ahqwTdYSOVZCAYdy6SDUw=,tag:I0xrv5s+jAogD6kkBNbMXw==,type:str]
+sops:
+    kms: []
+    gcp_kms: []
+    azure_kv: []
+    hc_vault: []
+    age:
+        - recipient: age104suv6e7k8st7a55wegfs4x5ykavpzp7mpdm65zzrh8aws0u7pss7ec9kq
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBUOVViWmdWemc4cm1RZjhw
+            aGFPN2xCSFp3R1puVFZQY1VMKy9hSjlqUmkwClJteThTWTVqc2lnaXB1bmdIVmZG
+            MnFEZVdCYlpORVR3Tm5kemdnTWQ2MEEKLS0tIHVhaytBOVl0UE44M1BTeVJtUTBM
+            LzJxaXRwMzVjaHZLU0NwRGVJMnBsRXMKl4kzVWmYgIDchWV7VaGHrD8s3QeqfLpT
+            NzClxNITYskB+Hlr7UaueiaH4hJ8UewiCEqgTd3SceO4iX5G2C9fwA==
+            -----END AGE ENCRYPTED FILE-----
+    lastmodified: "2026-10-18T19:37:53Z"
+    mac: ENC[AES256_GCM,data:cOlDwhiBIR0NETB5//TQ4UnsGuEtSe2Yv1nltyys99rcOwtv1b2q/Rya7mEk5GHyJP6Yik79rbzxqP7paUoSkErVnrjyyXknhTvu+Mzhcfa36DXc4eQGCyoHIi4RIQ8vV7Yftw0C8WMqsfbBduEcE/5QylMBq1Kelui+nLKg3XU=,iv:JK+pNJL+b1eiyerdXUQuXzV5U2j55+aX/ZT5aLFfyu4=,tag:xR4xOlW4s0LT02x+cl8c9w==,type:str]
+    pgp: []
+    unencrypted_suffix: _unencrypted
+    version: 3.7.3
+`
+
+const sopsJSON = `{
+	"apiVersion": "ENC[AES256_GCM,data:1WM=,iv:RKznWkMtgibFW13smZpw9zeUckYDRS82CjflzgNlLYs=,tag:Z2NKT5uQD8MCmjwGNDzxvQ==,type:str]",
+	"kind": "ENC[AES256_GCM,data:vGD6Mnni,iv:d7+7IXD8pKxZUY1c2ZwPhYLqogPVZxxq7rijto2bqJk=,tag:Fb16Px2usXXcFGrE4X+yUA==,type:str]",
+	"metadata": {
+		"name": "ENC[AES256_GCM,data:3nBrsdAScKcn6A==,iv:f0cijBFyyupjA3CQFHzEMJm4Nr9FaEWxduXJDuGYmXw=,tag:mAPWycmOwzWXjP0eRIawQA==,type:str]",
+		"namespace": "ENC[AES256_GCM,data:7y1v,iv:I0VNhc5DSMMocq+enGFhlaMOTTYTj8oLdgLYK/pWol8=,tag:fcaWkzYHasq3Ref8xYzghg==,type:str]"
+	},
+	"stringData": {
+		"password": "ENC[AES256_GCM,data:IRCwaj2d,iv:TdQAqhJ9rPppxTEqh+448W8fn58G5UIymiQjgwODJm0=,tag:EDhAb9WyEbJ2PSwN2NpTkQ==,type:str]"
+	},
+	"sops": {
+		"kms": null,
+		"gcp_kms": null,
+		"azure_kv": null,
+		"hc_vault": null,
+		"age": [
+			{
+				"recipient": "age104suv6e7k8st7a55wegfs4x5ykavpzp7mpdm65zzrh8aws0u7pss7ec9kq",
+				"enc": "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBqRTVmWjNwcXFpMS9vWVY1\nL3lHQlV3bVZnQ0RkcW5FaFp6ZDU4R0lVMkJvCkpmVXFkN1p6bDNJeU1yd0hIU3gz\nenVMb2JhMjNtNW5kNDM3bGt5UDQralkKLS0tIEdzWGxpYVNEQVR0K0FhV0cyU0pm\nSEhHbjFWUUlmTVd0eUdtSnJveGQ2M0kKipTGuhh6fxuTFW0QhsFFX1tZUU9Q59FW\nDzUoIalVAU3vyJV29vziIW0HCBRfhiG+K+458B7Is6UWVYgCywjn0A==\n-----END AGE ENCRYPTED FILE-----\n"
+			}
+		],
+		"lastmodified": "2026-10-18T19:37:53Z",
+		"mac": "ENC[AES256_GCM,data:m/c1Eyd8XS601K4srj8F6cNidsb9lB9Ol/97rfFsAFz8MugRFEtupHDUeoPBDh9Ce2nutb5Mfzk0s+t1+6wfQ6IUBuaVLLukGA1MMhzD2N61IMYj0BgW6l7iU+flUmlim8F6he1/Wh+BlOdtyzlwNb3QYmic3DRcTg2WSg550p8=,iv:mPvgwYUrySSJ76Vci1VJNtLlLyzPZXQFmUIgfmViQbg=,tag:qRWc9WW4XWYPdXH5ExUyZw==,type:str]",
+		"pgp": null,
+		"unencrypted_suffix": "_unencrypted",
+		"version": "3.7.3"
+	}
+}`
+
+const ageArmoredYAML = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSB3YkVyTWRleXI4U0Zxc2NS
+dmhSSXZVV0kxL1phNXdnMi9TSHhUZFl0RVRVCmc3VDhZQVc5YjlLMC9uOHM2d1Zv
+OTdqekp1MSt4dUk2WHlSQVlGOG8rM00KLS0tIFlzMDhVYmpPbXhqZWRiTnp6RlYx
+amFjV3J2QlBOTHhwZFdLSEt1cWYxSDAK0j+4Be8bHW6pH+WJQHruim0QymizxRie
+JI0GgA6zzvMgaz6kmKG8+uCwUlDPw8mLeiRrP3Tj4PJfdu3cqmGSEdWtZfEao45M
+obnUve+/8F6KqpCtaZE04zOFwJ5jUZXEnryofGZwAQdeaE40DW9/H4YeZtC0I/Ue
+bBr8wyPnp9rvnYV7/74BjALsu+j4k8QKVdUvx1SC7JRWOm80Ggor+gVqMsPE8nI/
+Rsf7xYIrzED73+0=
+-----END AGE ENCRYPTED FILE-----
+`
+
+// sopsTamperedYAML is sopsTypedYAML with an unencrypted value modified.
+const sopsTamperedYAML = `apiVersion: ENC[AES256_GCM,data:w4HvGG/8hpOakUkT+Yk=,iv:gmsT4UGLEqvymJnEaY4L/7PnheWlvOmBnpwUQrULc/0=,tag:CB+++HgoatfALfXonwE/eA==,type:str]
+kind: ENC[AES256_GCM,data:uuL/570X+g==,iv:ALI0t5UWOGv7fOCfFabNI21qkT0iwRxm3BrjbFIwgCQ=,tag:spkkgZ8w7TgbUm3f3SowaA==,type:str]
+metadata:
+    name: ENC[AES256_GCM,data:QXSGrUk=,iv:Iwpb4xbxKOE8IpE+9PxG1iM1lbmSsBusrRQtgR5vCEg=,tag:mwHqD7nCxQp951BVXUjR5Q==,type:str]
+    namespace: ENC[AES256_GCM,data:hCku,iv:QjQ8ycxM/L5ywCB2IvSkiHWfCoJKVZvoIc4AmHhJ8pI=,tag:ShxgybAIzQlEJrpZsRJhaw==,type:str]
+spec:
+    replicas: ENC[AES256_GCM,data:CQ==,iv:TG5rfvtEP2ISiukWvrhhb429PzFv6z5+57FzdiZBfws=,tag:3yiK5EcbsB00B1+V7qMzPQ==,type:int]
+    ratio: ENC[AES256_GCM,data:V3TS,iv:wQ9ClvDs5YuRImn2dcMuEYG4OhvvM9cHOmO3lCBi7LA=,tag:UgrqVw48JL0WWoyhSG5tMw==,type:float]
+    paused: ENC[AES256_GCM,data:l4rvag==,iv:3WVA42buSnBbmlc+s8zSmtB6wB8GI4MX8pDm9nAjyJo=,tag:kStfgD9GiLHdcskhSMdJKw==,type:bool]
+    empty: ""
+    ports:
+        - ENC[AES256_GCM,data:v5s=,iv:vWsUzUn89KyOMa1o23TJ+6TC0t3ThxasulDI1/Ehr3Y=,tag:fMEmDve3qJMouevSx8w45Q==,type:int]
+        - ENC[AES256_GCM,data:DRar,iv:IRz+aeuEy17hSUs0s90Lsw0sAHqns8lIQpSS0uMWRJA=,tag:hhG0gkglh8R7U/4e2uxk4A==,type:int]
+    note_unencrypted: hidden
+sops:
+    kms: []
+    gcp_kms: []
+    azure_kv: []
+    hc_vault: []
+    age:
+        - recipient: age104suv6e7k8st7a55wegfs4x5ykavpzp7mpdm65zzrh8aws0u7pss7ec9kq
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBrNlllci91anc3STJ0RHVE
+            NjJYOS9kRTB6SnJ1Sk5kQ09VU041bGNEL3dBCmxEL0tHTm5DZ2dUOEI4VXpMOVBR
+            VVFvR0ZWMitQZ3lSNDFNOVBsOHRqcTAKLS0tIGo1Vkx1QTFqV3dFclNIL0JWTW1L
+            bXN6SjN4WjF5ZXR4M3hJOE1COGtUK2MKB9JIYLRcBJpEvjxbxSvlLYdoKMlm5qRn
+            LjLdji5B/BjQrYCkti9Ac8QWFA9e3polDJoI8Lm4EjXF6WK2PGjIYQ==
+            -----END AGE ENCRYPTED FILE-----
+    lastmodified: "2026-10-18T19:38:27Z"
+    mac: ENC[AES256_GCM,data:7OYsxRNmuwPb8FkpmnZ7OizYM/FZEVaY6LaJYIAsrc64PS2uGq+FtYZmCBK36ruNRftDLfKTGHy3OVBxWKZ1hokYU4zfLNyZ2VsdHAapf3bY0np27H0iNFaCU4zFoR0Au36lg9QOXJG2zszGNcItEdfrGdamBvGZDkqAk53KL7E=,iv:tRY3yvY3rvsHPbKUjkeG4gkZ9mWNauywn/1q8cykmJs=,tag:oDIJd1nIgOUh2S5X0u0asw==,type:str]
+    pgp: []
+    unencrypted_suffix: _unencrypted
+    version: 3.7.3
+`
+
+func TestFileReader_Read_Decrypt(t *testing.T) {
+	testCases := []struct {
+		name         string
+		fileName     string
+		fileContents string
+		want         []map[string]interface{}
+	}{
+		{
+			name:         "SOPS YAML with typed values",
+			fileName:     "typed.yaml",
+			fileContents: sopsTypedYAML,
+			want: []map[string]interface{}{{
+				"apiVersion": "example.com/v1",
+				"kind":       "Example",
+				"metadata": map[string]interface{}{
+					"name":        "typed",
+					"namespace":   "foo",
+					"annotations": map[string]interface{}{},
+					"labels":      map[string]interface{}{},
+				},
+				"spec": map[string]interface{}{
+					"replicas":         int64(3),
+					"ratio":            0.5,
+					"paused":           true,
+					"empty":            "",
+					"ports":            []interface{}{int64(80), int64(443)},
+					"note_unencrypted": "visible",
+				},
+			}},
+		},
+		{
+			name:         "SOPS YAML with multiple documents",
+			fileName:     "multi.yaml",
+			fileContents: sopsMultiDocumentYAML,
+			want: []map[string]interface{}{
+				{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]interface{}{
+						"name":        "settings",
+						"namespace":   "foo",
+						"annotations": map[string]interface{}{},
+						"labels":      map[string]interface{}{},
+					},
+					"data": map[string]interface{}{
+						"replicas":         "3",
+						"list_unencrypted": "plain",
+					},
+				},
+				{
+					"apiVersion": "v1",
+					"kind":       "Secret",
+					"metadata": map[string]interface{}{
+						"name":        "token",
+						"namespace":   "foo",
+						"annotations": map[string]interface{}{},
+						"labels":      map[string]interface{}{},
+					},
+					"stringData": map[string]interface{}{
+						"token":       "abc",
+						"items_count": "2",
+					},
+				},
+			},
+		},
+		{
+			name:         "SOPS JSON",
+			fileName:     "secret.json",
+			fileContents: sopsJSON,
+			want: []map[string]interface{}{{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]interface{}{
+					"name":        "json-creds",
+					"namespace":   "foo",
+					"annotations": map[string]interface{}{},
+					"labels":      map[string]interface{}{},
+				},
+				"stringData": map[string]interface{}{
+					"password": "s3cret",
+				},
+			}},
+		},
+		{
+			name:         "age armored YAML",
+			fileName:     "secret.yaml.age",
+			fileContents: ageArmoredYAML,
+			want: []map[string]interface{}{{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata": map[string]interface{}{
+					"name":        "creds",
+					"namespace":   "foo",
+					"annotations": map[string]interface{}{},
+					"labels":      map[string]interface{}{},
+				},
+				"type": "Opaque",
+				"stringData": map[string]interface{}{
+					"password": "hunter2",
+					"port":     "5432",
+					"enabled":  "true",
+				},
+			}},
+		},
+	}
+
+	identities, err := reader.ParseAgeIdentities(testAgeKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := ft.NewTestDir(t, ft.FileContents(tc.fileName, tc.fileContents))
+			r := reader.File{AgeIdentities: identities}
+			objs, err := r.Read(dir.FilePaths(tc.fileName))
+			if err != nil {
+				t.Fatalf("got Read() = %v, want nil", err)
+			}
+			var got []map[string]interface{}
+			for _, obj := range objs {
+				got = append(got, obj.Object)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// The files in testdata/sops are encrypted for testAgeKey with the sops 3.10.2
+// CLI, except typed.sops-3.9.0.enc.yaml, and with the age CLI, e.g.
+//
+//	sops encrypt --age <recipient> [--encrypted-regex ...] typed.yaml > typed.enc.yaml
+//	age --encrypt --recipient <recipient> [--armor] configmap.yaml > configmap.yaml.age
+//
+// Decrypting them must read the same objects as their plaintext files.
+func TestFileReader_Read_DecryptVectors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		plaintext string
+		encrypted string
+	}{
+		{
+			name:      "SOPS YAML with typed values and comments",
+			plaintext: "typed.yaml",
+			encrypted: "typed.enc.yaml",
+		},
+		{
+			name:      "SOPS YAML encrypted by sops 3.9.0",
+			plaintext: "typed.yaml",
+			encrypted: "typed.sops-3.9.0.enc.yaml",
+		},
+		{
+			name:      "SOPS YAML with timestamps",
+			plaintext: "timestamp.yaml",
+			encrypted: "timestamp.enc.yaml",
+		},
+		{
+			name:      "SOPS YAML with multiple documents",
+			plaintext: "multidoc.yaml",
+			encrypted: "multidoc.enc.yaml",
+		},
+		{
+			name:      "SOPS JSON",
+			plaintext: "typed.json",
+			encrypted: "typed.enc.json",
+		},
+		{
+			name:      "SOPS YAML with encrypted_regex",
+			plaintext: "secret.yaml",
+			encrypted: "encrypted_regex.enc.yaml",
+		},
+		{
+			name:      "SOPS YAML with unencrypted_regex",
+			plaintext: "secret.yaml",
+			encrypted: "unencrypted_regex.enc.yaml",
+		},
+		{
+			name:      "SOPS YAML with encrypted_suffix",
+			plaintext: "suffix.yaml",
+			encrypted: "encrypted_suffix.enc.yaml",
+		},
+		{
+			name:      "SOPS YAML with mac_only_encrypted",
+			plaintext: "secret.yaml",
+			encrypted: "mac_only_encrypted.enc.yaml",
+		},
+		{
+			name:      "age binary YAML",
+			plaintext: "configmap.yaml",
+			encrypted: "configmap.yaml.age",
+		},
+		{
+			name:      "age armored YAML",
+			plaintext: "configmap.yaml",
+			encrypted: "configmap.armored.yaml.age",
+		},
+	}
+
+	identities, err := reader.ParseAgeIdentities(testAgeKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []ft.TestDirOpt
+			for _, name := range []string{tc.plaintext, tc.encrypted} {
+				contents, err := ioutil.ReadFile(filepath.Join("testdata", "sops", name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts = append(opts, ft.FileContents(name, string(contents)))
+			}
+			dir := ft.NewTestDir(t, opts...)
+			r := reader.File{AgeIdentities: identities}
+
+			want, errs := r.Read(dir.FilePaths(tc.plaintext))
+			if errs != nil {
+				t.Fatalf("got Read(%s) = %v, want nil", tc.plaintext, errs)
+			}
+			got, errs := r.Read(dir.FilePaths(tc.encrypted))
+			if errs != nil {
+				t.Fatalf("got Read(%s) = %v, want nil", tc.encrypted, errs)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d objects, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if diff := cmp.Diff(want[i].Object, got[i].Object); diff != "" {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+}
+
+func TestFileReader_Read_DecryptError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		key          string
+		fileName     string
+		fileContents string
+	}{
+		{
+			name:         "SOPS without the recipient key",
+			key:          otherAgeKey,
+			fileName:     "typed.yaml",
+			fileContents: sopsTypedYAML,
+		},
+		{
+			name:         "age without the recipient key",
+			key:          otherAgeKey,
+			fileName:     "secret.yaml.age",
+			fileContents: ageArmoredYAML,
+		},
+		{
+			name:         "SOPS with a modified value",
+			key:          testAgeKey,
+			fileName:     "typed.yaml",
+			fileContents: sopsTamperedYAML,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			identities, err := reader.ParseAgeIdentities(tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := ft.NewTestDir(t, ft.FileContents(tc.fileName, tc.fileContents))
+			r := reader.File{AgeIdentities: identities}
+			_, errs := r.Read(dir.FilePaths(tc.fileName))
+
+			if errs == nil {
+				t.Fatal("got Read() = nil, want err")
+			}
+			if len(errs.Errors()) != 1 || errs.Errors()[0].Code() != reader.EncryptedFileErrorCode {
+				t.Fatalf("got Read() = %v, want a single %s error", errs, reader.EncryptedFileErrorCode)
+			}
+			if _, isPathError := errs.Errors()[0].(status.PathError); !isPathError {
+				t.Errorf("got Read() = %T, want PathError", errs.Errors()[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"kpt.dev/configsync/pkg/importer/id"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// EncryptedFileErrorCode is the error code for when a file in the source is
+// encrypted.
+const EncryptedFileErrorCode = "1071"
+
+var encryptedFileErrorBase = status.NewErrorBuilder(EncryptedFileErrorCode)
+
+// EncryptedFileError reports that a file is encrypted, but no key to decrypt
+// it is configured, so its content cannot be synced.
+func EncryptedFileError(path id.Path, format string) status.Error {
+	return encryptedFileErrorBase.
+		Sprintf("The file is encrypted with %s, but no decryption key is configured. "+
+			"To fix, set spec.decryption.secretRef on the RootSync or RepoSync, "+
+			"or remove the file from the sync directory or list it in a .configsyncignore file.", format).
+		BuildWithPaths(path)
+}
+
+// DecryptionError reports that a file encrypted with SOPS or age could not be
+// decrypted with the configured keys.
+func DecryptionError(path id.Path, format string, err error) status.Error {
+	return encryptedFileErrorBase.Wrap(err).
+		Sprintf("Failed to decrypt the file encrypted with %s. "+
+			"Check that the Secret referenced by spec.decryption.secretRef holds an age identity of the file recipients, "+
+			"and that the file was not modified after it was encrypted", format).
+		BuildWithPaths(path)
+}
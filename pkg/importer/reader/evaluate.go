@@ -0,0 +1,265 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/google/go-jsonnet"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// jsonnetExtension is the extension of the Jsonnet files which are
+	// evaluated. Jsonnet libraries, ending in .libsonnet, are only imported.
+	jsonnetExtension = ".jsonnet"
+	// cueExtension is the extension of the files of CUE packages.
+	cueExtension = ".cue"
+
+	// clusterNameExtVar is the Jsonnet external variable set to the name of
+	// the cluster.
+	clusterNameExtVar = "clusterName"
+)
+
+const evaluatedValueError = "must evaluate to a Kubernetes object, a list of objects, or an object whose fields are objects"
+
+// IsEvaluated returns true if the objects read from the file at path are the
+// result of evaluating a Jsonnet or CUE program, which may depend on other
+// files.
+func IsEvaluated(path string) bool {
+	switch filepath.Ext(path) {
+	case jsonnetExtension, cueExtension:
+		return true
+	default:
+		return false
+	}
+}
+
+// evaluateJsonnet evaluates the Jsonnet file at path into unstructured objects.
+// The library directories, relative to rootDir, are searched for imports,
+// which may not load files outside rootDir.
+func (r *File) evaluateJsonnet(rootDir, path string) ([]*unstructured.Unstructured, error) {
+	root, err := filepath.EvalSymlinks(rootDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve the source directory")
+	}
+	var libPaths []string
+	for _, lib := range r.JsonnetLibPaths {
+		libPaths = append(libPaths, filepath.Join(rootDir, filepath.FromSlash(lib)))
+	}
+	vm := jsonnet.MakeVM()
+	vm.Importer(&rootedImporter{
+		rootDir: root,
+		jPaths:  libPaths,
+		cache:   make(map[string]jsonnet.Contents),
+	})
+	vm.ExtVar(clusterNameExtVar, r.ClusterName)
+	for k, v := range r.JsonnetExtVars {
+		vm.ExtVar(k, v)
+	}
+	out, err := vm.EvaluateFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate the Jsonnet file")
+	}
+	return parseEvaluatedJSON([]byte(out))
+}
+
+// rootedImporter is a Jsonnet importer which rejects the imports resolved
+// outside of the root directory, so that a Jsonnet file cannot read the files
+// of the reconciler container. Imports are searched like the
+// jsonnet.FileImporter does, in the directory of the importing file and then
+// in the library paths from the last to the first, but their symlinks are
+// resolved and checked before the files are read.
+type rootedImporter struct {
+	// rootDir is the root directory, with its symlinks resolved.
+	rootDir string
+	jPaths  []string
+	// cache are the contents of the imported files by resolved path, so that
+	// a file imported twice has the same contents.
+	cache map[string]jsonnet.Contents
+}
+
+// Import implements jsonnet.Importer.
+func (i *rootedImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	dirs := []string{""}
+	if importedFrom != "" {
+		dirs[0] = filepath.Dir(importedFrom)
+	}
+	for j := len(i.jPaths) - 1; j >= 0; j-- {
+		dirs = append(dirs, i.jPaths[j])
+	}
+	for _, dir := range dirs {
+		foundAt := importedPath
+		if !filepath.IsAbs(foundAt) {
+			foundAt = filepath.Join(dir, foundAt)
+		}
+		resolved, err := filepath.EvalSymlinks(foundAt)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return jsonnet.Contents{}, "", err
+		}
+		rel, err := filepath.Rel(i.rootDir, resolved)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return jsonnet.Contents{}, "", errors.Errorf("import %q is outside of the source directory", importedPath)
+		}
+		contents, found := i.cache[resolved]
+		if !found {
+			b, err := ioutil.ReadFile(resolved)
+			if err != nil {
+				return jsonnet.Contents{}, "", err
+			}
+			contents = jsonnet.MakeContents(string(b))
+			i.cache[resolved] = contents
+		}
+		return contents, foundAt, nil
+	}
+	return jsonnet.Contents{}, "", errors.Errorf("couldn't open import %q: no match locally or in the Jsonnet library paths", importedPath)
+}
+
+// evaluateCUE evaluates the CUE package made of the files in dir into
+// unstructured objects, and returns the path of the file declaring each of
+// them.
+//
+// Only the imports of the CUE standard library are supported.
+func evaluateCUE(dir string, files []string) ([]*unstructured.Unstructured, []string, error) {
+	inst := build.NewContext().NewInstance(dir, nil)
+	for _, f := range files {
+		contents, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := inst.AddFile(f, contents); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse the CUE file")
+		}
+	}
+	value := cuecontext.New().BuildInstance(inst)
+	if err := value.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to evaluate the CUE package")
+	}
+
+	var values []cue.Value
+	switch value.Kind() {
+	case cue.NullKind:
+		return nil, nil, nil
+	case cue.ListKind:
+		iter, err := value.List()
+		if err != nil {
+			return nil, nil, err
+		}
+		for iter.Next() {
+			values = append(values, iter.Value())
+		}
+	case cue.StructKind:
+		if value.LookupPath(cue.ParsePath("kind")).Exists() {
+			values = []cue.Value{value}
+			break
+		}
+		iter, err := value.Fields()
+		if err != nil {
+			return nil, nil, err
+		}
+		fields := make(map[string]cue.Value)
+		var labels []string
+		for iter.Next() {
+			label := iter.Selector().String()
+			fields[label] = iter.Value()
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			values = append(values, fields[label])
+		}
+	default:
+		return nil, nil, errors.New(evaluatedValueError)
+	}
+
+	var result []*unstructured.Unstructured
+	var paths []string
+	for _, v := range values {
+		out, err := v.MarshalJSON()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to export the CUE value")
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(out, &m); err != nil || m == nil {
+			return nil, nil, errors.New(evaluatedValueError)
+		}
+		u := &unstructured.Unstructured{Object: m}
+		if len(filterLocalConfigUnstructured([]*unstructured.Unstructured{u})) == 0 {
+			continue
+		}
+		// The object is attributed to the file declaring its first conjunct.
+		path := v.Pos().Filename()
+		if path == "" {
+			path = files[0]
+		}
+		result = append(result, u)
+		paths = append(paths, path)
+	}
+	return result, paths, nil
+}
+
+// parseEvaluatedJSON converts the JSON value returned by a Jsonnet or CUE
+// evaluation into unstructured objects.
+func parseEvaluatedJSON(contents []byte) ([]*unstructured.Unstructured, error) {
+	var value interface{}
+	if err := json.Unmarshal(contents, &value); err != nil {
+		return nil, errors.Wrap(err, "unable to decode the evaluated value")
+	}
+
+	var objs []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		objs = v
+	case map[string]interface{}:
+		if _, found := v["kind"]; found {
+			objs = []interface{}{v}
+			break
+		}
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			objs = append(objs, v[k])
+		}
+	default:
+		return nil, errors.New(evaluatedValueError)
+	}
+
+	var result []*unstructured.Unstructured
+	for _, o := range objs {
+		m, ok := o.(map[string]interface{})
+		if !ok {
+			return nil, errors.New(evaluatedValueError)
+		}
+		result = append(result, &unstructured.Unstructured{Object: m})
+	}
+	return filterLocalConfigUnstructured(result), nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ft "kpt.dev/configsync/pkg/importer/filesystem/filesystemtest"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/status"
+)
+
+const configMapJsonnet = `{apiVersion: "v1", kind: "ConfigMap", metadata: {name: "%s", namespace: "foo"}}`
+
+func configMap(name string) string {
+	return strings.ReplaceAll(configMapJsonnet, "%s", name)
+}
+
+func TestFileReader_Read_Jsonnet(t *testing.T) {
+	testCases := []struct {
+		name      string
+		contents  string
+		lib       string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "single object",
+			contents:  configMap("a"),
+			wantNames: []string{"a"},
+		},
+		{
+			name:      "list of objects",
+			contents:  "[" + configMap("a") + "," + configMap("b") + "]",
+			wantNames: []string{"a", "b"},
+		},
+		{
+			name:      "object of objects",
+			contents:  `{second: ` + configMap("b") + `, first: ` + configMap("a") + "}",
+			wantNames: []string{"a", "b"},
+		},
+		{
+			name:      "external variables",
+			contents:  configMap("a") + ` + {metadata+: {name: std.extVar("clusterName") + "-" + std.extVar("env")}}`,
+			wantNames: []string{"prod-test"},
+		},
+		{
+			name:      "library import",
+			contents:  `local cm = import "cm.libsonnet"; [cm]`,
+			lib:       configMap("lib"),
+			wantNames: []string{"lib"},
+		},
+		{
+			name:     "not an object",
+			contents: `"hello"`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid Jsonnet",
+			contents: `{`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := ft.NewTestDir(t,
+				ft.FileContents("main.jsonnet", tc.contents),
+				ft.FileContents("lib/cm.libsonnet", tc.lib))
+			r := reader.File{
+				ClusterName:     "prod",
+				JsonnetLibPaths: []string{"lib"},
+				JsonnetExtVars:  map[string]string{"env": "test"},
+			}
+
+			objs, err := r.Read(dir.FilePaths("main.jsonnet"))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("got Read() = nil, want err")
+				}
+				if _, isPathError := err.Errors()[0].(status.PathError); !isPathError {
+					t.Errorf("got Read() = %T, want PathError", err.Errors()[0])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got Read() = %v, want nil", err)
+			}
+			var gotNames []string
+			for _, o := range objs {
+				gotNames = append(gotNames, o.GetName())
+				if o.SlashPath() != "main.jsonnet" {
+					t.Errorf("got object path %q, want main.jsonnet", o.SlashPath())
+				}
+			}
+			if strings.Join(gotNames, ",") != strings.Join(tc.wantNames, ",") {
+				t.Errorf("got objects %v, want %v", gotNames, tc.wantNames)
+			}
+		})
+	}
+}
+
+func TestFileReader_Read_JsonnetImportOutsideOfSourceDirectory(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "cm.libsonnet"), []byte(configMap("a")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := ft.NewTestDir(t,
+		ft.FileContents("main.jsonnet", `import "`+filepath.Join(outside, "cm.libsonnet")+`"`))
+	r := reader.File{}
+
+	_, err := r.Read(dir.FilePaths("main.jsonnet"))
+	if err == nil {
+		t.Fatal("got Read() = nil, want err")
+	}
+	if _, isPathError := err.Errors()[0].(status.PathError); !isPathError {
+		t.Errorf("got Read() = %T, want PathError", err.Errors()[0])
+	}
+}
+
+func TestFileReader_Read_JsonnetImportSymlink(t *testing.T) {
+	testCases := []struct {
+		name string
+		// link and target are the symlink, relative to the source directory,
+		// and its target, relative to the source directory or to a directory
+		// outside of it.
+		link    string
+		target  string
+		outside bool
+		wantErr bool
+	}{
+		{
+			name:   "file within the source directory",
+			link:   "cm.libsonnet",
+			target: "real/cm.libsonnet",
+		},
+		{
+			name:    "file outside of the source directory",
+			link:    "cm.libsonnet",
+			target:  "cm.libsonnet",
+			outside: true,
+			wantErr: true,
+		},
+		{
+			name:    "library directory outside of the source directory",
+			link:    "lib",
+			target:  ".",
+			outside: true,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outside := t.TempDir()
+			if err := os.WriteFile(filepath.Join(outside, "cm.libsonnet"), []byte(configMap("outside")), 0644); err != nil {
+				t.Fatal(err)
+			}
+			dir := ft.NewTestDir(t,
+				ft.FileContents("main.jsonnet", `import "cm.libsonnet"`),
+				ft.FileContents("real/cm.libsonnet", configMap("a")))
+			root := dir.Root().OSPath()
+			target := filepath.Join(root, filepath.FromSlash(tc.target))
+			if tc.outside {
+				target = filepath.Join(outside, filepath.FromSlash(tc.target))
+			}
+			if err := os.Symlink(target, filepath.Join(root, tc.link)); err != nil {
+				t.Fatal(err)
+			}
+			r := reader.File{JsonnetLibPaths: []string{"lib"}}
+
+			objs, err := r.Read(dir.FilePaths("main.jsonnet"))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("got Read() = %v, want err", objs)
+				}
+				if !strings.Contains(err.Error(), "outside of the source directory") {
+					t.Errorf("got Read() = %v, want an import outside of the source directory error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got Read() = %v, want nil", err)
+			}
+			if len(objs) != 1 || objs[0].GetName() != "a" {
+				t.Errorf("got objects %v, want the ConfigMap a", objs)
+			}
+		})
+	}
+}
+
+func TestFileReader_Read_CUEPackage(t *testing.T) {
+	dir := ft.NewTestDir(t,
+		ft.FileContents("app/a.cue", `package app
+
+#ConfigMap: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: {namespace: "foo", ...}
+}
+
+a: #ConfigMap & {metadata: name: "a"}
+`),
+		ft.FileContents("app/b.cue", `package app
+
+b: #ConfigMap & {metadata: name: "b"}
+`))
+	r := reader.File{}
+
+	objs, err := r.Read(dir.FilePaths("app/a.cue", "app/b.cue"))
+	if err != nil {
+		t.Fatalf("got Read() = %v, want nil", err)
+	}
+	var got []string
+	for _, o := range objs {
+		got = append(got, o.GetName()+"@"+o.SlashPath())
+	}
+	if want := "a@app/a.cue,b@app/b.cue"; strings.Join(got, ",") != want {
+		t.Errorf("got Read() = %v, want %s", got, want)
+	}
+}
+
+func TestFileReader_Read_InvalidCUE(t *testing.T) {
+	dir := ft.NewTestDir(t,
+		ft.FileContents("app/a.cue", "package app\n\na: 1\n"),
+		ft.FileContents("app/b.cue", "package app\n\na: 2\n"))
+	r := reader.File{}
+
+	_, err := r.Read(dir.FilePaths("app/a.cue", "app/b.cue"))
+	if err == nil {
+		t.Fatal("got Read() = nil, want err")
+	}
+	if _, isPathError := err.Errors()[0].(status.PathError); !isPathError {
+		t.Errorf("got Read() = %T, want PathError", err.Errors()[0])
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -53,14 +54,42 @@ type FilePaths struct {
 }
 
 // File reads FileObjects from a filesystem.
-type File struct{}
+//
+// Jsonnet files are evaluated in-process, and the files of each CUE package
+// are evaluated together. The SOPS encrypted
+// documents and the age encrypted files are decrypted in memory, so their
+// plaintext is never written to disk.
+type File struct {
+	// ClusterName is the name of the cluster, passed to Jsonnet files as the
+	// clusterName external variable.
+	ClusterName string
+	// JsonnetLibPaths are the slash-separated paths of the directories,
+	// relative to the root directory, which are searched for Jsonnet imports.
+	JsonnetLibPaths []string
+	// JsonnetExtVars are the external variables passed to Jsonnet files.
+	JsonnetExtVars map[string]string
+	// AgeIdentities decrypt the SOPS encrypted documents and the age encrypted
+	// files. The encrypted files are rejected if it is empty.
+	AgeIdentities []age.Identity
+}
 
 var _ Reader = &File{}
 
 func (r *File) Read(filePaths FilePaths) ([]ast.FileObject, status.MultiError) {
 	var objs []ast.FileObject
 	var errs status.MultiError
+	// cuePackages maps the directory of each CUE package to its files.
+	cuePackages := make(map[string][]cmpath.Absolute)
+	var cueDirs []string
 	for _, f := range filePaths.Files {
+		if filepath.Ext(f.OSPath()) == cueExtension {
+			dir := filepath.Dir(f.OSPath())
+			if _, found := cuePackages[dir]; !found {
+				cueDirs = append(cueDirs, dir)
+			}
+			cuePackages[dir] = append(cuePackages[dir], f)
+			continue
+		}
 		newObjs, err := r.read(filePaths.RootDir, filePaths.PolicyDir, f)
 		if err != nil {
 			errs = status.Append(errs, err)
@@ -68,6 +97,14 @@ func (r *File) Read(filePaths FilePaths) ([]ast.FileObject, status.MultiError) {
 		}
 		objs = append(objs, newObjs...)
 	}
+	for _, dir := range cueDirs {
+		newObjs, err := r.readCUEPackage(filePaths.RootDir, filePaths.PolicyDir, cuePackages[dir])
+		if err != nil {
+			errs = status.Append(errs, err)
+			continue
+		}
+		objs = append(objs, newObjs...)
+	}
 	if errs != nil {
 		return nil, errs
 	}
@@ -84,21 +121,64 @@ func (r *File) read(rootDir cmpath.Absolute, policyDir cmpath.Relative, file cmp
 		}
 	}
 
-	unstructureds, err := parseFile(file.OSPath())
+	if filepath.Ext(file.OSPath()) == ageFileExtension {
+		return r.readAgeFile(rootDir, policyDir, file)
+	}
+
+	var unstructureds []*unstructured.Unstructured
+	var err error
+	if filepath.Ext(file.OSPath()) == jsonnetExtension {
+		unstructureds, err = r.evaluateJsonnet(rootDir.OSPath(), file.OSPath())
+	} else if contents, isSOPS := readSOPSContents(file.OSPath()); isSOPS {
+		return r.readSOPSFile(rootDir, policyDir, file, contents)
+	} else {
+		unstructureds, err = parseFile(file.OSPath())
+	}
 	if err != nil {
 		return nil, status.PathWrapError(err, file.OSPath())
 	}
+	return toAllFileObjects(unstructureds, rootDir, policyDir, file)
+}
 
+// readCUEPackage evaluates the files of a CUE package. Each object is
+// attributed to the file of the package declaring it.
+func (r *File) readCUEPackage(rootDir cmpath.Absolute, policyDir cmpath.Relative, files []cmpath.Absolute) ([]ast.FileObject, status.MultiError) {
+	var paths []string
+	byPath := make(map[string]cmpath.Absolute)
+	for _, f := range files {
+		paths = append(paths, f.OSPath())
+		byPath[f.OSPath()] = f
+	}
+	unstructureds, objPaths, err := evaluateCUE(filepath.Dir(paths[0]), paths)
+	if err != nil {
+		return nil, status.PathWrapError(err, paths...)
+	}
 	var fileObjects []ast.FileObject
 	var errs status.MultiError
-	for _, u := range unstructureds {
+	for i, u := range unstructureds {
+		file, found := byPath[objPaths[i]]
+		if !found {
+			file = files[0]
+		}
 		newFileObjects, err := toFileObjects(u, rootDir, policyDir, file)
 		if err != nil {
 			errs = status.Append(errs, err)
 		}
 		fileObjects = append(fileObjects, newFileObjects...)
 	}
+	return fileObjects, errs
+}
 
+func toAllFileObjects(unstructureds []*unstructured.Unstructured, rootDir cmpath.Absolute, policyDir cmpath.Relative, file cmpath.Absolute) ([]ast.FileObject, status.MultiError) {
+	var fileObjects []ast.FileObject
+	var errs status.MultiError
+	for _, u := range unstructureds {
+		newFileObjects, err := toFileObjects(u, rootDir, policyDir, file)
+		if err != nil {
+			errs = status.Append(errs, err)
+		}
+		fileObjects = append(fileObjects, newFileObjects...)
+	}
 	return fileObjects, errs
 }
 
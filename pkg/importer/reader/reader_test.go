@@ -228,3 +228,52 @@ workflow:
 		}
 	}
 }
+
+func TestFileReader_Read_EncryptedFile(t *testing.T) {
+	testCases := []struct {
+		name         string
+		fileName     string
+		fileContents string
+	}{
+		{
+			name:     "SOPS document",
+			fileName: "secret.yaml",
+			fileContents: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: foo
+data:
+  password: ENC[AES256_GCM,data:Tr7o=,iv:1=,tag:2=,type:str]
+sops:
+  mac: ENC[AES256_GCM,data:3=,iv:4=,tag:5=,type:str]
+  version: 3.7.3
+`,
+		},
+		{
+			name:         "age file",
+			fileName:     "secret.yaml.age",
+			fileContents: "age-encryption.org/v1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := ft.NewTestDir(t, ft.FileContents(tc.fileName, tc.fileContents))
+			r := reader.File{}
+			_, err := r.Read(dir.FilePaths(tc.fileName))
+
+			if err == nil {
+				t.Fatal("got Read() = nil, want err")
+			}
+			errs := err.Errors()
+			if len(errs) != 1 || errs[0].Code() != reader.EncryptedFileErrorCode {
+				t.Fatalf("got Read() = %v, want a single %s error", err, reader.EncryptedFileErrorCode)
+			}
+			if _, isPathError := errs[0].(status.PathError); !isPathError {
+				t.Errorf("got Read() = %T, want PathError", errs[0])
+			}
+		})
+	}
+}
@@ -224,6 +224,11 @@ func RepoSyncV1Beta1() schema.GroupVersionKind {
 	return configsyncv1beta1.SchemeGroupVersion.WithKind(configsync.RepoSyncKind)
 }
 
+// RepoSyncTemplate returns the canonical RepoSyncTemplate GroupVersionKind.
+func RepoSyncTemplate() schema.GroupVersionKind {
+	return configsyncv1beta1.SchemeGroupVersion.WithKind(configsync.RepoSyncTemplateKind)
+}
+
 // RootSyncV1Alpha1 returns the canonical RootSync GroupVersionKind.
 func RootSyncV1Alpha1() schema.GroupVersionKind {
 	return v1alpha1.SchemeGroupVersion.WithKind(configsync.RootSyncKind)
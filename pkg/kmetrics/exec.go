@@ -104,6 +104,21 @@ func RunKustomizeBuild(ctx context.Context, sendMetrics bool, inputDir string, f
 	return out, buildErr
 }
 
+// RecordKustomizeBuild records the measurements about a `kustomize build` of
+// inputDir which ran in-process: how many resources it rendered, how long it
+// took, and the field usage of the kustomization stack.
+func RecordKustomizeBuild(ctx context.Context, inputDir string, resourceCount int, executionTime time.Duration) {
+	RecordKustomizeResourceCount(ctx, resourceCount)
+	RecordKustomizeExecutionTime(ctx, float64(executionTime.Nanoseconds()))
+	kt, err := readKustomizeFile(inputDir)
+	if kt != nil && err == nil {
+		fieldMetrics, fieldErr := kustomizeFieldUsage(kt, inputDir)
+		if fieldErr == nil && fieldMetrics != nil {
+			RecordKustomizeFieldCountData(ctx, fieldMetrics)
+		}
+	}
+}
+
 // runKustomizeBuild will run `kustomize build` and also record measurements
 // about kustomize usage via OpenCensus. This assumes that there is already an OC
 // agent that is sending to a collector.
@@ -80,4 +80,10 @@ var (
 		"kustomize_build_latency",
 		"Kustomize build latency",
 		stats.UnitMilliseconds)
+
+	// RenderCacheLookups is the number of lookups in the render cache
+	RenderCacheLookups = stats.Int64(
+		"render_cache_lookup_count",
+		"The number of lookups of rendered configs in the render cache",
+		stats.UnitDimensionless)
 )
@@ -33,6 +33,7 @@ var (
 	keyBaseCount, _              = tag.NewKey("base_source")
 	keyPatchCount, _             = tag.NewKey("patch_field")
 	keyTopTierCount, _           = tag.NewKey("top_tier_field")
+	keyRenderCacheResult, _      = tag.NewKey("result")
 )
 
 // RecordKustomizeFieldCountData records all data relevant to the kustomization's field counts
@@ -66,6 +67,16 @@ func RecordKustomizeExecutionTime(ctx context.Context, executionTime float64) {
 	record(ctx, KustomizeExecutionTime.M(executionTime))
 }
 
+// RecordRenderCacheLookup produces measurement for RenderCacheLookups view
+func RecordRenderCacheLookup(ctx context.Context, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	tagCtx, _ := tag.New(ctx, tag.Upsert(keyRenderCacheResult, result))
+	record(tagCtx, RenderCacheLookups.M(1))
+}
+
 // recordKustomizeFieldCount produces measurement for KustomizeFieldCount view
 func recordKustomizeFieldCount(ctx context.Context, fieldCount map[string]int) {
 	for field, count := range fieldCount {
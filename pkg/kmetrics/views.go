@@ -109,6 +109,15 @@ var (
 		Description: "Execution time of `kustomize build`",
 		Aggregation: view.Distribution(0, 10, 20, 40, 80, 160, 320, 640, 1280, 2560, 5120, 10240),
 	}
+
+	// RenderCacheLookupsView is the number of render cache hits and misses
+	RenderCacheLookupsView = &view.View{
+		Name:        RenderCacheLookups.Name(),
+		Measure:     RenderCacheLookups,
+		Description: "The number of lookups of rendered configs in the render cache, by hit or miss",
+		TagKeys:     []tag.Key{keyRenderCacheResult},
+		Aggregation: view.Count(),
+	}
 )
 
 // RegisterKustomizeMetricsViews registers the views so that recorded metrics can be exported. .
@@ -124,5 +133,6 @@ func RegisterKustomizeMetricsViews() error {
 		KustomizeTopTierMetricsView,
 		KustomizeResourceCountView,
 		KustomizeExecutionTimeView,
+		RenderCacheLookupsView,
 	)
 }
@@ -137,6 +137,76 @@ const (
 	// RootSync/RepoSync objects to indicate what do do with the managed
 	// resources when the RootSync/RepoSync object is deleted.
 	DeletionPropagationPolicyAnnotationKey = configsync.ConfigSyncPrefix + "deletion-propagation-policy"
+
+	// IgnoreDifferencesAnnotationKey is the annotation key listing fields of a
+	// managed resource which are owned by another controller, as a comma
+	// separated list of JSON pointers or JSONPath expressions. Config Sync
+	// neither applies nor reverts drift on these fields.
+	// This annotation is set by Config Sync users on a managed resource.
+	IgnoreDifferencesAnnotationKey = configsync.ConfigSyncPrefix + "ignore-differences"
+
+	// SecretFromAnnotationKey is the annotation key referencing the data of a
+	// managed Secret held outside of the source, as either
+	// `secret:[<namespace>/]<name>` or `file:<path>` of a file or directory
+	// of the spec.secretVolume of the RootSync. The data is resolved just
+	// before apply.
+	// This annotation is set by Config Sync users on a managed Secret.
+	SecretFromAnnotationKey = configsync.ConfigSyncPrefix + "secret-from"
+
+	// SubstitutedFieldsAnnotationKey is the annotation key listing the fields
+	// of a managed resource whose values had cluster variables substituted, as
+	// a comma separated list of field paths.
+	// This annotation is set by Config Sync on a managed resource.
+	SubstitutedFieldsAnnotationKey = configsync.ConfigSyncPrefix + "substituted-fields"
+
+	// PruneBudgetAckAnnotationKey is the annotation key set on RootSync/RepoSync
+	// objects to acknowledge that a commit may prune more objects than allowed
+	// by spec.pruneBudget. The value must be the hash of that commit.
+	PruneBudgetAckAnnotationKey = configsync.ConfigSyncPrefix + "prune-budget-ack"
+
+	// ChangeSummaryAnnotationKey is the annotation key set on ResourceGroup
+	// objects by the reconciler. The value is the JSON encoded summary of the
+	// changes to the declared resources made by the commit being synced.
+	ChangeSummaryAnnotationKey = configsync.ConfigSyncPrefix + "change-summary"
+
+	// DeletionPolicyAnnotationKey is the annotation key set on managed
+	// resources to override whether deletion propagation deletes or orphans
+	// the resource when its RootSync/RepoSync object is deleted.
+	// This annotation is set by Config Sync users on a managed resource.
+	DeletionPolicyAnnotationKey = configsync.ConfigSyncPrefix + "deletion-policy"
+
+	// AdoptionAnnotationKey is the annotation key set on managed resources to
+	// override the adoption policy of their RootSync/RepoSync object, which
+	// decides whether the resource is adopted if it already exists.
+	// This annotation is set by Config Sync users on a managed resource.
+	AdoptionAnnotationKey = configsync.ConfigSyncPrefix + "adoption"
+
+	// DelegateClusterRoleAnnotationKey is the annotation key set on RepoSync
+	// objects declared in a hierarchical root repository to delegate the
+	// namespaces below their directory. A RoleBinding granting the ClusterRole
+	// to the namespace reconciler is declared in each of these namespaces.
+	// This annotation is set by Config Sync users on a RepoSync.
+	DelegateClusterRoleAnnotationKey = configsync.ConfigSyncPrefix + "delegate-cluster-role"
+
+	// DelegateSecretFromAnnotationKey is the annotation key set on RepoSync
+	// objects declared in a hierarchical root repository to copy their
+	// authentication Secret into each delegated namespace. The value is a
+	// reference in the format of the secret-from annotation.
+	// This annotation is set by Config Sync users on a RepoSync.
+	DelegateSecretFromAnnotationKey = configsync.ConfigSyncPrefix + "delegate-secret-from"
+
+	// DelegatedByAnnotationKey is the annotation key set on the objects
+	// declared for a RepoSync delegating namespaces. The value is the name of
+	// the RepoSync.
+	// This annotation is set by Config Sync on a managed resource.
+	DelegatedByAnnotationKey = configsync.ConfigSyncPrefix + "delegated-by"
+
+	// AllowedRepoSyncsAnnotationKey is the annotation key set on Namespaces to
+	// allow the RepoSyncs of other namespaces to sync objects to them, with
+	// spec.namespaces. The value is a comma-separated list of RepoSyncs, in the
+	// format <namespace>/<name>.
+	// This annotation is set by Config Sync users on a Namespace.
+	AllowedRepoSyncsAnnotationKey = configsync.ConfigSyncPrefix + "allowed-repo-syncs"
 )
 
 // Lifecycle annotations
@@ -219,4 +289,59 @@ const (
 	// affecting the managed resources.
 	// This is the default behavior if the annotation is not specified.
 	DeletionPropagationPolicyOrphan = DeletionPropagationPolicy("Orphan")
+
+	// DeletionPropagationPolicyDryRun indicates that the managed resources
+	// which would be deleted or orphaned with the Foreground policy should be
+	// listed in the status of the RootSync/RepoSync object, without deleting
+	// anything.
+	// Like Orphan, this will NOT block deletion of the RootSync/RepoSync.
+	DeletionPropagationPolicyDryRun = DeletionPropagationPolicy("DryRun")
+)
+
+// DeletionPolicy is the type used to identify value enums to use with the
+// deletion-policy annotation.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyOrphan indicates that deletion propagation should orphan
+	// the managed resource, even if it would otherwise be deleted.
+	DeletionPolicyOrphan = DeletionPolicy("orphan")
+
+	// DeletionPolicyDelete indicates that deletion propagation should delete
+	// the managed resource, even if its kind is orphaned by the RootSync/RepoSync.
+	DeletionPolicyDelete = DeletionPolicy("delete")
+)
+
+// AdoptionPolicy is the type used to identify value enums to use with the
+// adoption annotation and the spec.adoption field of RootSync/RepoSync objects.
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyNever indicates that a resource which already exists, and
+	// is not managed by the RootSync/RepoSync, is not applied.
+	AdoptionPolicyNever = AdoptionPolicy("never")
+
+	// AdoptionPolicyIfUnmanaged indicates that a resource which already exists
+	// is adopted, unless it is managed by Helm, another RootSync/RepoSync, or
+	// another inventory.
+	AdoptionPolicyIfUnmanaged = AdoptionPolicy("ifUnmanaged")
+
+	// AdoptionPolicyAlways indicates that a resource which already exists is
+	// adopted, and the ownership metadata of its previous manager is removed.
+	AdoptionPolicyAlways = AdoptionPolicy("always")
+)
+
+// Helm release ownership metadata, set by Helm on the resources of a release.
+const (
+	// HelmReleaseNameAnnotationKey is the annotation key for the name of the
+	// Helm release which owns a resource.
+	HelmReleaseNameAnnotationKey = "meta.helm.sh/release-name"
+
+	// HelmReleaseNamespaceAnnotationKey is the annotation key for the
+	// namespace of the Helm release which owns a resource.
+	HelmReleaseNamespaceAnnotationKey = "meta.helm.sh/release-namespace"
+
+	// HelmManagedByValue is the value of the app.kubernetes.io/managed-by
+	// label set by Helm.
+	HelmManagedByValue = "Helm"
 )
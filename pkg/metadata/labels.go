@@ -53,6 +53,12 @@ const (
 	// This is used to enable selecting pods by label, primarily for printing logs.
 	// Example: kubectl logs deployment/<deploy-name> <container-name> -n config-management-system
 	DeploymentNameLabel = configsync.ConfigSyncPrefix + "deployment-name"
+
+	// RepoSyncTemplateLabel indicates the name of the RepoSyncTemplate which
+	// created the object.
+	// This label is set by the reconciler-manager on the RepoSyncs, RoleBindings
+	// and Secrets created from a RepoSyncTemplate.
+	RepoSyncTemplateLabel = configsync.ConfigSyncPrefix + "repo-sync-template"
 )
 
 // DepthSuffix is a label suffix for hierarchical namespace depth.
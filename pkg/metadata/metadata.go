@@ -54,6 +54,12 @@ var sourceAnnotations = map[string]bool{
 	ResourceManagementKey:                  true,
 	LifecycleMutationAnnotation:            true,
 	DeletionPropagationPolicyAnnotationKey: true,
+	IgnoreDifferencesAnnotationKey:         true,
+	SecretFromAnnotationKey:                true,
+	DeletionPolicyAnnotationKey:            true,
+	AdoptionAnnotationKey:                  true,
+	DelegateClusterRoleAnnotationKey:       true,
+	DelegateSecretFromAnnotationKey:        true,
 }
 
 // IsSourceAnnotation returns true if the annotation is a ConfigSync source
@@ -62,6 +62,13 @@ var (
 		"The number of declared resources parsed from Git",
 		stats.UnitDimensionless)
 
+	// IgnoredFiles metric measures the number of files and directories
+	// excluded from the sync directory by .configsyncignore files.
+	IgnoredFiles = stats.Int64(
+		"ignored_files",
+		"The number of files and directories excluded by .configsyncignore files",
+		stats.UnitDimensionless)
+
 	// ApplyOperations metric measures the number of applier apply events.
 	ApplyOperations = stats.Int64(
 		"apply_operations",
@@ -103,4 +110,12 @@ var (
 		"internal_errors",
 		"The number of internal errors triggered by Config Sync",
 		stats.UnitDimensionless)
+
+	// AdmissionAuditEvents metric measures the number of admission requests
+	// which the admission webhook admitted in audit-only mode, but would have
+	// denied in enforcement mode.
+	AdmissionAuditEvents = stats.Int64(
+		"admission_audit_events",
+		"The number of admission requests modifying managed resources which were admitted in audit-only mode",
+		stats.UnitDimensionless)
 )
@@ -217,5 +217,9 @@ service:
     metrics/kubernetes:
       receivers: [opencensus]
       processors: [batch, filter/kubernetes, attributes/kubernetes, metricstransform/kubernetes, resourcedetection]
-      exporters: [googlecloud/kubernetes]`
+      exporters: [googlecloud/kubernetes]
+    traces/cloudtrace:
+      receivers: [opencensus]
+      processors: [batch, resourcedetection]
+      exporters: [googlecloud]`
 )
@@ -108,6 +108,12 @@ func RecordDeclaredResources(ctx context.Context, numResources int) {
 	record(ctx, measurement)
 }
 
+// RecordIgnoredFiles produces a measurement for the IgnoredFiles view.
+func RecordIgnoredFiles(ctx context.Context, numFiles int) {
+	measurement := IgnoredFiles.M(int64(numFiles))
+	record(ctx, measurement)
+}
+
 // RecordApplyOperation produces a measurement for the ApplyOperations view.
 func RecordApplyOperation(ctx context.Context, controller, operation, status string, gvk schema.GroupVersionKind) {
 	tagCtx, _ := tag.New(ctx,
@@ -177,3 +183,14 @@ func RecordInternalError(ctx context.Context, source string) {
 	measurement := InternalErrors.M(1)
 	record(tagCtx, measurement)
 }
+
+// RecordAdmissionAuditEvent produces measurements for the AdmissionAuditEvents view.
+func RecordAdmissionAuditEvent(ctx context.Context, user string, gvk schema.GroupVersionKind, operation string) {
+	tagCtx, _ := tag.New(ctx,
+		tag.Upsert(KeyUser, user),
+		tag.Upsert(KeyType, gvk.String()),
+		tag.Upsert(KeyOperation, operation),
+	)
+	measurement := AdmissionAuditEvents.M(1)
+	record(tagCtx, measurement)
+}
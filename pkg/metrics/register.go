@@ -47,6 +47,11 @@ func RegisterReconcilerManagerMetricsViews() error {
 	return view.Register(ReconcileDurationView)
 }
 
+// RegisterAdmissionWebhookMetricsViews registers the views so that recorded metrics can be exported in the admission webhook.
+func RegisterAdmissionWebhookMetricsViews() error {
+	return view.Register(AdmissionAuditEventsView)
+}
+
 // RegisterReconcilerMetricsViews registers the views so that recorded metrics can be exported in the reconcilers.
 func RegisterReconcilerMetricsViews() error {
 	return view.Register(
@@ -56,6 +61,7 @@ func RegisterReconcilerMetricsViews() error {
 		LastApplyTimestampView,
 		LastSyncTimestampView,
 		DeclaredResourcesView,
+		IgnoredFilesView,
 		ApplyOperationsView,
 		ApplyDurationView,
 		ResourceFightsView,
@@ -67,6 +67,9 @@ var (
 
 	// KeyResourceType groups metris by their resource types. Possible values: cpu, memory.
 	KeyResourceType, _ = tag.NewKey("resource")
+
+	// KeyUser groups metrics by the user who made an admission request.
+	KeyUser, _ = tag.NewKey("user")
 )
 
 // The following metric tag keys are available from the otel-collector
@@ -91,6 +91,14 @@ var (
 		Aggregation: view.LastValue(),
 	}
 
+	// IgnoredFilesView aggregates the IgnoredFiles metric measurements.
+	IgnoredFilesView = &view.View{
+		Name:        IgnoredFiles.Name(),
+		Measure:     IgnoredFiles,
+		Description: "The current number of files and directories excluded by .configsyncignore files",
+		Aggregation: view.LastValue(),
+	}
+
 	// ApplyOperationsView aggregates the ApplyOps metric measurements.
 	ApplyOperationsView = &view.View{
 		Name:        ApplyOperations.Name() + "_total",
@@ -151,4 +159,13 @@ var (
 		TagKeys:     []tag.Key{KeyInternalErrorSource},
 		Aggregation: view.Count(),
 	}
+
+	// AdmissionAuditEventsView aggregates the AdmissionAuditEvents metric measurements.
+	AdmissionAuditEventsView = &view.View{
+		Name:        AdmissionAuditEvents.Name() + "_total",
+		Measure:     AdmissionAuditEvents,
+		Description: "The total number of admission requests modifying managed resources which were admitted in audit-only mode",
+		TagKeys:     []tag.Key{KeyUser, KeyType, KeyOperation},
+		Aggregation: view.Count(),
+	}
 )
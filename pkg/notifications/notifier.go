@@ -0,0 +1,397 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notifications posts the results of the sync of a RootSync or
+// RepoSync as CloudEvents to the HTTP endpoints of spec.notifications, so
+// teams are told when a sync fails or a new commit lands without polling.
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+)
+
+const (
+	// SignatureHeader is the header of the HMAC-SHA256 signature of the body
+	// of a notification, like "sha256=<hex digest>".
+	SignatureHeader = "X-Config-Sync-Signature"
+	// ContentType is the content type of the CloudEvents in structured mode.
+	ContentType = "application/cloudevents+json"
+
+	// eventTypePrefix prefixes the CloudEvents types, like
+	// dev.kpt.configsync.sync.failed.
+	eventTypePrefix = "dev.kpt.configsync.sync."
+	// maxErrors is the maximum number of errors in a notification. The
+	// errorCount field counts all of them.
+	maxErrors = 20
+	// maxSyncedCommits is the number of synced commits remembered to detect
+	// rollbacks.
+	maxSyncedCommits = 20
+	// queueSize is the number of notifications waiting to be posted, after
+	// which the new notifications are dropped.
+	queueSize = 100
+	// maxAttempts is the number of times a notification is posted before it
+	// is dropped.
+	maxAttempts = 5
+	// requestTimeout is the timeout of a single post.
+	requestTimeout = 10 * time.Second
+)
+
+// eventTypes maps the sync results to the suffixes of their CloudEvents types.
+var eventTypes = map[string]string{
+	v1beta1.NotificationSyncSucceeded: "succeeded",
+	v1beta1.NotificationSyncFailed:    "failed",
+	v1beta1.NotificationStalled:       "stalled",
+	v1beta1.NotificationConflict:      "conflict",
+	v1beta1.NotificationRollback:      "rollback",
+}
+
+// resets lists the sync results which are notified again, even for the same
+// commit and errors, once a sync result is notified.
+var resets = map[string][]string{
+	v1beta1.NotificationSyncSucceeded: {v1beta1.NotificationSyncFailed, v1beta1.NotificationStalled, v1beta1.NotificationConflict},
+	v1beta1.NotificationSyncFailed:    {v1beta1.NotificationSyncSucceeded},
+	v1beta1.NotificationStalled:       {v1beta1.NotificationSyncSucceeded},
+}
+
+// Data is the data of the CloudEvents.
+type Data struct {
+	// Cluster is the name of the cluster, if it is known.
+	Cluster string `json:"cluster,omitempty"`
+	// Kind is either RootSync or RepoSync.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Commit is the commit of the sync result.
+	Commit string `json:"commit"`
+	// PreviousCommit is the commit synced before a rollback.
+	PreviousCommit string `json:"previousCommit,omitempty"`
+	// ObjectCount is the number of objects declared in the commit.
+	ObjectCount int `json:"objectCount,omitempty"`
+	// ErrorCount is the number of errors, of which at most 20 are listed in
+	// Errors.
+	ErrorCount int                       `json:"errorCount,omitempty"`
+	Errors     []v1beta1.ConfigSyncError `json:"errors,omitempty"`
+}
+
+// CloudEvent is a CloudEvent in the JSON format of the structured mode.
+type CloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject,omitempty"`
+	Time            string `json:"time"`
+	DataContentType string `json:"datacontenttype"`
+	Data            Data   `json:"data"`
+}
+
+// delivery is a notification waiting to be posted to an endpoint.
+type delivery struct {
+	url  string
+	body []byte
+}
+
+// Notifier posts the notifications of the sync results. The methods of a nil
+// Notifier do nothing, so the reconciler can run without notifications.
+type Notifier struct {
+	endpoints    []v1beta1.NotificationEndpoint
+	hmacKey      []byte
+	clusterName  string
+	syncKind     string
+	syncName     types.NamespacedName
+	client       *http.Client
+	queue        chan delivery
+	retryBackoff time.Duration
+
+	mux sync.Mutex
+	// lastSent maps the sync results to the commit and errors last notified,
+	// so repeated status updates are not notified again.
+	lastSent map[string]string
+	// syncedCommits are the commits most recently synced without errors,
+	// oldest first.
+	syncedCommits []string
+}
+
+// NewNotifier returns a Notifier posting to the endpoints of config, a JSON
+// encoded list of NotificationEndpoints, the results of the sync of the
+// RootSync or RepoSync syncKind/syncName. The notifications are signed with
+// hmacKey, unless it is empty. It returns nil if there are no endpoints.
+func NewNotifier(config, hmacKey, clusterName, syncKind string, syncName types.NamespacedName) (*Notifier, error) {
+	if config == "" {
+		return nil, nil
+	}
+	var endpoints []v1beta1.NotificationEndpoint
+	if err := json.Unmarshal([]byte(config), &endpoints); err != nil {
+		return nil, errors.Wrap(err, "invalid notification config")
+	}
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+	return &Notifier{
+		endpoints:    endpoints,
+		hmacKey:      []byte(hmacKey),
+		clusterName:  clusterName,
+		syncKind:     syncKind,
+		syncName:     syncName,
+		client:       &http.Client{Timeout: requestTimeout},
+		queue:        make(chan delivery, queueSize),
+		retryBackoff: time.Second,
+		lastSent:     make(map[string]string),
+	}, nil
+}
+
+// Run posts the notifications until the context is done.
+func (n *Notifier) Run(ctx context.Context) {
+	if n == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-n.queue:
+			n.post(ctx, d)
+		}
+	}
+}
+
+// SyncSucceeded notifies that the commit was synced without errors, and that
+// it was rolled back to if it was synced before a later commit.
+func (n *Notifier) SyncSucceeded(commit string, objectCount int) {
+	if n == nil {
+		return
+	}
+	n.notify(v1beta1.NotificationSyncSucceeded, Data{Commit: commit, ObjectCount: objectCount})
+
+	n.mux.Lock()
+	var previous string
+	if len(n.syncedCommits) > 0 {
+		previous = n.syncedCommits[len(n.syncedCommits)-1]
+	}
+	rollback := false
+	var commits []string
+	for _, c := range n.syncedCommits {
+		if c == commit {
+			rollback = commit != previous
+			continue
+		}
+		commits = append(commits, c)
+	}
+	commits = append(commits, commit)
+	if len(commits) > maxSyncedCommits {
+		commits = commits[len(commits)-maxSyncedCommits:]
+	}
+	n.syncedCommits = commits
+	n.mux.Unlock()
+
+	if rollback {
+		n.notify(v1beta1.NotificationRollback, Data{Commit: commit, PreviousCommit: previous, ObjectCount: objectCount})
+	}
+}
+
+// SyncFailed notifies that the sync of the commit failed with errs.
+func (n *Notifier) SyncFailed(commit string, errs status.MultiError, objectCount int) {
+	n.notifyErrors(v1beta1.NotificationSyncFailed, Data{Commit: commit, ObjectCount: objectCount}, errs)
+}
+
+// Stalled notifies that the commit cannot be synced because of the source or
+// rendering errors errs.
+func (n *Notifier) Stalled(commit string, errs status.MultiError) {
+	n.notifyErrors(v1beta1.NotificationStalled, Data{Commit: commit}, errs)
+}
+
+// Conflict notifies the management conflicts errs found while syncing the
+// commit.
+func (n *Notifier) Conflict(commit string, errs status.MultiError) {
+	n.notifyErrors(v1beta1.NotificationConflict, Data{Commit: commit}, errs)
+}
+
+func (n *Notifier) notifyErrors(result string, data Data, errs status.MultiError) {
+	if n == nil {
+		return
+	}
+	cses := status.ToCSE(errs)
+	data.ErrorCount = len(cses)
+	if len(cses) > maxErrors {
+		cses = cses[:maxErrors]
+	}
+	data.Errors = cses
+	n.notify(result, data)
+}
+
+// notify queues the notification of the sync result to the endpoints which
+// filter it, unless it was already notified for the same commit and errors.
+func (n *Notifier) notify(result string, data Data) {
+	if n.duplicate(result, data) {
+		klog.V(4).Infof("Skipping the %s notification of commit %s, which was already sent", result, data.Commit)
+		return
+	}
+
+	data.Cluster = n.clusterName
+	data.Kind = n.syncKind
+	data.Namespace = n.syncName.Namespace
+	data.Name = n.syncName.Name
+	event := CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              uuid.New().String(),
+		Source:          n.source(),
+		Type:            eventTypePrefix + eventTypes[result],
+		Subject:         data.Commit,
+		Time:            time.Now().UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		klog.Errorf("Failed to encode the %s notification: %v", result, err)
+		return
+	}
+
+	for _, e := range n.endpoints {
+		if !filters(e, result) {
+			continue
+		}
+		select {
+		case n.queue <- delivery{url: e.URL, body: body}:
+		default:
+			klog.Warningf("Dropping the %s notification to %s, as too many notifications are waiting", result, e.URL)
+		}
+	}
+}
+
+// duplicate returns true if the sync result was already notified for the same
+// commit and errors, and records it otherwise.
+func (n *Notifier) duplicate(result string, data Data) bool {
+	key := dedupKey(data)
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if n.lastSent[result] == key {
+		return true
+	}
+	n.lastSent[result] = key
+	for _, r := range resets[result] {
+		delete(n.lastSent, r)
+	}
+	return false
+}
+
+// dedupKey identifies a notification by its commit and errors.
+func dedupKey(data Data) string {
+	h := sha256.New()
+	for _, e := range data.Errors {
+		fmt.Fprintf(h, "%s\n%s\n", e.Code, e.ErrorMessage)
+	}
+	return fmt.Sprintf("%s/%s/%d/%x", data.Commit, data.PreviousCommit, data.ErrorCount, h.Sum(nil))
+}
+
+// source returns the CloudEvents source, the path of the RootSync or RepoSync.
+func (n *Notifier) source() string {
+	resource := "reposyncs"
+	if n.syncKind == configsync.RootSyncKind {
+		resource = "rootsyncs"
+	}
+	return fmt.Sprintf("/apis/%s/namespaces/%s/%s/%s",
+		v1beta1.SchemeGroupVersion, n.syncName.Namespace, resource, n.syncName.Name)
+}
+
+// filters returns true if the endpoint is notified of the sync result.
+func filters(e v1beta1.NotificationEndpoint, result string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, event := range e.Events {
+		if event == result {
+			return true
+		}
+	}
+	return false
+}
+
+// post posts the notification, retrying with an exponential backoff on
+// network errors, server errors and throttling.
+func (n *Notifier) post(ctx context.Context, d delivery) {
+	backoff := n.retryBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.send(ctx, d)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == maxAttempts {
+			klog.Warningf("Failed to post the notification to %s after %d attempt(s): %v", d.url, attempt, err)
+			return
+		}
+		klog.V(3).Infof("Retrying to post the notification to %s: %v", d.url, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send posts the notification once. It returns whether the post may be
+// retried if it failed.
+func (n *Notifier) send(ctx context.Context, d delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(d.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	if len(n.hmacKey) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.hmacKey, d.body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, errors.Errorf("unexpected status %s", resp.Status)
+}
+
+// Sign returns the value of the signature header of the body signed with key.
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature is the signature of the body signed with
+// key, for the receivers of the notifications.
+func Verify(key, body []byte, signature string) bool {
+	expected := Sign(key, body)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature)))
+}
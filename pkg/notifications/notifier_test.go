@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// receiver records the notifications posted to a test server, after failing
+// the first failures posts.
+type receiver struct {
+	mux       sync.Mutex
+	failures  int
+	events    []CloudEvent
+	verified  []bool
+	hmacKey   []byte
+	delivered chan struct{}
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	var event CloudEvent
+	if err := json.Unmarshal(body, &event); err != nil || req.Header.Get("Content-Type") != ContentType {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.events = append(r.events, event)
+	r.verified = append(r.verified, Verify(r.hmacKey, body, req.Header.Get(SignatureHeader)))
+	r.delivered <- struct{}{}
+}
+
+func (r *receiver) wait(t *testing.T, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		select {
+		case <-r.delivered:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d notifications, want %d", i, count)
+		}
+	}
+}
+
+func newTestNotifier(t *testing.T, endpoints []v1beta1.NotificationEndpoint, hmacKey string) *Notifier {
+	t.Helper()
+	config, err := json.Marshal(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := NewNotifier(string(config), hmacKey, "prod", configsync.RootSyncKind,
+		types.NamespacedName{Namespace: configsync.ControllerNamespace, Name: configsync.RootSyncName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.retryBackoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go n.Run(ctx)
+	return n
+}
+
+func TestNotifier_SyncResults(t *testing.T) {
+	r := &receiver{hmacKey: []byte("secret"), failures: 2, delivered: make(chan struct{}, 10)}
+	server := httptest.NewServer(r)
+	defer server.Close()
+	n := newTestNotifier(t, []v1beta1.NotificationEndpoint{{URL: server.URL}}, "secret")
+
+	errs := status.Append(nil, errors.New("apply failed"))
+	n.SyncFailed("abc123", errs, 3)
+	// Repeated status updates are not notified again.
+	n.SyncFailed("abc123", errs, 3)
+	n.SyncSucceeded("abc123", 3)
+	n.SyncSucceeded("abc123", 3)
+	r.wait(t, 2)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.events) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(r.events))
+	}
+	failed := r.events[0]
+	if failed.Type != "dev.kpt.configsync.sync.failed" || failed.SpecVersion != "1.0" ||
+		failed.Source != "/apis/configsync.gke.io/v1beta1/namespaces/config-management-system/rootsyncs/root-sync" {
+		t.Errorf("got CloudEvent %+v", failed)
+	}
+	if failed.Data.Commit != "abc123" || failed.Data.ErrorCount != 1 || failed.Data.ObjectCount != 3 || failed.Data.Cluster != "prod" {
+		t.Errorf("got data %+v", failed.Data)
+	}
+	if r.events[1].Type != "dev.kpt.configsync.sync.succeeded" {
+		t.Errorf("got type %q, want dev.kpt.configsync.sync.succeeded", r.events[1].Type)
+	}
+	for i, v := range r.verified {
+		if !v {
+			t.Errorf("got an invalid signature of notification %d", i)
+		}
+	}
+}
+
+func TestNotifier_Rollback(t *testing.T) {
+	r := &receiver{delivered: make(chan struct{}, 10)}
+	server := httptest.NewServer(r)
+	defer server.Close()
+	n := newTestNotifier(t, []v1beta1.NotificationEndpoint{{
+		URL:    server.URL,
+		Events: []string{v1beta1.NotificationRollback},
+	}}, "")
+
+	n.SyncSucceeded("first", 1)
+	n.SyncSucceeded("second", 1)
+	n.SyncSucceeded("first", 1)
+	r.wait(t, 1)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.events) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(r.events))
+	}
+	if got := r.events[0]; got.Type != "dev.kpt.configsync.sync.rollback" || got.Data.Commit != "first" || got.Data.PreviousCommit != "second" {
+		t.Errorf("got CloudEvent %+v, want a rollback from second to first", got)
+	}
+}
+
+func TestNewNotifier_NoEndpoints(t *testing.T) {
+	n, err := NewNotifier("", "", "", configsync.RootSyncKind, types.NamespacedName{})
+	if err != nil || n != nil {
+		t.Fatalf("got %v, %v, want a nil Notifier", n, err)
+	}
+	// The methods of a nil Notifier do nothing.
+	n.SyncSucceeded("abc123", 1)
+	n.Stalled("abc123", status.Append(nil, errors.New("render failed")))
+	n.Run(context.Background())
+
+	if _, err := NewNotifier("{", "", "", configsync.RootSyncKind, types.NamespacedName{}); err == nil {
+		t.Error("got no error for an invalid config")
+	}
+}
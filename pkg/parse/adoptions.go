@@ -0,0 +1,50 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+// maxAdoptions is the maximum number of adoptions listed in
+// `.status.sync.adoptions`. The oldest are dropped first.
+const maxAdoptions = 50
+
+// setSyncStatusAdoptions adds the adoptions which are not listed yet to
+// `.status.sync.adoptions`. The adoptions already listed are kept, so that
+// the adoptions performed before the reconciler restarted are still reported.
+func setSyncStatusAdoptions(syncStatus *v1beta1.Status, adoptions []v1beta1.Adoption) {
+	for _, adoption := range adoptions {
+		if !containsAdoption(syncStatus.Sync.Adoptions, adoption) {
+			syncStatus.Sync.Adoptions = append(syncStatus.Sync.Adoptions, *adoption.DeepCopy())
+		}
+	}
+	if len(syncStatus.Sync.Adoptions) > maxAdoptions {
+		syncStatus.Sync.Adoptions = syncStatus.Sync.Adoptions[len(syncStatus.Sync.Adoptions)-maxAdoptions:]
+	}
+}
+
+// containsAdoption returns true if the adoption is listed. The time is not
+// compared, as it is truncated to seconds when the status is stored.
+func containsAdoption(adoptions []v1beta1.Adoption, adoption v1beta1.Adoption) bool {
+	for _, a := range adoptions {
+		if a.Resource == adoption.Resource && a.Commit == adoption.Commit && a.PreviousManager == adoption.PreviousManager &&
+			equality.Semantic.DeepEqual(a.RemovedMetadata, adoption.RemovedMetadata) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+func adoptionOf(name string, t time.Time) v1beta1.Adoption {
+	return v1beta1.Adoption{
+		Resource: v1beta1.ResourceRef{
+			Name:      name,
+			Namespace: "bar",
+			GVK:       metav1.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+		},
+		PreviousManager: "kubectl",
+		Commit:          "abc123",
+		Time:            metav1.NewTime(t),
+	}
+}
+
+func TestSetSyncStatusAdoptions(t *testing.T) {
+	now := time.Date(2022, 10, 18, 12, 0, 0, 123456789, time.UTC)
+	stored := now.Truncate(time.Second)
+	var many []v1beta1.Adoption
+	for i := 0; i < maxAdoptions+5; i++ {
+		many = append(many, adoptionOf(fmt.Sprintf("role-%d", i), now))
+	}
+	testCases := []struct {
+		name      string
+		current   []v1beta1.Adoption
+		adoptions []v1beta1.Adoption
+		expected  []v1beta1.Adoption
+	}{
+		{
+			name: "no adoptions",
+		},
+		{
+			name:      "add adoptions",
+			current:   []v1beta1.Adoption{adoptionOf("role-a", stored)},
+			adoptions: []v1beta1.Adoption{adoptionOf("role-b", now)},
+			expected:  []v1beta1.Adoption{adoptionOf("role-a", stored), adoptionOf("role-b", now)},
+		},
+		{
+			name:      "keep adoptions listed with a truncated time",
+			current:   []v1beta1.Adoption{adoptionOf("role-a", stored)},
+			adoptions: []v1beta1.Adoption{adoptionOf("role-a", now)},
+			expected:  []v1beta1.Adoption{adoptionOf("role-a", stored)},
+		},
+		{
+			name:      "drop the oldest adoptions",
+			adoptions: many,
+			expected:  many[5:],
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &v1beta1.Status{}
+			s.Sync.Adoptions = tc.current
+			setSyncStatusAdoptions(s, tc.adoptions)
+			if diff := cmp.Diff(tc.expected, s.Sync.Adoptions); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
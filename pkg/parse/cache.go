@@ -38,6 +38,10 @@ type cacheForCommit struct {
 	// This field is only set after the reconciler successfully reads all the source files.
 	source sourceState
 
+	// fileObjects caches the objects read from the source files.
+	// Unlike the other fields, it is carried over when the cache is reset.
+	fileObjects *fileObjectCache
+
 	// hasParserResult indicates whether the cache includes the parser result.
 	hasParserResult bool
 
@@ -0,0 +1,160 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"encoding/json"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxChangeSummaryResources is the maximum number of resources listed for
+// each kind of change in a ChangeSummary. The counts are not capped.
+const maxChangeSummaryResources = 10
+
+// updateChangeSummary computes the changes the parsed objects make to the
+// declared resources, before they are applied, and publishes them in
+// `.status.sync.changeSummary` and on the ResourceGroup.
+//
+// The summary is only computed once per commit, since retries and resyncs of
+// the same commit compare against declarations which are already updated.
+// It is not computed right after the reconciler starts, since there are no
+// previous declarations to compare to.
+func updateChangeSummary(ctx context.Context, p Parser, state *reconcilerState) {
+	commit := state.cache.source.commit
+	if state.changeSummary != nil && state.changeSummary.Commit == commit {
+		return
+	}
+	opts := p.options()
+	summary, found, err := opts.resources.ChangeSummary(filesystem.AsCoreObjects(state.cache.objsToApply))
+	if err != nil {
+		klog.Warningf("Failed to compute the change summary for commit %s: %v", commit, err)
+		return
+	}
+	if !found {
+		return
+	}
+	state.changeSummary = toChangeSummaryStatus(commit, summary)
+	klog.Infof("Commit %s changes: %d to create, %d to update, %d to prune",
+		commit, state.changeSummary.CreateCount, state.changeSummary.UpdateCount, state.changeSummary.PruneCount)
+
+	if err := setSyncStatus(ctx, p, state, true, p.SyncErrors()); err != nil {
+		klog.Warningf("failed to update sync status with the change summary: %v", err)
+	}
+	if err := setChangeSummaryAnnotation(ctx, opts.k8sClient(), opts.syncName, inventoryNamespace(opts.scope), state.changeSummary); err != nil {
+		klog.Warningf("failed to set the change summary annotation on the ResourceGroup: %v", err)
+	}
+}
+
+// toChangeSummaryStatus converts a ChangeSummary into its status
+// representation, capping the lists of resources.
+func toChangeSummaryStatus(commit string, summary declared.ChangeSummary) *v1beta1.ChangeSummary {
+	result := &v1beta1.ChangeSummary{
+		Commit:      commit,
+		CreateCount: len(summary.Create),
+		UpdateCount: len(summary.Update),
+		PruneCount:  len(summary.Prune),
+	}
+	var truncated bool
+	result.Create, truncated = toResourceRefs(summary.Create)
+	result.Truncated = result.Truncated || truncated
+	result.Update, truncated = toResourceRefs(summary.Update)
+	result.Truncated = result.Truncated || truncated
+	result.Prune, truncated = toResourceRefs(summary.Prune)
+	result.Truncated = result.Truncated || truncated
+	return result
+}
+
+func toResourceRefs(objs []*unstructured.Unstructured) ([]v1beta1.ResourceRef, bool) {
+	truncated := len(objs) > maxChangeSummaryResources
+	if truncated {
+		objs = objs[:maxChangeSummaryResources]
+	}
+	var refs []v1beta1.ResourceRef
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		refs = append(refs, v1beta1.ResourceRef{
+			SourcePath: status.GetSourceAnnotation(obj),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+			GVK: metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+		})
+	}
+	return refs, truncated
+}
+
+// setSyncStatusChangeSummary sets `.status.sync.changeSummary`. A summary for
+// an older commit is cleared when no summary is known for the new commit.
+func setSyncStatusChangeSummary(syncStatus *v1beta1.Status, summary *v1beta1.ChangeSummary) {
+	if summary != nil && summary.Commit == syncStatus.Sync.Commit {
+		syncStatus.Sync.ChangeSummary = summary.DeepCopy()
+	} else if syncStatus.Sync.ChangeSummary != nil && syncStatus.Sync.ChangeSummary.Commit != syncStatus.Sync.Commit {
+		syncStatus.Sync.ChangeSummary = nil
+	}
+}
+
+// setChangeSummaryAnnotation records the change summary on the ResourceGroup
+// of the RootSync or RepoSync. Before the first sync the ResourceGroup does
+// not exist yet, in which case nothing is recorded.
+func setChangeSummaryAnnotation(ctx context.Context, c client.Client, name, namespace string, summary *v1beta1.ChangeSummary) error {
+	value, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				metadata.ChangeSummaryAnnotationKey: string(value),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	rg := &unstructured.Unstructured{}
+	rg.SetGroupVersionKind(kinds.ResourceGroup())
+	rg.SetName(name)
+	rg.SetNamespace(namespace)
+	if err := c.Patch(ctx, rg, client.RawPatch(types.MergePatchType, patch)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// inventoryNamespace returns the namespace of the ResourceGroup of the
+// reconciler with the given scope.
+func inventoryNamespace(scope declared.Scope) string {
+	if scope == declared.RootReconciler {
+		return configmanagement.ControllerNamespace
+	}
+	return string(scope)
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func roles(n int) []*unstructured.Unstructured {
+	var result []*unstructured.Unstructured
+	for i := 0; i < n; i++ {
+		result = append(result, fake.UnstructuredObject(kinds.Role(), core.Name(fmt.Sprintf("role-%d", i)), core.Namespace("bar")))
+	}
+	return result
+}
+
+func TestToChangeSummaryStatus(t *testing.T) {
+	summary := declared.ChangeSummary{
+		Create: roles(maxChangeSummaryResources + 2),
+		Prune:  roles(1),
+	}
+	got := toChangeSummaryStatus("abc123", summary)
+
+	if got.Commit != "abc123" || got.CreateCount != maxChangeSummaryResources+2 || got.UpdateCount != 0 || got.PruneCount != 1 {
+		t.Errorf("got counts %+v, want commit abc123 with %d to create, 0 to update and 1 to prune", got, maxChangeSummaryResources+2)
+	}
+	if len(got.Create) != maxChangeSummaryResources {
+		t.Errorf("got %d resources to create, want %d", len(got.Create), maxChangeSummaryResources)
+	}
+	if !got.Truncated {
+		t.Error("got truncated = false, want true")
+	}
+	wantPrune := []v1beta1.ResourceRef{{
+		Name:      "role-0",
+		Namespace: "bar",
+		GVK:       metav1.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	}}
+	if diff := cmp.Diff(wantPrune, got.Prune); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSetSyncStatusChangeSummary(t *testing.T) {
+	oldSummary := &v1beta1.ChangeSummary{Commit: "old", CreateCount: 1}
+	newSummary := &v1beta1.ChangeSummary{Commit: "new", PruneCount: 2}
+	testCases := []struct {
+		name     string
+		current  *v1beta1.ChangeSummary
+		commit   string
+		summary  *v1beta1.ChangeSummary
+		expected *v1beta1.ChangeSummary
+	}{
+		{
+			name:     "set summary for the synced commit",
+			current:  oldSummary,
+			commit:   "new",
+			summary:  newSummary,
+			expected: newSummary,
+		},
+		{
+			name:     "clear summary of an older commit",
+			current:  oldSummary,
+			commit:   "new",
+			expected: nil,
+		},
+		{
+			name:     "keep summary of the synced commit",
+			current:  oldSummary,
+			commit:   "old",
+			expected: oldSummary,
+		},
+		{
+			name:     "ignore summary of another commit",
+			commit:   "old",
+			summary:  newSummary,
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &v1beta1.Status{}
+			s.Sync.Commit = tc.commit
+			s.Sync.ChangeSummary = tc.current
+			setSyncStatusChangeSummary(s, tc.summary)
+			if diff := cmp.Diff(tc.expected, s.Sync.ChangeSummary); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+)
+
+// fileObjectCache caches the objects read from each source file, keyed by the
+// path of the file relative to the sync directory.
+//
+// Unlike the rest of cacheForCommit, the fileObjectCache is carried over to the
+// next commit, so that only the files whose content changed are read again.
+type fileObjectCache struct {
+	// files maps the relative path of each file read by the last parse to its
+	// content hash and the objects read from it.
+	files map[string]cachedFile
+	// paths lists the keys of files in the order the files were listed.
+	paths []string
+
+	// validated contains the objects returned by the last parse which passed
+	// validation without any error. It is nil if the next parse needs to
+	// validate all the objects.
+	validated []ast.FileObject
+
+	// variables are the cluster variables substituted in the validated
+	// objects.
+	variables map[string]string
+}
+
+// cachedFile is the content hash of a file and the objects read from it.
+type cachedFile struct {
+	hash string
+	objs []ast.FileObject
+}
+
+// fileChanges describes how the source files differ from the last parse.
+type fileChanges struct {
+	// changed contains the relative paths of the files whose content changed.
+	changed map[string]bool
+	// local is true if the changes only affect the objects declared in the
+	// changed files, so only those objects need to be validated again.
+	local bool
+}
+
+// globalKinds are the kinds of the objects which affect how other objects are
+// validated or hydrated. Changing, adding or removing one of them requires
+// validating every object again.
+var globalKinds = map[schema.GroupKind]bool{
+	kinds.Namespace().GroupKind():         true,
+	kinds.NamespaceSelector().GroupKind(): true,
+	kinds.ClusterSelector().GroupKind():   true,
+	kinds.Cluster().GroupKind():           true,
+	kinds.HierarchyConfig().GroupKind():   true,
+	kinds.Repo().GroupKind():              true,
+	kinds.CustomResourceDefinition():      true,
+}
+
+func isGlobalKind(o ast.FileObject) bool {
+	return globalKinds[o.GetObjectKind().GroupVersionKind().GroupKind()]
+}
+
+// read returns the objects declared in filePaths.Files. Only the files whose
+// content hash changed since the last call are read with parser; the objects
+// of the other files are copied from the cache.
+func (c *fileObjectCache) read(parser filesystem.ConfigParser, filePaths reader.FilePaths) ([]ast.FileObject, fileChanges, status.MultiError) {
+	changes := fileChanges{changed: make(map[string]bool)}
+	hashes := make(map[string]string, len(filePaths.Files))
+	var paths []string
+	var toRead []cmpath.Absolute
+	for _, f := range filePaths.Files {
+		rel, err := filepath.Rel(filePaths.RootDir.OSPath(), f.OSPath())
+		if err != nil {
+			return nil, changes, status.PathWrapError(errors.Wrapf(err, "unable to get relative path to %s", filePaths.RootDir.OSPath()), f.OSPath())
+		}
+		hash, err := hashFile(f.OSPath())
+		if err != nil {
+			return nil, changes, status.PathWrapError(err, f.OSPath())
+		}
+		paths = append(paths, rel)
+		hashes[rel] = hash
+		// Evaluated files may import other files, so they are always read.
+		if cached, found := c.files[rel]; !found || cached.hash != hash || reader.IsEvaluated(rel) {
+			changes.changed[rel] = true
+			toRead = append(toRead, f)
+		}
+	}
+
+	newObjs, errs := parser.Parse(reader.FilePaths{
+		RootDir:   filePaths.RootDir,
+		PolicyDir: filePaths.PolicyDir,
+		Files:     toRead,
+	})
+	if errs != nil {
+		return nil, changes, errs
+	}
+	klog.V(4).Infof("Read %d of %d source files, the others are unchanged", len(toRead), len(filePaths.Files))
+
+	newFiles := make(map[string]cachedFile, len(paths))
+	for _, rel := range paths {
+		if !changes.changed[rel] {
+			newFiles[rel] = c.files[rel]
+		} else {
+			newFiles[rel] = cachedFile{hash: hashes[rel]}
+		}
+	}
+	for _, o := range newObjs {
+		rel := o.OSPath()
+		file, found := newFiles[rel]
+		if !found {
+			// The parser returned objects from a file which was not listed.
+			paths = append(paths, rel)
+			changes.changed[rel] = true
+		}
+		file.objs = append(file.objs, o.DeepCopy())
+		newFiles[rel] = file
+	}
+
+	changes.local = c.validated != nil && len(c.files) == len(newFiles)
+	for rel := range changes.changed {
+		if !changes.local {
+			break
+		}
+		old, found := c.files[rel]
+		changes.local = found && localChange(old.objs, newFiles[rel].objs)
+	}
+	c.files = newFiles
+	c.paths = paths
+	return c.objects(), changes, nil
+}
+
+// localChange returns true if a file declares the same objects before and
+// after a change, and none of them is of a global kind.
+func localChange(oldObjs, newObjs []ast.FileObject) bool {
+	if len(oldObjs) != len(newObjs) {
+		return false
+	}
+	for i := range oldObjs {
+		if isGlobalKind(oldObjs[i]) || isGlobalKind(newObjs[i]) {
+			return false
+		}
+		if core.IDOf(oldObjs[i]) != core.IDOf(newObjs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate validates and hydrates objs with validateFn.
+//
+// If the changes are local, only the objects from the changed files, along
+// with the objects of global kinds they may depend on, are validated. The
+// result is merged with the validated objects of the unchanged files from the
+// last parse. Otherwise, or if validating the changed objects fails, all the
+// objects are validated.
+func (c *fileObjectCache) validate(objs []ast.FileObject, changes fileChanges, validateFn func([]ast.FileObject) ([]ast.FileObject, status.MultiError)) ([]ast.FileObject, status.MultiError) {
+	previous := c.validated
+	c.validated = nil
+
+	if changes.local {
+		var subset []ast.FileObject
+		for _, o := range objs {
+			if changes.changed[o.OSPath()] || isGlobalKind(o) {
+				subset = append(subset, o)
+			}
+		}
+		validated, errs := validateFn(subset)
+		if errs == nil {
+			var result []ast.FileObject
+			for _, o := range previous {
+				if !changes.changed[o.OSPath()] {
+					result = append(result, o.DeepCopy())
+				}
+			}
+			for _, o := range validated {
+				if changes.changed[o.OSPath()] {
+					result = append(result, o)
+				}
+			}
+			klog.V(4).Infof("Validated the objects from %d changed files only", len(changes.changed))
+			c.setValidated(result)
+			return result, nil
+		}
+		klog.V(4).Infof("Validating all objects since validating the objects from the changed files failed: %v", errs)
+		// The objects may have been hydrated in place, so read them again
+		// from the cache.
+		objs = c.objects()
+	}
+
+	validated, errs := validateFn(objs)
+	if errs == nil {
+		c.setValidated(validated)
+	}
+	return validated, errs
+}
+
+// setVariables records the cluster variables substituted in the objects, and
+// returns true if they differ from the variables of the last parse. Since
+// variables may be referenced by any object, all the objects need to be
+// validated again when they change.
+func (c *fileObjectCache) setVariables(vars map[string]string) bool {
+	changed := len(vars) != len(c.variables)
+	for k, v := range vars {
+		if old, found := c.variables[k]; !found || old != v {
+			changed = true
+		}
+	}
+	c.variables = vars
+	return changed
+}
+
+// setValidated caches copies of the validated objects, since the objects are
+// further modified before being applied.
+func (c *fileObjectCache) setValidated(objs []ast.FileObject) {
+	c.validated = make([]ast.FileObject, len(objs))
+	for i, o := range objs {
+		c.validated[i] = o.DeepCopy()
+	}
+}
+
+// objects returns copies of all the cached objects read from the source files.
+func (c *fileObjectCache) objects() []ast.FileObject {
+	var objs []ast.FileObject
+	for _, rel := range c.paths {
+		for _, o := range c.files[rel].objs {
+			objs = append(objs, o.DeepCopy())
+		}
+	}
+	return objs
+}
+
+func hashFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
+	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/status"
+)
+
+const (
+	namespaceYAML = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: bar
+`
+	configMapYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: bar
+`
+)
+
+func writeSourceFile(t *testing.T, dir, name, content string) cmpath.Absolute {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	abs, err := cmpath.AbsoluteOS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abs
+}
+
+func TestFileObjectCache(t *testing.T) {
+	dir := t.TempDir()
+	root, err := cmpath.AbsoluteOS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePaths := reader.FilePaths{
+		RootDir: root,
+		Files: []cmpath.Absolute{
+			writeSourceFile(t, dir, "ns.yaml", namespaceYAML),
+			writeSourceFile(t, dir, "cm-a.yaml", fmt.Sprintf(configMapYAML, "a")),
+			writeSourceFile(t, dir, "cm-b.yaml", fmt.Sprintf(configMapYAML, "b")),
+		},
+	}
+	parser := filesystem.NewParser(&reader.File{})
+	cache := &fileObjectCache{}
+
+	var validatedCount int
+	validateFn := func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+		validatedCount = len(objs)
+		return objs, nil
+	}
+
+	parse := func() fileChanges {
+		t.Helper()
+		objs, changes, errs := cache.read(parser, filePaths)
+		if errs != nil {
+			t.Fatal(errs)
+		}
+		if len(objs) != 3 {
+			t.Fatalf("got %d objects, want 3", len(objs))
+		}
+		validated, errs := cache.validate(objs, changes, validateFn)
+		if errs != nil {
+			t.Fatal(errs)
+		}
+		if len(validated) != 3 {
+			t.Fatalf("got %d validated objects, want 3", len(validated))
+		}
+		return changes
+	}
+
+	// The first parse reads and validates every file.
+	changes := parse()
+	if len(changes.changed) != 3 || changes.local {
+		t.Errorf("got changes %+v, want 3 changed files and a full validation", changes)
+	}
+	if validatedCount != 3 {
+		t.Errorf("got %d objects validated, want 3", validatedCount)
+	}
+
+	// Nothing changed.
+	changes = parse()
+	if len(changes.changed) != 0 || !changes.local {
+		t.Errorf("got changes %+v, want no changed file", changes)
+	}
+
+	// A change to a ConfigMap only validates that ConfigMap along with the
+	// Namespace.
+	writeSourceFile(t, dir, "cm-a.yaml", fmt.Sprintf(configMapYAML, "a")+"data:\n  key: value\n")
+	changes = parse()
+	if !changes.changed["cm-a.yaml"] || len(changes.changed) != 1 || !changes.local {
+		t.Errorf("got changes %+v, want a local change to cm-a.yaml", changes)
+	}
+	if validatedCount != 2 {
+		t.Errorf("got %d objects validated, want 2", validatedCount)
+	}
+
+	// A change to the Namespace validates every object.
+	writeSourceFile(t, dir, "ns.yaml", namespaceYAML+"  labels:\n    team: a\n")
+	changes = parse()
+	if !changes.changed["ns.yaml"] || changes.local {
+		t.Errorf("got changes %+v, want a global change to ns.yaml", changes)
+	}
+	if validatedCount != 3 {
+		t.Errorf("got %d objects validated, want 3", validatedCount)
+	}
+
+	// Renaming an object validates every object.
+	writeSourceFile(t, dir, "cm-b.yaml", fmt.Sprintf(configMapYAML, "c"))
+	changes = parse()
+	if changes.local {
+		t.Errorf("got changes %+v, want a full validation", changes)
+	}
+	if validatedCount != 3 {
+		t.Errorf("got %d objects validated, want 3", validatedCount)
+	}
+}
+
+func TestFileObjectCache_ValidationErrors(t *testing.T) {
+	dir := t.TempDir()
+	root, err := cmpath.AbsoluteOS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePaths := reader.FilePaths{
+		RootDir: root,
+		Files: []cmpath.Absolute{
+			writeSourceFile(t, dir, "cm-a.yaml", fmt.Sprintf(configMapYAML, "a")),
+		},
+	}
+	parser := filesystem.NewParser(&reader.File{})
+	cache := &fileObjectCache{}
+
+	objs, changes, errs := cache.read(parser, filePaths)
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	_, errs = cache.validate(objs, changes, func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+		return nil, status.InternalError("invalid")
+	})
+	if errs == nil {
+		t.Fatal("got no error, want an error")
+	}
+	if cache.validated != nil {
+		t.Error("got validated objects cached after a validation error")
+	}
+
+	// The next parse validates every object again, even if nothing changed.
+	_, changes, errs = cache.read(parser, filePaths)
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	if changes.local {
+		t.Errorf("got changes %+v, want a full validation", changes)
+	}
+}
+
+func TestFileObjectCache_SetVariables(t *testing.T) {
+	cache := &fileObjectCache{}
+	if cache.setVariables(nil) {
+		t.Error("got variables changed from no variables to no variables")
+	}
+	if !cache.setVariables(map[string]string{"region": "us-east1"}) {
+		t.Error("got variables unchanged after adding a variable")
+	}
+	if cache.setVariables(map[string]string{"region": "us-east1"}) {
+		t.Error("got variables changed after setting the same variables")
+	}
+	if !cache.setVariables(map[string]string{"region": "us-west1"}) {
+		t.Error("got variables unchanged after changing a value")
+	}
+	if !cache.setVariables(nil) {
+		t.Error("got variables unchanged after removing all variables")
+	}
+}
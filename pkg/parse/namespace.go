@@ -27,10 +27,12 @@ import (
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/events"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/reader"
 	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/notifications"
 	"kpt.dev/configsync/pkg/remediator"
 	"kpt.dev/configsync/pkg/reposync"
 	"kpt.dev/configsync/pkg/status"
@@ -41,7 +43,7 @@ import (
 )
 
 // NewNamespaceRunner creates a new runnable parser for parsing a Namespace repo.
-func NewNamespaceRunner(clusterName, syncName, reconcilerName string, scope declared.Scope, fileReader reader.Reader, c client.Client, pollingPeriod, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface) (Parser, error) {
+func NewNamespaceRunner(clusterName, syncName, reconcilerName string, scope declared.Scope, namespaces []string, fileReader reader.Reader, c client.Client, pollingPeriod, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface, ignoreRules declared.IgnoreRules, recorder *events.Recorder, notifier *notifications.Notifier) (Parser, error) {
 	converter, err := declared.NewValueConverter(dc)
 	if err != nil {
 		return nil, err
@@ -67,9 +69,13 @@ func NewNamespaceRunner(clusterName, syncName, reconcilerName string, scope decl
 			},
 			discoveryInterface: dc,
 			converter:          converter,
+			ignoreRules:        ignoreRules,
+			events:             recorder,
+			notifier:           notifier,
 			mux:                &sync.Mutex{},
 		},
-		scope: scope,
+		scope:      scope,
+		namespaces: namespaces,
 	}, nil
 }
 
@@ -78,8 +84,12 @@ type namespace struct {
 
 	// scope is the name of the Namespace this parser is for.
 	// It is an error for this parser's repository to contain resources outside of
-	// this Namespace.
+	// this Namespace and the other namespaces.
 	scope declared.Scope
+
+	// namespaces are the other namespaces this parser's repository may contain
+	// resources in, from the spec.namespaces field of the RepoSync.
+	namespaces []string
 }
 
 var _ Parser = &namespace{}
@@ -89,7 +99,7 @@ func (p *namespace) options() *opts {
 }
 
 // parseSource implements the Parser interface
-func (p *namespace) parseSource(ctx context.Context, state sourceState) ([]ast.FileObject, status.MultiError) {
+func (p *namespace) parseSource(ctx context.Context, state sourceState, fileObjects *fileObjectCache) ([]ast.FileObject, status.MultiError) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
@@ -105,10 +115,17 @@ func (p *namespace) parseSource(ctx context.Context, state sourceState) ([]ast.F
 	builder := utildiscovery.ScoperBuilder(p.discoveryInterface)
 
 	klog.Infof("Parsing files from source dir: %s", state.syncDir.OSPath())
-	objs, err := p.parser.Parse(filePaths)
+	objs, changes, err := fileObjects.read(p.parser, filePaths)
 	if err != nil {
 		return nil, err
 	}
+	vars, varErr := readVariables(ctx, p.client)
+	if varErr != nil {
+		return nil, varErr
+	}
+	if fileObjects.setVariables(vars) {
+		changes.local = false
+	}
 
 	options := validate.Options{
 		ClusterName:    p.clusterName,
@@ -117,10 +134,14 @@ func (p *namespace) parseSource(ctx context.Context, state sourceState) ([]ast.F
 		PreviousCRDs:   crds,
 		BuildScoper:    builder,
 		Converter:      p.converter,
+		IgnoreRules:    p.ignoreRules,
+		Variables:      vars,
 	}
-	options = OptionsForScope(options, p.scope)
+	options = OptionsForScope(options, p.scope, p.namespaces...)
 
-	objs, err = validate.Unstructured(objs, options)
+	objs, err = fileObjects.validate(objs, changes, func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+		return validate.Unstructured(ctx, objs, options)
+	})
 
 	if status.HasBlockingErrors(err) {
 		return nil, err
@@ -20,8 +20,10 @@ import (
 	"time"
 
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/events"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/filesystem"
+	"kpt.dev/configsync/pkg/notifications"
 	"kpt.dev/configsync/pkg/status"
 	"kpt.dev/configsync/pkg/util/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -68,6 +70,18 @@ type opts struct {
 	// objects in Git.
 	converter *declared.ValueConverter
 
+	// ignoreRules selects the fields of declared objects which are owned by
+	// another controller, and so are neither applied nor reverted.
+	ignoreRules declared.IgnoreRules
+
+	// events records the Events of the sync lifecycle on the RootSync or
+	// RepoSync object.
+	events *events.Recorder
+
+	// notifier posts the notifications of the sync results to the endpoints of
+	// spec.notifications.
+	notifier *notifications.Notifier
+
 	// mux prevents status update conflicts.
 	mux *sync.Mutex
 
@@ -77,7 +91,7 @@ type opts struct {
 
 // Parser represents a parser that can be pointed at and continuously parse a source.
 type Parser interface {
-	parseSource(ctx context.Context, state sourceState) ([]ast.FileObject, status.MultiError)
+	parseSource(ctx context.Context, state sourceState, fileObjects *fileObjectCache) ([]ast.FileObject, status.MultiError)
 	setSourceStatus(ctx context.Context, newStatus sourceStatus) error
 	setRenderingStatus(ctx context.Context, oldStatus, newStatus renderingStatus) error
 	SetSyncStatus(ctx context.Context, newStatus syncStatus) error
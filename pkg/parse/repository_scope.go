@@ -15,60 +15,93 @@
 package parse
 
 import (
+	"strconv"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/analyzer/validation/nonhierarchical"
 	"kpt.dev/configsync/pkg/status"
 	"kpt.dev/configsync/pkg/validate"
+	rawvalidate "kpt.dev/configsync/pkg/validate/raw/validate"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // OptionsForScope returns new Options that have been updated for the given
-// Scope.
-func OptionsForScope(options validate.Options, scope declared.Scope) validate.Options {
+// Scope. The namespaces are the other namespaces which a Namespace repo may
+// declare objects in.
+func OptionsForScope(options validate.Options, scope declared.Scope, namespaces ...string) validate.Options {
 	if scope == declared.RootReconciler {
 		options.DefaultNamespace = metav1.NamespaceDefault
 		options.IsNamespaceReconciler = false
 	} else {
 		options.DefaultNamespace = string(scope)
 		options.IsNamespaceReconciler = true
-		options.Visitors = append(options.Visitors, repositoryScopeVisitor(scope))
+		options.Visitors = append(options.Visitors, repositoryScopeVisitor(scope, namespaces),
+			secretFromScopeVisitor(scope, namespaces))
 	}
 	return options
 }
 
 // repositoryScopeVisitor ensures all objects in a Namespace Repo are either
 // 1) The Namespace for the scope, or
-// 2) Namespace-scoped objects that define metadata.namespace matching the scope, or
-//      omit metadata.namespace.
-func repositoryScopeVisitor(scope declared.Scope) validate.VisitorFunc {
+// 2) Namespace-scoped objects that define metadata.namespace matching the scope
+//      or one of the other namespaces, or omit metadata.namespace.
+func repositoryScopeVisitor(scope declared.Scope, namespaces []string) validate.VisitorFunc {
+	allowed := map[string]bool{string(scope): true}
+	for _, ns := range namespaces {
+		allowed[ns] = true
+	}
 	return func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
 		var errs status.MultiError
 		for _, obj := range objs {
 			// By this point we've validated that there are no cluster-scoped objects
 			// in this repo.
-			switch obj.GetNamespace() {
-			case string(scope):
+			switch ns := obj.GetNamespace(); {
+			case allowed[ns]:
 				// This is what we want, so ignore.
-			case "":
+			case ns == "":
 				// Missing metadata.namespace, so set it to be the one for this Repo.
 				// Otherwise this will invalidly default to the "default" Namespace.
 				obj.SetNamespace(string(scope))
 			default:
 				// There's an object declaring an invalid metadata.namespace, so this is
 				// an error.
-				errs = status.Append(errs, BadScopeErr(obj, scope))
+				errs = status.Append(errs, BadScopeErr(obj, scope, namespaces...))
 			}
 		}
 		return objs, errs
 	}
 }
 
+// secretFromScopeVisitor ensures the secret-from annotations of the objects in a
+// Namespace repo only reference Secrets in the scope or the other namespaces.
+func secretFromScopeVisitor(scope declared.Scope, namespaces []string) validate.VisitorFunc {
+	allowed := append([]string{string(scope)}, namespaces...)
+	return func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+		var errs status.MultiError
+		for _, obj := range objs {
+			errs = status.Append(errs, rawvalidate.SecretFromNamespaces(obj, allowed...))
+		}
+		return objs, errs
+	}
+}
+
 // BadScopeErr reports that the passed resource declares a Namespace for a
-// different Namespace repository.
-func BadScopeErr(resource client.Object, want declared.Scope) status.ResourceError {
+// different Namespace repository. The namespaces are the other namespaces the
+// repository may declare objects in.
+func BadScopeErr(resource client.Object, want declared.Scope, namespaces ...string) status.ResourceError {
+	if len(namespaces) == 0 {
+		return nonhierarchical.BadScopeErrBuilder.
+			Sprintf("Resources in the %q repo must either omit metadata.namespace or declare metadata.namespace=%q", want, want).
+			BuildWithResources(resource)
+	}
+	quoted := []string{strconv.Quote(string(want))}
+	for _, ns := range namespaces {
+		quoted = append(quoted, strconv.Quote(ns))
+	}
 	return nonhierarchical.BadScopeErrBuilder.
-		Sprintf("Resources in the %q repo must either omit metadata.namespace or declare metadata.namespace=%q", want, want).
+		Sprintf("Resources in the %q repo must either omit metadata.namespace or declare metadata.namespace as one of %s", want, strings.Join(quoted, ", ")).
 		BuildWithResources(resource)
 }
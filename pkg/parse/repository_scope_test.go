@@ -29,11 +29,12 @@ import (
 
 func TestNamespaceScopeVisitor(t *testing.T) {
 	testCases := []struct {
-		name    string
-		scope   declared.Scope
-		obj     ast.FileObject
-		want    ast.FileObject
-		wantErr status.Error
+		name       string
+		scope      declared.Scope
+		namespaces []string
+		obj        ast.FileObject
+		want       ast.FileObject
+		wantErr    status.Error
 	}{
 		{
 			name:  "correct Namespace pass",
@@ -52,6 +53,26 @@ func TestNamespaceScopeVisitor(t *testing.T) {
 			obj:     fake.Role(core.Namespace("bar")),
 			wantErr: nonhierarchical.BadScopeErrBuilder.Sprint("").BuildWithResources(fake.Role()),
 		},
+		{
+			name:       "other Namespace in scope pass",
+			scope:      "foo",
+			namespaces: []string{"bar", "qux"},
+			obj:        fake.Role(core.Namespace("bar")),
+		},
+		{
+			name:       "blank Namespace with other namespaces update Namespace",
+			scope:      "foo",
+			namespaces: []string{"bar"},
+			obj:        fake.Role(core.Namespace("")),
+			want:       fake.Role(core.Namespace("foo")),
+		},
+		{
+			name:       "Namespace out of scope error",
+			scope:      "foo",
+			namespaces: []string{"bar"},
+			obj:        fake.Role(core.Namespace("qux")),
+			wantErr:    nonhierarchical.BadScopeErrBuilder.Sprint("").BuildWithResources(fake.Role()),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -61,7 +82,7 @@ func TestNamespaceScopeVisitor(t *testing.T) {
 				tc.want = tc.obj.DeepCopy()
 			}
 
-			visitor := repositoryScopeVisitor(tc.scope)
+			visitor := repositoryScopeVisitor(tc.scope, tc.namespaces)
 
 			_, err := visitor([]ast.FileObject{tc.obj})
 			if !errors.Is(err, tc.wantErr) {
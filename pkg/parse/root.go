@@ -34,12 +34,14 @@ import (
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/diff"
+	"kpt.dev/configsync/pkg/events"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/importer/reader"
 	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/notifications"
 	"kpt.dev/configsync/pkg/remediator"
 	"kpt.dev/configsync/pkg/rootsync"
 	"kpt.dev/configsync/pkg/status"
@@ -51,7 +53,7 @@ import (
 )
 
 // NewRootRunner creates a new runnable parser for parsing a Root repository.
-func NewRootRunner(clusterName, syncName, reconcilerName string, format filesystem.SourceFormat, fileReader reader.Reader, c client.Client, pollingPeriod, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface) (Parser, error) {
+func NewRootRunner(clusterName, syncName, reconcilerName string, format filesystem.SourceFormat, fileReader reader.Reader, c client.Client, pollingPeriod, resyncPeriod, retryPeriod, statusUpdatePeriod time.Duration, fs FileSource, dc discovery.DiscoveryInterface, resources *declared.Resources, app applier.Applier, rem remediator.Interface, ignoreRules declared.IgnoreRules, recorder *events.Recorder, notifier *notifications.Notifier) (Parser, error) {
 	converter, err := declared.NewValueConverter(dc)
 	if err != nil {
 		return nil, err
@@ -77,6 +79,9 @@ func NewRootRunner(clusterName, syncName, reconcilerName string, format filesyst
 			},
 			discoveryInterface: dc,
 			converter:          converter,
+			ignoreRules:        ignoreRules,
+			events:             recorder,
+			notifier:           notifier,
 			mux:                &sync.Mutex{},
 		},
 		sourceFormat: format,
@@ -99,7 +104,7 @@ func (p *root) options() *opts {
 }
 
 // parseSource implements the Parser interface
-func (p *root) parseSource(ctx context.Context, state sourceState) ([]ast.FileObject, status.MultiError) {
+func (p *root) parseSource(ctx context.Context, state sourceState, fileObjects *fileObjectCache) ([]ast.FileObject, status.MultiError) {
 	wantFiles := state.files
 	if p.sourceFormat == filesystem.SourceFormatHierarchy {
 		// We're using hierarchical mode for the root repository, so ignore files
@@ -120,10 +125,17 @@ func (p *root) parseSource(ctx context.Context, state sourceState) ([]ast.FileOb
 	builder := utildiscovery.ScoperBuilder(p.discoveryInterface)
 
 	klog.Infof("Parsing files from source dir: %s", state.syncDir.OSPath())
-	objs, err := p.parser.Parse(filePaths)
+	objs, changes, err := fileObjects.read(p.parser, filePaths)
 	if err != nil {
 		return nil, err
 	}
+	vars, varErr := readVariables(ctx, p.client)
+	if varErr != nil {
+		return nil, varErr
+	}
+	if fileObjects.setVariables(vars) {
+		changes.local = false
+	}
 
 	options := validate.Options{
 		ClusterName:    p.clusterName,
@@ -132,14 +144,23 @@ func (p *root) parseSource(ctx context.Context, state sourceState) ([]ast.FileOb
 		PreviousCRDs:   crds,
 		BuildScoper:    builder,
 		Converter:      p.converter,
+		IgnoreRules:    p.ignoreRules,
+		Variables:      vars,
 	}
 	options = OptionsForScope(options, p.scope)
 
 	if p.sourceFormat == filesystem.SourceFormatUnstructured {
 		options.Visitors = append(options.Visitors, p.addImplicitNamespaces)
-		objs, err = validate.Unstructured(objs, options)
+		objs, err = fileObjects.validate(objs, changes, func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+			return validate.Unstructured(ctx, objs, options)
+		})
 	} else {
-		objs, err = validate.Hierarchical(objs, options)
+		// Objects in abstract namespaces are inherited by every descendant
+		// namespace, so the hierarchy is always validated as a whole.
+		changes.local = false
+		objs, err = fileObjects.validate(objs, changes, func(objs []ast.FileObject) ([]ast.FileObject, status.MultiError) {
+			return validate.Hierarchical(ctx, objs, options)
+		})
 	}
 
 	if status.HasBlockingErrors(err) {
@@ -426,6 +447,8 @@ func setSyncStatusFields(syncStatus *v1beta1.Status, newStatus syncStatus, denom
 	syncStatus.Sync.Oci = syncStatus.Source.Oci
 	syncStatus.Sync.Helm = syncStatus.Source.Helm
 	setSyncStatusErrors(syncStatus, cse, denominator)
+	setSyncStatusChangeSummary(syncStatus, newStatus.changeSummary)
+	setSyncStatusAdoptions(syncStatus, newStatus.adoptions)
 	syncStatus.Sync.LastUpdate = newStatus.lastUpdate
 }
 
@@ -916,7 +916,7 @@ type fakeApplier struct {
 	errors []status.Error
 }
 
-func (a *fakeApplier) Apply(_ context.Context, objs []client.Object) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
+func (a *fakeApplier) Apply(_ context.Context, objs []client.Object, _ string) (map[schema.GroupVersionKind]struct{}, status.MultiError) {
 	if a.errors == nil {
 		a.got = objs
 		gvks := make(map[schema.GroupVersionKind]struct{})
@@ -932,6 +932,18 @@ func (a *fakeApplier) Apply(_ context.Context, objs []client.Object) (map[schema
 	return nil, errs
 }
 
+func (a *fakeApplier) SecretReferencesChanged(_ context.Context) bool {
+	return false
+}
+
+func (a *fakeApplier) PruneBlocked(_ context.Context, _ []client.Object, _ string) []core.ID {
+	return nil
+}
+
+func (a *fakeApplier) Adoptions() []v1beta1.Adoption {
+	return nil
+}
+
 func (a *fakeApplier) Errors() status.MultiError {
 	var errs status.MultiError
 	for _, e := range a.errors {
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/declared"
@@ -27,6 +28,7 @@ import (
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/tracing"
 	webhookconfiguration "kpt.dev/configsync/pkg/webhook/configuration"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -143,9 +145,18 @@ func Run(ctx context.Context, p Parser) {
 }
 
 func run(ctx context.Context, p Parser, trigger string, state *reconcilerState) {
+	ctx, span := trace.StartSpan(ctx, "parse.run")
+	span.AddAttributes(
+		trace.StringAttribute(tracing.TriggerKey, trigger),
+		trace.StringAttribute(tracing.ReconcilerKey, p.options().reconcilerName))
+	defer span.End()
+
 	var syncDir cmpath.Absolute
 	gs := sourceStatus{}
+	_, fetchSpan := trace.StartSpan(ctx, "parse.fetch")
 	gs.commit, syncDir, gs.errs = hydrate.SourceCommitAndDir(p.options().SourceType, p.options().SourceDir, p.options().SyncDir, p.options().reconcilerName)
+	tracing.EndSpan(fetchSpan, gs.errs)
+	span.AddAttributes(trace.StringAttribute(tracing.CommitKey, gs.commit))
 
 	// If failed to fetch the source commit and directory, set `.status.source` to fail early.
 	// Otherwise, set `.status.rendering` before `.status.source` because the parser needs to
@@ -156,6 +167,7 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 		if state.needToSetSourceStatus(gs) {
 			setSourceStatusErr = p.setSourceStatus(ctx, gs)
 			if setSourceStatusErr == nil {
+				notifyStalled(p, gs.commit, gs.errs)
 				state.sourceStatus = gs
 				state.syncingConditionLastUpdate = gs.lastUpdate
 			}
@@ -190,6 +202,7 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 		rs.errs = status.InternalHydrationError(err, "unable to read the done file: %s", doneFilePath)
 		setRenderingStatusErr := p.setRenderingStatus(ctx, state.renderingStatus, rs)
 		if setRenderingStatusErr == nil {
+			recordRenderingEvent(ctx, p, state.renderingStatus, rs)
 			state.renderingStatus = rs
 			state.syncingConditionLastUpdate = rs.lastUpdate
 		}
@@ -197,6 +210,12 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 		return
 	}
 
+	// Link the reconciliation to the rendering of the commit by the
+	// hydration-controller, if it was traced.
+	if sc, ok := hydrate.DoneSpanContext(doneFilePath); ok {
+		tracing.Link(span, sc)
+	}
+
 	// rendering is done, starts to read the source or hydrated configs.
 	oldSyncDir := state.cache.source.syncDir
 	// `read` is called no matter what the trigger is.
@@ -204,7 +223,10 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 		commit:  gs.commit,
 		syncDir: syncDir,
 	}
-	if errs := read(ctx, p, trigger, state, ps); errs != nil {
+	readCtx, readSpan := tracing.StartSpan(ctx, "parse.read", gs.commit)
+	errs := read(readCtx, p, trigger, state, ps)
+	tracing.EndSpan(readSpan, errs)
+	if errs != nil {
 		state.invalidate(errs)
 		return
 	}
@@ -216,11 +238,17 @@ func run(ctx context.Context, p Parser, trigger string, state *reconcilerState)
 	//   * If all the former parse-apply-watch sequences for syncDir failed, the next retry will call the sequence;
 	//   * The retry logic tracks the number of reconciliation attempts failed with the same errors, and when
 	//     the next retry should happen. Calling the parse-apply-watch sequence here makes the retry logic meaningless.
+	// The exception is when the secret data referenced by the applied objects
+	// changed, in which case the objects are applied again.
 	if trigger == triggerReimport && oldSyncDir == newSyncDir {
-		return
+		if !p.options().secretReferencesChanged(ctx) {
+			return
+		}
+		klog.Infof("The secret data referenced by the applied objects changed")
+		state.cache.hasApplierResult = false
 	}
 
-	errs := parseAndUpdate(ctx, p, trigger, state)
+	errs = parseAndUpdate(ctx, p, trigger, state)
 	if errs != nil {
 		state.invalidate(errs)
 		return
@@ -239,6 +267,7 @@ func read(ctx context.Context, p Parser, trigger string, state *reconcilerState,
 	// read and parse the configs after rendering is done and there might have errors.
 	setRenderingStatusErr := p.setRenderingStatus(ctx, state.renderingStatus, hydrationStatus)
 	if setRenderingStatusErr == nil {
+		recordRenderingEvent(ctx, p, state.renderingStatus, hydrationStatus)
 		state.renderingStatus = hydrationStatus
 		state.syncingConditionLastUpdate = hydrationStatus.lastUpdate
 	}
@@ -258,6 +287,7 @@ func read(ctx context.Context, p Parser, trigger string, state *reconcilerState,
 	if state.needToSetSourceStatus(sourceStatus) {
 		setSourceStatusErr := p.setSourceStatus(ctx, sourceStatus)
 		if setSourceStatusErr == nil {
+			notifyStalled(p, sourceStatus.commit, sourceStatus.errs)
 			state.sourceStatus = sourceStatus
 			state.syncingConditionLastUpdate = sourceStatus.lastUpdate
 		}
@@ -311,12 +341,13 @@ func readFromSource(ctx context.Context, p Parser, trigger string, state *reconc
 	}
 
 	klog.Infof("New source changes (%s) detected, reset the cache", sourceState.syncDir.OSPath())
+	opts.events.SourceFetched(ctx, sourceState.commit)
 
 	// Reset the cache to make sure all the steps of a parse-apply-watch loop will run.
 	state.resetCache()
 
 	// Read all the files under state.syncDir
-	sourceStatus.errs = opts.readConfigFiles(&sourceState)
+	sourceStatus.errs = opts.readConfigFiles(ctx, &sourceState)
 	if sourceStatus.errs == nil {
 		// Set `state.cache.source` after `readConfigFiles` succeeded
 		state.cache.source = sourceState
@@ -330,8 +361,14 @@ func parseSource(ctx context.Context, p Parser, trigger string, state *reconcile
 		return nil
 	}
 
+	if state.cache.fileObjects == nil {
+		state.cache.fileObjects = &fileObjectCache{}
+	}
+
 	start := time.Now()
-	objs, sourceErrs := p.parseSource(ctx, state.cache.source)
+	parseCtx, span := tracing.StartSpan(ctx, "parse.parse", state.cache.source.commit)
+	objs, sourceErrs := p.parseSource(parseCtx, state.cache.source, state.cache.fileObjects)
+	tracing.EndSpan(span, sourceErrs)
 	metrics.RecordParserDuration(ctx, trigger, "parse", metrics.StatusTagKey(sourceErrs), start)
 	state.cache.setParserResult(objs, sourceErrs)
 
@@ -366,6 +403,7 @@ func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconc
 			// than `Status.Sync.Commit`.
 			return status.Append(sourceErrs, err)
 		}
+		notifyStalled(p, newSourceStatus.commit, newSourceStatus.errs)
 		state.sourceStatus = newSourceStatus
 		state.syncingConditionLastUpdate = newSourceStatus.lastUpdate
 	}
@@ -374,13 +412,21 @@ func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconc
 		return sourceErrs
 	}
 
+	updateChangeSummary(ctx, p, state)
+
+	if state.syncStatus.commit != state.cache.source.commit {
+		p.options().events.SyncStarted(ctx, state.cache.source.commit)
+	}
+
 	// Create a new context with its cancellation function.
 	ctxForUpdateSyncStatus, cancel := context.WithCancel(context.Background())
 
 	go updateSyncStatusPeriodically(ctxForUpdateSyncStatus, p, state)
 
 	start := time.Now()
-	syncErrs := p.options().Update(ctx, &state.cache)
+	updateCtx, span := tracing.StartSpan(ctx, "parse.update", state.cache.source.commit)
+	syncErrs := p.options().Update(updateCtx, &state.cache)
+	tracing.EndSpan(span, syncErrs)
 	metrics.RecordParserDuration(ctx, trigger, "update", metrics.StatusTagKey(syncErrs), start)
 
 	// This is to terminate `updateSyncStatusPeriodically`.
@@ -400,15 +446,21 @@ func parseAndUpdate(ctx context.Context, p Parser, trigger string, state *reconc
 func setSyncStatus(ctx context.Context, p Parser, state *reconcilerState, syncing bool, syncErrs status.MultiError) error {
 	// Update the RSync status, if necessary
 	newSyncStatus := syncStatus{
-		syncing:    syncing,
-		commit:     state.cache.source.commit,
-		errs:       syncErrs,
-		lastUpdate: metav1.Now(),
+		syncing:       syncing,
+		commit:        state.cache.source.commit,
+		errs:          syncErrs,
+		changeSummary: state.changeSummary,
+		adoptions:     p.options().adoptions(),
+		lastUpdate:    metav1.Now(),
 	}
 	if state.needToSetSyncStatus(newSyncStatus) {
 		if err := p.SetSyncStatus(ctx, newSyncStatus); err != nil {
 			return err
 		}
+		if !syncing {
+			recordSyncEvent(ctx, p, newSyncStatus)
+			notifySyncResult(p, newSyncStatus, len(state.cache.objsToApply))
+		}
 		state.syncStatus = newSyncStatus
 		state.syncingConditionLastUpdate = newSyncStatus.lastUpdate
 	}
@@ -422,6 +474,13 @@ func setSyncStatus(ctx context.Context, p Parser, state *reconcilerState, syncin
 			}
 		}
 	}
+	if len(conflictErrs) > 0 {
+		var errs status.MultiError
+		for _, conflictErr := range conflictErrs {
+			errs = status.Append(errs, conflictErr)
+		}
+		p.options().notifier.Conflict(newSyncStatus.commit, errs)
+	}
 	// Report conflict errors to the remote manager, if it's a RootSync.
 	if err := reportRootSyncConflicts(ctx, p.K8sClient(), conflictErrs); err != nil {
 		return errors.Wrapf(err, "failed to report remote conflicts")
@@ -429,6 +488,49 @@ func setSyncStatus(ctx context.Context, p Parser, state *reconcilerState, syncin
 	return nil
 }
 
+// recordRenderingEvent records an Event when the rendering of a commit
+// succeeded or failed, unless it was already recorded for the same status.
+func recordRenderingEvent(ctx context.Context, p Parser, oldStatus, newStatus renderingStatus) {
+	if oldStatus.commit == newStatus.commit && oldStatus.message == newStatus.message {
+		return
+	}
+	switch newStatus.message {
+	case RenderingSucceeded:
+		p.options().events.RenderingSucceeded(ctx, newStatus.commit)
+	case RenderingFailed:
+		p.options().events.RenderingFailed(ctx, newStatus.commit, newStatus.errs)
+		notifyStalled(p, newStatus.commit, newStatus.errs)
+	}
+}
+
+// recordSyncEvent records an Event when the sync of a commit completed or
+// failed.
+func recordSyncEvent(ctx context.Context, p Parser, newStatus syncStatus) {
+	if newStatus.errs == nil {
+		p.options().events.SyncCompleted(ctx, newStatus.commit)
+	} else {
+		p.options().events.SyncFailed(ctx, newStatus.commit, len(newStatus.errs.Errors()))
+	}
+}
+
+// notifySyncResult notifies that the sync of a commit succeeded or failed.
+// The notifier skips the results which were already notified.
+func notifySyncResult(p Parser, newStatus syncStatus, objectCount int) {
+	if newStatus.errs == nil {
+		p.options().notifier.SyncSucceeded(newStatus.commit, objectCount)
+	} else {
+		p.options().notifier.SyncFailed(newStatus.commit, newStatus.errs, objectCount)
+	}
+}
+
+// notifyStalled notifies that a commit cannot be synced because of blocking
+// source or rendering errors.
+func notifyStalled(p Parser, commit string, errs status.MultiError) {
+	if status.HasBlockingErrors(errs) {
+		p.options().notifier.Stalled(commit, errs)
+	}
+}
+
 // updateSyncStatusPeriodically update the sync status periodically until the
 // cancellation function of the context is called.
 func updateSyncStatusPeriodically(ctx context.Context, p Parser, state *reconcilerState) {
@@ -15,6 +15,7 @@
 package parse
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -26,8 +27,10 @@ import (
 	v1 "kpt.dev/configsync/pkg/api/configmanagement/v1"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/hydrate"
+	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/reconcilermanager"
 	"kpt.dev/configsync/pkg/status"
 )
@@ -78,7 +81,8 @@ type sourceState struct {
 // - if rendered is true, state.syncDir contains the hydrated files.
 // - if rendered is false, state.syncDir contains the source files.
 // readConfigFiles should be called after sourceState is populated.
-func (o *files) readConfigFiles(state *sourceState) status.Error {
+// Files excluded by .configsyncignore files are left out of state.files.
+func (o *files) readConfigFiles(ctx context.Context, state *sourceState) status.Error {
 	if state == nil || state.commit == "" || state.syncDir.OSPath() == "" {
 		return status.InternalError("sourceState is not populated yet")
 	}
@@ -90,12 +94,14 @@ func (o *files) readConfigFiles(state *sourceState) status.Error {
 		o.currentSyncDir = syncDir.OSPath()
 	}
 
-	var fileList []cmpath.Absolute
-	var err error
-	fileList, err = listFiles(syncDir, map[string]bool{".git": true})
+	fileList, ignored, err := listFiles(syncDir, map[string]bool{".git": true})
 	if err != nil {
 		return status.PathWrapError(errors.Wrap(err, "listing files in the configs directory"), syncDir.OSPath())
 	}
+	if ignored > 0 {
+		klog.V(4).Infof("Ignored %d paths listed in %s files under %s", ignored, filesystem.IgnoreFileName, syncDir.OSPath())
+	}
+	metrics.RecordIgnoredFiles(ctx, ignored)
 	state.files = fileList
 	return nil
 }
@@ -134,9 +140,14 @@ func (o *files) readHydratedDir(hydratedRoot cmpath.Absolute, link, reconciler s
 	return result, nil
 }
 
-// listFiles returns a list of all files in the specified directory.
-func listFiles(dir cmpath.Absolute, ignore map[string]bool) ([]cmpath.Absolute, error) {
+// listFiles returns a list of all files in the specified directory, skipping
+// the directories named in ignore and the paths excluded by .configsyncignore
+// files. It also returns the number of paths excluded by .configsyncignore
+// files.
+func listFiles(dir cmpath.Absolute, ignore map[string]bool) ([]cmpath.Absolute, int, error) {
 	var result []cmpath.Absolute
+	ignorer := filesystem.NewIgnorer(dir)
+	ignored := 0
 	err := filepath.Walk(dir.OSPath(),
 		func(path string, fi os.FileInfo, err error) error {
 			if err != nil {
@@ -146,6 +157,19 @@ func listFiles(dir cmpath.Absolute, ignore map[string]bool) ([]cmpath.Absolute,
 				if _, contains := ignore[fi.Name()]; contains {
 					return filepath.SkipDir
 				}
+			}
+			skip, err := ignorer.Ignored(path, fi.IsDir())
+			if err != nil {
+				return err
+			}
+			if skip {
+				ignored++
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if fi.IsDir() {
 				return nil
 			}
 			abs, err := cmpath.AbsoluteOS(path)
@@ -159,7 +183,7 @@ func listFiles(dir cmpath.Absolute, ignore map[string]bool) ([]cmpath.Absolute,
 			result = append(result, abs)
 			return nil
 		})
-	return result, err
+	return result, ignored, err
 }
 
 // hydratedError returns the error details from the error file generated by the hydration controller.
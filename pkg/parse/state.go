@@ -18,8 +18,10 @@ import (
 	"math"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/status"
 )
 
@@ -50,14 +52,18 @@ func (rs renderingStatus) equal(other renderingStatus) bool {
 }
 
 type syncStatus struct {
-	syncing    bool
-	commit     string
-	errs       status.MultiError
-	lastUpdate metav1.Time
+	syncing       bool
+	commit        string
+	errs          status.MultiError
+	changeSummary *v1beta1.ChangeSummary
+	adoptions     []v1beta1.Adoption
+	lastUpdate    metav1.Time
 }
 
 func (gs syncStatus) equal(other syncStatus) bool {
-	return gs.syncing == other.syncing && gs.commit == other.commit && status.DeepEqual(gs.errs, other.errs)
+	return gs.syncing == other.syncing && gs.commit == other.commit && status.DeepEqual(gs.errs, other.errs) &&
+		equality.Semantic.DeepEqual(gs.changeSummary, other.changeSummary) &&
+		equality.Semantic.DeepEqual(gs.adoptions, other.adoptions)
 }
 
 type reconcilerState struct {
@@ -78,6 +84,11 @@ type reconcilerState struct {
 
 	// cache tracks the progress made by the reconciler for a source commit.
 	cache cacheForCommit
+
+	// changeSummary summarizes the changes to the declared resources made by
+	// the latest commit. It is not part of the cache, because retries and
+	// resyncs of the same commit must not replace it with an empty summary.
+	changeSummary *v1beta1.ChangeSummary
 }
 
 func (s *reconcilerState) checkpoint() {
@@ -143,7 +154,9 @@ func calculateNextRetryTime(retries int) time.Time {
 //
 // resetCache is called when a new source commit is detected.
 func (s *reconcilerState) resetCache() {
+	fileObjects := s.cache.fileObjects
 	s.cache = cacheForCommit{}
+	s.cache.fileObjects = fileObjects
 }
 
 // resetAllButSourceState resets the whole cache except for the cached sourceState.
@@ -153,8 +166,14 @@ func (s *reconcilerState) resetCache() {
 //   - one of the watchers noticed a management conflict.
 func (s *reconcilerState) resetAllButSourceState() {
 	source := s.cache.source
+	fileObjects := s.cache.fileObjects
 	s.cache = cacheForCommit{}
 	s.cache.source = source
+	if fileObjects != nil {
+		// Validate all the objects again on the next parse.
+		fileObjects.validated = nil
+	}
+	s.cache.fileObjects = fileObjects
 }
 
 // needToSetSourceStatus returns true if `p.setSourceStatus` should be called.
@@ -22,6 +22,7 @@ import (
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
+	configsyncv1beta1 "kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/importer/filesystem"
@@ -55,6 +56,16 @@ func (u *updater) managementConflict() bool {
 	return u.remediator.ManagementConflict()
 }
 
+func (u *updater) secretReferencesChanged(ctx context.Context) bool {
+	return u.applier.SecretReferencesChanged(ctx)
+}
+
+// adoptions returns the most recent pre-existing objects adopted by the
+// applier, oldest first.
+func (u *updater) adoptions() []configsyncv1beta1.Adoption {
+	return u.applier.Adoptions()
+}
+
 // Errors returns the latest known set of errors from the updater.
 // This method is safe to call while Update is running.
 func (u *updater) Errors() status.MultiError {
@@ -117,6 +128,10 @@ func (u *updater) Update(ctx context.Context, cache *cacheForCommit) status.Mult
 
 	objs := filesystem.AsCoreObjects(cache.objsToApply)
 
+	// Keep the Remediator from deleting the objects whose prune is blocked by
+	// the prune budget, before they are removed from the declared resources.
+	u.resources.Retain(u.applier.PruneBlocked(ctx, objs, cache.source.commit))
+
 	// Update the declared resources so that the Remediator immediately
 	// starts enforcing the updated state.
 	if !cache.resourceDeclSetUpdated {
@@ -141,7 +156,7 @@ func (u *updater) Update(ctx context.Context, cache *cacheForCommit) status.Mult
 		// there were no previous applier errors
 	} else {
 		applyStart := time.Now()
-		gvks, applyErrs = u.applier.Apply(ctx, objs)
+		gvks, applyErrs = u.applier.Apply(ctx, objs, cache.source.commit)
 		metrics.RecordApplyDuration(ctx, metrics.StatusTagKey(applyErrs), cache.source.commit, applyStart)
 		if applyErrs != nil {
 			klog.Warningf("Failed to apply declared resources: %v", applyErrs)
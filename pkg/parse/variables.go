@@ -0,0 +1,52 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VariablesConfigMapName is the name of the ConfigMap in the
+// config-management-system namespace whose data defines the `vars.` cluster
+// variables substituted in the declared objects.
+const VariablesConfigMapName = "cluster-variables"
+
+// readVariables returns the data of the cluster variables ConfigMap. No
+// variables are defined if the ConfigMap does not exist.
+//
+// Both the root and the namespace reconcilers are allowed to read the
+// ConfigMap, the latter through the configsync.gke.io:ns-reconciler Role in
+// the config-management-system namespace, so a Forbidden error is returned
+// like any other error, rather than syncing the objects without their
+// variables substituted.
+func readVariables(ctx context.Context, c client.Client) (map[string]string, status.Error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: configmanagement.ControllerNamespace, Name: VariablesConfigMapName}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("No cluster variables are read from ConfigMap %s: %v", key, err)
+			return nil, nil
+		}
+		return nil, status.APIServerError(err, "failed to get the cluster variables ConfigMap")
+	}
+	return cm.Data, nil
+}
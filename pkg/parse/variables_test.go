@@ -0,0 +1,60 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/core"
+	syncertest "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReadVariables(t *testing.T) {
+	cm := fake.ConfigMapObject(core.Namespace(configmanagement.ControllerNamespace), core.Name(VariablesConfigMapName))
+	cm.Data = map[string]string{"region": "us-east1"}
+
+	testCases := []struct {
+		name   string
+		client client.Client
+		want   map[string]string
+	}{
+		{
+			name:   "ConfigMap found",
+			client: syncertest.NewClient(t, core.Scheme, cm),
+			want:   map[string]string{"region": "us-east1"},
+		},
+		{
+			name:   "ConfigMap not found",
+			client: syncertest.NewClient(t, core.Scheme),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := readVariables(context.Background(), tc.client)
+			if err != nil {
+				t.Fatalf("readVariables() got error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protection
+
+import (
+	"context"
+	"sync"
+
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Protector decides which managed objects are protected from pruning and
+// deletion. The rules are replaced with Refresh, so the same Protector can be
+// shared by the applier and the remediator.
+//
+// A nil Protector protects the objects matched by the DefaultRules.
+type Protector struct {
+	mux   sync.RWMutex
+	rules Rules
+}
+
+// NewProtector returns a Protector with the DefaultRules.
+func NewProtector() *Protector {
+	return &Protector{rules: DefaultRules()}
+}
+
+// Refresh replaces the rules with the rules of the protected resources
+// ConfigMap. The invalid rules are returned as errors, and the other rules are
+// still used. The current rules are kept if the ConfigMap cannot be read.
+func (p *Protector) Refresh(ctx context.Context, c client.Reader) status.MultiError {
+	rules, errs := ReadRules(ctx, c)
+	if rules != nil {
+		p.SetRules(rules)
+	}
+	return errs
+}
+
+// SetRules replaces the rules.
+func (p *Protector) SetRules(rules Rules) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.rules = rules
+}
+
+// Rules returns the current rules.
+func (p *Protector) Rules() Rules {
+	if p == nil {
+		return DefaultRules()
+	}
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.rules
+}
+
+// Protects returns the name of the rule which protects the given object, and
+// false if the object is not protected.
+func (p *Protector) Protects(obj client.Object) (string, bool) {
+	return p.Rules().Match(obj)
+}
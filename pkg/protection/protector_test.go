@@ -0,0 +1,101 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protection
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/core"
+	syncertestfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestProtector_Refresh(t *testing.T) {
+	cm := fake.ConfigMapObject(core.Name(ConfigMapName), core.Namespace(configmanagement.ControllerNamespace))
+	cm.Data = map[string]string{
+		"bookstore": `namespace: bookstore`,
+	}
+	c := syncertestfake.NewClient(t, runtime.NewScheme(), cm)
+	ns := fake.NamespaceObject("bookstore")
+
+	p := NewProtector()
+	if _, protected := p.Protects(ns); protected {
+		t.Fatal("got protected before Refresh, want not protected")
+	}
+	if errs := p.Refresh(context.Background(), c); errs != nil {
+		t.Fatal(errs)
+	}
+	if rule, protected := p.Protects(ns); !protected || rule != "bookstore" {
+		t.Fatalf("got rule %q (protected %t) after Refresh, want rule %q", rule, protected, "bookstore")
+	}
+
+	// Only the default rules are used once the ConfigMap is deleted.
+	if err := c.Delete(context.Background(), cm); err != nil {
+		t.Fatal(err)
+	}
+	if errs := p.Refresh(context.Background(), c); errs != nil {
+		t.Fatal(errs)
+	}
+	if _, protected := p.Protects(ns); protected {
+		t.Error("got protected after the ConfigMap is deleted, want not protected")
+	}
+	if _, protected := p.Protects(fake.NamespaceObject("kube-system")); !protected {
+		t.Error("got kube-system not protected, want protected by the default rules")
+	}
+}
+
+// forbiddenClient is forbidden to get any object.
+type forbiddenClient struct {
+	client.Client
+}
+
+func (c forbiddenClient) Get(_ context.Context, key client.ObjectKey, _ client.Object) error {
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, key.Name, nil)
+}
+
+func TestProtector_RefreshForbidden(t *testing.T) {
+	cm := fake.ConfigMapObject(core.Name(ConfigMapName), core.Namespace(configmanagement.ControllerNamespace))
+	cm.Data = map[string]string{
+		"bookstore": `namespace: bookstore`,
+	}
+	c := syncertestfake.NewClient(t, runtime.NewScheme(), cm)
+	ns := fake.NamespaceObject("bookstore")
+
+	p := NewProtector()
+	if errs := p.Refresh(context.Background(), c); errs != nil {
+		t.Fatal(errs)
+	}
+
+	// The rules which can no longer be read are still used.
+	if errs := p.Refresh(context.Background(), forbiddenClient{c}); errs == nil {
+		t.Fatal("got no error when forbidden to read the ConfigMap, want error")
+	}
+	if rule, protected := p.Protects(ns); !protected || rule != "bookstore" {
+		t.Errorf("got rule %q (protected %t) after a forbidden Refresh, want rule %q", rule, protected, "bookstore")
+	}
+}
+
+func TestProtector_Nil(t *testing.T) {
+	var p *Protector
+	if rule, protected := p.Protects(fake.NamespaceObject("default")); !protected || rule != "default:default" {
+		t.Errorf("got rule %q (protected %t), want rule %q", rule, protected, "default:default")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protection defines the managed objects which Config Sync never
+// prunes or deletes. A protected object is unmanaged instead, by removing it
+// from the inventory and removing its Config Sync metadata.
+package protection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configmanagement"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/syncer/differ"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigMapName is the name of the ConfigMap in the config-management-system
+// namespace which defines the protection rules. The ConfigMap may be declared
+// in the root repository or created directly on the cluster.
+//
+// Each key of the ConfigMap is the name of a rule, and each value is a YAML
+// Rule. The rules are added to the DefaultRules.
+const ConfigMapName = "protected-resources"
+
+// InvalidProtectionRuleErrorCode is the error code for a protection rule which
+// cannot be parsed.
+const InvalidProtectionRuleErrorCode = "2019"
+
+var invalidProtectionRuleErrorBuilder = status.NewErrorBuilder(InvalidProtectionRuleErrorCode)
+
+// InvalidProtectionRuleError reports that the protection rule defined under
+// the given key of the protected resources ConfigMap is invalid. The rule does
+// not protect any object until it is fixed.
+func InvalidProtectionRuleError(key string, err error) status.Error {
+	return invalidProtectionRuleErrorBuilder.Wrap(err).Sprintf(
+		"invalid protection rule %q in the ConfigMap %s/%s; the rule does not protect any object",
+		key, configmanagement.ControllerNamespace, ConfigMapName).Build()
+}
+
+// defaultRulePrefix prefixes the names of the DefaultRules. The prefix is not
+// valid in a ConfigMap key, so the rules of the ConfigMap cannot override them.
+const defaultRulePrefix = "default:"
+
+// Rule selects the protected objects. An empty field matches every object, so
+// a rule with only a Kind protects every object of that kind. At least one of
+// Kind, Namespace, Name or LabelSelector must be set.
+type Rule struct {
+	// Group is the API group of the protected objects, empty for the core
+	// group. It is only matched if Kind is set.
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the protected objects.
+	Kind string `json:"kind,omitempty"`
+	// Namespace is the namespace of the protected objects. Deleting a
+	// Namespace deletes all the objects in it, so the Namespace itself is also
+	// protected, whatever the other fields of the rule.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the protected objects.
+	Name string `json:"name,omitempty"`
+	// LabelSelector selects the protected objects by the labels of the
+	// objects on the cluster.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// validate returns an error if the rule would match every object, or if its
+// label selector is invalid.
+func (r Rule) validate() error {
+	if r.Kind == "" && r.Namespace == "" && r.Name == "" && r.LabelSelector == nil {
+		return fmt.Errorf("at least one of kind, namespace, name or labelSelector must be set")
+	}
+	if r.Group != "" && r.Kind == "" {
+		return fmt.Errorf("the kind must be set with the group %q", r.Group)
+	}
+	if r.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.LabelSelector); err != nil {
+			return fmt.Errorf("invalid labelSelector: %w", err)
+		}
+	}
+	return nil
+}
+
+// protectsNamespace returns true if the given object identity is the Namespace
+// of the objects protected by the rule.
+func (r Rule) protectsNamespace(gk schema.GroupKind, name string) bool {
+	return r.Namespace != "" && r.Namespace == name && gk == kinds.Namespace().GroupKind()
+}
+
+// selectsID returns true if the rule matches the given object identity,
+// ignoring its label selector.
+func (r Rule) selectsID(gk schema.GroupKind, namespace, name string) bool {
+	if r.protectsNamespace(gk, name) {
+		return true
+	}
+	if r.Kind != "" && (r.Kind != gk.Kind || r.Group != gk.Group) {
+		return false
+	}
+	if r.Namespace != "" && r.Namespace != namespace {
+		return false
+	}
+	if r.Name != "" && r.Name != name {
+		return false
+	}
+	return true
+}
+
+// matches returns true if the rule matches the given object.
+func (r Rule) matches(obj client.Object) bool {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if r.protectsNamespace(gk, obj.GetName()) {
+		return true
+	}
+	if !r.selectsID(gk, obj.GetNamespace(), obj.GetName()) {
+		return false
+	}
+	if r.LabelSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(r.LabelSelector)
+	if err != nil {
+		// Invalid selectors are rejected when the rules are parsed.
+		return false
+	}
+	return selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// Rules maps the names of the protection rules to the rules.
+type Rules map[string]Rule
+
+// DefaultRules returns the rules which protect the special namespaces, like
+// kube-system, which Config Sync never removes from a cluster.
+func DefaultRules() Rules {
+	rules := make(Rules, len(differ.SpecialNamespaces))
+	for ns := range differ.SpecialNamespaces {
+		rules[defaultRulePrefix+ns] = Rule{
+			Kind: kinds.Namespace().Kind,
+			Name: ns,
+		}
+	}
+	return rules
+}
+
+// ParseRules parses the data of the protected resources ConfigMap, and returns
+// its rules with the DefaultRules. The invalid rules are reported as errors and
+// left out of the returned Rules.
+func ParseRules(data map[string]string) (Rules, status.MultiError) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rules := DefaultRules()
+	var errs status.MultiError
+	for _, k := range keys {
+		r := Rule{}
+		if err := yaml.UnmarshalStrict([]byte(data[k]), &r); err != nil {
+			errs = status.Append(errs, InvalidProtectionRuleError(k, err))
+			continue
+		}
+		if err := r.validate(); err != nil {
+			errs = status.Append(errs, InvalidProtectionRuleError(k, err))
+			continue
+		}
+		rules[k] = r
+	}
+	return rules, errs
+}
+
+// ReadRules returns the rules of the protected resources ConfigMap with the
+// DefaultRules. Only the DefaultRules are returned if the ConfigMap does not
+// exist. No rules are returned if the ConfigMap cannot be read, including when
+// the caller is forbidden to read it, since the rules it declares are unknown.
+func ReadRules(ctx context.Context, c client.Reader) (Rules, status.MultiError) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: configmanagement.ControllerNamespace, Name: ConfigMapName}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("No protection rules are read from ConfigMap %s: %v", key, err)
+			return DefaultRules(), nil
+		}
+		return nil, status.APIServerError(err, "failed to get the protected resources ConfigMap")
+	}
+	return ParseRules(cm.Data)
+}
+
+// SelectsID returns true if a rule may match the object with the given
+// identity. The object is only protected if its labels also match the label
+// selector of the rule, which callers check with Match on the live object.
+func (r Rules) SelectsID(gk schema.GroupKind, namespace, name string) bool {
+	for _, rule := range r {
+		if rule.selectsID(gk, namespace, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the name of the first rule, in name order, which protects the
+// given object. It returns false if the object is not protected.
+func (r Rules) Match(obj client.Object) (string, bool) {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if r[name].matches(obj) {
+			return name, true
+		}
+	}
+	return "", false
+}
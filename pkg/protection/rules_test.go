@@ -0,0 +1,249 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protection
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// withDefaults returns the given rules with the DefaultRules.
+func withDefaults(rules Rules) Rules {
+	result := DefaultRules()
+	for name, r := range rules {
+		result[name] = r
+	}
+	return result
+}
+
+func TestParseRules(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      map[string]string
+		want      Rules
+		wantError bool
+	}{
+		{
+			name: "no rules",
+			want: DefaultRules(),
+		},
+		{
+			name: "valid rules",
+			data: map[string]string{
+				"crds": `
+group: apiextensions.k8s.io
+kind: CustomResourceDefinition
+`,
+				"keep": `
+labelSelector:
+  matchLabels:
+    example.com/keep: "true"
+`,
+			},
+			want: withDefaults(Rules{
+				"crds": {Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+				"keep": {LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/keep": "true"}}},
+			}),
+		},
+		{
+			name: "empty rule",
+			data: map[string]string{
+				"all":   `{}`,
+				"roles": `kind: Role`,
+			},
+			want: withDefaults(Rules{
+				"roles": {Kind: "Role"},
+			}),
+			wantError: true,
+		},
+		{
+			name: "group without kind",
+			data: map[string]string{
+				"rbac": `group: rbac.authorization.k8s.io`,
+			},
+			want:      DefaultRules(),
+			wantError: true,
+		},
+		{
+			name: "unknown field",
+			data: map[string]string{
+				"db": `resource: statefulsets`,
+			},
+			want:      DefaultRules(),
+			wantError: true,
+		},
+		{
+			name: "invalid label selector",
+			data: map[string]string{
+				"keep": `
+labelSelector:
+  matchExpressions:
+  - key: example.com/keep
+    operator: Equals
+`,
+			},
+			want:      DefaultRules(),
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, errs := ParseRules(tc.data)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+			if tc.wantError {
+				if errs == nil {
+					t.Fatal("got no error, want error")
+				}
+				for _, err := range errs.Errors() {
+					if err.Code() != InvalidProtectionRuleErrorCode {
+						t.Errorf("got error %v, want error code %s", err, InvalidProtectionRuleErrorCode)
+					}
+				}
+			} else if errs != nil {
+				t.Errorf("got error %v, want nil", errs)
+			}
+		})
+	}
+}
+
+func TestRules_Match(t *testing.T) {
+	rules := withDefaults(Rules{
+		"crds": {Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+		"db":   {Kind: "StatefulSet", Group: "apps", Namespace: "payments", Name: "db"},
+		"keep": {LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/keep": "true"}}},
+	})
+
+	testCases := []struct {
+		name     string
+		obj      client.Object
+		wantRule string
+	}{
+		{
+			name:     "special namespace",
+			obj:      fake.NamespaceObject("kube-system"),
+			wantRule: "default:kube-system",
+		},
+		{
+			name: "other namespace",
+			obj:  fake.NamespaceObject("bookstore"),
+		},
+		{
+			name:     "kind",
+			obj:      fake.CustomResourceDefinitionV1Object(core.Name("widgets.example.com")),
+			wantRule: "crds",
+		},
+		{
+			name:     "namespace and name",
+			obj:      fake.UnstructuredObject(kinds.StatefulSet(), core.Namespace("payments"), core.Name("db")),
+			wantRule: "db",
+		},
+		{
+			name: "other name",
+			obj:  fake.UnstructuredObject(kinds.StatefulSet(), core.Namespace("payments"), core.Name("cache")),
+		},
+		{
+			name:     "namespace of protected objects",
+			obj:      fake.NamespaceObject("payments"),
+			wantRule: "db",
+		},
+		{
+			name:     "labels",
+			obj:      fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("config"), core.Label("example.com/keep", "true")),
+			wantRule: "keep",
+		},
+		{
+			name: "other labels",
+			obj:  fake.ConfigMapObject(core.Namespace("bookstore"), core.Name("config"), core.Label("example.com/keep", "false")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, protected := rules.Match(tc.obj)
+			if protected != (tc.wantRule != "") {
+				t.Fatalf("got protected %t, want %t", protected, tc.wantRule != "")
+			}
+			if got != tc.wantRule {
+				t.Errorf("got rule %q, want %q", got, tc.wantRule)
+			}
+		})
+	}
+}
+
+func TestRules_SelectsID(t *testing.T) {
+	rules := Rules{
+		"db":   {Kind: "StatefulSet", Group: "apps", Namespace: "payments", Name: "db"},
+		"keep": {Namespace: "bookstore", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/keep": "true"}}},
+	}
+
+	testCases := []struct {
+		name      string
+		gk        schema.GroupKind
+		namespace string
+		objName   string
+		want      bool
+	}{
+		{
+			name:      "selected by identity",
+			gk:        schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+			namespace: "payments",
+			objName:   "db",
+			want:      true,
+		},
+		{
+			name:      "selected by identity, ignoring labels",
+			gk:        kinds.ConfigMap().GroupKind(),
+			namespace: "bookstore",
+			objName:   "config",
+			want:      true,
+		},
+		{
+			name:    "namespace of protected objects",
+			gk:      kinds.Namespace().GroupKind(),
+			objName: "bookstore",
+			want:    true,
+		},
+		{
+			name:      "other group",
+			gk:        schema.GroupKind{Group: "example.com", Kind: "StatefulSet"},
+			namespace: "payments",
+			objName:   "db",
+		},
+		{
+			name:      "other namespace",
+			gk:        kinds.ConfigMap().GroupKind(),
+			namespace: "payments",
+			objName:   "config",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := rules.SelectsID(tc.gk, tc.namespace, tc.objName); got != tc.want {
+				t.Errorf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -48,6 +48,10 @@ import (
 // Use `configsync.gke.io/deletion-propagation-policy: Orphan` or remove the
 // annotation to disable deletion propagation (default behavior).
 //
+// Use `configsync.gke.io/deletion-propagation-policy: DryRun` to list the
+// managed objects which deletion propagation would delete and orphan in the
+// status, without enabling it.
+//
 // The `configsync.gke.io/reconciler` finalizer is used to block deletion until
 // all the managed objects can be deleted.
 type Controller struct {
@@ -143,6 +147,7 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 // reconcileFinalizer adds or removes the `configsync.gke.io/reconciler`
 // finalizer, depending on the existance and value of the
 // `configsync.gke.io/deletion-propagation-policy` annotation.
+// In dry-run mode, it also updates the deletion preview in the status.
 func (c *Controller) reconcileFinalizer(ctx context.Context, obj client.Object) error {
 	policyStr, found := obj.GetAnnotations()[metadata.DeletionPropagationPolicyAnnotationKey]
 	policy := metadata.DeletionPropagationPolicy(policyStr)
@@ -159,10 +164,22 @@ func (c *Controller) reconcileFinalizer(ctx context.Context, obj client.Object)
 		if _, err := c.Finalizer.RemoveFinalizer(ctx, obj); err != nil {
 			return err
 		}
+	case metadata.DeletionPropagationPolicyDryRun:
+		if _, err := c.Finalizer.RemoveFinalizer(ctx, obj); err != nil {
+			return err
+		}
+		if _, err := c.Finalizer.UpdateDeletionPreview(ctx, obj, true); err != nil {
+			return err
+		}
+		return nil
 	default:
 		klog.Warningf("%T %s has an invalid value for the annotation %q: %q",
 			obj, client.ObjectKeyFromObject(obj), metadata.DeletionPropagationPolicyAnnotationKey, policy)
 		// User error. Retry won't help, so don't return the error.
 	}
+	// The deletion preview is only kept up to date in dry-run mode.
+	if _, err := c.Finalizer.UpdateDeletionPreview(ctx, obj, false); err != nil {
+		return err
+	}
 	return nil
 }
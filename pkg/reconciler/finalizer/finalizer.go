@@ -18,9 +18,12 @@ import (
 	"context"
 	"fmt"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -30,6 +33,7 @@ type Finalizer interface {
 	Finalize(ctx context.Context, syncObj client.Object) error
 	AddFinalizer(ctx context.Context, syncObj client.Object) (bool, error)
 	RemoveFinalizer(ctx context.Context, syncObj client.Object) (bool, error)
+	UpdateDeletionPreview(ctx context.Context, syncObj client.Object, enabled bool) (bool, error)
 }
 
 // New constructs a new RootSyncFinalizer or RepoSyncFinalizer, depending on the
@@ -65,6 +69,47 @@ func removeFinalizer(syncObj client.Object) bool {
 	return controllerutil.RemoveFinalizer(syncObj, metadata.ReconcilerFinalizer)
 }
 
+// maxDeletionPreviewResources is the maximum number of resources listed for
+// each action in a DeletionPreview. The counts are not capped.
+const maxDeletionPreviewResources = 100
+
+// toDeletionPreview converts a DestroyPlan into its status representation,
+// capping the lists of resources.
+func toDeletionPreview(plan applier.DestroyPlan) *v1beta1.DeletionPreview {
+	preview := &v1beta1.DeletionPreview{
+		DeleteCount: len(plan.Delete),
+		OrphanCount: len(plan.Orphan),
+	}
+	var truncated bool
+	preview.Delete, truncated = toResourceRefs(plan.Delete)
+	preview.Truncated = preview.Truncated || truncated
+	preview.Orphan, truncated = toResourceRefs(plan.Orphan)
+	preview.Truncated = preview.Truncated || truncated
+	return preview
+}
+
+func toResourceRefs(objs []client.Object) ([]v1beta1.ResourceRef, bool) {
+	truncated := len(objs) > maxDeletionPreviewResources
+	if truncated {
+		objs = objs[:maxDeletionPreviewResources]
+	}
+	var refs []v1beta1.ResourceRef
+	for _, obj := range objs {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		refs = append(refs, v1beta1.ResourceRef{
+			SourcePath: status.GetSourceAnnotation(obj),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+			GVK: metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+		})
+	}
+	return refs, truncated
+}
+
 func objSummary(obj client.Object) string {
 	return fmt.Sprintf("%T %s %s/%s",
 		obj, obj.GetObjectKind().GroupVersionKind(),
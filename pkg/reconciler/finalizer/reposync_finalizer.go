@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
@@ -131,6 +132,46 @@ func (f *RepoSyncFinalizer) RemoveFinalizer(ctx context.Context, syncObj client.
 	return updated, nil
 }
 
+// UpdateDeletionPreview sets `.status.deletionPreview` to the managed objects
+// which deletion propagation would delete and orphan, if enabled is true, or
+// removes it otherwise.
+//
+// The specified syncObj must be of type `*v1beta1.RepoSync`.
+func (f *RepoSyncFinalizer) UpdateDeletionPreview(ctx context.Context, syncObj client.Object, enabled bool) (bool, error) {
+	rs, ok := syncObj.(*v1beta1.RepoSync)
+	if !ok {
+		return false, errors.Errorf("invalid syncObj type: expected *v1beta1.RepoSync, but got %T", syncObj)
+	}
+	if !enabled && rs.Status.DeletionPreview == nil {
+		// Already removed. No change necessary.
+		return false, nil
+	}
+	var preview *v1beta1.DeletionPreview
+	if enabled {
+		plan, errs := f.Destroyer.PlanDestroy(ctx, applier.NewDestroyOptions(rs.Spec.DeletionPropagation))
+		if errs != nil {
+			return false, errors.Wrap(errs, "planning deletion of managed objects")
+		}
+		preview = toDeletionPreview(plan)
+	}
+	updated, err := mutate.Status(ctx, f.Client, rs, func() error {
+		if equality.Semantic.DeepEqual(rs.Status.DeletionPreview, preview) {
+			return &mutate.NoUpdateError{}
+		}
+		rs.Status.DeletionPreview = preview
+		return nil
+	})
+	if err != nil {
+		return updated, errors.Wrapf(err, "failed to update deletion preview")
+	}
+	if updated {
+		klog.Info("Deletion preview update successful")
+	} else {
+		klog.V(5).Info("Deletion preview update skipped: already up to date")
+	}
+	return updated, nil
+}
+
 // setFinalizingCondition sets the ReconcilerFinalizing condition on the
 // specified object.
 func (f *RepoSyncFinalizer) setFinalizingCondition(ctx context.Context, syncObj *v1beta1.RepoSync) (bool, error) {
@@ -176,7 +217,7 @@ func (f *RepoSyncFinalizer) removeFinalizingCondition(ctx context.Context, syncO
 // deleteManagedObjects uses the destroyer to delete managed objects and then
 // updates the ReconcilerFinalizerFailure condition on the specified object.
 func (f *RepoSyncFinalizer) deleteManagedObjects(ctx context.Context, syncObj *v1beta1.RepoSync) error {
-	destroyErrs := f.Destroyer.Destroy(ctx)
+	destroyErrs := f.Destroyer.Destroy(ctx, applier.NewDestroyOptions(syncObj.Spec.DeletionPropagation))
 	// Update the FinalizerFailure condition whether the destroy succeeded or failed
 	if _, updateErr := f.updateFailureCondition(ctx, syncObj, destroyErrs); updateErr != nil {
 		updateErr = errors.Wrap(updateErr, "updating FinalizerFailure condition")
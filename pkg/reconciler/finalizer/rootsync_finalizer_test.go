@@ -586,6 +586,138 @@ func TestRootSyncRemoveFinalizer(t *testing.T) {
 	}
 }
 
+func TestRootSyncUpdateDeletionPreview(t *testing.T) {
+	rootSync1 := yamlToTypedObject(t, rootSync1Yaml).(*v1beta1.RootSync)
+
+	deployment1 := newDeploymentWithSource("deployment-1", "example", "namespaces/example/deployment-1.yaml")
+	deployment2 := newDeploymentWithSource("deployment-2", "example", "")
+	plan := applier.DestroyPlan{
+		Delete: []client.Object{deployment1},
+		Orphan: []client.Object{deployment2},
+	}
+	preview := &v1beta1.DeletionPreview{
+		DeleteCount: 1,
+		OrphanCount: 1,
+		Delete: []v1beta1.ResourceRef{{
+			SourcePath: "namespaces/example/deployment-1.yaml",
+			Name:       "deployment-1",
+			Namespace:  "example",
+			GVK:        metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		}},
+		Orphan: []v1beta1.ResourceRef{{
+			Name:      "deployment-2",
+			Namespace: "example",
+			GVK:       metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		}},
+	}
+
+	testCases := []struct {
+		name            string
+		rsync           *v1beta1.RootSync
+		enabled         bool
+		destroyErrs     status.MultiError
+		expectedError   error
+		expectedUpdated bool
+		expectedPreview *v1beta1.DeletionPreview
+	}{
+		{
+			name:            "add preview",
+			rsync:           rootSync1.DeepCopy(),
+			enabled:         true,
+			expectedUpdated: true,
+			expectedPreview: preview,
+		},
+		{
+			name: "preview unchanged",
+			rsync: func() *v1beta1.RootSync {
+				obj := rootSync1.DeepCopy()
+				obj.Status.DeletionPreview = preview.DeepCopy()
+				return obj
+			}(),
+			enabled:         true,
+			expectedUpdated: false,
+			expectedPreview: preview,
+		},
+		{
+			name: "remove preview",
+			rsync: func() *v1beta1.RootSync {
+				obj := rootSync1.DeepCopy()
+				obj.Status.DeletionPreview = preview.DeepCopy()
+				return obj
+			}(),
+			enabled:         false,
+			expectedUpdated: true,
+			expectedPreview: nil,
+		},
+		{
+			name:            "preview already removed",
+			rsync:           rootSync1.DeepCopy(),
+			enabled:         false,
+			expectedUpdated: false,
+			expectedPreview: nil,
+		},
+		{
+			name:            "plan error",
+			rsync:           rootSync1.DeepCopy(),
+			enabled:         true,
+			destroyErrs:     status.InternalError("plan failed"),
+			expectedError:   errors.Wrap(status.InternalError("plan failed"), "planning deletion of managed objects"),
+			expectedUpdated: false,
+			expectedPreview: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClient(t, scheme, tc.rsync)
+			ctx := context.Background()
+
+			fakeDestroyer := newFakeDestroyer(tc.destroyErrs, nil)
+			fakeDestroyer.plan = plan
+			finalizer := &RootSyncFinalizer{
+				Destroyer: fakeDestroyer,
+				Client:    fakeClient,
+			}
+
+			updated, err := finalizer.UpdateDeletionPreview(ctx, tc.rsync, tc.enabled)
+			if tc.expectedError != nil && err != nil {
+				assert.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				testutil.AssertEqual(t, tc.expectedError, err)
+			}
+			assert.Equal(t, tc.expectedUpdated, updated)
+
+			rs := &v1beta1.RootSync{}
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(tc.rsync), rs))
+			testutil.AssertEqual(t, tc.expectedPreview, rs.Status.DeletionPreview)
+		})
+	}
+}
+
+func TestToDeletionPreviewTruncated(t *testing.T) {
+	var objs []client.Object
+	for i := 0; i < maxDeletionPreviewResources+1; i++ {
+		objs = append(objs, newDeploymentWithSource(fmt.Sprintf("deployment-%d", i), "example", ""))
+	}
+	preview := toDeletionPreview(applier.DestroyPlan{Delete: objs})
+	assert.Equal(t, maxDeletionPreviewResources+1, preview.DeleteCount)
+	assert.Equal(t, 0, preview.OrphanCount)
+	assert.Len(t, preview.Delete, maxDeletionPreviewResources)
+	assert.Empty(t, preview.Orphan)
+	assert.True(t, preview.Truncated)
+}
+
+func newDeploymentWithSource(name, namespace, sourcePath string) client.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(kinds.Deployment())
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	if sourcePath != "" {
+		core.SetAnnotation(obj, metadata.SourcePathAnnotationKey, sourcePath)
+	}
+	return obj
+}
+
 func yamlToTypedObject(t *testing.T, yml string) client.Object {
 	uObj := &unstructured.Unstructured{}
 	_, _, err := decoder.Decode([]byte(yml), nil, uObj)
@@ -605,6 +737,7 @@ func yamlToTypedObject(t *testing.T, yml string) client.Object {
 
 type fakeDestroyer struct {
 	errs        status.MultiError
+	plan        applier.DestroyPlan
 	destroyFunc func(context.Context) status.MultiError
 }
 
@@ -617,13 +750,17 @@ func newFakeDestroyer(errs status.MultiError, destroyFunc func(context.Context)
 	}
 }
 
-func (d *fakeDestroyer) Destroy(ctx context.Context) status.MultiError {
+func (d *fakeDestroyer) Destroy(ctx context.Context, _ applier.DestroyOptions) status.MultiError {
 	if d.destroyFunc != nil {
 		return d.destroyFunc(ctx)
 	}
 	return d.errs
 }
 
+func (d *fakeDestroyer) PlanDestroy(_ context.Context, _ applier.DestroyOptions) (applier.DestroyPlan, status.MultiError) {
+	return d.plan, d.errs
+}
+
 func (d *fakeDestroyer) Errors() status.MultiError {
 	return d.errs
 }
@@ -16,20 +16,25 @@ package reconciler
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
+	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/client/restconfig"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/events"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/importer/reader"
+	"kpt.dev/configsync/pkg/notifications"
 	"kpt.dev/configsync/pkg/parse"
 	"kpt.dev/configsync/pkg/reconciler/finalizer"
 	"kpt.dev/configsync/pkg/remediator"
@@ -37,6 +42,7 @@ import (
 	syncerclient "kpt.dev/configsync/pkg/syncer/client"
 	"kpt.dev/configsync/pkg/syncer/metrics"
 	"kpt.dev/configsync/pkg/syncer/reconcile"
+	"kpt.dev/configsync/pkg/util/fieldmanager"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -62,6 +68,9 @@ type Options struct {
 	// At most one Reconciler may have a given value for Scope on a cluster. More
 	// than one results in undefined behavior.
 	ReconcilerScope declared.Scope
+	// ScopeNamespaces are the other namespaces which a namespace reconciler
+	// may sync objects to, from the spec.namespaces field of the RepoSync.
+	ScopeNamespaces []string
 	// SyncName is the name of the RootSync or RepoSync object.
 	SyncName string
 	// ReconcilerName is the name of the Reconciler Deployment.
@@ -105,6 +114,32 @@ type Options struct {
 	ReconcileTimeout string
 	// APIServerTimeout is the client-side timeout used for talking to the API server
 	APIServerTimeout string
+	// IgnoreDifferences is the JSON encoding of the spec.ignoreDifferences field
+	// of the RootSync or RepoSync.
+	IgnoreDifferences string
+	// PruneBudgetMaxObjects is the maximum number of objects which may be
+	// pruned by a single commit without acknowledgment. 0 means no limit.
+	PruneBudgetMaxObjects int
+	// PruneBudgetMaxPercentage is the maximum percentage of the inventory which
+	// may be pruned by a single commit without acknowledgment. 0 means no limit.
+	PruneBudgetMaxPercentage int
+	// ConflictPolicy is the JSON encoding of the spec.conflictPolicy field of
+	// the RootSync or RepoSync.
+	ConflictPolicy string
+	// AdoptionPolicy is the spec.adoption field of the RootSync or RepoSync.
+	AdoptionPolicy string
+	// JsonnetConfig is the JSON encoding of the spec.jsonnet field of the
+	// RootSync or RepoSync.
+	JsonnetConfig string
+	// NotificationConfig is the JSON encoding of the endpoints of the
+	// spec.notifications field of the RootSync or RepoSync.
+	NotificationConfig string
+	// NotificationHMACKey is the key with which the notifications are signed.
+	// They are not signed if it is empty.
+	NotificationHMACKey string
+	// DecryptionAgeKey holds the age identities which decrypt the encrypted
+	// files in the source. They are rejected if it is empty.
+	DecryptionAgeKey string
 	// RootOptions is the set of options to fill in if this is configuring the
 	// Root reconciler.
 	// Unset for Namespace repositories.
@@ -159,9 +194,36 @@ func Run(opts Options) {
 		klog.Fatalf("failed to create client: %v", err)
 	}
 
+	// Record the Events of the sync lifecycle on the RootSync or RepoSync.
+	syncKind := configsync.RepoSyncKind
+	syncRef := types.NamespacedName{Namespace: string(opts.ReconcilerScope), Name: opts.SyncName}
+	if opts.ReconcilerScope == declared.RootReconciler {
+		syncKind = configsync.RootSyncKind
+		syncRef.Namespace = configsync.ControllerNamespace
+	}
+	recorder, err := events.NewRecorder(cfg, cl, syncKind, syncRef, opts.ReconcilerName)
+	if err != nil {
+		klog.Fatalf("Error creating the Event recorder: %v", err)
+	}
+	defer recorder.Shutdown()
+
+	// Post the notifications of the sync results, if configured.
+	notifier, err := notifications.NewNotifier(opts.NotificationConfig, opts.NotificationHMACKey, opts.ClusterName, syncKind, syncRef)
+	if err != nil {
+		klog.Fatalf("Error creating the notifier: %v", err)
+	}
+
 	// Configure the Applier.
+	conflictPolicy, err := fieldmanager.ParseConflictPolicy(opts.ConflictPolicy)
+	if err != nil {
+		klog.Fatalf("Error parsing conflictPolicy: %v", err)
+	}
+	adoptionPolicy, err := applier.ParseAdoptionPolicy(opts.AdoptionPolicy)
+	if err != nil {
+		klog.Fatalf("Error parsing adoption: %v", err)
+	}
 	genericClient := syncerclient.New(cl, metrics.APICallDuration)
-	baseApplier, err := reconcile.NewApplierForMultiRepo(cfg, genericClient)
+	baseApplier, err := reconcile.NewApplierForMultiRepo(cfg, genericClient, conflictPolicy, recorder)
 	if err != nil {
 		klog.Fatalf("Instantiating Applier: %v", err)
 	}
@@ -173,11 +235,19 @@ func Run(opts Options) {
 	if reconcileTimeout < 0 {
 		klog.Fatalf("Invalid reconcileTimeout: %v, timeout should not be negative", reconcileTimeout)
 	}
-	clientSet, err := applier.NewClientSet(cl, configFlags, opts.StatusMode)
+	clientSet, err := applier.NewClientSet(cl, configFlags, opts.StatusMode, opts.ReconcilerScope)
 	if err != nil {
 		klog.Fatalf("Error creating clients: %v", err)
 	}
-	supervisor, err := applier.NewSupervisor(clientSet, opts.ReconcilerScope, opts.SyncName, reconcileTimeout)
+	clientSet.Events = recorder
+	supervisor, err := applier.NewSupervisor(clientSet, opts.ReconcilerScope, opts.SyncName, reconcileTimeout, applier.SupervisorOptions{
+		PruneBudget: applier.PruneBudget{
+			MaxObjects:    opts.PruneBudgetMaxObjects,
+			MaxPercentage: opts.PruneBudgetMaxPercentage,
+		},
+		ConflictPolicy: conflictPolicy,
+		AdoptionPolicy: adoptionPolicy,
+	})
 	if err != nil {
 		klog.Fatalf("Error creating applier: %v", err)
 	}
@@ -193,11 +263,31 @@ func Run(opts Options) {
 		klog.Fatalf("Error creating rest config for the remediator: %v", err)
 	}
 
-	rem, err := remediator.New(opts.ReconcilerScope, opts.SyncName, cfgForWatch, baseApplier, decls, opts.NumWorkers)
+	// The remediator resolves the secret references of the objects it
+	// corrects, the same as the applier.
+	remApplier := applier.WithSecretResolver(baseApplier, applier.NewSecretResolver(cl, applier.SecretMountDir))
+	rem, err := remediator.New(opts.ReconcilerScope, opts.ScopeNamespaces, opts.SyncName, cfgForWatch, remApplier, decls, opts.NumWorkers, recorder, clientSet.Protection, adoptionPolicy)
 	if err != nil {
 		klog.Fatalf("Instantiating Remediator: %v", err)
 	}
 
+	ignoreRules, err := declared.ParseIgnoreRules(opts.IgnoreDifferences)
+	if err != nil {
+		klog.Fatalf("Error parsing ignoreDifferences: %v", err)
+	}
+
+	fileReader, err := newFileReader(opts.ClusterName, opts.JsonnetConfig)
+	if err != nil {
+		klog.Fatalf("Error parsing jsonnetConfig: %v", err)
+	}
+	if opts.DecryptionAgeKey != "" {
+		fileReader.AgeIdentities, err = reader.ParseAgeIdentities(opts.DecryptionAgeKey)
+		if err != nil {
+			// The error is not logged, since it may quote the key.
+			klog.Fatal("Error parsing the age identities of the decryption Secret")
+		}
+	}
+
 	// Configure the Parser.
 	var parser parse.Parser
 	fs := parse.FileSource{
@@ -212,14 +302,14 @@ func Run(opts Options) {
 		SourceRev:    opts.SourceRev,
 	}
 	if opts.ReconcilerScope == declared.RootReconciler {
-		parser, err = parse.NewRootRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.SourceFormat, &reader.File{}, cl,
-			opts.PollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, discoveryClient, decls, supervisor, rem)
+		parser, err = parse.NewRootRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.SourceFormat, fileReader, cl,
+			opts.PollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, discoveryClient, decls, supervisor, rem, ignoreRules, recorder, notifier)
 		if err != nil {
 			klog.Fatalf("Instantiating Root Repository Parser: %v", err)
 		}
 	} else {
-		parser, err = parse.NewNamespaceRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.ReconcilerScope, &reader.File{}, cl,
-			opts.PollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, discoveryClient, decls, supervisor, rem)
+		parser, err = parse.NewNamespaceRunner(opts.ClusterName, opts.SyncName, opts.ReconcilerName, opts.ReconcilerScope, opts.ScopeNamespaces, fileReader, cl,
+			opts.PollingPeriod, opts.ResyncPeriod, opts.RetryPeriod, opts.StatusUpdatePeriod, fs, discoveryClient, decls, supervisor, rem, ignoreRules, recorder, notifier)
 		if err != nil {
 			klog.Fatalf("Instantiating Namespace Repository Parser: %v", err)
 		}
@@ -303,6 +393,8 @@ func Run(opts Options) {
 	// TODO: Convert the Remediator to use the controller-manager framework.
 	doneChanForRemediator := rem.Start(ctx) // non-blocking
 
+	go notifier.Run(ctx) // returns immediately without notifications
+
 	klog.Info("Starting Parser")
 	// TODO: Convert the Parser to use the controller-manager framework.
 	parse.Run(ctx, parser) // blocks until ctx.Done()
@@ -323,3 +415,19 @@ func Run(opts Options) {
 	<-signalCtx.Done()
 	klog.Info("All controllers exited")
 }
+
+// newFileReader returns the reader of the source files, configured with the
+// JSON encoding of the spec.jsonnet field.
+func newFileReader(clusterName, jsonnetConfig string) (*reader.File, error) {
+	r := &reader.File{ClusterName: clusterName}
+	if jsonnetConfig == "" {
+		return r, nil
+	}
+	var config v1beta1.JsonnetConfig
+	if err := json.Unmarshal([]byte(jsonnetConfig), &config); err != nil {
+		return nil, err
+	}
+	r.JsonnetLibPaths = config.LibPaths
+	r.JsonnetExtVars = config.ExtVars
+	return r, nil
+}
@@ -77,6 +77,59 @@ const (
 	// StatusMode is to control if the kpt applier needs to inject the actuation data
 	// into the ResourceGroup object.
 	StatusMode = "STATUS_MODE"
+
+	// IgnoreDifferences is the JSON encoded list of rules selecting fields of
+	// declared objects which the reconciler neither applies nor reverts.
+	IgnoreDifferences = "IGNORE_DIFFERENCES"
+
+	// PruneBudgetMaxObjects is the maximum number of objects which may be
+	// pruned by a single commit without acknowledgment.
+	PruneBudgetMaxObjects = "PRUNE_BUDGET_MAX_OBJECTS"
+
+	// PruneBudgetMaxPercentage is the maximum percentage of the inventory which
+	// may be pruned by a single commit without acknowledgment.
+	PruneBudgetMaxPercentage = "PRUNE_BUDGET_MAX_PERCENTAGE"
+
+	// ConflictPolicy is the JSON encoded policy deciding which fields owned by
+	// other field managers the reconciler takes over.
+	ConflictPolicy = "CONFLICT_POLICY"
+
+	// AdoptionPolicy is the policy deciding whether the reconciler adopts
+	// declared objects which already exist on the cluster.
+	AdoptionPolicy = "ADOPTION_POLICY"
+
+	// JsonnetConfig is the JSON encoded configuration of the evaluation of the
+	// Jsonnet files in the source.
+	JsonnetConfig = "JSONNET_CONFIG"
+
+	// KustomizeConfig is the JSON encoded configuration of the restrictions on
+	// the remote sources loaded by the kustomizations in the source.
+	KustomizeConfig = "KUSTOMIZE_CONFIG"
+
+	// RenderCacheDir is the directory of the render cache shared by the
+	// hydration-controllers.
+	RenderCacheDir = "RENDER_CACHE_DIR"
+
+	// TraceSamplingFraction is the probability of sampling the trace spans of
+	// the reconciler and the hydration-controller.
+	TraceSamplingFraction = "TRACE_SAMPLING_FRACTION"
+
+	// NotificationConfig is the JSON encoded list of the HTTP endpoints to
+	// which the reconciler posts the notifications of the sync results.
+	NotificationConfig = "NOTIFICATION_CONFIG"
+
+	// NotificationHMACKey is the key with which the reconciler signs the
+	// notifications of the sync results.
+	NotificationHMACKey = "NOTIFICATION_HMAC_KEY"
+
+	// DecryptionAgeKey holds the age identities with which the reconciler
+	// decrypts the encrypted files in the source.
+	DecryptionAgeKey = "DECRYPTION_AGE_KEY"
+
+	// ScopeNamespaces is the comma-separated list of the namespaces, in
+	// addition to the namespace of the RepoSync, which the namespace
+	// reconciler may sync objects to.
+	ScopeNamespaces = "SCOPE_NAMESPACES"
 )
 
 const (
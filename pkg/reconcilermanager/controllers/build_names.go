@@ -32,8 +32,22 @@ func RepoSyncPermissionsName() string {
 	return fmt.Sprintf("%s:%s", configsync.GroupName, core.NsReconcilerPrefix)
 }
 
+// RepoSyncScopePermissionsName returns the name of the namespace reconciler
+// permissions in the namespaces of its spec.namespaces scope.
+// e.g. configsync.gke.io:ns-reconciler-scope
+func RepoSyncScopePermissionsName() string {
+	return fmt.Sprintf("%s-scope", RepoSyncPermissionsName())
+}
+
 // RootSyncPermissionsName returns root reconciler permissions name.
 // e.g. configsync.gke.io:root-reconciler
 func RootSyncPermissionsName() string {
 	return fmt.Sprintf("%s:%s", configsync.GroupName, core.RootReconcilerPrefix)
 }
+
+// RepoSyncTemplatePermissionsName returns the name of the RoleBindings created
+// by a RepoSyncTemplate.
+// e.g. configsync.gke.io:repo-sync-template:tenants
+func RepoSyncTemplatePermissionsName(templateName string) string {
+	return fmt.Sprintf("%s:repo-sync-template:%s", configsync.GroupName, templateName)
+}
@@ -65,6 +65,12 @@ func (r *RepoSyncReconciler) cleanupNSControllerResources(ctx context.Context, r
 	if err := r.deleteRoleBinding(ctx, reconcilerRef, rsKey); err != nil {
 		return err
 	}
+	if _, err := r.deleteScopeRoleBindings(ctx, reconcilerRef, nil); err != nil {
+		return err
+	}
+	if err := r.deleteConfigRoleBinding(ctx, reconcilerRef); err != nil {
+		return err
+	}
 	// secret
 	if err := r.deleteSecrets(ctx, reconcilerRef); err != nil {
 		return err
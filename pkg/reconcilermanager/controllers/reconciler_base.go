@@ -62,6 +62,16 @@ const (
 	// It will be used in both the indexing and watching.
 	helmSecretRefField = ".spec.helm.secretRef.name"
 
+	// notificationSecretRefField is the path of the field in the
+	// RootSync|RepoSync CRDs that we wish to use as the "object reference".
+	// It will be used in both the indexing and watching.
+	notificationSecretRefField = ".spec.notifications.secretRef.name"
+
+	// decryptionSecretRefField is the path of the field in the
+	// RootSync|RepoSync CRDs that we wish to use as the "object reference".
+	// It will be used in both the indexing and watching.
+	decryptionSecretRefField = ".spec.decryption.secretRef.name"
+
 	// fleetMembershipName is the name of the fleet membership
 	fleetMembershipName = "membership"
 
@@ -101,6 +111,14 @@ type reconcilerBase struct {
 	hydrationPollingPeriod  time.Duration
 	membership              *hubv1.Membership
 
+	// renderCacheClaim is the name of the PersistentVolumeClaim of the render
+	// cache shared by the hydration-controllers, empty if it is disabled.
+	renderCacheClaim string
+
+	// traceSamplingFraction is the probability of sampling the trace spans of
+	// the reconcilers and the hydration-controllers, 0 if tracing is disabled.
+	traceSamplingFraction float64
+
 	// syncKind is the kind of the sync object: RootSync or RepoSync.
 	syncKind string
 
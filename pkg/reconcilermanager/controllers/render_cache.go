@@ -0,0 +1,61 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/metadata"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EnsureRenderCacheClaim creates the PersistentVolumeClaim of the render cache
+// shared by the hydration-controllers of all reconcilers, unless it exists.
+// The reconcilers run on different nodes, so the claim requests the
+// ReadWriteMany access mode. An existing claim is left untouched.
+func EnsureRenderCacheClaim(ctx context.Context, c client.Client, name, storageClass string, size resource.Quantity) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: name}
+	err := c.Get(ctx, key, pvc)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "unable to get the render cache PersistentVolumeClaim %s", key)
+	}
+
+	pvc = &corev1.PersistentVolumeClaim{}
+	pvc.Name = name
+	pvc.Namespace = configsync.ControllerNamespace
+	pvc.Labels = map[string]string{
+		metadata.SystemLabel: "true",
+	}
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	pvc.Spec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: size,
+	}
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	if err := c.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "unable to create the render cache PersistentVolumeClaim %s", key)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEnsureRenderCacheClaim(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := syncerFake.NewClient(t, core.Scheme)
+	key := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: "render-cache"}
+
+	if err := EnsureRenderCacheClaim(ctx, fakeClient, key.Name, "nfs", resource.MustParse("1Gi")); err != nil {
+		t.Fatalf("EnsureRenderCacheClaim() got error: %v", err)
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := fakeClient.Get(ctx, key, pvc); err != nil {
+		t.Fatalf("render cache PersistentVolumeClaim not created: %v", err)
+	}
+	if len(pvc.Spec.AccessModes) != 1 || pvc.Spec.AccessModes[0] != corev1.ReadWriteMany {
+		t.Errorf("got access modes %v, want [ReadWriteMany]", pvc.Spec.AccessModes)
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "nfs" {
+		t.Errorf("got storage class %v, want nfs", pvc.Spec.StorageClassName)
+	}
+
+	// An existing claim is left untouched.
+	if err := EnsureRenderCacheClaim(ctx, fakeClient, key.Name, "", resource.MustParse("5Gi")); err != nil {
+		t.Fatalf("EnsureRenderCacheClaim() on an existing claim got error: %v", err)
+	}
+	if err := fakeClient.Get(ctx, key, pvc); err != nil {
+		t.Fatal(err)
+	}
+	if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.String() != "1Gi" {
+		t.Errorf("got storage request %s, want 1Gi", got.String())
+	}
+}
+
+func TestRenderCacheVolumeMounts(t *testing.T) {
+	if got := renderCacheVolumeMounts("", rootSyncRenderCacheSubPath, nil); len(got) != 0 {
+		t.Errorf("got VolumeMounts %v with the render cache disabled, want none", got)
+	}
+
+	// The RootSyncs, and the RepoSyncs of each namespace, mount their own
+	// directory of the render cache.
+	testCases := map[string]string{
+		rootSyncRenderCacheSubPath:              "root-syncs",
+		repoSyncRenderCacheSubPath("shop"):      "repo-syncs/shop",
+		repoSyncRenderCacheSubPath("bookstore"): "repo-syncs/bookstore",
+	}
+	for subPath, want := range testCases {
+		got := renderCacheVolumeMounts("render-cache", subPath, nil)
+		if len(got) != 1 {
+			t.Fatalf("got VolumeMounts %v, want one", got)
+		}
+		if got[0].Name != RenderCacheVolume || got[0].MountPath != RenderCachePath || got[0].SubPath != want {
+			t.Errorf("got VolumeMount %+v, want the subPath %s of %s mounted at %s", got[0], want, RenderCacheVolume, RenderCachePath)
+		}
+	}
+}
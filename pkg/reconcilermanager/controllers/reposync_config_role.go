@@ -0,0 +1,129 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/health"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/parse"
+	"kpt.dev/configsync/pkg/protection"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// repoSyncConfigMapNames are the names of the ConfigMaps in the
+// config-management-system namespace which configure the namespace reconcilers.
+var repoSyncConfigMapNames = []string{
+	parse.VariablesConfigMapName,
+	protection.ConfigMapName,
+	health.ConfigMapName,
+}
+
+// repoSyncConfigRules are the rules of the configsync.gke.io:ns-reconciler
+// Role in the config-management-system namespace. The namespace reconcilers
+// run in that namespace, but are only bound to the
+// configsync.gke.io:ns-reconciler ClusterRole in the namespaces they sync, so
+// the Role grants them reading their ConfigMaps, and nothing else.
+func repoSyncConfigRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: repoSyncConfigMapNames,
+			Verbs:         []string{"get"},
+		},
+	}
+}
+
+// upsertConfigRoleBinding creates or updates the
+// configsync.gke.io:ns-reconciler Role in the config-management-system
+// namespace, and binds the namespace reconciler to it.
+func (r *RepoSyncReconciler) upsertConfigRoleBinding(ctx context.Context, reconcilerRef types.NamespacedName) (client.ObjectKey, error) {
+	ref := client.ObjectKey{
+		Namespace: configsync.ControllerNamespace,
+		Name:      RepoSyncPermissionsName(),
+	}
+
+	childRole := &rbacv1.Role{}
+	childRole.Name = ref.Name
+	childRole.Namespace = ref.Namespace
+	op, err := controllerruntime.CreateOrUpdate(ctx, r.client, childRole, func() error {
+		childRole.Rules = repoSyncConfigRules()
+		return nil
+	})
+	if err != nil {
+		return ref, err
+	}
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("Managed object upsert successful",
+			logFieldObject, ref.String(),
+			logFieldKind, "Role",
+			logFieldOperation, op)
+	}
+
+	childRB := &rbacv1.RoleBinding{}
+	childRB.Name = ref.Name
+	childRB.Namespace = ref.Namespace
+	op, err = controllerruntime.CreateOrUpdate(ctx, r.client, childRB, func() error {
+		childRB.RoleRef = rolereference(RepoSyncPermissionsName(), "Role")
+		childRB.Subjects = addSubject(childRB.Subjects, r.serviceAccountSubject(reconcilerRef))
+		return nil
+	})
+	if err != nil {
+		return ref, err
+	}
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("Managed object upsert successful",
+			logFieldObject, ref.String(),
+			logFieldKind, "RoleBinding",
+			logFieldOperation, op)
+	}
+	return ref, nil
+}
+
+// deleteConfigRoleBinding removes the namespace reconciler from the
+// configsync.gke.io:ns-reconciler RoleBinding in the config-management-system
+// namespace. The RoleBinding and its Role are deleted once the RoleBinding is
+// left without subjects.
+func (r *RepoSyncReconciler) deleteConfigRoleBinding(ctx context.Context, reconcilerRef types.NamespacedName) error {
+	ref := client.ObjectKey{
+		Namespace: configsync.ControllerNamespace,
+		Name:      RepoSyncPermissionsName(),
+	}
+	rb := &rbacv1.RoleBinding{}
+	if err := r.client.Get(ctx, ref, rb); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The reconciler was created before the RoleBinding existed.
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get the RoleBinding object %s", ref)
+	}
+	rb.Subjects = removeSubject(rb.Subjects, r.serviceAccountSubject(reconcilerRef))
+	if len(rb.Subjects) > 0 {
+		return r.client.Update(ctx, rb)
+	}
+	if err := r.cleanup(ctx, ref, kinds.RoleBinding()); err != nil {
+		return err
+	}
+	return r.cleanup(ctx, ref, kinds.Role())
+}
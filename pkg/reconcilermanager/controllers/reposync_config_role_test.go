@@ -0,0 +1,135 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/health"
+	"kpt.dev/configsync/pkg/parse"
+	"kpt.dev/configsync/pkg/protection"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// roleAllows returns whether one of the rules grants the verb on the core
+// resource with the given name.
+func roleAllows(rules []rbacv1.PolicyRule, verb, resource, name string) bool {
+	contains := func(list []string, s string) bool {
+		for _, item := range list {
+			if item == s || item == rbacv1.VerbAll {
+				return true
+			}
+		}
+		return false
+	}
+	for _, rule := range rules {
+		if contains(rule.APIGroups, "") && contains(rule.Resources, resource) && contains(rule.Verbs, verb) &&
+			(len(rule.ResourceNames) == 0 || contains(rule.ResourceNames, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRepoSyncConfigRoleBinding(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	rs1 := repoSync(reposyncNs, reposyncName, reposyncRef(gitRevision), reposyncBranch(branch), reposyncSecretType(configsync.AuthNone))
+	rs2 := repoSync("videoinfo", configsync.RepoSyncName, reposyncRef(gitRevision), reposyncBranch(branch), reposyncSecretType(configsync.AuthNone))
+	fakeClient, _, testReconciler := setupNSReconciler(t, rs1, rs2)
+
+	ctx := context.Background()
+	for _, rs := range []client.Object{rs1, rs2} {
+		if _, err := testReconciler.Reconcile(ctx, namespacedName(rs.GetName(), rs.GetNamespace())); err != nil {
+			t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+		}
+	}
+
+	key := client.ObjectKey{Namespace: configsync.ControllerNamespace, Name: RepoSyncPermissionsName()}
+	role := &rbacv1.Role{}
+	if err := fakeClient.Get(ctx, key, role); err != nil {
+		t.Fatalf("Role[%s] not found: %v", key, err)
+	}
+	for _, name := range []string{parse.VariablesConfigMapName, protection.ConfigMapName, health.ConfigMapName} {
+		if !roleAllows(role.Rules, "get", "configmaps", name) {
+			t.Errorf("Role[%s] does not allow reading the ConfigMap %s", key, name)
+		}
+	}
+	for _, denied := range []struct{ verb, resource, name string }{
+		{"get", "configmaps", "other"},
+		{"list", "configmaps", parse.VariablesConfigMapName},
+		{"update", "configmaps", protection.ConfigMapName},
+		{"get", "secrets", parse.VariablesConfigMapName},
+	} {
+		if roleAllows(role.Rules, denied.verb, denied.resource, denied.name) {
+			t.Errorf("Role[%s] unexpectedly allows %s on %s %s", key, denied.verb, denied.resource, denied.name)
+		}
+	}
+
+	nsReconcilerName2 := core.NsReconcilerName(rs2.Namespace, rs2.Name)
+	rb := fake.RoleBindingObject(core.Name(key.Name), core.Namespace(key.Namespace))
+	rb.RoleRef = rolereference(RepoSyncPermissionsName(), "Role")
+	rb.Subjects = addSubjectByName(addSubjectByName(nil, nsReconcilerName), nsReconcilerName2)
+	wantRoleBindings := map[core.ID]*rbacv1.RoleBinding{core.IDOf(rb): rb}
+	validateRoleBindings(t, wantRoleBindings, fakeClient)
+	gotRB := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(ctx, key, gotRB); err != nil {
+		t.Fatal(err)
+	}
+	if gotRB.RoleRef != rb.RoleRef {
+		t.Errorf("RoleBinding[%s] got roleRef %v, want %v", key, gotRB.RoleRef, rb.RoleRef)
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	// Deleting a RepoSync unbinds its reconciler.
+	deleteRepoSync(t, fakeClient, testReconciler, rs1)
+	rb.Subjects = deleteSubjectByName(rb.Subjects, nsReconcilerName)
+	validateRoleBindings(t, wantRoleBindings, fakeClient)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	// Deleting the last RepoSync deletes the RoleBinding and the Role.
+	deleteRepoSync(t, fakeClient, testReconciler, rs2)
+	if err := validateResourceDeleted(core.IDOf(rb), fakeClient); err != nil {
+		t.Error(err)
+	}
+	role.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("Role"))
+	if err := validateResourceDeleted(core.IDOf(role), fakeClient); err != nil {
+		t.Error(err)
+	}
+}
+
+func deleteRepoSync(t *testing.T, fakeClient *syncerFake.Client, testReconciler *RepoSyncReconciler, rs client.Object) {
+	t.Helper()
+
+	ctx := context.Background()
+	rs.SetResourceVersion("") // Skip ResourceVersion validation
+	if err := fakeClient.Delete(ctx, rs); err != nil {
+		t.Fatalf("failed to delete the RepoSync, got error: %v, want error: nil", err)
+	}
+	if _, err := testReconciler.Reconcile(ctx, namespacedName(rs.GetName(), rs.GetNamespace())); err != nil {
+		t.Fatalf("unexpected reconciliation error upon request deletion, got error: %q, want error: nil", err)
+	}
+}
@@ -70,7 +70,7 @@ type RepoSyncReconciler struct {
 }
 
 // NewRepoSyncReconciler returns a new RepoSyncReconciler.
-func NewRepoSyncReconciler(clusterName string, reconcilerPollingPeriod, hydrationPollingPeriod time.Duration, client client.Client, dynamicClient dynamic.Interface, log logr.Logger, scheme *runtime.Scheme) *RepoSyncReconciler {
+func NewRepoSyncReconciler(clusterName string, reconcilerPollingPeriod, hydrationPollingPeriod time.Duration, renderCacheClaim string, traceSamplingFraction float64, client client.Client, dynamicClient dynamic.Interface, log logr.Logger, scheme *runtime.Scheme) *RepoSyncReconciler {
 	return &RepoSyncReconciler{
 		reconcilerBase: reconcilerBase{
 			clusterName:             clusterName,
@@ -80,6 +80,8 @@ func NewRepoSyncReconciler(clusterName string, reconcilerPollingPeriod, hydratio
 			scheme:                  scheme,
 			reconcilerPollingPeriod: reconcilerPollingPeriod,
 			hydrationPollingPeriod:  hydrationPollingPeriod,
+			renderCacheClaim:        renderCacheClaim,
+			traceSamplingFraction:   traceSamplingFraction,
 			syncKind:                configsync.RepoSyncKind,
 		},
 		repoSyncs: make(map[types.NamespacedName]struct{}),
@@ -88,6 +90,7 @@ func NewRepoSyncReconciler(clusterName string, reconcilerPollingPeriod, hydratio
 
 // +kubebuilder:rbac:groups=configsync.gke.io,resources=reposyncs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=configsync.gke.io,resources=reposyncs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile the RepoSync resource.
 func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntime.Request) (controllerruntime.Result, error) {
@@ -233,6 +236,48 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, errors.Wrap(err, "Secret reconcile failed")
 	}
 
+	// Create secret in config-management-system namespace using the
+	// existing secret in the reposync.namespace.
+	if sRef, err := upsertNotificationSecret(ctx, log, rs, r.client, reconcilerRef); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, sRef.String(),
+			logFieldKind, "Secret",
+			"type", "notification")
+		reposync.SetStalled(rs, "Secret", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "Secret reconcile failed")
+	}
+
+	// Create secret in config-management-system namespace using the
+	// existing secret in the reposync.namespace.
+	if sRef, err := upsertDecryptionSecret(ctx, log, rs, r.client, reconcilerRef); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, sRef.String(),
+			logFieldKind, "Secret",
+			"type", "decryption")
+		reposync.SetStalled(rs, "Secret", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "Secret reconcile failed")
+	}
+
 	labelMap := map[string]string{
 		metadata.SyncNamespaceLabel: rs.Namespace,
 		metadata.SyncNameLabel:      rs.Name,
@@ -292,7 +337,76 @@ func (r *RepoSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, errors.Wrap(err, "RoleBinding reconcile failed")
 	}
 
-	containerEnvs := r.populateContainerEnvs(ctx, rs, reconcilerRef.Name)
+	// Bind the reconciler to read its ConfigMaps in config-management-system.
+	if rbRef, err := r.upsertConfigRoleBinding(ctx, reconcilerRef); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, rbRef.String(),
+			logFieldKind, "RoleBinding")
+		reposync.SetStalled(rs, "RoleBinding", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "RoleBinding reconcile failed")
+	}
+
+	// Bind the reconciler in the other namespaces of its scope.
+	scopeNamespaces, err := r.scopeNamespaces(ctx, rs)
+	if err != nil {
+		log.Error(err, "Namespace scope computation failed",
+			logFieldObject, rsRef.String(),
+			logFieldKind, r.syncKind)
+		reposync.SetStalled(rs, "RoleBinding", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "RoleBinding reconcile failed")
+	}
+	if rbRef, err := r.upsertScopeRoleBindings(ctx, reconcilerRef, scopeNamespaces); err != nil {
+		log.Error(err, "Managed object upsert failed",
+			logFieldObject, rbRef.String(),
+			logFieldKind, "RoleBinding")
+		reposync.SetStalled(rs, "RoleBinding", err)
+		// Upsert errors should always trigger retry (return error),
+		// even if status update is successful.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		if updateErr != nil {
+			log.Error(updateErr, "Object status update failed",
+				logFieldObject, rsRef.String(),
+				logFieldKind, r.syncKind)
+		}
+		// Use the upsert error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, errors.Wrap(err, "RoleBinding reconcile failed")
+	}
+
+	containerEnvs, err := r.populateContainerEnvs(ctx, rs, reconcilerRef.Name)
+	if err != nil {
+		log.Error(err, "Spec invalid",
+			logFieldObject, rsRef.String(),
+			logFieldKind, r.syncKind)
+		reposync.SetStalled(rs, "Validation", err)
+		// Validation errors should not trigger retry (return error),
+		// unless the status update also fails.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		// Use the validation error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, updateErr
+	}
+	containerEnvs[reconcilermanager.Reconciler] = append(containerEnvs[reconcilermanager.Reconciler], scopeNamespacesEnvs(scopeNamespaces)...)
 	mut := r.mutationsFor(ctx, rs, containerEnvs)
 
 	// Upsert Namespace reconciler deployment.
@@ -430,6 +544,26 @@ func (r *RepoSyncReconciler) SetupWithManager(mgr controllerruntime.Manager, wat
 	}); err != nil {
 		return err
 	}
+	// Index the `notificationSecretRefField` field, so that we will be able to lookup RepoSync be a referenced `notificationSecretRefField` name.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1beta1.RepoSync{}, notificationSecretRefField, func(rawObj client.Object) []string {
+		rs := rawObj.(*v1beta1.RepoSync)
+		if name := notificationSecretName(rs.Spec.Notifications); name != "" {
+			return []string{name}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	// Index the `decryptionSecretRefField` field, so that we will be able to lookup RepoSync be a referenced `decryptionSecretRefField` name.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1beta1.RepoSync{}, decryptionSecretRefField, func(rawObj client.Object) []string {
+		rs := rawObj.(*v1beta1.RepoSync)
+		if name := decryptionSecretName(rs.Spec.Decryption); name != "" {
+			return []string{name}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
 
 	controllerBuilder := controllerruntime.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
@@ -448,7 +582,12 @@ func (r *RepoSyncReconciler) SetupWithManager(mgr controllerruntime.Manager, wat
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Watches(&source.Kind{Type: &rbacv1.RoleBinding{}},
 			handler.EnqueueRequestsFromMapFunc(r.mapObjectToRepoSync),
-			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		// Custom Watch to trigger Reconcile when a Namespace may enter or leave
+		// the spec.namespaces scope of a RepoSync.
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToRepoSyncs),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})))
 
 	if watchFleetMembership {
 		// Custom Watch for membership to trigger reconciliation.
@@ -556,7 +695,7 @@ func (r *RepoSyncReconciler) mapSecretToRepoSyncs(secret client.Object) []reconc
 	// The user-managed ns-reconciler Secret might be shared among multiple RepoSync objects in the same namespace,
 	// so requeue all the attached RepoSync objects.
 	attachedRepoSyncs := &v1beta1.RepoSyncList{}
-	secretFields := []string{gitSecretRefField, caCertSecretRefField, helmSecretRefField}
+	secretFields := []string{gitSecretRefField, caCertSecretRefField, helmSecretRefField, notificationSecretRefField, decryptionSecretRefField}
 	for _, secretField := range secretFields {
 		listOps := &client.ListOptions{
 			FieldSelector: fields.OneTermEqualSelector(secretField, secret.GetName()),
@@ -664,11 +803,40 @@ func requeueRepoSyncRequest(obj client.Object, rs *v1beta1.RepoSync) []reconcile
 	}
 }
 
-func (r *RepoSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1beta1.RepoSync, reconcilerName string) map[string][]corev1.EnvVar {
+func (r *RepoSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1beta1.RepoSync, reconcilerName string) (map[string][]corev1.EnvVar, error) {
+	ignoreDifferences, err := ignoreDifferencesEnvs(rs.Spec.IgnoreDifferences)
+	if err != nil {
+		return nil, err
+	}
 	result := map[string][]corev1.EnvVar{
 		reconcilermanager.HydrationController: hydrationEnvs(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, declared.Scope(rs.Namespace), reconcilerName, r.hydrationPollingPeriod.String()),
-		reconcilermanager.Reconciler:          reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.Scope(rs.Namespace), rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, reposync.GetHelmBase(rs.Spec.Helm), r.reconcilerPollingPeriod.String(), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)),
+		reconcilermanager.Reconciler:          append(reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.Scope(rs.Namespace), rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, reposync.GetHelmBase(rs.Spec.Helm), r.reconcilerPollingPeriod.String(), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)), ignoreDifferences...),
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneBudgetEnvs(rs.Spec.PruneBudget)...)
+	conflictPolicy, err := conflictPolicyEnvs(rs.Spec.ConflictPolicy)
+	if err != nil {
+		return nil, err
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], conflictPolicy...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], adoptionPolicyEnvs(rs.Spec.Adoption)...)
+	jsonnetConfig, err := jsonnetConfigEnvs(rs.Spec.Jsonnet)
+	if err != nil {
+		return nil, err
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], jsonnetConfig...)
+	kustomizeConfig, err := kustomizeConfigEnvs(rs.Spec.Kustomize)
+	if err != nil {
+		return nil, err
+	}
+	result[reconcilermanager.HydrationController] = append(result[reconcilermanager.HydrationController], kustomizeConfig...)
+	result[reconcilermanager.HydrationController] = append(result[reconcilermanager.HydrationController], renderCacheEnvs(r.renderCacheClaim)...)
+	result[reconcilermanager.HydrationController] = append(result[reconcilermanager.HydrationController], tracingEnvs(r.traceSamplingFraction)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], tracingEnvs(r.traceSamplingFraction)...)
+	notificationConfig, err := notificationConfigEnvs(rs.Spec.Notifications)
+	if err != nil {
+		return nil, err
 	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], notificationConfig...)
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
 		result[reconcilermanager.GitSync] = gitSyncEnvs(ctx, options{
@@ -687,10 +855,28 @@ func (r *RepoSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1be
 	case v1beta1.HelmSource:
 		result[reconcilermanager.HelmSync] = helmSyncEnvs(&rs.Spec.Helm.HelmBase, rs.Namespace)
 	}
-	return result
+	return result, nil
 }
 
 func (r *RepoSyncReconciler) validateSpec(ctx context.Context, rs *v1beta1.RepoSync, reconcilerName string) error {
+	if err := validate.IgnoreDifferencesSpec(rs.Spec.IgnoreDifferences, rs); err != nil {
+		return err
+	}
+	if err := validate.ConflictPolicySpec(rs.Spec.ConflictPolicy, rs); err != nil {
+		return err
+	}
+	if err := validate.JsonnetSpec(rs.Spec.Jsonnet, rs); err != nil {
+		return err
+	}
+	if err := validate.KustomizeSpec(rs.Spec.Kustomize, rs); err != nil {
+		return err
+	}
+	if err := validate.NotificationSpec(rs.Spec.Notifications, rs); err != nil {
+		return err
+	}
+	if err := validate.NamespaceScopeSpec(rs.Spec.Namespaces, rs); err != nil {
+		return err
+	}
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
 		return r.validateGitSpec(ctx, rs, reconcilerName)
@@ -854,6 +1040,7 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 			caCertSecretRefName = ReconcilerResourceName(reconcilerName, caCertSecretRefName)
 		}
 		templateSpec.Volumes = filterVolumes(templateSpec.Volumes, auth, secretName, caCertSecretRefName, rs.Spec.SourceType, r.membership)
+		templateSpec.Volumes = renderCacheVolumes(r.renderCacheClaim, templateSpec.Volumes)
 		var updatedContainers []corev1.Container
 		// Mutate spec.Containers to update name, configmap references and volumemounts.
 		for _, container := range templateSpec.Containers {
@@ -861,9 +1048,16 @@ func (r *RepoSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RepoS
 			switch container.Name {
 			case reconcilermanager.Reconciler:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
+				if name := notificationSecretName(rs.Spec.Notifications); name != "" {
+					container.Env = append(container.Env, notificationHMACKeyEnv(ReconcilerResourceName(reconcilerName, name))...)
+				}
+				if name := decryptionSecretName(rs.Spec.Decryption); name != "" {
+					container.Env = append(container.Env, decryptionKeyEnv(ReconcilerResourceName(reconcilerName, name))...)
+				}
 				mutateContainerResource(&container, rs.Spec.Override)
 			case reconcilermanager.HydrationController:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
+				container.VolumeMounts = renderCacheVolumeMounts(r.renderCacheClaim, repoSyncRenderCacheSubPath(rs.Namespace), container.VolumeMounts)
 				if rs.Spec.SafeOverride().EnableShellInRendering == nil || !*rs.Spec.SafeOverride().EnableShellInRendering {
 					container.Image = strings.ReplaceAll(container.Image, reconcilermanager.HydrationControllerWithShell, reconcilermanager.HydrationController)
 				} else {
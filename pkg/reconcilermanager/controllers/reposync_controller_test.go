@@ -276,6 +276,8 @@ func setupNSReconciler(t *testing.T, objs ...client.Object) (*syncerFake.Client,
 		testCluster,
 		filesystemPollingPeriod,
 		hydrationPollingPeriod,
+		"",
+		0,
 		fakeClient,
 		fakeDynamicClient,
 		controllerruntime.Log.WithName("controllers").WithName(configsync.RepoSyncKind),
@@ -284,6 +286,17 @@ func setupNSReconciler(t *testing.T, objs ...client.Object) (*syncerFake.Client,
 	return fakeClient, fakeDynamicClient, testReconciler
 }
 
+// repoSyncContainerEnvs returns the container environment variables of the
+// reconciler of the RepoSync.
+func repoSyncContainerEnvs(t *testing.T, r *RepoSyncReconciler, rs *v1beta1.RepoSync, reconcilerName string) map[string][]corev1.EnvVar {
+	t.Helper()
+	envs, err := r.populateContainerEnvs(context.Background(), rs, reconcilerName)
+	if err != nil {
+		t.Fatalf("failed to populate the container envs: %v", err)
+	}
+	return envs
+}
+
 func TestCreateAndUpdateNamespaceReconcilerWithOverride(t *testing.T) {
 	// Mock out parseDeployment for testing.
 	parseDeployment = parsedDeployment
@@ -329,7 +342,7 @@ func TestCreateAndUpdateNamespaceReconcilerWithOverride(t *testing.T) {
 	reposync.SetReconciling(wantRs, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs, fakeClient)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -456,7 +469,7 @@ func TestUpdateNamespaceReconcilerWithOverride(t *testing.T) {
 	reposync.SetReconciling(wantRs, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs, fakeClient)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -685,7 +698,7 @@ func TestRepoSyncCreateWithNoSSLVerify(t *testing.T) {
 	reposync.SetReconciling(wantRs, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs, fakeClient)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -723,7 +736,7 @@ func TestRepoSyncUpdateNoSSLVerify(t *testing.T) {
 	reposync.SetReconciling(wantRs, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs, fakeClient)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -859,7 +872,7 @@ func TestRepoSyncUpdateNoSSLVerify(t *testing.T) {
 	reposync.SetReconciling(wantRs, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs, fakeClient)
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	updatedRepoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1000,7 +1013,7 @@ func TestRepoSyncCreateWithCACert(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnvs := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 
 	nsSecretName := nsReconcilerName + "-" + secretName
 	nsCACertSecret := nsReconcilerName + "-" + caCertSecret
@@ -1039,7 +1052,7 @@ func TestRepoSyncUpdateCACert(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnvs := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	nsSecretName := nsReconcilerName + "-" + secretName
 	repoDeployment := rootSyncDeployment(nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1087,7 +1100,7 @@ func TestRepoSyncUpdateCACert(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnvs = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	nsCACertSecret := nsReconcilerName + "-" + caCertSecret
 	updatedRepoDeployment := rootSyncDeployment(nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1142,7 +1155,7 @@ func TestRepoSyncCreateWithOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1174,7 +1187,7 @@ func TestRepoSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1206,7 +1219,7 @@ func TestRepoSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	updatedRepoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1238,7 +1251,7 @@ func TestRepoSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	updatedRepoDeployment = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1315,7 +1328,7 @@ func TestRepoSyncCreateWithOverrideReconcileTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1347,7 +1360,7 @@ func TestRepoSyncUpdateOverrideReconcileTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1379,7 +1392,7 @@ func TestRepoSyncUpdateOverrideReconcileTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	updatedRepoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1456,7 +1469,7 @@ func TestRepoSyncCreateWithOverrideAPIServerTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
@@ -1486,7 +1499,7 @@ func TestRepoSyncUpdateOverrideAPIServerTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1515,7 +1528,7 @@ func TestRepoSyncUpdateOverrideAPIServerTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	updatedRepoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1605,7 +1618,7 @@ func TestRepoSyncSwitchAuthTypes(t *testing.T) {
 		core.UID("1"), core.ResourceVersion("1"), core.Generation(1),
 	)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1644,7 +1657,7 @@ func TestRepoSyncSwitchAuthTypes(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1675,7 +1688,7 @@ func TestRepoSyncSwitchAuthTypes(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1714,7 +1727,7 @@ func TestRepoSyncReconcilerRestart(t *testing.T) {
 	reposync.SetReconciling(wantRs, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs, fakeClient)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1849,7 +1862,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 	roleBinding1.Subjects = addSubjectByName(roleBinding1.Subjects, nsReconcilerName)
 	wantRoleBindings := map[core.ID]*rbacv1.RoleBinding{core.IDOf(roleBinding1): roleBinding1}
 
-	repoContainerEnv1 := testReconciler.populateContainerEnvs(ctx, rs1, nsReconcilerName)
+	repoContainerEnv1 := repoSyncContainerEnvs(t, testReconciler, rs1, nsReconcilerName)
 	repoDeployment1 := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -1897,7 +1910,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	repoContainerEnv2 := testReconciler.populateContainerEnvs(ctx, rs2, nsReconcilerName2)
+	repoContainerEnv2 := repoSyncContainerEnvs(t, testReconciler, rs2, nsReconcilerName2)
 	repoDeployment2 := repoSyncDeployment(
 		nsReconcilerName2,
 		setServiceAccountName(nsReconcilerName2),
@@ -1960,7 +1973,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	repoContainerEnv3 := testReconciler.populateContainerEnvs(ctx, rs3, nsReconcilerName3)
+	repoContainerEnv3 := repoSyncContainerEnvs(t, testReconciler, rs3, nsReconcilerName3)
 	repoDeployment3 := repoSyncDeployment(
 		nsReconcilerName3,
 		setServiceAccountName(nsReconcilerName3),
@@ -2022,7 +2035,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	repoContainerEnv4 := testReconciler.populateContainerEnvs(ctx, rs4, nsReconcilerName4)
+	repoContainerEnv4 := repoSyncContainerEnvs(t, testReconciler, rs4, nsReconcilerName4)
 	repoDeployment4 := repoSyncDeployment(
 		nsReconcilerName4,
 		setServiceAccountName(nsReconcilerName4),
@@ -2084,7 +2097,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	repoContainerEnv5 := testReconciler.populateContainerEnvs(ctx, rs5, nsReconcilerName5)
+	repoContainerEnv5 := repoSyncContainerEnvs(t, testReconciler, rs5, nsReconcilerName5)
 	repoDeployment5 := repoSyncDeployment(
 		nsReconcilerName5,
 		setServiceAccountName(nsReconcilerName5),
@@ -2134,7 +2147,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 	reposync.SetReconciling(wantRs1, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs1, fakeClient)
 
-	repoContainerEnv1 = testReconciler.populateContainerEnvs(ctx, rs1, nsReconcilerName)
+	repoContainerEnv1 = repoSyncContainerEnvs(t, testReconciler, rs1, nsReconcilerName)
 	repoDeployment1 = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -2168,7 +2181,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 	reposync.SetReconciling(wantRs2, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs2, fakeClient)
 
-	repoContainerEnv2 = testReconciler.populateContainerEnvs(ctx, rs2, nsReconcilerName2)
+	repoContainerEnv2 = repoSyncContainerEnvs(t, testReconciler, rs2, nsReconcilerName2)
 	repoDeployment2 = repoSyncDeployment(
 		nsReconcilerName2,
 		setServiceAccountName(nsReconcilerName2),
@@ -2202,7 +2215,7 @@ func TestMultipleRepoSyncs(t *testing.T) {
 	reposync.SetReconciling(wantRs3, "Deployment", "Replicas: 0/1")
 	validateRepoSyncStatus(t, wantRs3, fakeClient)
 
-	repoContainerEnv3 = testReconciler.populateContainerEnvs(ctx, rs3, nsReconcilerName3)
+	repoContainerEnv3 = repoSyncContainerEnvs(t, testReconciler, rs3, nsReconcilerName3)
 	repoDeployment3 = repoSyncDeployment(
 		nsReconcilerName3,
 		setServiceAccountName(nsReconcilerName3),
@@ -2653,7 +2666,7 @@ func TestInjectFleetWorkloadIdentityCredentialsToRepoSync(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
@@ -2720,7 +2733,7 @@ func TestInjectFleetWorkloadIdentityCredentialsToRepoSync(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 
 	repoDeployment = repoSyncDeployment(
 		nsReconcilerName,
@@ -2753,7 +2766,7 @@ func TestInjectFleetWorkloadIdentityCredentialsToRepoSync(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -2788,7 +2801,7 @@ func TestRepoSyncWithHelm(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
-	repoContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnvs := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 
 	repoDeployment := rootSyncDeployment(nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -2818,7 +2831,7 @@ func TestRepoSyncWithHelm(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	repoContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnvs = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment = repoSyncDeployment(nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
 		containersWithRepoVolumeMutator(noneHelmContainers()),
@@ -2911,7 +2924,7 @@ func TestRepoSyncWithOCI(t *testing.T) {
 		core.UID("1"), core.ResourceVersion("1"), core.Generation(1),
 	)
 
-	repoContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv := repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment := repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -2953,7 +2966,7 @@ func TestRepoSyncWithOCI(t *testing.T) {
 		t.Errorf("ServiceAccount validation failed: %v", err)
 	}
 
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -2982,7 +2995,7 @@ func TestRepoSyncWithOCI(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
-	repoContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, nsReconcilerName)
+	repoContainerEnv = repoSyncContainerEnvs(t, testReconciler, rs, nsReconcilerName)
 	repoDeployment = repoSyncDeployment(
 		nsReconcilerName,
 		setServiceAccountName(nsReconcilerName),
@@ -3435,13 +3448,11 @@ func TestPopulateRepoContainerEnvs(t *testing.T) {
 		},
 	}
 
-	ctx := context.Background()
-
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			_, _, testReconciler := setupNSReconciler(t, tc.repoSync, secretObj(t, reposyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(tc.repoSync.Namespace)))
 
-			env := testReconciler.populateContainerEnvs(ctx, tc.repoSync, nsReconcilerName)
+			env := repoSyncContainerEnvs(t, testReconciler, tc.repoSync, nsReconcilerName)
 
 			for container, vars := range env {
 				if diff := cmp.Diff(tc.expected[container], vars, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b corev1.EnvVar) bool { return a.Name < b.Name })); diff != "" {
@@ -0,0 +1,190 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// scopeNamespaces returns the sorted names of the existing namespaces in the
+// spec.namespaces scope of the RepoSync, other than its own namespace.
+// The config-management-system namespace and terminating namespaces are never
+// in the scope, nor the namespaces which do not allow the RepoSync with the
+// allowed-repo-syncs annotation.
+func (r *RepoSyncReconciler) scopeNamespaces(ctx context.Context, rs *v1beta1.RepoSync) ([]string, error) {
+	scope := rs.Spec.Namespaces
+	if scope == nil {
+		return nil, nil
+	}
+	names := map[string]bool{}
+	for _, name := range scope.Names {
+		names[name] = true
+	}
+	selector := metav1.LabelSelector{}
+	if scope.Selector != nil {
+		selector = *scope.Selector
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(&selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 && labelSelector.Empty() {
+		return nil, nil
+	}
+
+	nsList := &corev1.NamespaceList{}
+	if err := r.client.List(ctx, nsList); err != nil {
+		return nil, errors.Wrap(err, "failed to list Namespaces")
+	}
+	var namespaces []string
+	for _, ns := range nsList.Items {
+		if ns.Name == rs.Namespace || ns.Name == configsync.ControllerNamespace || !ns.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if !names[ns.Name] && (labelSelector.Empty() || !labelSelector.Matches(labels.Set(ns.Labels))) {
+			continue
+		}
+		if !allowsRepoSync(&ns, rs) {
+			klog.V(3).Infof("Namespace %s does not allow RepoSync %s/%s with the %s annotation, skipping it",
+				ns.Name, rs.Namespace, rs.Name, metadata.AllowedRepoSyncsAnnotationKey)
+			continue
+		}
+		namespaces = append(namespaces, ns.Name)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// allowsRepoSync returns whether the allowed-repo-syncs annotation of the
+// Namespace lists the RepoSync. The owners of a namespace opt in to the
+// RepoSyncs of other namespaces syncing objects to it.
+func allowsRepoSync(ns *corev1.Namespace, rs *v1beta1.RepoSync) bool {
+	ref := rs.Namespace + "/" + rs.Name
+	for _, allowed := range strings.Split(core.GetAnnotation(ns, metadata.AllowedRepoSyncsAnnotationKey), ",") {
+		if strings.TrimSpace(allowed) == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// upsertScopeRoleBindings binds the namespace reconciler to the
+// configsync.gke.io:ns-reconciler-scope ClusterRole in each namespace of its
+// scope, and unbinds it from the namespaces which left the scope. Unlike the
+// configsync.gke.io:ns-reconciler ClusterRole bound in the namespace of the
+// RepoSync, it does not grant writing RepoSyncs nor ResourceGroups, which the
+// reconciler only needs in its own namespace.
+func (r *RepoSyncReconciler) upsertScopeRoleBindings(ctx context.Context, reconcilerRef types.NamespacedName, namespaces []string) (client.ObjectKey, error) {
+	for _, ns := range namespaces {
+		rbRef := client.ObjectKey{
+			Namespace: ns,
+			Name:      RepoSyncScopePermissionsName(),
+		}
+		childRB := &rbacv1.RoleBinding{}
+		childRB.Name = rbRef.Name
+		childRB.Namespace = rbRef.Namespace
+		op, err := controllerruntime.CreateOrUpdate(ctx, r.client, childRB, func() error {
+			childRB.RoleRef = rolereference(RepoSyncScopePermissionsName(), "ClusterRole")
+			childRB.Subjects = addSubject(childRB.Subjects, r.serviceAccountSubject(reconcilerRef))
+			return nil
+		})
+		if err != nil {
+			return rbRef, err
+		}
+		if op != controllerutil.OperationResultNone {
+			r.log.Info("Managed object upsert successful",
+				logFieldObject, rbRef.String(),
+				logFieldKind, "RoleBinding",
+				logFieldOperation, op)
+		}
+	}
+	return r.deleteScopeRoleBindings(ctx, reconcilerRef, namespaces)
+}
+
+// deleteScopeRoleBindings removes the namespace reconciler from the
+// configsync.gke.io:ns-reconciler-scope RoleBindings in the namespaces other
+// than the kept namespaces. A RoleBinding left without subjects is deleted.
+func (r *RepoSyncReconciler) deleteScopeRoleBindings(ctx context.Context, reconcilerRef types.NamespacedName, keep []string) (client.ObjectKey, error) {
+	kept := map[string]bool{}
+	for _, ns := range keep {
+		kept[ns] = true
+	}
+	rbList := &rbacv1.RoleBindingList{}
+	if err := r.client.List(ctx, rbList); err != nil {
+		return client.ObjectKey{Name: RepoSyncScopePermissionsName()}, errors.Wrap(err, "failed to list RoleBindings")
+	}
+	subject := r.serviceAccountSubject(reconcilerRef)
+	for i := range rbList.Items {
+		rb := &rbList.Items[i]
+		rbRef := client.ObjectKeyFromObject(rb)
+		if rb.Name != RepoSyncScopePermissionsName() || kept[rb.Namespace] || findSubjectIndex(rb.Subjects, subject) < 0 {
+			continue
+		}
+		rb.Subjects = removeSubject(rb.Subjects, subject)
+		if len(rb.Subjects) == 0 {
+			if err := r.cleanup(ctx, rbRef, kinds.RoleBinding()); err != nil {
+				return rbRef, err
+			}
+			continue
+		}
+		if err := r.client.Update(ctx, rb); err != nil {
+			return rbRef, err
+		}
+		r.log.Info("Managed object update successful",
+			logFieldObject, rbRef.String(),
+			logFieldKind, "RoleBinding")
+	}
+	return client.ObjectKey{}, nil
+}
+
+// mapNamespaceToRepoSyncs requeues the RepoSyncs which specify
+// spec.namespaces, since the Namespace may enter or leave their scope when its
+// labels or its allowed-repo-syncs annotation change.
+func (r *RepoSyncReconciler) mapNamespaceToRepoSyncs(_ client.Object) []reconcile.Request {
+	allRepoSyncs := &v1beta1.RepoSyncList{}
+	if err := r.client.List(context.Background(), allRepoSyncs); err != nil {
+		klog.Errorf("failed to list all RepoSyncs: %v", err)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, rs := range allRepoSyncs.Items {
+		if rs.Spec.Namespaces == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&rs),
+		})
+	}
+	return requests
+}
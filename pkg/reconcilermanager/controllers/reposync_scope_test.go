@@ -0,0 +1,203 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reconcilermanager"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func reposyncNamespaces(names []string, selector *metav1.LabelSelector) func(*v1beta1.RepoSync) {
+	return func(rs *v1beta1.RepoSync) {
+		rs.Spec.Namespaces = &v1beta1.NamespaceScope{Names: names, Selector: selector}
+	}
+}
+
+func TestRepoSyncNamespaceScope(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+
+	shop := core.Label("team", "shop")
+	rs := repoSync(reposyncNs, reposyncName, reposyncRef(gitRevision), reposyncBranch(branch), reposyncSecretType(configsync.AuthNone),
+		reposyncNamespaces([]string{"bar", "missing", configsync.ControllerNamespace}, &metav1.LabelSelector{MatchLabels: map[string]string{"team": "shop"}}))
+	reqNamespacedName := namespacedName(rs.Name, rs.Namespace)
+	allowed := core.Annotation(metadata.AllowedRepoSyncsAnnotationKey, "other/repo-sync, "+reposyncNs+"/"+reposyncName)
+	fakeClient, fakeDynamicClient, testReconciler := setupNSReconciler(t, rs,
+		fake.NamespaceObject(reposyncNs, shop),
+		fake.NamespaceObject("bar", allowed),
+		fake.NamespaceObject("qux", shop, allowed),
+		// Namespaces which do not allow the RepoSync are not in its scope.
+		fake.NamespaceObject("quux", shop),
+		fake.NamespaceObject("corge", shop, core.Annotation(metadata.AllowedRepoSyncsAnnotationKey, reposyncNs+"/other")),
+		fake.NamespaceObject("other"))
+
+	ctx := context.Background()
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+	}
+	// The config-management-system namespace is rejected.
+	if err := fakeClient.Get(ctx, reqNamespacedName.NamespacedName, rs); err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.Status.Conditions) == 0 || rs.Status.Conditions[len(rs.Status.Conditions)-1].Reason != "Validation" {
+		t.Fatalf("got conditions %v, want a Validation error", rs.Status.Conditions)
+	}
+
+	rs.Spec.Namespaces.Names = []string{"bar", "missing"}
+	if err := fakeClient.Update(ctx, rs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+	}
+
+	wantSubjects := addSubjectByName(nil, nsReconcilerName)
+	// The namespace of the RepoSync is bound to the
+	// configsync.gke.io:ns-reconciler ClusterRole, and the other namespaces of
+	// the scope to the narrower configsync.gke.io:ns-reconciler-scope
+	// ClusterRole.
+	rb := func(ns string) *rbacv1.RoleBinding {
+		return rolebinding(RepoSyncScopePermissionsName(), nsReconcilerName, core.Namespace(ns))
+	}
+	ownRB := rolebinding(RepoSyncPermissionsName(), nsReconcilerName, core.Namespace(reposyncNs))
+	ownRB.Subjects = wantSubjects
+	wantRB := rb("bar")
+	wantRB.Subjects = wantSubjects
+	wantRoleBindings := map[core.ID]*rbacv1.RoleBinding{
+		core.IDOf(ownRB):     ownRB,
+		core.IDOf(rb("bar")): wantRB,
+		core.IDOf(rb("qux")): wantRB,
+	}
+	validateRoleBindings(t, wantRoleBindings, fakeClient)
+	for _, ns := range []string{reposyncNs, "quux", "corge", "other", "missing"} {
+		if err := validateResourceDeleted(core.IDOf(rb(ns)), fakeClient); err != nil {
+			t.Error(err)
+		}
+	}
+	gotRB := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(wantRB), gotRB); err != nil {
+		t.Fatal(err)
+	}
+	if want := rolereference(RepoSyncScopePermissionsName(), "ClusterRole"); gotRB.RoleRef != want {
+		t.Errorf("got roleRef %v, want %v", gotRB.RoleRef, want)
+	}
+	validateScopeNamespacesEnv(t, fakeDynamicClient, "bar,qux")
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	// Namespaces leaving the scope are unbound.
+	if err := fakeClient.Get(ctx, reqNamespacedName.NamespacedName, rs); err != nil {
+		t.Fatal(err)
+	}
+	rs.Spec.Namespaces.Selector = nil
+	if err := fakeClient.Update(ctx, rs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
+	}
+	delete(wantRoleBindings, core.IDOf(rb("qux")))
+	validateRoleBindings(t, wantRoleBindings, fakeClient)
+	if err := validateResourceDeleted(core.IDOf(rb("qux")), fakeClient); err != nil {
+		t.Error(err)
+	}
+	validateScopeNamespacesEnv(t, fakeDynamicClient, "bar")
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	// Namespaces which no longer allow the RepoSync leave the scope.
+	bar := &corev1.Namespace{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Name: "bar"}, bar); err != nil {
+		t.Fatal(err)
+	}
+	core.RemoveAnnotations(bar, metadata.AllowedRepoSyncsAnnotationKey)
+	if err := fakeClient.Update(ctx, bar); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error upon namespace update, got error: %q, want error: nil", err)
+	}
+	delete(wantRoleBindings, core.IDOf(rb("bar")))
+	validateRoleBindings(t, wantRoleBindings, fakeClient)
+	if err := validateResourceDeleted(core.IDOf(rb("bar")), fakeClient); err != nil {
+		t.Error(err)
+	}
+	validateScopeNamespacesEnv(t, fakeDynamicClient, "")
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	// Deleting the RepoSync unbinds the reconciler in all the namespaces.
+	if err := fakeClient.Get(ctx, reqNamespacedName.NamespacedName, rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Delete(ctx, rs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error upon request deletion, got error: %q, want error: nil", err)
+	}
+	for id := range wantRoleBindings {
+		if err := validateResourceDeleted(id, fakeClient); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func validateScopeNamespacesEnv(t *testing.T, fakeDynamicClient *syncerFake.DynamicClient, want string) {
+	t.Helper()
+
+	u, err := fakeDynamicClient.Resource(kinds.DeploymentResource()).
+		Namespace(configsync.ControllerNamespace).
+		Get(context.Background(), nsReconcilerName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the reconciler Deployment: %v", err)
+	}
+	d := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if c.Name != reconcilermanager.Reconciler {
+			continue
+		}
+		for _, env := range c.Env {
+			if env.Name == reconcilermanager.ScopeNamespaces {
+				got = env.Value
+			}
+		}
+	}
+	if got != want {
+		t.Errorf("got %s=%q, want %q", reconcilermanager.ScopeNamespaces, got, want)
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/status"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var _ reconcile.Reconciler = &RepoSyncTemplateReconciler{}
+
+// RepoSyncTemplateReconciler reconciles RepoSyncTemplate objects. It creates a
+// RepoSync, with its RoleBinding and Secrets, in every Namespace selected by a
+// RepoSyncTemplate, and deletes them from the Namespaces which are no longer
+// selected. The RepoSyncs are then reconciled by the RepoSyncReconciler.
+type RepoSyncTemplateReconciler struct {
+	client client.Client
+	log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+// NewRepoSyncTemplateReconciler returns a new RepoSyncTemplateReconciler.
+func NewRepoSyncTemplateReconciler(client client.Client, log logr.Logger, scheme *runtime.Scheme) *RepoSyncTemplateReconciler {
+	return &RepoSyncTemplateReconciler{
+		client: client,
+		log:    log,
+		scheme: scheme,
+	}
+}
+
+// templateChild identifies an object created from a RepoSyncTemplate.
+type templateChild struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// +kubebuilder:rbac:groups=configsync.gke.io,resources=reposynctemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=configsync.gke.io,resources=reposynctemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile the RepoSyncTemplate resource.
+func (r *RepoSyncTemplateReconciler) Reconcile(ctx context.Context, req controllerruntime.Request) (controllerruntime.Result, error) {
+	log := r.log.WithValues("reposynctemplate", req.Name)
+
+	tmpl := &v1beta1.RepoSyncTemplate{}
+	if err := r.client.Get(ctx, req.NamespacedName, tmpl); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The created objects are garbage collected with their owner.
+			return controllerruntime.Result{}, nil
+		}
+		return controllerruntime.Result{}, status.APIServerError(err, "failed to get RepoSyncTemplate")
+	}
+	if !tmpl.DeletionTimestamp.IsZero() {
+		return controllerruntime.Result{}, nil
+	}
+	// The errors refer to the RepoSyncTemplate, which needs its GVK.
+	tmpl.SetGroupVersionKind(kinds.RepoSyncTemplate())
+	currentTmpl := tmpl.DeepCopy()
+
+	var errs status.MultiError
+	namespaces, err := r.selectedNamespaces(ctx, tmpl)
+	if err != nil {
+		errs = status.Append(errs, err)
+	}
+	desired := make(map[templateChild]bool)
+	var namespaceNames []string
+	for i := range namespaces {
+		namespaceNames = append(namespaceNames, namespaces[i].Name)
+		errs = status.Append(errs, r.upsertNamespace(ctx, tmpl, &namespaces[i], desired))
+	}
+	// Objects are only pruned once the Namespaces could be listed, so that a
+	// failure to list them does not delete every RepoSync.
+	if err == nil {
+		errs = status.Append(errs, r.prune(ctx, tmpl, desired))
+	}
+
+	tmpl.Status.ObservedGeneration = tmpl.Generation
+	tmpl.Status.Namespaces = namespaceNames
+	tmpl.Status.NamespaceCount = len(namespaceNames)
+	tmpl.Status.Errors = status.ToCSE(errs)
+	tmpl.Status.ErrorSummary = &v1beta1.ErrorSummary{
+		TotalCount:                len(tmpl.Status.Errors),
+		ErrorCountAfterTruncation: len(tmpl.Status.Errors),
+	}
+	if !equality.Semantic.DeepEqual(currentTmpl.Status, tmpl.Status) {
+		if err := r.client.Status().Update(ctx, tmpl); err != nil {
+			log.Error(err, "Failed to update RepoSyncTemplate status")
+			return controllerruntime.Result{}, status.APIServerError(err, "failed to update RepoSyncTemplate status", tmpl)
+		}
+	}
+
+	// Only retry the errors returned by the API server. The other errors are
+	// fixed by updating the RepoSyncTemplate, a Namespace or a Secret, which
+	// triggers a new reconciliation.
+	if errs != nil {
+		for _, e := range errs.Errors() {
+			if e.Code() == status.APIServerErrorCode || e.Code() == status.InsufficientPermissionErrorCode {
+				log.Error(errs, "Failed to reconcile RepoSyncTemplate")
+				return controllerruntime.Result{}, errs
+			}
+		}
+	}
+	return controllerruntime.Result{}, nil
+}
+
+// selectedNamespaces returns the Namespaces selected by the RepoSyncTemplate,
+// sorted by name. An empty selector selects no Namespace.
+func (r *RepoSyncTemplateReconciler) selectedNamespaces(ctx context.Context, tmpl *v1beta1.RepoSyncTemplate) ([]corev1.Namespace, status.Error) {
+	selector, err := metav1.LabelSelectorAsSelector(&tmpl.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, reposync.RepoSyncTemplateError(tmpl, "", err)
+	}
+	if selector.Empty() {
+		return nil, nil
+	}
+	nsList := &corev1.NamespaceList{}
+	if err := r.client.List(ctx, nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, status.APIServerError(err, "failed to list Namespaces", tmpl)
+	}
+	var namespaces []corev1.Namespace
+	for _, ns := range nsList.Items {
+		// RepoSyncs are not allowed in the config-management-system namespace,
+		// and objects cannot be created in terminating Namespaces.
+		if ns.Name == configsync.ControllerNamespace || !ns.DeletionTimestamp.IsZero() {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+	return namespaces, nil
+}
+
+// upsertNamespace creates or updates the Secrets, the RoleBinding and the
+// RepoSync of the RepoSyncTemplate in the given Namespace, and records them
+// as desired.
+func (r *RepoSyncTemplateReconciler) upsertNamespace(ctx context.Context, tmpl *v1beta1.RepoSyncTemplate, ns *corev1.Namespace, desired map[templateChild]bool) status.MultiError {
+	rsName := reposync.TemplateRepoSyncName(tmpl)
+	var errs status.MultiError
+
+	for _, s := range tmpl.Spec.Secrets {
+		desired[templateChild{kind: "Secret", namespace: ns.Name, name: s.Name}] = true
+		errs = status.Append(errs, r.upsertSecret(ctx, tmpl, ns.Name, s))
+	}
+
+	if tmpl.Spec.ClusterRole != "" {
+		rbName := RepoSyncTemplatePermissionsName(tmpl.Name)
+		desired[templateChild{kind: "RoleBinding", namespace: ns.Name, name: rbName}] = true
+		rb := &rbacv1.RoleBinding{}
+		rb.Name = rbName
+		rb.Namespace = ns.Name
+		errs = status.Append(errs, r.upsert(ctx, tmpl, rb, "RoleBinding", func() {
+			rb.RoleRef = rolereference(tmpl.Spec.ClusterRole, "ClusterRole")
+			rb.Subjects = []rbacv1.Subject{
+				newSubject(core.NsReconcilerName(ns.Name, rsName), configsync.ControllerNamespace, kinds.ServiceAccount().Kind),
+			}
+		}))
+	}
+
+	// The RepoSync is kept when its spec cannot be rendered, so that a typo in
+	// the template or a missing label does not stop the sync.
+	desired[templateChild{kind: configsync.RepoSyncKind, namespace: ns.Name, name: rsName}] = true
+	spec, err := reposync.TemplateSpec(tmpl, ns)
+	if err != nil {
+		return status.Append(errs, err)
+	}
+	rs := &v1beta1.RepoSync{}
+	rs.Name = rsName
+	rs.Namespace = ns.Name
+	return status.Append(errs, r.upsert(ctx, tmpl, rs, configsync.RepoSyncKind, func() {
+		rs.Spec = spec
+	}))
+}
+
+// upsertSecret copies the type and data of the source Secret in the
+// config-management-system namespace to the given namespace.
+func (r *RepoSyncTemplateReconciler) upsertSecret(ctx context.Context, tmpl *v1beta1.RepoSyncTemplate, namespace string, s v1beta1.RepoSyncTemplateSecret) status.Error {
+	sourceRef := types.NamespacedName{
+		Namespace: configsync.ControllerNamespace,
+		Name:      s.SourceName,
+	}
+	if sourceRef.Name == "" {
+		sourceRef.Name = s.Name
+	}
+	source := &corev1.Secret{}
+	if err := r.client.Get(ctx, sourceRef, source); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reposync.RepoSyncTemplateError(tmpl, namespace,
+				fmt.Errorf("the source Secret %s does not exist", sourceRef))
+		}
+		return status.APIServerErrorf(err, "failed to get Secret %s", sourceRef)
+	}
+	secret := &corev1.Secret{}
+	secret.Name = s.Name
+	secret.Namespace = namespace
+	return r.upsert(ctx, tmpl, secret, "Secret", func() {
+		secret.Type = source.Type
+		secret.Data = source.Data
+	})
+}
+
+// upsert creates or updates an object created from the RepoSyncTemplate.
+// Objects which already exist, but were not created from the
+// RepoSyncTemplate, are reported instead of being taken over.
+func (r *RepoSyncTemplateReconciler) upsert(ctx context.Context, tmpl *v1beta1.RepoSyncTemplate, obj client.Object, kind string, mutate func()) status.Error {
+	op, err := controllerruntime.CreateOrUpdate(ctx, r.client, obj, func() error {
+		if obj.GetResourceVersion() != "" && obj.GetLabels()[metadata.RepoSyncTemplateLabel] != tmpl.Name {
+			return reposync.RepoSyncTemplateError(tmpl, obj.GetNamespace(),
+				fmt.Errorf("the %s %s already exists and was not created from the RepoSyncTemplate", kind, obj.GetName()))
+		}
+		core.SetLabel(obj, metadata.RepoSyncTemplateLabel, tmpl.Name)
+		mutate()
+		return controllerutil.SetControllerReference(tmpl, obj, r.scheme)
+	})
+	if err != nil {
+		if statusErr, ok := err.(status.Error); ok {
+			return statusErr
+		}
+		return status.APIServerErrorf(err, "failed to upsert %s %s", kind, client.ObjectKeyFromObject(obj))
+	}
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("Managed object upsert successful",
+			logFieldObject, client.ObjectKeyFromObject(obj).String(),
+			logFieldKind, kind,
+			logFieldOperation, op)
+	}
+	return nil
+}
+
+// prune deletes the objects created from the RepoSyncTemplate which are no
+// longer desired, such as the objects in the Namespaces which are no longer
+// selected.
+func (r *RepoSyncTemplateReconciler) prune(ctx context.Context, tmpl *v1beta1.RepoSyncTemplate, desired map[templateChild]bool) status.MultiError {
+	opts := client.MatchingLabels{metadata.RepoSyncTemplateLabel: tmpl.Name}
+	var objs []client.Object
+
+	rsList := &v1beta1.RepoSyncList{}
+	if err := r.client.List(ctx, rsList, opts); err != nil {
+		return status.APIServerError(err, "failed to list RepoSyncs", tmpl)
+	}
+	for i := range rsList.Items {
+		objs = append(objs, &rsList.Items[i])
+	}
+	rbList := &rbacv1.RoleBindingList{}
+	if err := r.client.List(ctx, rbList, opts); err != nil {
+		return status.APIServerError(err, "failed to list RoleBindings", tmpl)
+	}
+	for i := range rbList.Items {
+		objs = append(objs, &rbList.Items[i])
+	}
+	secretList := &corev1.SecretList{}
+	if err := r.client.List(ctx, secretList, opts); err != nil {
+		return status.APIServerError(err, "failed to list Secrets", tmpl)
+	}
+	for i := range secretList.Items {
+		objs = append(objs, &secretList.Items[i])
+	}
+
+	var errs status.MultiError
+	for _, obj := range objs {
+		kind := templateChildKind(obj)
+		if desired[templateChild{kind: kind, namespace: obj.GetNamespace(), name: obj.GetName()}] {
+			continue
+		}
+		// Objects which were labelled by someone else are left alone.
+		if !metav1.IsControlledBy(obj, tmpl) {
+			continue
+		}
+		if err := r.client.Delete(ctx, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = status.Append(errs, status.APIServerErrorf(err, "failed to delete %s %s", kind, client.ObjectKeyFromObject(obj)))
+			}
+			continue
+		}
+		r.log.Info("Managed object delete successful",
+			logFieldObject, client.ObjectKeyFromObject(obj).String(),
+			logFieldKind, kind)
+	}
+	return errs
+}
+
+// templateChildKind returns the kind of an object created from a
+// RepoSyncTemplate. The items of typed lists have no type information.
+func templateChildKind(obj client.Object) string {
+	switch obj.(type) {
+	case *v1beta1.RepoSync:
+		return configsync.RepoSyncKind
+	case *rbacv1.RoleBinding:
+		return "RoleBinding"
+	default:
+		return "Secret"
+	}
+}
+
+// SetupWithManager registers RepoSyncTemplate controller with reconciler-manager.
+func (r *RepoSyncTemplateReconciler) SetupWithManager(mgr controllerruntime.Manager) error {
+	return controllerruntime.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		For(&v1beta1.RepoSyncTemplate{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&v1beta1.RepoSync{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&corev1.Secret{}).
+		// Custom Watch to trigger Reconcile when the labels or annotations of a
+		// Namespace change, since they select it and fill in the template.
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToTemplates),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		// Custom Watch to trigger Reconcile when a source Secret changes.
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToTemplates),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Complete(r)
+}
+
+// mapNamespaceToTemplates requeues all the RepoSyncTemplates, since any of
+// them may select the Namespace.
+func (r *RepoSyncTemplateReconciler) mapNamespaceToTemplates(_ client.Object) []reconcile.Request {
+	tmplList := &v1beta1.RepoSyncTemplateList{}
+	if err := r.client.List(context.Background(), tmplList); err != nil {
+		klog.Errorf("failed to list all RepoSyncTemplates: %v", err)
+		return nil
+	}
+	requests := make([]reconcile.Request, len(tmplList.Items))
+	for i, tmpl := range tmplList.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: tmpl.Name},
+		}
+	}
+	return requests
+}
+
+// mapSecretToTemplates maps a Secret in the config-management-system
+// namespace to the RepoSyncTemplates which copy it.
+func (r *RepoSyncTemplateReconciler) mapSecretToTemplates(secret client.Object) []reconcile.Request {
+	if secret.GetNamespace() != configsync.ControllerNamespace {
+		return nil
+	}
+	tmplList := &v1beta1.RepoSyncTemplateList{}
+	if err := r.client.List(context.Background(), tmplList); err != nil {
+		klog.Errorf("failed to list all RepoSyncTemplates for Secret (name: %s, namespace: %s): %v", secret.GetName(), secret.GetNamespace(), err)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, tmpl := range tmplList.Items {
+		for _, s := range tmpl.Spec.Secrets {
+			if s.SourceName == secret.GetName() || (s.SourceName == "" && s.Name == secret.GetName()) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: tmpl.Name},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
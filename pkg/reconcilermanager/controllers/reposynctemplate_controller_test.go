@@ -0,0 +1,254 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	syncerFake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const tenantTemplateName = "tenants"
+
+func tenantNamespace(name string, labels, annotations map[string]string) *corev1.Namespace {
+	ns := &corev1.Namespace{}
+	ns.Name = name
+	ns.Labels = labels
+	ns.Annotations = annotations
+	return ns
+}
+
+func tenantTemplate() *v1beta1.RepoSyncTemplate {
+	tmpl := &v1beta1.RepoSyncTemplate{}
+	tmpl.Name = tenantTemplateName
+	tmpl.Spec.NamespaceSelector = metav1.LabelSelector{
+		MatchLabels: map[string]string{"tenant": "true"},
+	}
+	tmpl.Spec.Template = v1beta1.RepoSyncSpec{
+		SourceType: string(v1beta1.GitSource),
+		Git: &v1beta1.Git{
+			Repo:      "https://github.com/example/${namespace.labels.team}",
+			Dir:       "${namespace.annotations.example.com/dir}",
+			Branch:    "main",
+			Auth:      configsync.AuthToken,
+			SecretRef: &v1beta1.SecretReference{Name: "git-creds"},
+		},
+	}
+	tmpl.Spec.ClusterRole = "edit"
+	tmpl.Spec.Secrets = []v1beta1.RepoSyncTemplateSecret{{Name: "git-creds", SourceName: "tenant-git-creds"}}
+	return tmpl
+}
+
+func tenantSourceSecret() *corev1.Secret {
+	s := &corev1.Secret{}
+	s.Name = "tenant-git-creds"
+	s.Namespace = configsync.ControllerNamespace
+	s.Type = corev1.SecretTypeOpaque
+	s.Data = map[string][]byte{"username": []byte("bot"), "token": []byte("t0ken")}
+	return s
+}
+
+func setupRepoSyncTemplateReconciler(t *testing.T, objs ...client.Object) (*syncerFake.Client, *RepoSyncTemplateReconciler) {
+	t.Helper()
+
+	fakeClient := syncerFake.NewClient(t, core.Scheme, objs...)
+	testReconciler := NewRepoSyncTemplateReconciler(
+		fakeClient,
+		controllerruntime.Log.WithName("controllers").WithName(configsync.RepoSyncTemplateKind),
+		fakeClient.Scheme(),
+	)
+	return fakeClient, testReconciler
+}
+
+func reconcileTemplate(t *testing.T, r *RepoSyncTemplateReconciler) {
+	t.Helper()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: tenantTemplateName}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected reconciliation error: %v", err)
+	}
+}
+
+func getTemplate(t *testing.T, c client.Client) *v1beta1.RepoSyncTemplate {
+	t.Helper()
+	tmpl := &v1beta1.RepoSyncTemplate{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: tenantTemplateName}, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+func TestRepoSyncTemplateReconciler(t *testing.T) {
+	shop := tenantNamespace("shop", map[string]string{"tenant": "true", "team": "shop-team"},
+		map[string]string{"example.com/dir": "config"})
+	billing := tenantNamespace("billing", map[string]string{"tenant": "true", "team": "billing-team"},
+		map[string]string{"example.com/dir": "manifests"})
+	other := tenantNamespace("other", map[string]string{"team": "other-team"}, nil)
+
+	fakeClient, r := setupRepoSyncTemplateReconciler(t, tenantTemplate(), tenantSourceSecret(), shop, billing, other)
+	ctx := context.Background()
+	reconcileTemplate(t, r)
+
+	for _, ns := range []*corev1.Namespace{shop, billing} {
+		rs := &v1beta1.RepoSync{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: reposync.DefaultTemplateRepoSyncName}, rs); err != nil {
+			t.Fatalf("RepoSync in %s: %v", ns.Name, err)
+		}
+		if want := "https://github.com/example/" + ns.Labels["team"]; rs.Spec.Git.Repo != want {
+			t.Errorf("RepoSync in %s: got repo %q, want %q", ns.Name, rs.Spec.Git.Repo, want)
+		}
+		if want := ns.Annotations["example.com/dir"]; rs.Spec.Git.Dir != want {
+			t.Errorf("RepoSync in %s: got dir %q, want %q", ns.Name, rs.Spec.Git.Dir, want)
+		}
+		if rs.Labels[metadata.RepoSyncTemplateLabel] != tenantTemplateName {
+			t.Errorf("RepoSync in %s: missing template label, got labels %v", ns.Name, rs.Labels)
+		}
+
+		rb := &rbacv1.RoleBinding{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: RepoSyncTemplatePermissionsName(tenantTemplateName)}, rb); err != nil {
+			t.Fatalf("RoleBinding in %s: %v", ns.Name, err)
+		}
+		wantSubjects := []rbacv1.Subject{{
+			Kind:      "ServiceAccount",
+			Name:      core.NsReconcilerName(ns.Name, reposync.DefaultTemplateRepoSyncName),
+			Namespace: configsync.ControllerNamespace,
+		}}
+		if diff := cmp.Diff(wantSubjects, rb.Subjects); diff != "" {
+			t.Errorf("RoleBinding in %s: subjects diff (- want, + got):\n%s", ns.Name, diff)
+		}
+		if rb.RoleRef.Name != "edit" {
+			t.Errorf("RoleBinding in %s: got roleRef %q, want %q", ns.Name, rb.RoleRef.Name, "edit")
+		}
+
+		secret := &corev1.Secret{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "git-creds"}, secret); err != nil {
+			t.Fatalf("Secret in %s: %v", ns.Name, err)
+		}
+		if diff := cmp.Diff(tenantSourceSecret().Data, secret.Data); diff != "" {
+			t.Errorf("Secret in %s: data diff (- want, + got):\n%s", ns.Name, diff)
+		}
+	}
+
+	rs := &v1beta1.RepoSync{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: other.Name, Name: reposync.DefaultTemplateRepoSyncName}, rs); !apierrors.IsNotFound(err) {
+		t.Errorf("RepoSync in the unselected namespace: got %v, want NotFound", err)
+	}
+
+	tmpl := getTemplate(t, fakeClient)
+	if diff := cmp.Diff([]string{"billing", "shop"}, tmpl.Status.Namespaces); diff != "" {
+		t.Errorf("status.namespaces diff (- want, + got):\n%s", diff)
+	}
+	if len(tmpl.Status.Errors) != 0 {
+		t.Errorf("unexpected status errors: %v", tmpl.Status.Errors)
+	}
+
+	// The namespace stops matching the selector.
+	billing = &corev1.Namespace{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "billing"}, billing); err != nil {
+		t.Fatal(err)
+	}
+	delete(billing.Labels, "tenant")
+	if err := fakeClient.Update(ctx, billing); err != nil {
+		t.Fatal(err)
+	}
+	reconcileTemplate(t, r)
+
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "billing", Name: reposync.DefaultTemplateRepoSyncName}, &v1beta1.RepoSync{}); !apierrors.IsNotFound(err) {
+		t.Errorf("RepoSync in the unselected namespace: got %v, want NotFound", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "billing", Name: RepoSyncTemplatePermissionsName(tenantTemplateName)}, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+		t.Errorf("RoleBinding in the unselected namespace: got %v, want NotFound", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "billing", Name: "git-creds"}, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Secret in the unselected namespace: got %v, want NotFound", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "shop", Name: reposync.DefaultTemplateRepoSyncName}, &v1beta1.RepoSync{}); err != nil {
+		t.Errorf("RepoSync in the selected namespace: %v", err)
+	}
+	tmpl = getTemplate(t, fakeClient)
+	if diff := cmp.Diff([]string{"shop"}, tmpl.Status.Namespaces); diff != "" {
+		t.Errorf("status.namespaces diff (- want, + got):\n%s", diff)
+	}
+}
+
+func TestRepoSyncTemplateReconcilerErrors(t *testing.T) {
+	// The annotation holding the directory is missing.
+	shop := tenantNamespace("shop", map[string]string{"tenant": "true", "team": "shop-team"}, nil)
+	// The RepoSync already exists and was not created from the template.
+	billing := tenantNamespace("billing", map[string]string{"tenant": "true", "team": "billing-team"},
+		map[string]string{"example.com/dir": "manifests"})
+	existing := &v1beta1.RepoSync{}
+	existing.Name = reposync.DefaultTemplateRepoSyncName
+	existing.Namespace = "billing"
+
+	fakeClient, r := setupRepoSyncTemplateReconciler(t, tenantTemplate(), tenantSourceSecret(), shop, billing, existing)
+	ctx := context.Background()
+	reconcileTemplate(t, r)
+
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "shop", Name: reposync.DefaultTemplateRepoSyncName}, &v1beta1.RepoSync{}); !apierrors.IsNotFound(err) {
+		t.Errorf("RepoSync with an undefined variable: got %v, want NotFound", err)
+	}
+	rs := &v1beta1.RepoSync{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "billing", Name: reposync.DefaultTemplateRepoSyncName}, rs); err != nil {
+		t.Fatal(err)
+	}
+	if rs.Spec.Git != nil || rs.Labels[metadata.RepoSyncTemplateLabel] != "" {
+		t.Errorf("the existing RepoSync was taken over: %v", rs)
+	}
+
+	tmpl := getTemplate(t, fakeClient)
+	if len(tmpl.Status.Errors) != 2 {
+		t.Fatalf("got %d status errors, want 2: %v", len(tmpl.Status.Errors), tmpl.Status.Errors)
+	}
+	for _, e := range tmpl.Status.Errors {
+		if e.Code != reposync.RepoSyncTemplateErrorCode {
+			t.Errorf("got error code %s, want %s: %s", e.Code, reposync.RepoSyncTemplateErrorCode, e.ErrorMessage)
+		}
+	}
+	if tmpl.Status.ErrorSummary == nil || tmpl.Status.ErrorSummary.TotalCount != 2 {
+		t.Errorf("got error summary %v, want a total count of 2", tmpl.Status.ErrorSummary)
+	}
+}
+
+func TestMapSecretToTemplates(t *testing.T) {
+	_, r := setupRepoSyncTemplateReconciler(t, tenantTemplate())
+
+	source := tenantSourceSecret()
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: tenantTemplateName}}}
+	if diff := cmp.Diff(want, r.mapSecretToTemplates(source)); diff != "" {
+		t.Errorf("source Secret diff (- want, + got):\n%s", diff)
+	}
+
+	copied := &corev1.Secret{}
+	copied.Name = "tenant-git-creds"
+	copied.Namespace = "shop"
+	if got := r.mapSecretToTemplates(copied); len(got) != 0 {
+		t.Errorf("Secret outside of %s: got requests %v, want none", configsync.ControllerNamespace, got)
+	}
+}
@@ -78,7 +78,7 @@ type RootSyncReconciler struct {
 }
 
 // NewRootSyncReconciler returns a new RootSyncReconciler.
-func NewRootSyncReconciler(clusterName string, reconcilerPollingPeriod, hydrationPollingPeriod time.Duration, client client.Client, dynamicClient dynamic.Interface, log logr.Logger, scheme *runtime.Scheme) *RootSyncReconciler {
+func NewRootSyncReconciler(clusterName string, reconcilerPollingPeriod, hydrationPollingPeriod time.Duration, renderCacheClaim string, traceSamplingFraction float64, client client.Client, dynamicClient dynamic.Interface, log logr.Logger, scheme *runtime.Scheme) *RootSyncReconciler {
 	return &RootSyncReconciler{
 		reconcilerBase: reconcilerBase{
 			clusterName:             clusterName,
@@ -88,6 +88,8 @@ func NewRootSyncReconciler(clusterName string, reconcilerPollingPeriod, hydratio
 			scheme:                  scheme,
 			reconcilerPollingPeriod: reconcilerPollingPeriod,
 			hydrationPollingPeriod:  hydrationPollingPeriod,
+			renderCacheClaim:        renderCacheClaim,
+			traceSamplingFraction:   traceSamplingFraction,
 			syncKind:                configsync.RootSyncKind,
 		},
 	}
@@ -245,7 +247,19 @@ func (r *RootSyncReconciler) Reconcile(ctx context.Context, req controllerruntim
 		return controllerruntime.Result{}, errors.Wrap(err, "ClusterRoleBinding reconcile failed")
 	}
 
-	containerEnvs := r.populateContainerEnvs(ctx, rs, reconcilerRef.Name)
+	containerEnvs, err := r.populateContainerEnvs(ctx, rs, reconcilerRef.Name)
+	if err != nil {
+		log.Error(err, "Spec invalid",
+			logFieldObject, rsRef.String(),
+			logFieldKind, r.syncKind)
+		rootsync.SetStalled(rs, "Validation", err)
+		// Validation errors should not trigger retry (return error),
+		// unless the status update also fails.
+		_, updateErr := r.updateStatus(ctx, currentRS, rs)
+		// Use the validation error for metric tagging.
+		metrics.RecordReconcileDuration(ctx, metrics.StatusTagKey(err), start)
+		return controllerruntime.Result{}, updateErr
+	}
 	mut := r.mutationsFor(ctx, rs, containerEnvs)
 
 	// Upsert Root reconciler deployment.
@@ -471,11 +485,40 @@ func (r *RootSyncReconciler) mapSecretToRootSyncs(secret client.Object) []reconc
 	return requests
 }
 
-func (r *RootSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1beta1.RootSync, reconcilerName string) map[string][]corev1.EnvVar {
+func (r *RootSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1beta1.RootSync, reconcilerName string) (map[string][]corev1.EnvVar, error) {
+	ignoreDifferences, err := ignoreDifferencesEnvs(rs.Spec.IgnoreDifferences)
+	if err != nil {
+		return nil, err
+	}
 	result := map[string][]corev1.EnvVar{
 		reconcilermanager.HydrationController: hydrationEnvs(rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, declared.RootReconciler, reconcilerName, r.hydrationPollingPeriod.String()),
-		reconcilermanager.Reconciler:          append(reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.RootReconciler, rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, rootsync.GetHelmBase(rs.Spec.Helm), r.reconcilerPollingPeriod.String(), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)), sourceFormatEnv(rs.Spec.SourceFormat)),
+		reconcilermanager.Reconciler:          append(reconcilerEnvs(r.clusterName, rs.Name, reconcilerName, declared.RootReconciler, rs.Spec.SourceType, rs.Spec.Git, rs.Spec.Oci, rootsync.GetHelmBase(rs.Spec.Helm), r.reconcilerPollingPeriod.String(), rs.Spec.SafeOverride().StatusMode, v1beta1.GetReconcileTimeout(rs.Spec.SafeOverride().ReconcileTimeout), v1beta1.GetAPIServerTimeout(rs.Spec.SafeOverride().APIServerTimeout)), append(ignoreDifferences, sourceFormatEnv(rs.Spec.SourceFormat))...),
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], pruneBudgetEnvs(rs.Spec.PruneBudget)...)
+	conflictPolicy, err := conflictPolicyEnvs(rs.Spec.ConflictPolicy)
+	if err != nil {
+		return nil, err
 	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], conflictPolicy...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], adoptionPolicyEnvs(rs.Spec.Adoption)...)
+	jsonnetConfig, err := jsonnetConfigEnvs(rs.Spec.Jsonnet)
+	if err != nil {
+		return nil, err
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], jsonnetConfig...)
+	kustomizeConfig, err := kustomizeConfigEnvs(rs.Spec.Kustomize)
+	if err != nil {
+		return nil, err
+	}
+	result[reconcilermanager.HydrationController] = append(result[reconcilermanager.HydrationController], kustomizeConfig...)
+	result[reconcilermanager.HydrationController] = append(result[reconcilermanager.HydrationController], renderCacheEnvs(r.renderCacheClaim)...)
+	result[reconcilermanager.HydrationController] = append(result[reconcilermanager.HydrationController], tracingEnvs(r.traceSamplingFraction)...)
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], tracingEnvs(r.traceSamplingFraction)...)
+	notificationConfig, err := notificationConfigEnvs(rs.Spec.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	result[reconcilermanager.Reconciler] = append(result[reconcilermanager.Reconciler], notificationConfig...)
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
 		result[reconcilermanager.GitSync] = gitSyncEnvs(ctx, options{
@@ -494,10 +537,25 @@ func (r *RootSyncReconciler) populateContainerEnvs(ctx context.Context, rs *v1be
 	case v1beta1.HelmSource:
 		result[reconcilermanager.HelmSync] = helmSyncEnvs(&rs.Spec.Helm.HelmBase, rs.Spec.Helm.Namespace)
 	}
-	return result
+	return result, nil
 }
 
 func (r *RootSyncReconciler) validateSpec(ctx context.Context, rs *v1beta1.RootSync, log logr.Logger) error {
+	if err := validate.IgnoreDifferencesSpec(rs.Spec.IgnoreDifferences, rs); err != nil {
+		return err
+	}
+	if err := validate.ConflictPolicySpec(rs.Spec.ConflictPolicy, rs); err != nil {
+		return err
+	}
+	if err := validate.JsonnetSpec(rs.Spec.Jsonnet, rs); err != nil {
+		return err
+	}
+	if err := validate.KustomizeSpec(rs.Spec.Kustomize, rs); err != nil {
+		return err
+	}
+	if err := validate.NotificationSpec(rs.Spec.Notifications, rs); err != nil {
+		return err
+	}
 	switch v1beta1.SourceType(rs.Spec.SourceType) {
 	case v1beta1.GitSource:
 		return r.validateGitSpec(ctx, rs, log)
@@ -650,6 +708,8 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 		// authenticate with the git or helm repository using the authorization method specified
 		// in the RootSync CR.
 		templateSpec.Volumes = filterVolumes(templateSpec.Volumes, auth, secretRefName, caCertSecretRefName, rs.Spec.SourceType, r.membership)
+		templateSpec.Volumes = renderCacheVolumes(r.renderCacheClaim, templateSpec.Volumes)
+		templateSpec.Volumes = secretVolumes(rs.Spec.SecretVolume, templateSpec.Volumes)
 
 		var updatedContainers []corev1.Container
 
@@ -658,9 +718,13 @@ func (r *RootSyncReconciler) mutationsFor(ctx context.Context, rs *v1beta1.RootS
 			switch container.Name {
 			case reconcilermanager.Reconciler:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
+				container.Env = append(container.Env, notificationHMACKeyEnv(notificationSecretName(rs.Spec.Notifications))...)
+				container.Env = append(container.Env, decryptionKeyEnv(decryptionSecretName(rs.Spec.Decryption))...)
+				container.VolumeMounts = secretVolumeMounts(rs.Spec.SecretVolume, container.VolumeMounts)
 				mutateContainerResource(&container, rs.Spec.Override)
 			case reconcilermanager.HydrationController:
 				container.Env = append(container.Env, containerEnvs[container.Name]...)
+				container.VolumeMounts = renderCacheVolumeMounts(r.renderCacheClaim, rootSyncRenderCacheSubPath, container.VolumeMounts)
 				if rs.Spec.SafeOverride().EnableShellInRendering == nil || !*rs.Spec.SafeOverride().EnableShellInRendering {
 					container.Image = strings.ReplaceAll(container.Image, reconcilermanager.HydrationControllerWithShell, reconcilermanager.HydrationController)
 				} else {
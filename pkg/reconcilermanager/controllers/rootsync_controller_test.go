@@ -37,6 +37,7 @@ import (
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	hubv1 "kpt.dev/configsync/pkg/api/hub/v1"
+	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/importer/filesystem"
 	"kpt.dev/configsync/pkg/kinds"
@@ -118,6 +119,8 @@ func setupRootReconciler(t *testing.T, objs ...client.Object) (*syncerFake.Clien
 		testCluster,
 		filesystemPollingPeriod,
 		hydrationPollingPeriod,
+		"",
+		0,
 		fakeClient,
 		fakeDynamicClient,
 		controllerruntime.Log.WithName("controllers").WithName("RootSync"),
@@ -126,6 +129,17 @@ func setupRootReconciler(t *testing.T, objs ...client.Object) (*syncerFake.Clien
 	return fakeClient, fakeDynamicClient, testReconciler
 }
 
+// rootSyncContainerEnvs returns the container environment variables of the
+// reconciler of the RootSync.
+func rootSyncContainerEnvs(t *testing.T, r *RootSyncReconciler, rs *v1beta1.RootSync, reconcilerName string) map[string][]corev1.EnvVar {
+	t.Helper()
+	envs, err := r.populateContainerEnvs(context.Background(), rs, reconcilerName)
+	if err != nil {
+		t.Fatalf("failed to populate the container envs: %v", err)
+	}
+	return envs
+}
+
 func rootsyncRef(rev string) func(*v1beta1.RootSync) {
 	return func(rs *v1beta1.RootSync) {
 		rs.Spec.Revision = rev
@@ -211,6 +225,12 @@ func rootsyncCACert(caCertSecretRef string) func(*v1beta1.RootSync) {
 	}
 }
 
+func rootsyncSecretVolume(driver string) func(*v1beta1.RootSync) {
+	return func(rs *v1beta1.RootSync) {
+		rs.Spec.SecretVolume = &v1beta1.SecretVolume{CSI: corev1.CSIVolumeSource{Driver: driver}}
+	}
+}
+
 func rootSync(name string, opts ...func(*v1beta1.RootSync)) *v1beta1.RootSync {
 	rs := fake.RootSyncObjectV1Beta1(name)
 	rs.Spec.SourceType = string(v1beta1.GitSource)
@@ -290,7 +310,7 @@ func TestCreateAndUpdateRootReconcilerWithOverride(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -398,7 +418,7 @@ func TestUpdateRootReconcilerWithOverride(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -602,7 +622,7 @@ func TestRootSyncCreateWithNoSSLVerify(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -634,7 +654,7 @@ func TestRootSyncUpdateNoSSLVerify(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -686,7 +706,7 @@ func TestRootSyncUpdateNoSSLVerify(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	updatedRootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -744,7 +764,7 @@ func TestRootSyncCreateWithCACertSecret(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -763,6 +783,39 @@ func TestRootSyncCreateWithCACertSecret(t *testing.T) {
 	t.Log("Deployment successfully created")
 }
 
+func TestRootSyncCreateWithSecretVolume(t *testing.T) {
+	// Mock out parseDeployment for testing.
+	parseDeployment = parsedDeployment
+	csiDriver := "secrets-store.csi.k8s.io"
+	rs := rootSync(rootsyncName, rootsyncRef(gitRevision), rootsyncBranch(branch),
+		rootsyncSecretType(configsync.AuthSSH), rootsyncSecretRef(secretName),
+		rootsyncSecretVolume(csiDriver))
+	reqNamespacedName := namespacedName(rs.Name, rs.Namespace)
+	_, fakeDynamicClient, testReconciler := setupRootReconciler(t, rs, secretObj(t, secretName, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(rs.Namespace)))
+
+	// Test creating Deployment resources.
+	ctx := context.Background()
+	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
+		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
+	}
+
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
+
+	rootDeployment := rootSyncDeployment(rootReconcilerName,
+		setServiceAccountName(rootReconcilerName),
+		secretMutator(secretName),
+		secretVolumeMutator(csiDriver),
+		containerEnvMutator(rootContainerEnvs),
+		setUID("1"), setResourceVersion("1"), setGeneration(1),
+	)
+	wantDeployments := map[core.ID]*appsv1.Deployment{core.IDOf(rootDeployment): rootDeployment}
+
+	if err := validateDeployments(wantDeployments, fakeDynamicClient); err != nil {
+		t.Errorf("Deployment validation failed. err: %v", err)
+	}
+	t.Log("Deployment successfully created")
+}
+
 func TestRootSyncUpdateCACertSecret(t *testing.T) {
 	// Mock out parseDeployment for testing.
 	parseDeployment = parsedDeployment
@@ -779,7 +832,7 @@ func TestRootSyncUpdateCACertSecret(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -829,7 +882,7 @@ func TestRootSyncUpdateCACertSecret(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	updatedRootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		caCertSecretMutator(secretName, caCertSecret),
@@ -880,7 +933,7 @@ func TestRootSyncCreateWithOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -912,7 +965,7 @@ func TestRootSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -944,7 +997,7 @@ func TestRootSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	updatedRootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -975,7 +1028,7 @@ func TestRootSyncUpdateOverrideGitSyncDepth(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	updatedRootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1051,7 +1104,7 @@ func TestRootSyncCreateWithOverrideReconcileTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -1082,7 +1135,7 @@ func TestRootSyncUpdateOverrideReconcileTimeout(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
-	rootContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1112,7 +1165,7 @@ func TestRootSyncUpdateOverrideReconcileTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	updatedRootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1188,7 +1241,7 @@ func TestRootSyncCreateWithOverrideAPIServerTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -1216,7 +1269,7 @@ func TestRootSyncUpdateOverrideAPIServerTimeout(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
-	rootContainerEnv := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1242,7 +1295,7 @@ func TestRootSyncUpdateOverrideAPIServerTimeout(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnv = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnv = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	updatedRootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1326,7 +1379,7 @@ func TestRootSyncSwitchAuthTypes(t *testing.T) {
 		core.UID("1"), core.ResourceVersion("1"), core.Generation(1),
 	)
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -1365,7 +1418,7 @@ func TestRootSyncSwitchAuthTypes(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1395,7 +1448,7 @@ func TestRootSyncSwitchAuthTypes(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		containersWithRepoVolumeMutator(noneGitContainers()),
@@ -1426,7 +1479,7 @@ func TestRootSyncReconcilerRestart(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -1552,7 +1605,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 		core.UID("1"), core.ResourceVersion("1"), core.Generation(1),
 	)
 	crb.Subjects = addSubjectByName(crb.Subjects, rootReconcilerName)
-	rootContainerEnv1 := testReconciler.populateContainerEnvs(ctx, rs1, rootReconcilerName)
+	rootContainerEnv1 := rootSyncContainerEnvs(t, testReconciler, rs1, rootReconcilerName)
 	rootDeployment1 := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1594,7 +1647,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	rootContainerEnv2 := testReconciler.populateContainerEnvs(ctx, rs2, rootReconcilerName2)
+	rootContainerEnv2 := rootSyncContainerEnvs(t, testReconciler, rs2, rootReconcilerName2)
 	rootDeployment2 := rootSyncDeployment(rootReconcilerName2,
 		setServiceAccountName(rootReconcilerName2),
 		gceNodeMutator(""),
@@ -1650,7 +1703,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	rootContainerEnv3 := testReconciler.populateContainerEnvs(ctx, rs3, rootReconcilerName3)
+	rootContainerEnv3 := rootSyncContainerEnvs(t, testReconciler, rs3, rootReconcilerName3)
 	rootDeployment3 := rootSyncDeployment(rootReconcilerName3,
 		setServiceAccountName(rootReconcilerName3),
 		gceNodeMutator(gcpSAEmail),
@@ -1710,7 +1763,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	rootContainerEnvs4 := testReconciler.populateContainerEnvs(ctx, rs4, rootReconcilerName4)
+	rootContainerEnvs4 := rootSyncContainerEnvs(t, testReconciler, rs4, rootReconcilerName4)
 	rootDeployment4 := rootSyncDeployment(rootReconcilerName4,
 		setServiceAccountName(rootReconcilerName4),
 		secretMutator(reposyncCookie),
@@ -1770,7 +1823,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 		metadata.SyncKindLabel:      testReconciler.syncKind,
 	}
 
-	rootContainerEnvs5 := testReconciler.populateContainerEnvs(ctx, rs5, rootReconcilerName5)
+	rootContainerEnvs5 := rootSyncContainerEnvs(t, testReconciler, rs5, rootReconcilerName5)
 	rootDeployment5 := rootSyncDeployment(rootReconcilerName5,
 		setServiceAccountName(rootReconcilerName5),
 		secretMutator(secretName),
@@ -1829,7 +1882,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 	rootsync.SetReconciling(wantRs1, "Deployment", "Replicas: 0/1")
 	validateRootSyncStatus(t, wantRs1, fakeClient)
 
-	rootContainerEnv1 = testReconciler.populateContainerEnvs(ctx, rs1, rootReconcilerName)
+	rootContainerEnv1 = rootSyncContainerEnvs(t, testReconciler, rs1, rootReconcilerName)
 	rootDeployment1 = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -1865,7 +1918,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 	rootsync.SetReconciling(wantRs2, "Deployment", "Replicas: 0/1")
 	validateRootSyncStatus(t, wantRs2, fakeClient)
 
-	rootContainerEnv2 = testReconciler.populateContainerEnvs(ctx, rs2, rootReconcilerName2)
+	rootContainerEnv2 = rootSyncContainerEnvs(t, testReconciler, rs2, rootReconcilerName2)
 	rootDeployment2 = rootSyncDeployment(rootReconcilerName2,
 		setServiceAccountName(rootReconcilerName2),
 		gceNodeMutator(""),
@@ -1902,7 +1955,7 @@ func TestMultipleRootSyncs(t *testing.T) {
 	rootsync.SetReconciling(wantRs3, "Deployment", "Replicas: 0/1")
 	validateRootSyncStatus(t, wantRs3, fakeClient)
 
-	rootContainerEnv3 = testReconciler.populateContainerEnvs(ctx, rs3, rootReconcilerName3)
+	rootContainerEnv3 = rootSyncContainerEnvs(t, testReconciler, rs3, rootReconcilerName3)
 	rootDeployment3 = rootSyncDeployment(rootReconcilerName3,
 		setServiceAccountName(rootReconcilerName3),
 		gceNodeMutator(gcpSAEmail),
@@ -2162,7 +2215,7 @@ func TestInjectFleetWorkloadIdentityCredentialsToRootSync(t *testing.T) {
 	if _, err := testReconciler.Reconcile(ctx, reqNamespacedName); err != nil {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -2227,7 +2280,7 @@ func TestInjectFleetWorkloadIdentityCredentialsToRootSync(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		secretMutator(rootsyncSSHKey),
@@ -2257,7 +2310,7 @@ func TestInjectFleetWorkloadIdentityCredentialsToRootSync(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		containersWithRepoVolumeMutator(noneGitContainers()),
@@ -2292,7 +2345,7 @@ func TestRootSyncWithHelm(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -2322,7 +2375,7 @@ func TestRootSyncWithHelm(t *testing.T) {
 		t.Fatalf("unexpected reconciliation error upon request update, got error: %q, want error: nil", err)
 	}
 
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		containersWithRepoVolumeMutator(noneHelmContainers()),
@@ -2408,7 +2461,7 @@ func TestRootSyncWithOCI(t *testing.T) {
 		core.UID("1"), core.ResourceVersion("1"), core.Generation(1),
 	)
 
-	rootContainerEnvs := testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs := rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 
 	rootDeployment := rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
@@ -2450,7 +2503,7 @@ func TestRootSyncWithOCI(t *testing.T) {
 		t.Errorf("ServiceAccount validation failed: %v", err)
 	}
 
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		containersWithRepoVolumeMutator(noneOciContainers()),
@@ -2494,7 +2547,7 @@ func TestRootSyncWithOCI(t *testing.T) {
 	if err := validateServiceAccounts(wantServiceAccounts, fakeClient); err != nil {
 		t.Errorf("ServiceAccount validation failed: %v", err)
 	}
-	rootContainerEnvs = testReconciler.populateContainerEnvs(ctx, rs, rootReconcilerName)
+	rootContainerEnvs = rootSyncContainerEnvs(t, testReconciler, rs, rootReconcilerName)
 	rootDeployment = rootSyncDeployment(rootReconcilerName,
 		setServiceAccountName(rootReconcilerName),
 		containersWithRepoVolumeMutator(noneOciContainers()),
@@ -2934,13 +2987,11 @@ func TestPopulateRootContainerEnvs(t *testing.T) {
 		},
 	}
 
-	ctx := context.Background()
-
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			_, _, testReconciler := setupRootReconciler(t, tc.rootSync, secretObj(t, reposyncSSHKey, configsync.AuthSSH, v1beta1.GitSource, core.Namespace(tc.rootSync.Namespace)))
 
-			env := testReconciler.populateContainerEnvs(ctx, tc.rootSync, rootReconcilerName)
+			env := rootSyncContainerEnvs(t, testReconciler, tc.rootSync, rootReconcilerName)
 
 			for container, vars := range env {
 				if diff := cmp.Diff(tc.expected[container], vars, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b corev1.EnvVar) bool { return a.Name < b.Name })); diff != "" {
@@ -3028,6 +3079,24 @@ func caCertSecretMutator(secretName, caCertSecretName string) depMutator {
 	}
 }
 
+func secretVolumeMutator(csiDriver string) depMutator {
+	return func(dep *appsv1.Deployment) {
+		dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         SecretVolume,
+			VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{Driver: csiDriver}},
+		})
+		for i, con := range dep.Spec.Template.Spec.Containers {
+			if con.Name == reconcilermanager.Reconciler {
+				dep.Spec.Template.Spec.Containers[i].VolumeMounts = append(con.VolumeMounts, corev1.VolumeMount{
+					Name:      SecretVolume,
+					MountPath: applier.SecretMountDir,
+					ReadOnly:  true,
+				})
+			}
+		}
+	}
+}
+
 func envVarMutator(envName, secretName, key string) depMutator {
 	return func(dep *appsv1.Deployment) {
 		for i, con := range dep.Spec.Template.Spec.Containers {
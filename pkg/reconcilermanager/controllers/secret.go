@@ -45,6 +45,12 @@ func isUpsertedSecret(rs *v1beta1.RepoSync, secretName string) bool {
 	if shouldUpsertHelmSecret(rs) && secretName == ReconcilerResourceName(reconcilerName, v1beta1.GetSecretName(rs.Spec.Helm.SecretRef)) {
 		return true
 	}
+	if shouldUpsertNotificationSecret(rs) && secretName == ReconcilerResourceName(reconcilerName, notificationSecretName(rs.Spec.Notifications)) {
+		return true
+	}
+	if shouldUpsertDecryptionSecret(rs) && secretName == ReconcilerResourceName(reconcilerName, decryptionSecretName(rs.Spec.Decryption)) {
+		return true
+	}
 	return false
 }
 
@@ -60,6 +66,14 @@ func shouldUpsertHelmSecret(rs *v1beta1.RepoSync) bool {
 	return v1beta1.SourceType(rs.Spec.SourceType) == v1beta1.HelmSource && rs.Spec.Helm != nil && rs.Spec.Helm.SecretRef != nil && !SkipForAuth(rs.Spec.Helm.Auth)
 }
 
+func shouldUpsertNotificationSecret(rs *v1beta1.RepoSync) bool {
+	return notificationSecretName(rs.Spec.Notifications) != ""
+}
+
+func shouldUpsertDecryptionSecret(rs *v1beta1.RepoSync) bool {
+	return decryptionSecretName(rs.Spec.Decryption) != ""
+}
+
 // upsertAuthSecret creates or updates the auth secret in the
 // config-management-system namespace using an existing secret in the RepoSync
 // namespace.
@@ -133,6 +147,60 @@ func upsertCACertSecret(ctx context.Context, log logr.Logger, rs *v1beta1.RepoSy
 	return client.ObjectKey{}, nil
 }
 
+// upsertNotificationSecret creates or updates the secret signing the
+// notifications in the config-management-system namespace using an existing
+// secret in the RepoSync namespace.
+func upsertNotificationSecret(ctx context.Context, log logr.Logger, rs *v1beta1.RepoSync, c client.Client, reconcilerRef types.NamespacedName) (client.ObjectKey, error) {
+	rsRef := client.ObjectKeyFromObject(rs)
+	if shouldUpsertNotificationSecret(rs) {
+		nsSecretRef, cmsSecretRef := getSecretRefs(rsRef, reconcilerRef, notificationSecretName(rs.Spec.Notifications))
+		userSecret, err := getUserSecret(ctx, c, nsSecretRef)
+		if err != nil {
+			return cmsSecretRef, errors.Wrap(err, "user secret required for signing notifications")
+		}
+		op, err := upsertSecret(ctx, c, cmsSecretRef, rsRef, userSecret)
+		if err != nil {
+			return cmsSecretRef, err
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Info("Managed object upsert successful",
+				logFieldObject, cmsSecretRef.String(),
+				logFieldKind, "Secret",
+				logFieldOperation, op)
+		}
+		return cmsSecretRef, nil
+	}
+	// No secret required
+	return client.ObjectKey{}, nil
+}
+
+// upsertDecryptionSecret creates or updates the secret holding the decryption
+// keys in the config-management-system namespace using an existing secret in
+// the RepoSync namespace.
+func upsertDecryptionSecret(ctx context.Context, log logr.Logger, rs *v1beta1.RepoSync, c client.Client, reconcilerRef types.NamespacedName) (client.ObjectKey, error) {
+	rsRef := client.ObjectKeyFromObject(rs)
+	if shouldUpsertDecryptionSecret(rs) {
+		nsSecretRef, cmsSecretRef := getSecretRefs(rsRef, reconcilerRef, decryptionSecretName(rs.Spec.Decryption))
+		userSecret, err := getUserSecret(ctx, c, nsSecretRef)
+		if err != nil {
+			return cmsSecretRef, errors.Wrap(err, "user secret required for decrypting the source")
+		}
+		op, err := upsertSecret(ctx, c, cmsSecretRef, rsRef, userSecret)
+		if err != nil {
+			return cmsSecretRef, err
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Info("Managed object upsert successful",
+				logFieldObject, cmsSecretRef.String(),
+				logFieldKind, "Secret",
+				logFieldOperation, op)
+		}
+		return cmsSecretRef, nil
+	}
+	// No secret required
+	return client.ObjectKey{}, nil
+}
+
 func getSecretRefs(rsRef, reconcilerRef client.ObjectKey, secretName string) (nsSecretRef, cmsSecretRef client.ObjectKey) {
 	// User managed secret
 	nsSecretRef = client.ObjectKey{
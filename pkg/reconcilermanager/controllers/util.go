@@ -15,8 +15,11 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -188,6 +191,196 @@ func sourceFormatEnv(format string) corev1.EnvVar {
 	}
 }
 
+// ignoreDifferencesEnvs returns the environment variable for IGNORE_DIFFERENCES
+// in the reconciler container, if any ignoreDifferences rules are specified.
+func ignoreDifferencesEnvs(diffs []v1beta1.IgnoreDifference) ([]corev1.EnvVar, error) {
+	if len(diffs) == 0 {
+		return nil, nil
+	}
+	return jsonEnvs(reconcilermanager.IgnoreDifferences, diffs)
+}
+
+// jsonEnvs returns the environment variable holding the JSON encoding of v.
+func jsonEnvs(name string, v interface{}) ([]corev1.EnvVar, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode %s", name)
+	}
+	return []corev1.EnvVar{{
+		Name:  name,
+		Value: string(value),
+	}}, nil
+}
+
+// pruneBudgetEnvs returns the environment variables for the prune budget in
+// the reconciler container, if a prune budget is specified.
+func pruneBudgetEnvs(budget *v1beta1.PruneBudget) []corev1.EnvVar {
+	if budget == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  reconcilermanager.PruneBudgetMaxObjects,
+			Value: fmt.Sprint(budget.MaxObjects),
+		},
+		{
+			Name:  reconcilermanager.PruneBudgetMaxPercentage,
+			Value: fmt.Sprint(budget.MaxPercentage),
+		},
+	}
+}
+
+// conflictPolicyEnvs returns the environment variable for CONFLICT_POLICY in
+// the reconciler container, if a conflict policy is specified.
+func conflictPolicyEnvs(policy *v1beta1.ConflictPolicy) ([]corev1.EnvVar, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	return jsonEnvs(reconcilermanager.ConflictPolicy, policy)
+}
+
+// adoptionPolicyEnvs returns the environment variable for ADOPTION_POLICY in
+// the reconciler container, if an adoption policy is specified.
+func adoptionPolicyEnvs(policy string) []corev1.EnvVar {
+	if policy == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.AdoptionPolicy,
+		Value: policy,
+	}}
+}
+
+// scopeNamespacesEnvs returns the environment variable for SCOPE_NAMESPACES in
+// the reconciler container, if the RepoSync manages other namespaces.
+func scopeNamespacesEnvs(namespaces []string) []corev1.EnvVar {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.ScopeNamespaces,
+		Value: strings.Join(namespaces, ","),
+	}}
+}
+
+// jsonnetConfigEnvs returns the environment variable for JSONNET_CONFIG in the
+// reconciler container, if a Jsonnet configuration is specified.
+func jsonnetConfigEnvs(config *v1beta1.JsonnetConfig) ([]corev1.EnvVar, error) {
+	if config == nil {
+		return nil, nil
+	}
+	return jsonEnvs(reconcilermanager.JsonnetConfig, config)
+}
+
+// kustomizeConfigEnvs returns the environment variable for KUSTOMIZE_CONFIG in
+// the hydration-controller container, if a Kustomize configuration is specified.
+func kustomizeConfigEnvs(config *v1beta1.KustomizeConfig) ([]corev1.EnvVar, error) {
+	if config == nil {
+		return nil, nil
+	}
+	return jsonEnvs(reconcilermanager.KustomizeConfig, config)
+}
+
+// renderCacheEnvs returns the environment variable for RENDER_CACHE_DIR in the
+// hydration-controller container, if the render cache is enabled.
+func renderCacheEnvs(claimName string) []corev1.EnvVar {
+	if claimName == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.RenderCacheDir,
+		Value: RenderCachePath,
+	}}
+}
+
+// tracingEnvs returns the environment variable for TRACE_SAMPLING_FRACTION in
+// the reconciler and hydration-controller containers, if tracing is enabled.
+func tracingEnvs(samplingFraction float64) []corev1.EnvVar {
+	if samplingFraction <= 0 {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name:  reconcilermanager.TraceSamplingFraction,
+		Value: strconv.FormatFloat(samplingFraction, 'f', -1, 64),
+	}}
+}
+
+// notificationConfigEnvs returns the environment variable for
+// NOTIFICATION_CONFIG in the reconciler container, if notification endpoints
+// are specified.
+func notificationConfigEnvs(config *v1beta1.NotificationConfig) ([]corev1.EnvVar, error) {
+	if config == nil || len(config.Endpoints) == 0 {
+		return nil, nil
+	}
+	return jsonEnvs(reconcilermanager.NotificationConfig, config.Endpoints)
+}
+
+// notificationHMACKey is the key of the Secret referenced by
+// spec.notifications.secretRef, which holds the key of the signatures.
+const notificationHMACKey = "hmacKey"
+
+// notificationHMACKeyEnv returns the environment variable for
+// NOTIFICATION_HMAC_KEY in the reconciler container, read from the hmacKey
+// key of the Secret, if the notifications are signed.
+func notificationHMACKeyEnv(secretName string) []corev1.EnvVar {
+	if secretName == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name: reconcilermanager.NotificationHMACKey,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: notificationHMACKey,
+			},
+		},
+	}}
+}
+
+// notificationSecretName returns the name of the Secret with which the
+// notifications are signed, or an empty string if they are not signed.
+func notificationSecretName(config *v1beta1.NotificationConfig) string {
+	if config == nil {
+		return ""
+	}
+	return v1beta1.GetSecretName(config.SecretRef)
+}
+
+// decryptionAgeKey is the key of the Secret referenced by
+// spec.decryption.secretRef, which holds the age identities.
+const decryptionAgeKey = "age.agekey"
+
+// decryptionKeyEnv returns the environment variable for DECRYPTION_AGE_KEY in
+// the reconciler container, read from the age.agekey key of the Secret, if the
+// encrypted files are decrypted.
+func decryptionKeyEnv(secretName string) []corev1.EnvVar {
+	if secretName == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name: reconcilermanager.DecryptionAgeKey,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: decryptionAgeKey,
+			},
+		},
+	}}
+}
+
+// decryptionSecretName returns the name of the Secret holding the decryption
+// keys, or an empty string if the encrypted files are not decrypted.
+func decryptionSecretName(config *v1beta1.DecryptionConfig) string {
+	if config == nil {
+		return ""
+	}
+	return v1beta1.GetSecretName(config.SecretRef)
+}
+
 // ociSyncEnvs returns the environment variables for the oci-sync container.
 func ociSyncEnvs(image string, auth configsync.AuthType, period float64) []corev1.EnvVar {
 	var result []corev1.EnvVar
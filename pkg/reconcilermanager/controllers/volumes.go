@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"fmt"
+	"path"
 	"sort"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	hubv1 "kpt.dev/configsync/pkg/api/hub/v1"
+	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/metadata"
 )
 
@@ -41,6 +43,30 @@ const CACertSecretKey = "cert"
 // CACertPath is the path where the certificate is mounted.
 const CACertPath = "/etc/ca-cert"
 
+// RenderCacheVolume is the volume name of the render cache shared by the
+// hydration-controllers. Each hydration-controller only mounts the directory
+// of its RootSync or RepoSync namespace.
+const RenderCacheVolume = "render-cache"
+
+// RenderCachePath is the path where the render cache is mounted.
+const RenderCachePath = "/render-cache"
+
+// rootSyncRenderCacheSubPath is the directory of the render cache volume shared
+// by the hydration-controllers of the RootSyncs.
+const rootSyncRenderCacheSubPath = "root-syncs"
+
+// repoSyncRenderCacheSubPath returns the directory of the render cache volume
+// shared by the hydration-controllers of the RepoSyncs in the namespace. Each
+// namespace has its own directory, so that a RepoSync cannot read or poison
+// the rendered configs of another namespace, nor of the RootSyncs.
+func repoSyncRenderCacheSubPath(namespace string) string {
+	return path.Join("repo-syncs", namespace)
+}
+
+// SecretVolume is the volume name of the files referenced by the `file:`
+// references of the secret-from annotation.
+const SecretVolume = "secret-volume"
+
 // defaultMode is the default permission of the `gcp-ksa` volume.
 var defaultMode int32 = 0644
 
@@ -148,3 +174,59 @@ func volumeMounts(auth configsync.AuthType, caCertSecretRef, sourceType string,
 	})
 	return volumeMount
 }
+
+// renderCacheVolumes adds the volume of the render cache PersistentVolumeClaim
+// if the render cache is enabled.
+func renderCacheVolumes(claimName string, volumes []corev1.Volume) []corev1.Volume {
+	if claimName == "" {
+		return volumes
+	}
+	return append(volumes, corev1.Volume{
+		Name: RenderCacheVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		},
+	})
+}
+
+// renderCacheVolumeMounts adds the VolumeMount of the subPath directory of the
+// render cache if the render cache is enabled.
+func renderCacheVolumeMounts(claimName, subPath string, vm []corev1.VolumeMount) []corev1.VolumeMount {
+	if claimName == "" {
+		return vm
+	}
+	return append(vm, corev1.VolumeMount{
+		Name:      RenderCacheVolume,
+		MountPath: RenderCachePath,
+		SubPath:   subPath,
+	})
+}
+
+// secretVolumes adds the CSI volume referenced by the `file:` references of the
+// secret-from annotation if the RootSync specifies spec.secretVolume.
+func secretVolumes(sv *v1beta1.SecretVolume, volumes []corev1.Volume) []corev1.Volume {
+	if sv == nil {
+		return volumes
+	}
+	return append(volumes, corev1.Volume{
+		Name: SecretVolume,
+		VolumeSource: corev1.VolumeSource{
+			CSI: sv.CSI.DeepCopy(),
+		},
+	})
+}
+
+// secretVolumeMounts adds the read-only VolumeMount of the secret volume if the
+// RootSync specifies spec.secretVolume.
+func secretVolumeMounts(sv *v1beta1.SecretVolume, vm []corev1.VolumeMount) []corev1.VolumeMount {
+	if sv == nil {
+		return vm
+	}
+	return append(vm, corev1.VolumeMount{
+		Name:      SecretVolume,
+		MountPath: applier.SecretMountDir,
+		ReadOnly:  true,
+	})
+}
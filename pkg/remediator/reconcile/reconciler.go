@@ -21,9 +21,12 @@ import (
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/diff"
+	"kpt.dev/configsync/pkg/events"
 	"kpt.dev/configsync/pkg/importer/analyzer/validation/nonhierarchical"
 	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/protection"
+	"kpt.dev/configsync/pkg/remediator/queue"
 	"kpt.dev/configsync/pkg/status"
 	syncerreconcile "kpt.dev/configsync/pkg/syncer/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,6 +46,12 @@ type reconciler struct {
 	applier syncerreconcile.Applier
 	// declared is the threadsafe in-memory representation of declared configuration.
 	declared *declared.Resources
+	// events records the drift corrected by the reconciler.
+	events *events.Recorder
+	// protector decides which objects are unmanaged instead of deleted.
+	protector *protection.Protector
+	// adoptionPolicy is the default adoption policy of the declared objects.
+	adoptionPolicy metadata.AdoptionPolicy
 }
 
 // newReconciler instantiates a new reconciler.
@@ -51,18 +60,30 @@ func newReconciler(
 	syncName string,
 	applier syncerreconcile.Applier,
 	declared *declared.Resources,
+	recorder *events.Recorder,
+	protector *protection.Protector,
+	adoptionPolicy metadata.AdoptionPolicy,
 ) *reconciler {
 	return &reconciler{
-		scope:    scope,
-		syncName: syncName,
-		applier:  applier,
-		declared: declared,
+		scope:          scope,
+		syncName:       syncName,
+		applier:        applier,
+		declared:       declared,
+		events:         recorder,
+		protector:      protector,
+		adoptionPolicy: adoptionPolicy,
 	}
 }
 
 // Remediate takes a client.Object representing the object to update, and then
-// ensures that the version on the server matches it.
+// ensures that the version on the server matches it. obj is nil, or a
+// queue.Deleted holding its last known state, if the object was deleted.
 func (r *reconciler) Remediate(ctx context.Context, id core.ID, obj client.Object) status.Error {
+	var lastKnown client.Object
+	if deleted, wasDeleted := obj.(*queue.Deleted); wasDeleted {
+		lastKnown = deleted.Object
+		obj = nil
+	}
 	declU, found := r.declared.Get(id)
 	// Yes, this if block is necessary because Go is pedantic about nil interfaces.
 	// 1) var decl client.Object = declU results in a panic.
@@ -81,21 +102,48 @@ func (r *reconciler) Remediate(ctx context.Context, id core.ID, obj client.Objec
 		return nil
 	case diff.Create:
 		klog.V(3).Infof("The remediator is about to create object %v", core.GKNN(declU))
-		_, err := r.applier.Create(ctx, declU)
+		created, err := r.applier.Create(ctx, declU)
+		if created && r.wasManaged(lastKnown) {
+			r.events.DriftCorrected(ctx, declU, "create")
+		}
 		return err
 	case diff.Update:
 		actual, err := d.UnstructuredActual()
 		if err != nil {
 			return err
 		}
+		if diff.EffectiveAdoptionPolicy(declU, r.adoptionPolicy) != "" && diff.NeedsAdoption(r.scope, r.syncName, declU, actual) {
+			// With an adoption policy, pre-existing objects are only adopted
+			// by the applier, which checks and records the adoptions.
+			klog.V(3).Infof("The remediator is skipping object %v, which is not adopted yet", core.GKNN(actual))
+			return nil
+		}
 		klog.V(3).Infof("The remediator is about to update object %v", core.GKNN(actual))
-		_, err = r.applier.Update(ctx, declU, actual)
+		updated, err := r.applier.Update(ctx, declU, actual)
+		if updated && r.wasSynced(declU, actual) {
+			r.events.DriftCorrected(ctx, actual, "update")
+		}
 		return err
 	case diff.Delete:
 		actual, err := d.UnstructuredActual()
 		if err != nil {
 			return err
 		}
+		if r.declared.Retained(id) {
+			// The applier blocks the prune until it is acknowledged.
+			klog.V(3).Infof("The remediator is skipping object %v, whose prune is blocked by the prune budget", core.GKNN(actual))
+			return nil
+		}
+		if rule, protected := r.protector.Protects(actual); protected {
+			klog.V(3).Infof("The remediator is about to unmanage protected object %v", core.GKNN(actual))
+			updated, err := r.applier.RemoveNomosMeta(ctx, actual, metrics.RemediatorController)
+			if updated {
+				r.events.DeletionSkipped(ctx, actual, rule)
+			}
+			return err
+		}
+		// Deleting an object which is no longer declared prunes it ahead of the
+		// applier, which is not a drift correction.
 		klog.V(3).Infof("The remediator is about to delete object %v", core.GKNN(actual))
 		_, err = r.applier.Delete(ctx, actual)
 		return err
@@ -121,6 +169,26 @@ func (r *reconciler) Remediate(ctx context.Context, id core.ID, obj client.Objec
 	}
 }
 
+// wasManaged returns whether the last known state of a deleted object was
+// managed by this reconciler, so that recreating it corrects a drift. Objects
+// deleted before they were ever applied, or while managed by another
+// reconciler, are created for the first time.
+func (r *reconciler) wasManaged(lastKnown client.Object) bool {
+	return lastKnown != nil && diff.IsManager(r.scope, r.syncName, lastKnown)
+}
+
+// wasSynced returns whether the live object was last applied by this
+// reconciler from the same commit as the declared object, so that updating it
+// corrects a drift. Objects last applied from another commit are updated to a
+// new commit ahead of the applier.
+func (r *reconciler) wasSynced(declared, actual client.Object) bool {
+	if !diff.IsManager(r.scope, r.syncName, actual) {
+		return false
+	}
+	token := core.GetAnnotation(actual, metadata.SyncTokenAnnotationKey)
+	return token != "" && token == core.GetAnnotation(declared, metadata.SyncTokenAnnotationKey)
+}
+
 // GetClient returns the reconciler's underlying client.Client.
 func (r *reconciler) GetClient() client.Client {
 	return r.applier.GetClient()
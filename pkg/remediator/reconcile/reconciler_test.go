@@ -26,6 +26,8 @@ import (
 	"kpt.dev/configsync/pkg/importer/analyzer/validation/nonhierarchical"
 	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/policycontroller"
+	"kpt.dev/configsync/pkg/protection"
+	"kpt.dev/configsync/pkg/remediator/queue"
 	"kpt.dev/configsync/pkg/syncer/syncertest"
 	testingfake "kpt.dev/configsync/pkg/syncer/syncertest/fake"
 	"kpt.dev/configsync/pkg/testing/fake"
@@ -229,7 +231,7 @@ func TestRemediator_Reconcile(t *testing.T) {
 			// Simulate the Parser having already parsed the resource and recorded it.
 			d := makeDeclared(t, tc.declared)
 
-			r := newReconciler(declared.RootReconciler, configsync.RootSyncName, c.Applier(), d)
+			r := newReconciler(declared.RootReconciler, configsync.RootSyncName, c.Applier(), d, nil, nil, "")
 
 			// Get the triggering object for the reconcile event.
 			var obj client.Object
@@ -257,6 +259,158 @@ func TestRemediator_Reconcile(t *testing.T) {
 	}
 }
 
+func TestRemediator_ReconcileProtected(t *testing.T) {
+	actual := fake.ClusterRoleBindingObject(syncertest.ManagementEnabled,
+		core.Annotation(metadata.ResourceIDKey, "rbac.authorization.k8s.io_clusterrolebinding_default-name"),
+		core.Label("example.com/keep", "true"))
+	c := testingfake.NewClient(t, core.Scheme, actual)
+	d := makeDeclared(t)
+
+	p := protection.NewProtector()
+	p.SetRules(protection.Rules{
+		"keep": {LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/keep": "true"}}},
+	})
+	r := newReconciler(declared.RootReconciler, configsync.RootSyncName, c.Applier(), d, nil, p, "")
+
+	if err := r.Remediate(context.Background(), core.IDOf(actual), actual); err != nil {
+		t.Fatalf("got Reconcile() = %v, want nil", err)
+	}
+	// The protected object is unmanaged instead of deleted.
+	c.Check(t, fake.ClusterRoleBindingObject(core.Label("example.com/keep", "true"),
+		core.UID("1"), core.ResourceVersion("2"), core.Generation(1)))
+}
+
+func TestRemediator_ReconcilePruneBlocked(t *testing.T) {
+	actual := fake.ClusterRoleBindingObject(syncertest.ManagementEnabled,
+		core.Annotation(metadata.ResourceIDKey, "rbac.authorization.k8s.io_clusterrolebinding_default-name"))
+	c := testingfake.NewClient(t, core.Scheme, actual)
+	// The object is no longer declared, but its prune is blocked by the prune
+	// budget.
+	d := makeDeclared(t, actual)
+	d.Retain([]core.ID{core.IDOf(actual)})
+	if _, err := d.Update(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newReconciler(declared.RootReconciler, configsync.RootSyncName, c.Applier(), d, nil, nil, "")
+
+	if err := r.Remediate(context.Background(), core.IDOf(actual), actual); err != nil {
+		t.Fatalf("got Reconcile() = %v, want nil", err)
+	}
+	// The object is not deleted while its prune is blocked.
+	c.Check(t, fake.ClusterRoleBindingObject(syncertest.ManagementEnabled,
+		core.Annotation(metadata.ResourceIDKey, "rbac.authorization.k8s.io_clusterrolebinding_default-name"),
+		core.UID("1"), core.ResourceVersion("1"), core.Generation(1)))
+
+	// Once the prune is no longer blocked, the object is deleted.
+	d.Retain(nil)
+	if err := r.Remediate(context.Background(), core.IDOf(actual), actual); err != nil {
+		t.Fatalf("got Reconcile() = %v, want nil", err)
+	}
+	c.Check(t)
+}
+
+func TestRemediator_ReconcileNotAdopted(t *testing.T) {
+	declaredObj := fake.ClusterRoleBindingObject(syncertest.ManagementEnabled,
+		core.Annotation(metadata.ResourceManagerKey, declared.ResourceManager(declared.RootReconciler, configsync.RootSyncName)))
+	actual := fake.ClusterRoleBindingObject(core.Label("example.com/owner", "kubectl"))
+	c := testingfake.NewClient(t, core.Scheme, actual)
+	d := makeDeclared(t, declaredObj)
+
+	r := newReconciler(declared.RootReconciler, configsync.RootSyncName, c.Applier(), d, nil, nil, metadata.AdoptionPolicyIfUnmanaged)
+
+	if err := r.Remediate(context.Background(), core.IDOf(actual), actual); err != nil {
+		t.Fatalf("got Reconcile() = %v, want nil", err)
+	}
+	// With an adoption policy, the pre-existing object is left to the applier.
+	c.Check(t, fake.ClusterRoleBindingObject(core.Label("example.com/owner", "kubectl"),
+		core.UID("1"), core.ResourceVersion("1"), core.Generation(1)))
+}
+
+func TestRemediator_ReconcileDeleted(t *testing.T) {
+	declaredObj := fake.ClusterRoleBindingObject(syncertest.ManagementEnabled)
+	c := testingfake.NewClient(t, core.Scheme)
+	d := makeDeclared(t, declaredObj)
+
+	r := newReconciler(declared.RootReconciler, configsync.RootSyncName, c.Applier(), d, nil, nil, "")
+
+	// The worker passes the last known state of deleted objects.
+	deleted := queue.MarkDeleted(context.Background(), fake.ClusterRoleBindingObject(syncertest.ManagementEnabled))
+	if err := r.Remediate(context.Background(), core.IDOf(declaredObj), deleted); err != nil {
+		t.Fatalf("got Reconcile() = %v, want nil", err)
+	}
+	c.Check(t, fake.ClusterRoleBindingObject(syncertest.ManagementEnabled,
+		core.UID("1"), core.ResourceVersion("1"), core.Generation(1)))
+}
+
+func TestRemediator_DriftCorrected(t *testing.T) {
+	manager := core.Annotation(metadata.ResourceManagerKey, declared.ResourceManager(declared.RootReconciler, configsync.RootSyncName))
+	otherManager := core.Annotation(metadata.ResourceManagerKey, declared.ResourceManager(declared.RootReconciler, "other"))
+	r := newReconciler(declared.RootReconciler, configsync.RootSyncName, nil, nil, nil, nil, "")
+
+	testCases := []struct {
+		name string
+		// lastKnown is the state of the deleted object, for creates.
+		lastKnown client.Object
+		// declared and actual are the states of the updated object, for
+		// updates.
+		declared, actual client.Object
+		want             bool
+	}{
+		{
+			name: "create of an object deleted before it was applied",
+			want: false,
+		},
+		{
+			name:      "create of an unmanaged object",
+			lastKnown: fake.ClusterRoleBindingObject(),
+			want:      false,
+		},
+		{
+			name:      "create of an object managed by another reconciler",
+			lastKnown: fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, otherManager),
+			want:      false,
+		},
+		{
+			name:      "create of a managed object",
+			lastKnown: fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, manager),
+			want:      true,
+		},
+		{
+			name:     "update of an unmanaged object",
+			declared: fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, manager, syncertest.TokenAnnotation),
+			actual:   fake.ClusterRoleBindingObject(syncertest.TokenAnnotation),
+			want:     false,
+		},
+		{
+			name:     "update of an object applied from another commit",
+			declared: fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, manager, core.Annotation(metadata.SyncTokenAnnotationKey, "def456")),
+			actual:   fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, manager, core.Annotation(metadata.SyncTokenAnnotationKey, "abc123")),
+			want:     false,
+		},
+		{
+			name:     "update of an object applied from the same commit",
+			declared: fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, manager, core.Annotation(metadata.SyncTokenAnnotationKey, "abc123")),
+			actual:   fake.ClusterRoleBindingObject(syncertest.ManagementEnabled, manager, core.Annotation(metadata.SyncTokenAnnotationKey, "abc123")),
+			want:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got bool
+			if tc.actual != nil {
+				got = r.wasSynced(tc.declared, tc.actual)
+			} else {
+				got = r.wasManaged(tc.lastKnown)
+			}
+			if got != tc.want {
+				t.Errorf("got drift corrected %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
 func makeDeclared(t *testing.T, objs ...client.Object) *declared.Resources {
 	t.Helper()
 	d := &declared.Resources{}
@@ -18,17 +18,22 @@ import (
 	"context"
 	"time"
 
+	"go.opencensus.io/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/events"
+	"kpt.dev/configsync/pkg/metadata"
 	"kpt.dev/configsync/pkg/metrics"
+	"kpt.dev/configsync/pkg/protection"
 	"kpt.dev/configsync/pkg/remediator/queue"
 	"kpt.dev/configsync/pkg/status"
 	syncerclient "kpt.dev/configsync/pkg/syncer/client"
 	syncerreconcile "kpt.dev/configsync/pkg/syncer/reconcile"
+	"kpt.dev/configsync/pkg/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,10 +45,10 @@ type Worker struct {
 }
 
 // NewWorker returns a new Worker for the given queue and declared resources.
-func NewWorker(scope declared.Scope, syncName string, a syncerreconcile.Applier, q *queue.ObjectQueue, d *declared.Resources) *Worker {
+func NewWorker(scope declared.Scope, syncName string, a syncerreconcile.Applier, q *queue.ObjectQueue, d *declared.Resources, recorder *events.Recorder, protector *protection.Protector, adoptionPolicy metadata.AdoptionPolicy) *Worker {
 	return &Worker{
 		objectQueue: q,
-		reconciler:  newReconciler(scope, syncName, a, d),
+		reconciler:  newReconciler(scope, syncName, a, d, recorder, protector, adoptionPolicy),
 	}
 }
 
@@ -82,17 +87,14 @@ func (w *Worker) processNextObject(ctx context.Context) bool {
 }
 
 func (w *Worker) process(ctx context.Context, obj client.Object) bool {
-	var toRemediate client.Object
-	if queue.WasDeleted(ctx, obj) {
-		// Passing a nil Object to the reconciler signals that the accompanying ID
-		// is for an Object that was deleted.
-		toRemediate = nil
-	} else {
-		toRemediate = obj
-	}
-
 	now := time.Now()
-	err := w.reconciler.Remediate(ctx, core.IDOf(obj), toRemediate)
+	// The sync token of the live object is the commit which last applied it.
+	remediateCtx, span := tracing.StartSpan(ctx, "remediator.remediate", core.GetAnnotation(obj, metadata.SyncTokenAnnotationKey))
+	span.AddAttributes(trace.StringAttribute(tracing.ObjectKey, core.IDOf(obj).String()))
+	// A queue.Deleted Object signals to the reconciler that the accompanying ID
+	// is for an Object that was deleted.
+	err := w.reconciler.Remediate(remediateCtx, core.IDOf(obj), obj)
+	tracing.EndSpan(span, err)
 	metrics.RecordRemediateDuration(ctx, metrics.StatusTagKey(err), obj.GetObjectKind().GroupVersionKind(), now)
 	if err != nil {
 		// To debug the set of events we've missed, you may need to comment out this
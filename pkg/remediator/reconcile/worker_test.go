@@ -122,7 +122,7 @@ func TestWorker_ProcessNextObject(t *testing.T) {
 			}
 
 			d := makeDeclared(t, tc.declared...)
-			w := NewWorker(declared.RootReconciler, configsync.RootSyncName, c.Applier(), q, d)
+			w := NewWorker(declared.RootReconciler, configsync.RootSyncName, c.Applier(), q, d, nil, nil, "")
 
 			for _, obj := range tc.toProcess {
 				if ok := w.processNextObject(context.Background()); !ok {
@@ -141,7 +141,7 @@ func TestWorker_Run_CancelledWhenEmpty(t *testing.T) {
 	q := queue.New("test") // empty queue
 	c := testingfake.NewClient(t, core.Scheme)
 	d := makeDeclared(t) // no resources declared
-	w := NewWorker(declared.RootReconciler, configsync.RootSyncName, c.Applier(), q, d)
+	w := NewWorker(declared.RootReconciler, configsync.RootSyncName, c.Applier(), q, d, nil, nil, "")
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -213,7 +213,7 @@ func TestWorker_Run_CancelledWhenNotEmpty(t *testing.T) {
 
 	d := makeDeclared(t, declaredObjs...)
 	a := &testingfake.Applier{Client: c}
-	w := NewWorker(declared.RootReconciler, configsync.RootSyncName, a, q, d)
+	w := NewWorker(declared.RootReconciler, configsync.RootSyncName, a, q, d, nil, nil, "")
 
 	// Run worker in the background
 	doneCh := make(chan struct{})
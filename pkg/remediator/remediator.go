@@ -23,6 +23,9 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/events"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/protection"
 	"kpt.dev/configsync/pkg/remediator/queue"
 	"kpt.dev/configsync/pkg/remediator/reconcile"
 	"kpt.dev/configsync/pkg/remediator/watch"
@@ -39,6 +42,8 @@ import (
 type Remediator struct {
 	watchMgr *watch.Manager
 	workers  []*reconcile.Worker
+	// events records the management conflicts the remediator encounters.
+	events *events.Recorder
 	// The following fields are guarded by the mutex.
 	mux sync.Mutex
 	// conflictErrs tracks all the management conflicts the remediator encounters,
@@ -69,19 +74,26 @@ var _ Interface = &Remediator{}
 // cluster match the declared resources.
 //
 // It is safe for decls to be modified after they have been passed into the
-// Remediator.
-func New(scope declared.Scope, syncName string, cfg *rest.Config, applier syncerreconcile.Applier, decls *declared.Resources, numWorkers int) (*Remediator, error) {
+// Remediator. The namespaces are the other namespaces watched by a namespace
+// reconciler, in addition to its scope.
+func New(scope declared.Scope, namespaces []string, syncName string, cfg *rest.Config, applier syncerreconcile.Applier, decls *declared.Resources, numWorkers int, recorder *events.Recorder, protector *protection.Protector, adoptionPolicy metadata.AdoptionPolicy) (*Remediator, error) {
 	q := queue.New(string(scope))
 	workers := make([]*reconcile.Worker, numWorkers)
 	for i := 0; i < numWorkers; i++ {
-		workers[i] = reconcile.NewWorker(scope, syncName, applier, q, decls)
+		workers[i] = reconcile.NewWorker(scope, syncName, applier, q, decls, recorder, protector, adoptionPolicy)
 	}
 
 	remediator := &Remediator{
 		workers: workers,
+		events:  recorder,
 	}
 
-	watchMgr, err := watch.NewManager(scope, syncName, cfg, q, decls, nil,
+	options, err := watch.DefaultOptions(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating watch manager")
+	}
+	options.Namespaces = namespaces
+	watchMgr, err := watch.NewManager(scope, syncName, cfg, q, decls, options,
 		remediator.addConflictError, remediator.removeConflictError)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating watch manager")
@@ -145,6 +157,7 @@ func (r *Remediator) addConflictError(e status.ManagementConflictError) {
 		}
 	}
 	r.conflictErrs = append(r.conflictErrs, e)
+	r.events.ManagementConflict(context.Background(), e.Object(), e.ConflictingManager())
 }
 
 func (r *Remediator) removeConflictError(e status.ManagementConflictError) {
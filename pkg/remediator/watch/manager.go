@@ -34,6 +34,10 @@ type Manager struct {
 	// scope is the scope of the reconciler process running the Manager.
 	scope declared.Scope
 
+	// namespaces are the other namespaces watched by a namespace reconciler,
+	// from the spec.namespaces field of its RepoSync.
+	namespaces []string
+
 	// syncName is the corresponding RootSync|RepoSync name of the reconciler process running the Manager.
 	syncName string
 
@@ -69,6 +73,10 @@ type Options struct {
 	// Mapper is the RESTMapper to use for mapping GroupVersionKinds to Resources.
 	Mapper meta.RESTMapper
 
+	// Namespaces are the other namespaces to watch, in addition to the scope
+	// of a namespace reconciler.
+	Namespaces []string
+
 	watcherFunc createWatcherFunc
 }
 
@@ -102,6 +110,7 @@ func NewManager(scope declared.Scope, syncName string, cfg *rest.Config,
 
 	return &Manager{
 		scope:                   scope,
+		namespaces:              options.Namespaces,
 		syncName:                syncName,
 		cfg:                     cfg,
 		resources:               decls,
@@ -209,6 +218,7 @@ func (m *Manager) startWatcher(ctx context.Context, gvk schema.GroupVersionKind)
 		resources:               m.resources,
 		queue:                   m.queue,
 		scope:                   m.scope,
+		namespaces:              m.namespaces,
 		syncName:                m.syncName,
 		addConflictErrorFunc:    m.addConflictErrorFunc,
 		removeConflictErrorFunc: m.removeConflictErrorFunc,
@@ -0,0 +1,111 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// multiNamespaceWatcher runs one watcher per namespace for a GVK, for the
+// namespace reconcilers which manage several namespaces. Each watcher keeps
+// track of the resource version of its own namespace, so a restarted watch
+// does not skip the events of another namespace.
+type multiNamespaceWatcher struct {
+	// watchers maps the namespaces to their watchers.
+	watchers map[string]Runnable
+}
+
+// multiNamespaceWatcher implements the Runnable interface.
+var _ Runnable = &multiNamespaceWatcher{}
+
+func newMultiNamespaceWatcher(watchers map[string]Runnable) *multiNamespaceWatcher {
+	return &multiNamespaceWatcher{watchers: watchers}
+}
+
+// Stop stops the watchers of all the namespaces.
+func (w *multiNamespaceWatcher) Stop() {
+	for _, watcher := range w.watchers {
+		watcher.Stop()
+	}
+}
+
+// Run runs the watchers of all the namespaces, and blocks until they are all
+// stopped. If any of them fails, the others are stopped and the first error
+// is returned.
+func (w *multiNamespaceWatcher) Run(ctx context.Context) status.Error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr status.Error
+	for _, watcher := range w.watchers {
+		watcher := watcher
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := watcher.Run(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+					w.Stop()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// ManagementConflict returns true if the watcher of any namespace noticed a
+// management conflict.
+func (w *multiNamespaceWatcher) ManagementConflict() bool {
+	conflict := false
+	for _, watcher := range w.watchers {
+		if watcher.ManagementConflict() {
+			conflict = true
+		}
+	}
+	return conflict
+}
+
+// SetManagementConflict records the management conflict in the watcher of the
+// namespace of the object.
+func (w *multiNamespaceWatcher) SetManagementConflict(object client.Object) {
+	if watcher, found := w.watchers[object.GetNamespace()]; found {
+		watcher.SetManagementConflict(object)
+	}
+}
+
+// ClearManagementConflict clears the management conflicts of all the
+// namespaces.
+func (w *multiNamespaceWatcher) ClearManagementConflict() {
+	for _, watcher := range w.watchers {
+		watcher.ClearManagementConflict()
+	}
+}
+
+func (w *multiNamespaceWatcher) removeManagementConflictError(object client.Object) {
+	if watcher, found := w.watchers[object.GetNamespace()]; found {
+		watcher.removeManagementConflictError(object)
+	}
+}
+
+func (w *multiNamespaceWatcher) removeAllManagementConflictErrorsWithGVK(gvk schema.GroupVersionKind) {
+	for _, watcher := range w.watchers {
+		watcher.removeAllManagementConflictErrorsWithGVK(gvk)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/remediator/queue"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestMultiNamespaceWatcher(t *testing.T) {
+	deploymentFoo := fake.DeploymentObject(core.Name("hello"), core.Namespace("foo"))
+	deploymentBar := fake.DeploymentObject(core.Name("hello"), core.Namespace("bar"))
+
+	dr := &declared.Resources{}
+	ctx := context.Background()
+	if _, err := dr.Update(ctx, []client.Object{deploymentFoo, deploymentBar}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	q := queue.New("test")
+	bases := map[string]*watch.FakeWatcher{
+		"foo": watch.NewFake(),
+		"bar": watch.NewFake(),
+	}
+	watchers := make(map[string]Runnable)
+	for ns, base := range bases {
+		base := base
+		watchers[ns] = NewFiltered(ctx, watcherConfig{
+			scope:     "foo",
+			syncName:  "rs",
+			resources: dr,
+			queue:     q,
+			startWatch: func(options metav1.ListOptions) (watch.Interface, error) {
+				return base, nil
+			},
+		})
+	}
+	w := newMultiNamespaceWatcher(watchers)
+
+	go func() {
+		// Each base.Action() blocks until the watcher of the namespace reads it.
+		bases["foo"].Action(watch.Modified, deploymentFoo)
+		bases["bar"].Action(watch.Modified, deploymentBar)
+		w.Stop()
+	}()
+	// w.Run() blocks until the watchers of all the namespaces are stopped.
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("got Run() = %v, want Run() = <nil>", err)
+	}
+
+	var got []core.ID
+	for q.Len() > 0 {
+		obj, shutdown := q.Get()
+		if shutdown {
+			t.Fatal("Object queue was shut down unexpectedly.")
+		}
+		got = append(got, core.IDOf(obj))
+	}
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].String() < got[j].String()
+	})
+	want := []core.ID{core.IDOf(deploymentBar), core.IDOf(deploymentFoo)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("did not get desired object IDs: %v", diff)
+	}
+}
+
+func TestMultiNamespaceWatcherStartError(t *testing.T) {
+	ctx := context.Background()
+	q := queue.New("test")
+	base := watch.NewFake()
+	watchers := map[string]Runnable{
+		"foo": NewFiltered(ctx, watcherConfig{
+			scope:     "foo",
+			syncName:  "rs",
+			resources: &declared.Resources{},
+			queue:     q,
+			startWatch: func(options metav1.ListOptions) (watch.Interface, error) {
+				return base, nil
+			},
+		}),
+		"bar": NewFiltered(ctx, watcherConfig{
+			scope:     "foo",
+			syncName:  "rs",
+			resources: &declared.Resources{},
+			queue:     q,
+			startWatch: func(options metav1.ListOptions) (watch.Interface, error) {
+				return nil, errors.New("forbidden")
+			},
+		}),
+	}
+	w := newMultiNamespaceWatcher(watchers)
+
+	// The failure of the watcher of bar stops the watcher of foo.
+	if err := w.Run(ctx); err == nil {
+		t.Fatal("got Run() = <nil>, want an error")
+	}
+}
@@ -39,6 +39,7 @@ type watcherConfig struct {
 	resources               *declared.Resources
 	queue                   *queue.ObjectQueue
 	scope                   declared.Scope
+	namespaces              []string
 	syncName                string
 	startWatch              startWatchFunc
 	addConflictErrorFunc    func(status.ManagementConflictError)
@@ -65,6 +66,19 @@ func createWatcher(ctx context.Context, cfg watcherConfig) (Runnable, status.Err
 			cfg.startWatch = func(options metav1.ListOptions) (watch.Interface, error) {
 				return dynamicClient.Resource(mapping.Resource).Watch(ctx, options)
 			}
+		} else if len(cfg.namespaces) > 0 {
+			// A namespace reconciler which manages other namespaces watches each
+			// of them separately, since it is not allowed to watch the cluster.
+			watchers := make(map[string]Runnable)
+			for _, ns := range append([]string{string(cfg.scope)}, cfg.namespaces...) {
+				ns := ns
+				nsCfg := cfg
+				nsCfg.startWatch = func(options metav1.ListOptions) (watch.Interface, error) {
+					return dynamicClient.Resource(mapping.Resource).Namespace(ns).Watch(ctx, options)
+				}
+				watchers[ns] = NewFiltered(ctx, nsCfg)
+			}
+			return newMultiNamespaceWatcher(watchers), nil
 		} else {
 			cfg.startWatch = func(options metav1.ListOptions) (watch.Interface, error) {
 				return dynamicClient.Resource(mapping.Resource).Namespace(string(cfg.scope)).Watch(ctx, options)
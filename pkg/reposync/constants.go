@@ -15,6 +15,7 @@
 package reposync
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
 	"kpt.dev/configsync/pkg/declared"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,3 +37,20 @@ func GetHelmBase(helm *v1beta1.HelmRepoSync) *v1beta1.HelmBase {
 	}
 	return &helm.HelmBase
 }
+
+// AuthSecretName returns the name of the Secret holding the credentials of the
+// source of the given RepoSync, of any version, or an empty string if its
+// source does not reference one.
+func AuthSecretName(rs *unstructured.Unstructured) string {
+	sourceType, _, _ := unstructured.NestedString(rs.Object, "spec", "sourceType")
+	switch v1beta1.SourceType(sourceType) {
+	case v1beta1.OciSource:
+		return ""
+	case v1beta1.HelmSource:
+		name, _, _ := unstructured.NestedString(rs.Object, "spec", "helm", "secretRef", "name")
+		return name
+	default:
+		name, _, _ := unstructured.NestedString(rs.Object, "spec", "git", "secretRef", "name")
+		return name
+	}
+}
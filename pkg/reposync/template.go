@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reposync
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultTemplateRepoSyncName is the name of the RepoSyncs created by a
+	// RepoSyncTemplate which does not set spec.repoSyncName.
+	DefaultTemplateRepoSyncName = "repo-sync"
+
+	// NamespaceNameVariable is the template variable holding the name of the
+	// Namespace.
+	NamespaceNameVariable = "namespace.name"
+	// NamespaceLabelVariablePrefix prefixes the template variables holding the
+	// labels of the Namespace.
+	NamespaceLabelVariablePrefix = "namespace.labels."
+	// NamespaceAnnotationVariablePrefix prefixes the template variables
+	// holding the annotations of the Namespace.
+	NamespaceAnnotationVariablePrefix = "namespace.annotations."
+)
+
+// templateVariableRef matches the references to template variables, such as
+// `${namespace.labels.team}`. A reference preceded by an extra `$` is escaped
+// and is replaced by the literal reference.
+var templateVariableRef = regexp.MustCompile(`\$?\$\{(namespace\.[^}]*)\}`)
+
+// TemplateRepoSyncName returns the name of the RepoSyncs created by the
+// RepoSyncTemplate.
+func TemplateRepoSyncName(tmpl *v1beta1.RepoSyncTemplate) string {
+	if tmpl.Spec.RepoSyncName == "" {
+		return DefaultTemplateRepoSyncName
+	}
+	return tmpl.Spec.RepoSyncName
+}
+
+// TemplateSpec returns the spec of the RepoSync created by the
+// RepoSyncTemplate in the given Namespace, with the references to template
+// variables replaced by the name, labels and annotations of the Namespace.
+func TemplateSpec(tmpl *v1beta1.RepoSyncTemplate, ns *corev1.Namespace) (v1beta1.RepoSyncSpec, status.Error) {
+	var spec v1beta1.RepoSyncSpec
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tmpl.Spec.Template)
+	if err != nil {
+		return spec, RepoSyncTemplateError(tmpl, ns.Name, err)
+	}
+	vars := namespaceVariables(ns)
+	undefined := make(map[string]bool)
+	u = substituteTemplateVariables(u, vars, undefined).(map[string]interface{})
+	if len(undefined) > 0 {
+		var names []string
+		for name := range undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return spec, UndefinedTemplateVariableError(tmpl, ns.Name, names)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, &spec); err != nil {
+		return spec, RepoSyncTemplateError(tmpl, ns.Name, err)
+	}
+	return spec, nil
+}
+
+// namespaceVariables returns the values of the template variables for the
+// given Namespace.
+func namespaceVariables(ns *corev1.Namespace) map[string]string {
+	vars := map[string]string{NamespaceNameVariable: ns.Name}
+	for k, v := range ns.Labels {
+		vars[NamespaceLabelVariablePrefix+k] = v
+	}
+	for k, v := range ns.Annotations {
+		vars[NamespaceAnnotationVariablePrefix+k] = v
+	}
+	return vars
+}
+
+func substituteTemplateVariables(value interface{}, vars map[string]string, undefined map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = substituteTemplateVariables(v[k], vars, undefined)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = substituteTemplateVariables(v[i], vars, undefined)
+		}
+		return v
+	case string:
+		return templateVariableRef.ReplaceAllStringFunc(v, func(ref string) string {
+			if strings.HasPrefix(ref, "$$") {
+				return ref[1:]
+			}
+			name := templateVariableRef.FindStringSubmatch(ref)[1]
+			val, found := vars[name]
+			if !found {
+				undefined[name] = true
+				return ref
+			}
+			return val
+		})
+	default:
+		return v
+	}
+}
+
+// RepoSyncTemplateErrorCode is the error code for a RepoSyncTemplate which
+// could not be instantiated in a Namespace.
+const RepoSyncTemplateErrorCode = "2022"
+
+var repoSyncTemplateError = status.NewErrorBuilder(RepoSyncTemplateErrorCode)
+
+// RepoSyncTemplateError reports that the RepoSyncTemplate could not be
+// instantiated in the given Namespace. The namespace is empty for errors
+// which affect every Namespace.
+func RepoSyncTemplateError(tmpl client.Object, namespace string, err error) status.Error {
+	if namespace == "" {
+		return repoSyncTemplateError.Wrap(err).
+			Sprint("failed to instantiate the RepoSyncTemplate").
+			BuildWithResources(tmpl)
+	}
+	return repoSyncTemplateError.Wrap(err).
+		Sprintf("failed to instantiate the RepoSyncTemplate in the namespace %q", namespace).
+		BuildWithResources(tmpl)
+}
+
+// UndefinedTemplateVariableError reports that the template of the
+// RepoSyncTemplate refers to variables which are not defined for the given
+// Namespace.
+func UndefinedTemplateVariableError(tmpl client.Object, namespace string, names []string) status.Error {
+	return repoSyncTemplateError.
+		Sprintf("the RepoSyncTemplate refers to variables which are not defined for the namespace %q: %s. "+
+			"Variables are %q, %q followed by a label of the Namespace, or %q followed by an annotation of the Namespace. "+
+			"To use the literal text, escape the reference with an extra \"$\".",
+			namespace, strings.Join(names, ", "), NamespaceNameVariable, NamespaceLabelVariablePrefix, NamespaceAnnotationVariablePrefix).
+		BuildWithResources(tmpl)
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reposync
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+)
+
+func TestTemplateSpec(t *testing.T) {
+	ns := &corev1.Namespace{}
+	ns.Name = "shop"
+	ns.Labels = map[string]string{"team": "shop-team"}
+	ns.Annotations = map[string]string{"example.com/dir": "config"}
+
+	testCases := []struct {
+		name    string
+		git     v1beta1.Git
+		want    v1beta1.Git
+		wantErr status.Error
+	}{
+		{
+			name: "name, label and annotation",
+			git: v1beta1.Git{
+				Repo: "https://github.com/example/${namespace.labels.team}",
+				Dir:  "${namespace.annotations.example.com/dir}/${namespace.name}",
+			},
+			want: v1beta1.Git{
+				Repo: "https://github.com/example/shop-team",
+				Dir:  "config/shop",
+			},
+		},
+		{
+			name: "escaped and unrelated references",
+			git: v1beta1.Git{
+				Repo: "https://github.com/example/$${namespace.name}",
+				Dir:  "${HOME}",
+			},
+			want: v1beta1.Git{
+				Repo: "https://github.com/example/${namespace.name}",
+				Dir:  "${HOME}",
+			},
+		},
+		{
+			name: "undefined variables",
+			git: v1beta1.Git{
+				Repo: "https://github.com/example/${namespace.labels.owner}",
+				Dir:  "${namespace.annotations.dir}",
+			},
+			wantErr: UndefinedTemplateVariableError(&v1beta1.RepoSyncTemplate{}, "shop",
+				[]string{"namespace.annotations.dir", "namespace.labels.owner"}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := &v1beta1.RepoSyncTemplate{}
+			tmpl.Spec.Template.Git = tc.git.DeepCopy()
+			spec, err := TemplateSpec(tmpl, ns)
+			if tc.wantErr != nil {
+				if err == nil || err.Error() != tc.wantErr.Error() {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, *spec.Git); diff != "" {
+				t.Errorf("diff (- want, + got):\n%s", diff)
+			}
+			if tmpl.Spec.Template.Git.Repo != tc.git.Repo {
+				t.Errorf("the template was modified: %q", tmpl.Spec.Template.Git.Repo)
+			}
+		})
+	}
+}
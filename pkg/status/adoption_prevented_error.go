@@ -0,0 +1,43 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdoptionPreventedErrorCode is the error code for declared objects which were
+// not applied, because they already exist and the adoption policy prevents
+// adopting them.
+const AdoptionPreventedErrorCode = "2021"
+
+var adoptionPreventedError = NewErrorBuilder(AdoptionPreventedErrorCode)
+
+// AdoptionPreventedError reports that the declared object already exists on
+// the cluster, and was not applied because the adoption policy prevents
+// adopting it. The previous manager is empty for unmanaged objects.
+func AdoptionPreventedError(resource client.Object, policy, previousManager string) Error {
+	if previousManager == "" {
+		return adoptionPreventedError.
+			Sprintf("the object already exists on the cluster and the adoption policy %q prevents adopting it. "+
+				"Delete the object, or set the adoption policy or the configsync.gke.io/adoption annotation to allow adopting it.", policy).
+			BuildWithResources(resource)
+	}
+	return adoptionPreventedError.
+		Sprintf("the object already exists on the cluster, managed by %q, and the adoption policy %q prevents adopting it. "+
+			"Remove it from its previous manager, or set the adoption policy or the configsync.gke.io/adoption annotation to allow adopting it.",
+			previousManager, policy).
+		BuildWithResources(resource)
+}
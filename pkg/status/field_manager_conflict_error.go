@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "kpt.dev/configsync/pkg/api/configmanagement/v1"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManagerConflictErrorCode is the error code for server-side apply
+// conflicts with other field managers.
+const FieldManagerConflictErrorCode = "2017"
+
+var fieldManagerConflictErrorBuilder = NewErrorBuilder(FieldManagerConflictErrorCode)
+
+// FieldManagerConflictError indicates that fields of the passed resource are
+// owned by other field managers with conflicting values, and Config Sync did
+// not take ownership of them.
+func FieldManagerConflictError(resource client.Object, conflicts []v1beta1.FieldManagerConflict) Error {
+	return fieldManagerConflictErrorImpl{
+		underlying: fieldManagerConflictErrorBuilder.
+			Sprintf("Config Sync did not take ownership of fields with conflicting values owned by other field managers: %s. "+
+				"Either remove the fields from the declared resource, stop the other field managers from setting them, "+
+				"or force ownership with spec.conflictPolicy.",
+				formatFieldOwners(conflicts)).
+			Build(),
+		resource:  resource,
+		conflicts: conflicts,
+	}
+}
+
+// ForcedFieldManagerConflictErrorCode is the error code for warnings that
+// Config Sync took ownership of fields owned by other field managers.
+const ForcedFieldManagerConflictErrorCode = "2023"
+
+var forcedFieldManagerConflictErrorBuilder = NewErrorBuilder(ForcedFieldManagerConflictErrorCode)
+
+// ForcedFieldManagerConflictError indicates that Config Sync took ownership of
+// fields of the passed resource which were owned by other field managers with
+// conflicting values, as the conflict policy forces ownership of them.
+func ForcedFieldManagerConflictError(resource client.Object, conflicts []v1beta1.FieldManagerConflict) Error {
+	return fieldManagerConflictErrorImpl{
+		underlying: forcedFieldManagerConflictErrorBuilder.
+			Sprintf("Config Sync took ownership of fields with conflicting values owned by other field managers: %s. "+
+				"The other field managers may keep reverting them. Either remove the fields from the declared resource, "+
+				"stop the other field managers from setting them, or report the conflicts instead with spec.conflictPolicy.",
+				formatFieldOwners(conflicts)).
+			Build(),
+		resource:  resource,
+		conflicts: conflicts,
+	}
+}
+
+func formatFieldOwners(conflicts []v1beta1.FieldManagerConflict) string {
+	owners := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		owners[i] = fmt.Sprintf("%q owns [%s]", c.FieldManager, strings.Join(c.Fields, ", "))
+	}
+	return strings.Join(owners, "; ")
+}
+
+type fieldManagerConflictErrorImpl struct {
+	underlying Error
+	resource   client.Object
+	conflicts  []v1beta1.FieldManagerConflict
+}
+
+var _ Error = fieldManagerConflictErrorImpl{}
+
+func (f fieldManagerConflictErrorImpl) Cause() error {
+	return f.underlying.Cause()
+}
+
+func (f fieldManagerConflictErrorImpl) Error() string {
+	return format(f)
+}
+
+func (f fieldManagerConflictErrorImpl) Errors() []Error {
+	return []Error{f}
+}
+
+func (f fieldManagerConflictErrorImpl) ToCME() v1.ConfigManagementError {
+	cme := fromError(f)
+	cme.ErrorResources = append(cme.ErrorResources, toErrorResource(f.resource))
+	return cme
+}
+
+func (f fieldManagerConflictErrorImpl) ToCSE() v1beta1.ConfigSyncError {
+	cse := cseFromError(f)
+	cse.Resources = append(cse.Resources, toResourceRef(f.resource))
+	cse.FieldConflicts = append(cse.FieldConflicts, f.conflicts...)
+	return cse
+}
+
+func (f fieldManagerConflictErrorImpl) Code() string {
+	return f.underlying.Code()
+}
+
+func (f fieldManagerConflictErrorImpl) Body() string {
+	return formatBody(f.underlying.Body(), "\n\n", formatResources(f.resource))
+}
+
+func (f fieldManagerConflictErrorImpl) Is(target error) bool {
+	return f.underlying.Is(target)
+}
@@ -38,6 +38,8 @@ type ManagementConflictError interface {
 	CurrentManagerError() ManagementConflictError
 	// ConflictingManagerError returns the error that will be surfaced to the other conflicting manager.
 	ConflictingManagerError() ManagementConflictError
+	// Object returns the object declared by both managers.
+	Object() client.Object
 	Error
 }
 
@@ -70,6 +72,10 @@ func (m managementConflictErrorImpl) ConflictingManager() string {
 	return m.currentManager
 }
 
+func (m managementConflictErrorImpl) Object() client.Object {
+	return m.resource
+}
+
 func (m managementConflictErrorImpl) CurrentManagerError() ManagementConflictError {
 	return ManagementConflictErrorBuilder.
 		Sprint(currentErrorMsg(m.newManager, m.newManager)).
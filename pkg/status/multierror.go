@@ -32,8 +32,10 @@ type MultiError interface {
 }
 
 var nonBlockingErrorCodes = map[string]struct{}{
-	UnknownKindErrorCode:         {},
-	EncodeDeclaredFieldErrorCode: {},
+	UnknownKindErrorCode:                {},
+	EncodeDeclaredFieldErrorCode:        {},
+	ProtectedResourceErrorCode:          {},
+	ForcedFieldManagerConflictErrorCode: {},
 }
 
 // HasBlockingErrors return whether `errs` include any blocking errors.
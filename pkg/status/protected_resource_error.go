@@ -0,0 +1,33 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProtectedResourceErrorCode is the error code for warnings that a protected
+// object was unmanaged instead of being pruned or deleted.
+const ProtectedResourceErrorCode = "2020"
+
+var protectedResourceError = NewErrorBuilder(ProtectedResourceErrorCode)
+
+// ProtectedResourceError reports that the object is protected by the given
+// protection rule, so it was unmanaged instead of being pruned or deleted.
+func ProtectedResourceError(resource client.Object, rule string) Error {
+	return protectedResourceError.
+		Sprintf("the object is protected by the rule %q, so it was unmanaged instead of being deleted", rule).
+		BuildWithResources(resource)
+}
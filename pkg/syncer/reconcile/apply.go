@@ -39,12 +39,14 @@ import (
 	"k8s.io/kubectl/pkg/util/openapi"
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/events"
 	"kpt.dev/configsync/pkg/kinds"
 	"kpt.dev/configsync/pkg/metadata"
 	m "kpt.dev/configsync/pkg/metrics"
 	"kpt.dev/configsync/pkg/status"
 	syncerclient "kpt.dev/configsync/pkg/syncer/client"
 	"kpt.dev/configsync/pkg/syncer/metrics"
+	"kpt.dev/configsync/pkg/util/fieldmanager"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -69,16 +71,19 @@ type clientApplier struct {
 	client           *syncerclient.Client
 	fights           fightDetector
 	fLogger          fightLogger
+	conflictPolicy   fieldmanager.ConflictPolicy
+	// events records the fights on the objects.
+	events *events.Recorder
 }
 
 var _ Applier = &clientApplier{}
 
 // NewApplierForMultiRepo returns a new clientApplier for callers with multi repo feature enabled.
-func NewApplierForMultiRepo(cfg *rest.Config, client *syncerclient.Client) (Applier, error) {
-	return newApplier(cfg, client)
+func NewApplierForMultiRepo(cfg *rest.Config, client *syncerclient.Client, conflictPolicy fieldmanager.ConflictPolicy, recorder *events.Recorder) (Applier, error) {
+	return newApplier(cfg, client, conflictPolicy, recorder)
 }
 
-func newApplier(cfg *rest.Config, client *syncerclient.Client) (Applier, error) {
+func newApplier(cfg *rest.Config, client *syncerclient.Client, conflictPolicy fieldmanager.ConflictPolicy, recorder *events.Recorder) (Applier, error) {
 	c, err := dynamic.NewForConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -101,6 +106,8 @@ func newApplier(cfg *rest.Config, client *syncerclient.Client) (Applier, error)
 		client:           client,
 		fights:           newFightDetector(),
 		fLogger:          newFightLogger(),
+		conflictPolicy:   conflictPolicy,
+		events:           recorder,
 	}, nil
 }
 
@@ -125,6 +132,7 @@ func (c *clientApplier) Create(ctx context.Context, intendedState *unstructured.
 	}
 	if c.fights.detectFight(ctx, time.Now(), intendedState, &c.fLogger, "create") {
 		klog.Warningf("Fight detected on create of %s.", description(intendedState))
+		c.events.ResourceFight(ctx, intendedState, "create")
 	}
 	klog.V(3).Infof("Created object %v", core.GKNN(intendedState))
 	return true, nil
@@ -136,7 +144,10 @@ func (c *clientApplier) Update(ctx context.Context, intendedState, currentState
 	metrics.Operations.WithLabelValues("update", intendedState.GetKind(), metrics.StatusLabel(err)).Inc()
 	m.RecordApplyOperation(ctx, m.RemediatorController, "update", m.StatusTagKey(err), intendedState.GroupVersionKind())
 
+	var fieldConflictErr status.Error
 	switch {
+	case errors.As(err, &fieldConflictErr) && fieldConflictErr.Code() == status.FieldManagerConflictErrorCode:
+		return false, fieldConflictErr
 	case apierrors.IsConflict(err):
 		return false, syncerclient.ConflictUpdateOldVersion(err, intendedState)
 	case apierrors.IsNotFound(err):
@@ -148,8 +159,12 @@ func (c *clientApplier) Update(ctx context.Context, intendedState, currentState
 	updated := !isNoOpPatch(patch)
 	if updated {
 		if c.fights.detectFight(ctx, time.Now(), intendedState, &c.fLogger, "update") {
-			diff := cmp.Diff(currentState, intendedState)
+			diff := loggableDiff(currentState, intendedState)
 			klog.Warningf("Fight detected on update of %s with difference %s", description(intendedState), diff)
+			if conflicts, err := fieldmanager.ConflictsFromManagedFields(currentState, intendedState, configsync.FieldManager); err == nil && len(conflicts) > 0 {
+				klog.Warningf("Fight detected on update of %s with other field managers: %s", description(intendedState), conflicts)
+			}
+			c.events.ResourceFight(ctx, currentState, "update")
 		}
 		klog.V(3).Infof("The object %v was updated with the patch %v", core.GKNN(currentState), string(patch))
 	} else {
@@ -191,6 +206,7 @@ func (c *clientApplier) Delete(ctx context.Context, obj *unstructured.Unstructur
 	}
 	if c.fights.detectFight(ctx, time.Now(), obj, &c.fLogger, "delete") {
 		klog.Warningf("Fight detected on delete of %s.", description(obj))
+		c.events.ResourceFight(ctx, obj, "delete")
 	}
 	klog.V(3).Infof("Deleted object %v", core.GKNN(obj))
 	return true, nil
@@ -226,6 +242,9 @@ func (c *clientApplier) update(ctx context.Context, intendedState, currentState
 	if intendedState.GroupVersionKind().GroupKind() == kinds.APIService().GroupKind() {
 		return c.updateAPIService(ctx, intendedState, currentState)
 	}
+	if err := c.checkFieldConflicts(ctx, intendedState); err != nil {
+		return nil, err
+	}
 	objCopy := intendedState.DeepCopy()
 	// Run the server-side apply dryrun first.
 	// If the returned object doesn't change, skip running server-side apply.
@@ -242,7 +261,48 @@ func (c *clientApplier) update(ctx context.Context, intendedState, currentState
 	duration := time.Since(start).Seconds()
 	metrics.APICallDuration.WithLabelValues("update", intendedState.GroupVersionKind().String(), metrics.StatusLabel(err)).Observe(duration)
 	m.RecordAPICallDuration(ctx, "update", m.StatusTagKey(err), intendedState.GroupVersionKind(), start)
-	return []byte(cmp.Diff(currentState, intendedState)), err
+	return []byte(loggableDiff(currentState, intendedState)), err
+}
+
+// loggableDiff returns the difference between the two states of an object for
+// logging. The data of Secrets is never logged, so the difference of Secrets is
+// redacted.
+func loggableDiff(currentState, intendedState *unstructured.Unstructured) string {
+	if intendedState.GroupVersionKind().GroupKind() == kinds.Secret().GroupKind() {
+		return "<redacted Secret difference>"
+	}
+	return cmp.Diff(currentState, intendedState)
+}
+
+// checkFieldConflicts returns a FieldManagerConflictError if applying the
+// intended state conflicts with fields owned by other field managers, which the
+// conflict policy does not force ownership of. The conflicts which are forced
+// are logged, and are taken over by the apply. Nothing is checked without a
+// conflict policy, since the apply takes ownership of all conflicting fields.
+func (c *clientApplier) checkFieldConflicts(ctx context.Context, intendedState *unstructured.Unstructured) error {
+	if !c.conflictPolicy.Configured() {
+		return nil
+	}
+	// Run a server-side apply dryrun without forcing ownership, so the API
+	// server reports the conflicting fields.
+	err := c.client.Patch(ctx, intendedState.DeepCopy(), client.Apply, client.FieldOwner(configsync.FieldManager), client.DryRunAll)
+	if err == nil {
+		return nil
+	}
+	if !fieldmanager.IsApplyConflict(err) {
+		// Leave other errors to the apply itself.
+		klog.V(3).Infof("Failed to dry-run apply %s to compute conflicting fields: %v", description(intendedState), err)
+		return nil
+	}
+	conflicts := fieldmanager.ConflictsFromError(err)
+	unresolved := c.conflictPolicy.Unresolved(conflicts)
+	if len(unresolved) > 0 {
+		return status.FieldManagerConflictError(intendedState, unresolved.ByManager())
+	}
+	if len(conflicts) > 0 {
+		klog.Warningf("Taking ownership of fields of %s with conflicting values owned by other field managers: %s", description(intendedState), conflicts)
+	}
+	return nil
 }
 
 // updateAPIService updates APIService type resources.
@@ -339,9 +339,6 @@ func validateDeleteOptions(opts client.DeleteOptions) error {
 	if opts.GracePeriodSeconds != nil {
 		return errors.Errorf("fake.Client.Delete does not yet support GracePeriodSeconds, but got: %+v", opts)
 	}
-	if opts.Preconditions != nil {
-		return errors.Errorf("fake.Client.Delete does not yet support Preconditions, but got: %+v", opts)
-	}
 	if opts.PropagationPolicy != nil &&
 		*opts.PropagationPolicy != metav1.DeletePropagationBackground {
 		return errors.Errorf("fake.Client.Delete does not yet support PropagationPolicy %q",
@@ -379,6 +376,14 @@ func (c *Client) Delete(_ context.Context, obj client.Object, opts ...client.Del
 	if obj.GetResourceVersion() != "" && obj.GetResourceVersion() != cachedObj.GetResourceVersion() {
 		return newConflictingResourceVersion(id, obj.GetResourceVersion(), cachedObj.GetResourceVersion())
 	}
+	if p := options.Preconditions; p != nil {
+		if p.UID != nil && *p.UID != cachedObj.GetUID() {
+			return newConflictingUID(id, string(*p.UID), string(cachedObj.GetUID()))
+		}
+		if p.ResourceVersion != nil && *p.ResourceVersion != cachedObj.GetResourceVersion() {
+			return newConflictingResourceVersion(id, *p.ResourceVersion, cachedObj.GetResourceVersion())
+		}
+	}
 
 	// Delete method in real typed client(https://github.com/kubernetes-sigs/controller-runtime/blob/v0.14.1/pkg/client/typed_client.go#L84)
 	// does not copy the latest values back to input object which is different from other methods.
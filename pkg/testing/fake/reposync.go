@@ -58,3 +58,16 @@ func WithRepoSyncSourceType(sourceType v1beta1.SourceType) core.MetaMutator {
 		rs.Spec.SourceType = string(sourceType)
 	}
 }
+
+// RepoSyncTemplateObject initializes a RepoSyncTemplate.
+func RepoSyncTemplateObject(name string, opts ...core.MetaMutator) *v1beta1.RepoSyncTemplate {
+	result := &v1beta1.RepoSyncTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		TypeMeta: ToTypeMeta(kinds.RepoSyncTemplate()),
+	}
+	mutate(result, opts...)
+
+	return result
+}
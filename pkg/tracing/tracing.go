@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing configures the OpenCensus trace spans of the Config Sync
+// components and propagates the trace context between them.
+//
+// The spans are exported to the otel-agent with the OC Agent exporter which
+// exports the metrics, and forwarded to the otel-collector, where the exporters
+// of the traces pipeline are configured.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+const (
+	// CommitKey is the span attribute of the source commit.
+	CommitKey = "configsync.commit"
+	// TriggerKey is the span attribute of what triggered the reconciliation.
+	TriggerKey = "configsync.trigger"
+	// ReconcilerKey is the span attribute of the name of the reconciler.
+	ReconcilerKey = "configsync.reconciler"
+	// ObjectKey is the span attribute of the ID of a remediated object.
+	ObjectKey = "configsync.object"
+	// TaskGroupKey is the span attribute of the name of an applier task group.
+	TaskGroupKey = "configsync.taskGroup"
+
+	// traceParentVersion is the version of the W3C traceparent format.
+	traceParentVersion = "00"
+)
+
+// Register registers the exporter of the trace spans and samples the traces
+// with the given probability. Tracing is disabled if samplingFraction is 0.
+func Register(exporter trace.Exporter, samplingFraction float64) {
+	sampler := trace.NeverSample()
+	if samplingFraction > 0 {
+		sampler = trace.ProbabilitySampler(samplingFraction)
+		trace.RegisterExporter(exporter)
+	}
+	trace.ApplyConfig(trace.Config{DefaultSampler: sampler})
+}
+
+// StartSpan starts a span as a child of the span in ctx, if any, and
+// records the commit as an attribute.
+func StartSpan(ctx context.Context, name, commit string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, name)
+	if commit != "" {
+		span.AddAttributes(trace.StringAttribute(CommitKey, commit))
+	}
+	return ctx, span
+}
+
+// EndSpan sets the status of the span from the error and ends it.
+func EndSpan(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	span.End()
+}
+
+// Link links the span to the span of another component, like the rendering
+// span of the hydration-controller.
+func Link(span *trace.Span, sc trace.SpanContext) {
+	span.AddLink(trace.Link{
+		TraceID: sc.TraceID,
+		SpanID:  sc.SpanID,
+		Type:    trace.LinkTypeParent,
+	})
+}
+
+// FormatSpanContext formats the span context in the W3C traceparent format.
+func FormatSpanContext(sc trace.SpanContext) string {
+	return fmt.Sprintf("%s-%s-%s-%02x", traceParentVersion, sc.TraceID, sc.SpanID, uint8(sc.TraceOptions))
+}
+
+// ParseSpanContext parses a span context in the W3C traceparent format. It
+// returns false if the value is not a valid traceparent.
+func ParseSpanContext(value string) (trace.SpanContext, bool) {
+	var sc trace.SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != traceParentVersion {
+		return sc, false
+	}
+	if !decode(parts[1], sc.TraceID[:]) || !decode(parts[2], sc.SpanID[:]) {
+		return sc, false
+	}
+	var options [1]byte
+	if !decode(parts[3], options[:]) {
+		return sc, false
+	}
+	sc.TraceOptions = trace.TraceOptions(options[0])
+	if sc.TraceID == (trace.TraceID{}) || sc.SpanID == (trace.SpanID{}) {
+		return sc, false
+	}
+	return sc, true
+}
+
+// decode decodes the hex value into dst, which it must fill exactly.
+func decode(value string, dst []byte) bool {
+	if hex.DecodedLen(len(value)) != len(dst) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err == nil
+}
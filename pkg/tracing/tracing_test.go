@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/trace"
+)
+
+func TestParseSpanContext(t *testing.T) {
+	sc := trace.SpanContext{
+		TraceID:      trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:       trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceOptions: 1,
+	}
+	testCases := []struct {
+		name   string
+		value  string
+		want   trace.SpanContext
+		wantOK bool
+	}{
+		{
+			name:   "valid traceparent",
+			value:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:   sc,
+			wantOK: true,
+		},
+		{
+			name:   "round trip",
+			value:  FormatSpanContext(sc),
+			want:   sc,
+			wantOK: true,
+		},
+		{
+			name:   "trailing newline",
+			value:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01\n",
+			want:   sc,
+			wantOK: true,
+		},
+		{
+			name:  "empty",
+			value: "",
+		},
+		{
+			name:  "unknown version",
+			value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:  "short trace ID",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		},
+		{
+			name:  "invalid span ID",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+		},
+		{
+			name:  "zero trace ID",
+			value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ParseSpanContext(tc.value)
+			if ok != tc.wantOK {
+				t.Fatalf("ParseSpanContext(%q) ok = %t, want %t", tc.value, ok, tc.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseSpanContext(%q) diff (-want +got):\n%s", tc.value, diff)
+			}
+		})
+	}
+}
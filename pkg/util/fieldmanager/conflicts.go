@@ -0,0 +1,226 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fieldmanager reports and resolves server-side apply conflicts with
+// other field managers.
+package fieldmanager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+)
+
+// Conflict is a field owned by another field manager with a value which
+// conflicts with the declared value.
+type Conflict struct {
+	// Manager is the name of the field manager which owns the field.
+	Manager string
+	// Field is the path of the field, in server-side apply notation, e.g.
+	// ".spec.replicas" or ".spec.containers[name="nginx"].image".
+	Field string
+}
+
+// Conflicts is a list of conflicting fields.
+type Conflicts []Conflict
+
+// String returns a human readable list of the conflicts, grouped by manager.
+func (cs Conflicts) String() string {
+	var owners []string
+	for _, c := range cs.ByManager() {
+		owners = append(owners, fmt.Sprintf("%q owns [%s]", c.FieldManager, strings.Join(c.Fields, ", ")))
+	}
+	return strings.Join(owners, "; ")
+}
+
+// ByManager groups the conflicting fields by the manager which owns them, in
+// a deterministic order.
+func (cs Conflicts) ByManager() []v1beta1.FieldManagerConflict {
+	fields := make(map[string][]string)
+	for _, c := range cs {
+		fields[c.Manager] = append(fields[c.Manager], c.Field)
+	}
+	var result []v1beta1.FieldManagerConflict
+	for manager, fs := range fields {
+		sort.Strings(fs)
+		result = append(result, v1beta1.FieldManagerConflict{FieldManager: manager, Fields: fs})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FieldManager < result[j].FieldManager
+	})
+	return result
+}
+
+// applyConflictMessage is the prefix of the message of the error returned by
+// the API server when a server-side apply request conflicts with other field
+// managers.
+const applyConflictMessage = "Apply failed with"
+
+// IsApplyConflict returns true if the error is a server-side apply conflict.
+// Errors from the cli-utils applier do not wrap the underlying API error, so
+// the message is checked as well.
+func IsApplyConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) && apierrors.IsConflict(err) && statusErr.Status().Details != nil {
+		for _, cause := range statusErr.Status().Details.Causes {
+			if cause.Type == metav1.CauseTypeFieldManagerConflict {
+				return true
+			}
+		}
+	}
+	return strings.Contains(err.Error(), applyConflictMessage)
+}
+
+// causeManagerRegex matches the field manager in the message of a
+// FieldManagerConflict cause, e.g.
+// `conflict with "kubectl-client-side-apply" using apps/v1`.
+var causeManagerRegex = regexp.MustCompile(`conflict with ("(?:[^"\\]|\\.)*")`)
+
+// ConflictsFromError returns the conflicting fields reported by the API server
+// in the causes of a server-side apply conflict error.
+// Returns nil if the error does not carry any causes.
+func ConflictsFromError(err error) Conflicts {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return nil
+	}
+	var conflicts Conflicts
+	for _, cause := range statusErr.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		manager := cause.Message
+		if m := causeManagerRegex.FindStringSubmatch(cause.Message); m != nil {
+			if unquoted, err := strconv.Unquote(m[1]); err == nil {
+				manager = unquoted
+			}
+		}
+		conflicts = append(conflicts, Conflict{Manager: manager, Field: cause.Field})
+	}
+	return conflicts
+}
+
+// ConflictsFromManagedFields returns the fields of the live object which are
+// owned by field managers other than the given manager, and whose live value
+// differs from the intended value.
+func ConflictsFromManagedFields(live, intended *unstructured.Unstructured, manager string) (Conflicts, error) {
+	var conflicts Conflicts
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == manager || entry.FieldsV1 == nil {
+			continue
+		}
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, fmt.Errorf("unable to decode managed fields of %q: %w", entry.Manager, err)
+		}
+		set.Leaves().Iterate(func(p fieldpath.Path) {
+			intendedValue, found := lookup(intended.Object, p)
+			if !found {
+				return
+			}
+			liveValue, found := lookup(live.Object, p)
+			if found && value.Equals(value.NewValueInterface(liveValue), value.NewValueInterface(intendedValue)) {
+				return
+			}
+			conflicts = append(conflicts, Conflict{Manager: entry.Manager, Field: p.String()})
+		})
+	}
+	return conflicts, nil
+}
+
+// lookup returns the value at the given path of the object.
+func lookup(obj interface{}, p fieldpath.Path) (interface{}, bool) {
+	cur := obj
+	for _, pe := range p {
+		switch {
+		case pe.FieldName != nil:
+			m, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = m[*pe.FieldName]; !ok {
+				return nil, false
+			}
+		case pe.Key != nil:
+			list, ok := cur.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = findByKey(list, *pe.Key); !ok {
+				return nil, false
+			}
+		case pe.Value != nil:
+			list, ok := cur.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = findByValue(list, *pe.Value); !ok {
+				return nil, false
+			}
+		case pe.Index != nil:
+			list, ok := cur.([]interface{})
+			if !ok || *pe.Index < 0 || *pe.Index >= len(list) {
+				return nil, false
+			}
+			cur = list[*pe.Index]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func findByKey(list []interface{}, key value.FieldList) (interface{}, bool) {
+	for _, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matches := true
+		for _, f := range key {
+			v, found := m[f.Name]
+			if !found || !value.Equals(value.NewValueInterface(v), f.Value) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func findByValue(list []interface{}, v value.Value) (interface{}, bool) {
+	for _, e := range list {
+		if value.Equals(value.NewValueInterface(e), v) {
+			return e, true
+		}
+	}
+	return nil, false
+}
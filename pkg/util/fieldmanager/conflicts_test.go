@@ -0,0 +1,204 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldmanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+func applyConflictError(causes ...metav1.StatusCause) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    409,
+		Reason:  metav1.StatusReasonConflict,
+		Message: "Apply failed with 1 conflict",
+		Details: &metav1.StatusDetails{Causes: causes},
+	}}
+}
+
+func TestIsApplyConflict(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "api conflict with field manager cause",
+			err: applyConflictError(metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "kubectl" using apps/v1`,
+				Field:   ".spec.replicas",
+			}),
+			want: true,
+		},
+		{
+			name: "resource version conflict",
+			err:  apierrors.NewConflict(schema.GroupResource{Resource: "deployments"}, "foo", errors.New("object has been modified")),
+			want: false,
+		},
+		{
+			name: "conflict without details",
+			err: &apierrors.StatusError{ErrStatus: metav1.Status{
+				Status: metav1.StatusFailure,
+				Code:   409,
+				Reason: metav1.StatusReasonConflict,
+			}},
+			want: false,
+		},
+		{
+			name: "unwrapped apply conflict",
+			err:  errors.New(`failed to apply: Apply failed with 1 conflict: conflict with "kubectl": .spec.replicas`),
+			want: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsApplyConflict(tc.err); got != tc.want {
+				t.Errorf("IsApplyConflict() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConflictsFromError(t *testing.T) {
+	err := applyConflictError(
+		metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kubectl-client-side-apply" using apps/v1`,
+			Field:   ".spec.replicas",
+		},
+		metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "hpa-controller" using apps/v1`,
+			Field:   ".spec.template.spec.containers[name=\"nginx\"].image",
+		},
+		metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: "ignored",
+			Field:   ".metadata.name",
+		},
+	)
+	want := Conflicts{
+		{Manager: "kubectl-client-side-apply", Field: ".spec.replicas"},
+		{Manager: "hpa-controller", Field: ".spec.template.spec.containers[name=\"nginx\"].image"},
+	}
+	got := ConflictsFromError(err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+
+	wantByManager := []v1beta1.FieldManagerConflict{
+		{FieldManager: "hpa-controller", Fields: []string{".spec.template.spec.containers[name=\"nginx\"].image"}},
+		{FieldManager: "kubectl-client-side-apply", Fields: []string{".spec.replicas"}},
+	}
+	if diff := cmp.Diff(wantByManager, got.ByManager()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func deployment(replicas int64, image string, managedFields ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "foo",
+			"namespace": "bar",
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "nginx",
+							"image": image,
+						},
+					},
+				},
+			},
+		},
+	}}
+	u.SetManagedFields(managedFields)
+	return u
+}
+
+func managedFieldsEntry(manager, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func TestConflictsFromManagedFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		live     *unstructured.Unstructured
+		intended *unstructured.Unstructured
+		want     Conflicts
+	}{
+		{
+			name: "no other managers",
+			live: deployment(3, "nginx:1.0",
+				managedFieldsEntry("configsync.gke.io", `{"f:spec":{"f:replicas":{}}}`)),
+			intended: deployment(1, "nginx:1.0"),
+		},
+		{
+			name: "other manager owns field with same value",
+			live: deployment(1, "nginx:1.0",
+				managedFieldsEntry("kubectl", `{"f:spec":{"f:replicas":{}}}`)),
+			intended: deployment(1, "nginx:1.0"),
+		},
+		{
+			name: "other manager owns field with different value",
+			live: deployment(3, "nginx:1.0",
+				managedFieldsEntry("kubectl", `{"f:spec":{"f:replicas":{}}}`)),
+			intended: deployment(1, "nginx:1.0"),
+			want:     Conflicts{{Manager: "kubectl", Field: ".spec.replicas"}},
+		},
+		{
+			name: "other manager owns list item field",
+			live: deployment(1, "nginx:2.0",
+				managedFieldsEntry("kubectl", `{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"nginx\"}":{".":{},"f:image":{}}}}}}}`)),
+			intended: deployment(1, "nginx:1.0"),
+			want:     Conflicts{{Manager: "kubectl", Field: `.spec.template.spec.containers[name="nginx"].image`}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ConflictsFromManagedFields(tc.live, tc.intended, "configsync.gke.io")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+// ConflictPolicy decides which conflicting fields Config Sync takes ownership
+// of. The zero value forces ownership of all conflicting fields, which is the
+// default behavior.
+type ConflictPolicy struct {
+	// configured is true if spec.conflictPolicy is set.
+	configured    bool
+	report        bool
+	forceManagers map[string]bool
+	forceFields   []string
+}
+
+// NewConflictPolicy converts and validates the spec.conflictPolicy field of a
+// RootSync or RepoSync.
+func NewConflictPolicy(p *v1beta1.ConflictPolicy) (ConflictPolicy, error) {
+	var policy ConflictPolicy
+	if p == nil {
+		return policy, nil
+	}
+	policy.configured = true
+	switch p.Mode {
+	case "", v1beta1.ConflictModeForce:
+	case v1beta1.ConflictModeReport:
+		policy.report = true
+	default:
+		return ConflictPolicy{}, fmt.Errorf("conflictPolicy.mode must be %q or %q, got %q",
+			v1beta1.ConflictModeForce, v1beta1.ConflictModeReport, p.Mode)
+	}
+	for _, m := range p.ForceFieldManagers {
+		if policy.forceManagers == nil {
+			policy.forceManagers = make(map[string]bool)
+		}
+		policy.forceManagers[m] = true
+	}
+	for _, f := range p.ForceFields {
+		if !strings.HasPrefix(f, ".") || strings.HasSuffix(f, ".") {
+			return ConflictPolicy{}, fmt.Errorf("conflictPolicy.forceFields: field %q must be a path in server-side apply notation, e.g. \".spec.replicas\"", f)
+		}
+		policy.forceFields = append(policy.forceFields, f)
+	}
+	return policy, nil
+}
+
+// ParseConflictPolicy parses the JSON encoding of the spec.conflictPolicy
+// field, as passed to the reconciler by the reconciler-manager.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	if s == "" {
+		return ConflictPolicy{}, nil
+	}
+	p := &v1beta1.ConflictPolicy{}
+	if err := json.Unmarshal([]byte(s), p); err != nil {
+		return ConflictPolicy{}, fmt.Errorf("unable to decode conflictPolicy: %w", err)
+	}
+	return NewConflictPolicy(p)
+}
+
+// Configured returns true if spec.conflictPolicy is set. The conflicting
+// fields are only computed, with a server-side apply dry-run of each object,
+// when it is set. Otherwise Config Sync takes ownership of all of them without
+// reporting them.
+func (p ConflictPolicy) Configured() bool {
+	return p.configured
+}
+
+// ForceAll returns true if Config Sync takes ownership of all conflicting
+// fields.
+func (p ConflictPolicy) ForceAll() bool {
+	return !p.report
+}
+
+// Forced returns true if Config Sync takes ownership of the conflicting field.
+func (p ConflictPolicy) Forced(c Conflict) bool {
+	if !p.report || p.forceManagers[c.Manager] {
+		return true
+	}
+	for _, f := range p.forceFields {
+		if c.Field == f || strings.HasPrefix(c.Field, f+".") || strings.HasPrefix(c.Field, f+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Unresolved returns the conflicts which Config Sync does not take ownership
+// of.
+func (p ConflictPolicy) Unresolved(cs Conflicts) Conflicts {
+	var result Conflicts
+	for _, c := range cs {
+		if !p.Forced(c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldmanager
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+)
+
+func TestNewConflictPolicy(t *testing.T) {
+	testCases := []struct {
+		name           string
+		policy         *v1beta1.ConflictPolicy
+		wantConfigured bool
+		wantErr        bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:           "force",
+			policy:         &v1beta1.ConflictPolicy{Mode: v1beta1.ConflictModeForce},
+			wantConfigured: true,
+		},
+		{
+			name: "report with overrides",
+			policy: &v1beta1.ConflictPolicy{
+				Mode:               v1beta1.ConflictModeReport,
+				ForceFieldManagers: []string{"kubectl"},
+				ForceFields:        []string{".spec.replicas"},
+			},
+			wantConfigured: true,
+		},
+		{
+			name:    "unknown mode",
+			policy:  &v1beta1.ConflictPolicy{Mode: "Ignore"},
+			wantErr: true,
+		},
+		{
+			name: "field without leading dot",
+			policy: &v1beta1.ConflictPolicy{
+				Mode:        v1beta1.ConflictModeReport,
+				ForceFields: []string{"spec.replicas"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewConflictPolicy(tc.policy)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewConflictPolicy() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err == nil && p.Configured() != tc.wantConfigured {
+				t.Errorf("NewConflictPolicy().Configured() got %t, want %t", p.Configured(), tc.wantConfigured)
+			}
+		})
+	}
+}
+
+func TestConflictPolicyUnresolved(t *testing.T) {
+	conflicts := Conflicts{
+		{Manager: "kubectl", Field: ".spec.replicas"},
+		{Manager: "hpa-controller", Field: ".spec.replicas"},
+		{Manager: "hpa-controller", Field: `.spec.template.spec.containers[name="nginx"].image`},
+		{Manager: "hpa-controller", Field: ".spec.templateHash"},
+	}
+	testCases := []struct {
+		name   string
+		policy string
+		want   Conflicts
+	}{
+		{
+			name:   "default forces all",
+			policy: "",
+		},
+		{
+			name:   "report all",
+			policy: `{"mode":"Report"}`,
+			want:   conflicts,
+		},
+		{
+			name:   "force field manager",
+			policy: `{"mode":"Report","forceFieldManagers":["hpa-controller"]}`,
+			want:   Conflicts{{Manager: "kubectl", Field: ".spec.replicas"}},
+		},
+		{
+			name:   "force field and its children",
+			policy: `{"mode":"Report","forceFields":[".spec.template"]}`,
+			want: Conflicts{
+				{Manager: "kubectl", Field: ".spec.replicas"},
+				{Manager: "hpa-controller", Field: ".spec.replicas"},
+				{Manager: "hpa-controller", Field: ".spec.templateHash"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseConflictPolicy(tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, p.Unresolved(conflicts)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package objects
 
 import (
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
@@ -42,6 +43,11 @@ type Raw struct {
 	BuildScoper       utildiscovery.BuildScoperFunc
 	Converter         *declared.ValueConverter
 	AllowUnknownKinds bool
+	IgnoreRules       declared.IgnoreRules
+	Variables         map[string]string
+	// SubstitutedFields records the fields of each object whose values had
+	// variables substituted, so they can be annotated once validation passes.
+	SubstitutedFields map[*unstructured.Unstructured][]string
 }
 
 // Scoped builds a Scoped collection of objects from the Raw objects.
@@ -33,13 +33,26 @@ import (
 // selection to filter out objects which are not specified for the current
 // cluster.
 func ClusterSelectors(objs *objects.Raw) status.MultiError {
-	set, errs := buildHydratorSet(objs)
+	filtered, errs := selectedObjects(objs)
 	if errs != nil {
 		return errs
 	}
+	// We are done with Clusters and ClusterSelectors so we can filter them out
+	// now as well.
+	objs.Objects = filtered
+	return nil
+}
+
+// selectedObjects returns the objects which are specified for the current
+// cluster, without the Clusters and ClusterSelectors.
+func selectedObjects(objs *objects.Raw) ([]ast.FileObject, status.MultiError) {
+	set, errs := buildHydratorSet(objs)
+	if errs != nil {
+		return nil, errs
+	}
 	activeSelectors, errs := set.activeSelectors()
 	if errs != nil {
-		return errs
+		return nil, errs
 	}
 
 	var filtered []ast.FileObject
@@ -85,13 +98,9 @@ func ClusterSelectors(objs *objects.Raw) status.MultiError {
 	}
 
 	if errs != nil {
-		return errs
+		return nil, errs
 	}
-
-	// We are done with Clusters and ClusterSelectors so we can filter them out
-	// now as well.
-	objs.Objects = filtered
-	return nil
+	return filtered, nil
 }
 
 // buildHydratorSet splits the given Raw objects into important types (Cluster,
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+// delegationRoleBindingPrefix prefixes the names of the RoleBindings declared
+// for the RepoSyncs delegating namespaces.
+const delegationRoleBindingPrefix = configsync.GroupName + ":delegation:"
+
+// selectorAnnotations are the annotations of a delegating RepoSync which are
+// copied to the objects declared for it, so that they are selected for the
+// same clusters and namespaces as the RepoSync.
+var selectorAnnotations = []string{
+	metadata.LegacyClusterSelectorAnnotationKey,
+	metadata.ClusterNameSelectorAnnotationKey,
+	metadata.NamespaceSelectorAnnotationKey,
+}
+
+// Delegation hydrates the given Raw objects by declaring the objects needed by
+// each RepoSync which delegates namespaces: a RoleBinding granting the
+// delegated ClusterRole to the reconciler of the RepoSync, and a copy of the
+// authentication Secret of the RepoSync. They are declared in the same file as
+// the RepoSync, so that they are inherited by the same namespaces. The subject
+// of the RoleBinding is set once it is copied into a namespace, since the name
+// of the reconciler depends on the namespace.
+func Delegation(objs *objects.Raw) status.MultiError {
+	var delegated []ast.FileObject
+	for _, obj := range objs.Objects {
+		if obj.GetObjectKind().GroupVersionKind().GroupKind() != kinds.RepoSyncV1Beta1().GroupKind() {
+			continue
+		}
+		annotations := obj.GetAnnotations()
+		if role, found := annotations[metadata.DelegateClusterRoleAnnotationKey]; found {
+			delegated = append(delegated, delegationRoleBinding(obj, role))
+		}
+		if secretFrom, found := annotations[metadata.DelegateSecretFromAnnotationKey]; found {
+			delegated = append(delegated, delegationSecret(obj, secretFrom))
+		}
+	}
+	objs.Objects = append(objs.Objects, delegated...)
+	return nil
+}
+
+// delegationRoleBinding returns the RoleBinding granting the given ClusterRole
+// to the reconciler of the RepoSync, without the name of the reconciler.
+func delegationRoleBinding(rs ast.FileObject, role string) ast.FileObject {
+	u := delegatedObject(rs, kinds.RoleBinding().GroupVersion().String(), kinds.RoleBinding().Kind,
+		delegationRoleBindingPrefix+rs.GetName())
+	u.Object["roleRef"] = map[string]interface{}{
+		"apiGroup": kinds.ClusterRole().Group,
+		"kind":     kinds.ClusterRole().Kind,
+		"name":     role,
+	}
+	u.Object["subjects"] = []interface{}{
+		map[string]interface{}{
+			"kind":      kinds.ServiceAccount().Kind,
+			"name":      "",
+			"namespace": configsync.ControllerNamespace,
+		},
+	}
+	return ast.NewFileObject(u, rs.Relative)
+}
+
+// delegationSecret returns the authentication Secret of the RepoSync, whose
+// data is resolved from the given secret reference just before apply.
+func delegationSecret(rs ast.FileObject, secretFrom string) ast.FileObject {
+	u := delegatedObject(rs, kinds.Secret().GroupVersion().String(), kinds.Secret().Kind,
+		reposync.AuthSecretName(rs.Unstructured))
+	annotations := u.GetAnnotations()
+	annotations[metadata.SecretFromAnnotationKey] = secretFrom
+	u.SetAnnotations(annotations)
+	return ast.NewFileObject(u, rs.Relative)
+}
+
+// delegatedObject returns an object declared for the given RepoSync, with the
+// selector annotations of the RepoSync.
+func delegatedObject(rs ast.FileObject, apiVersion, kind, name string) *unstructured.Unstructured {
+	annotations := map[string]interface{}{
+		metadata.DelegatedByAnnotationKey: rs.GetName(),
+	}
+	for _, key := range selectorAnnotations {
+		if value, found := rs.GetAnnotations()[key]; found {
+			annotations[key] = value
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"annotations": annotations,
+		},
+	}}
+}
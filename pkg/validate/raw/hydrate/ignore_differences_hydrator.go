@@ -0,0 +1,39 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+// IgnoreDifferences strips the fields selected by the RootSync/RepoSync
+// ignoreDifferences rules and by each object's ignore-differences annotation
+// from the declared objects. Since the stripped fields are neither applied nor
+// recorded as declared fields, Config Sync does not revert drift on them and
+// the admission webhook does not protect them.
+func IgnoreDifferences(objs *objects.Raw) status.MultiError {
+	var errs status.MultiError
+	for _, obj := range objs.Objects {
+		if err := objs.IgnoreRules.Strip(obj.Unstructured); err != nil {
+			// The annotation is validated before hydration, so this should never
+			// happen.
+			errs = status.Append(errs, status.InternalErrorBuilder.Wrap(err).
+				Sprintf("unable to strip ignored fields from %s", core.GKNN(obj)).Build())
+		}
+	}
+	return errs
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/validate/objects"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func withReplicas(replicas int64) core.MetaMutator {
+	return func(o client.Object) {
+		u := o.(*unstructured.Unstructured)
+		_ = unstructured.SetNestedField(u.Object, replicas, "spec", "replicas")
+	}
+}
+
+func TestIgnoreDifferences(t *testing.T) {
+	rules, err := declared.NewIgnoreRules([]v1beta1.IgnoreDifference{{
+		Group:        kinds.Deployment().Group,
+		Kind:         kinds.Deployment().Kind,
+		JSONPointers: []string{"/spec/replicas"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs := &objects.Raw{
+		IgnoreRules: rules,
+		Objects: []ast.FileObject{
+			fake.UnstructuredAtPath(kinds.Deployment(), "deployment.yaml",
+				core.Name("hpa-managed"), core.Namespace("foo"), withReplicas(3)),
+			fake.UnstructuredAtPath(kinds.StatefulSet(), "statefulset.yaml",
+				core.Name("db"), core.Namespace("foo"), withReplicas(3)),
+			fake.UnstructuredAtPath(kinds.StatefulSet(), "annotated.yaml",
+				core.Name("annotated"), core.Namespace("foo"), withReplicas(3),
+				core.Annotation(metadata.IgnoreDifferencesAnnotationKey, ".spec.replicas")),
+		},
+	}
+	want := &objects.Raw{
+		IgnoreRules: rules,
+		Objects: []ast.FileObject{
+			fake.UnstructuredAtPath(kinds.Deployment(), "deployment.yaml",
+				core.Name("hpa-managed"), core.Namespace("foo"), withReplicas(3)),
+			fake.UnstructuredAtPath(kinds.StatefulSet(), "statefulset.yaml",
+				core.Name("db"), core.Namespace("foo"), withReplicas(3)),
+			fake.UnstructuredAtPath(kinds.StatefulSet(), "annotated.yaml",
+				core.Name("annotated"), core.Namespace("foo"), withReplicas(3),
+				core.Annotation(metadata.IgnoreDifferencesAnnotationKey, ".spec.replicas")),
+		},
+	}
+	unstructured.RemoveNestedField(want.Objects[0].Unstructured.Object, "spec", "replicas")
+	unstructured.RemoveNestedField(want.Objects[2].Unstructured.Object, "spec", "replicas")
+
+	if errs := IgnoreDifferences(objs); errs != nil {
+		t.Errorf("Got IgnoreDifferences() error %v, want nil", errs)
+	}
+	if diff := cmp.Diff(want, objs, ast.CompareFileObject, cmp.AllowUnexported(objects.Raw{})); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+const (
+	// ClusterNameVariable is the variable holding the name of the cluster.
+	ClusterNameVariable = "cluster.name"
+	// ClusterLabelVariablePrefix prefixes the variables holding the labels of
+	// the Cluster object whose name matches the name of the cluster.
+	ClusterLabelVariablePrefix = "cluster.labels."
+	// ConfigMapVariablePrefix prefixes the variables holding the data of the
+	// cluster variables ConfigMap.
+	ConfigMapVariablePrefix = "vars."
+)
+
+// variableRef matches the references to variables, such as
+// `${cluster.labels.region}`. A reference preceded by an extra `$` is escaped
+// and is replaced by the literal reference. Other `${...}` strings, such as
+// shell variables in scripts, are left as they are.
+var variableRef = regexp.MustCompile(`\$?\$\{((?:cluster|vars)\.[^}]*)\}`)
+
+// Variables hydrates the given Raw objects by substituting the references to
+// cluster variables in their string values. References to undefined variables
+// are reported as errors. The fields which had variables substituted are
+// recorded so that SubstitutedFields can annotate them after validation.
+//
+// The objects which are not selected for the current cluster are skipped,
+// since they may refer to variables which are only defined for the clusters
+// they are selected for. They are filtered out by ClusterSelectors later on.
+func Variables(objs *objects.Raw) status.MultiError {
+	vars := clusterVariables(objs)
+	selected := make(map[*unstructured.Unstructured]bool)
+	if filtered, errs := selectedObjects(objs); errs == nil {
+		for _, obj := range filtered {
+			selected[obj.Unstructured] = true
+		}
+	} else {
+		// The invalid cluster selectors are reported by the validators, so
+		// substitute the variables of all the objects in the meantime.
+		selected = nil
+	}
+	var errs status.MultiError
+	for _, obj := range objs.Objects {
+		if obj.GetObjectKind().GroupVersionKind() == kinds.Cluster() {
+			// Cluster objects define the variables.
+			continue
+		}
+		if selected != nil && !selected[obj.Unstructured] {
+			continue
+		}
+		s := &substituter{vars: vars}
+		obj.Object = s.substitute(nil, obj.Object).(map[string]interface{})
+		for _, name := range s.undefined {
+			errs = status.Append(errs, UndefinedVariableError(obj, name))
+		}
+		if len(s.fields) > 0 {
+			if objs.SubstitutedFields == nil {
+				objs.SubstitutedFields = make(map[*unstructured.Unstructured][]string)
+			}
+			objs.SubstitutedFields[obj.Unstructured] = s.fields
+		}
+	}
+	return errs
+}
+
+// SubstitutedFields annotates the given Raw objects with the fields which had
+// variables substituted by Variables.
+func SubstitutedFields(objs *objects.Raw) status.MultiError {
+	for _, obj := range objs.Objects {
+		if fields, found := objs.SubstitutedFields[obj.Unstructured]; found {
+			core.SetAnnotation(obj, metadata.SubstitutedFieldsAnnotationKey, strings.Join(fields, ","))
+		}
+	}
+	return nil
+}
+
+// clusterVariables returns the values of the variables defined for the
+// current cluster.
+func clusterVariables(objs *objects.Raw) map[string]string {
+	vars := make(map[string]string)
+	for k, v := range objs.Variables {
+		vars[ConfigMapVariablePrefix+k] = v
+	}
+	if objs.ClusterName == "" {
+		return vars
+	}
+	vars[ClusterNameVariable] = objs.ClusterName
+	for _, obj := range objs.Objects {
+		if obj.GetObjectKind().GroupVersionKind() != kinds.Cluster() || obj.GetName() != objs.ClusterName {
+			continue
+		}
+		for k, v := range obj.GetLabels() {
+			vars[ClusterLabelVariablePrefix+k] = v
+		}
+	}
+	return vars
+}
+
+// substituter replaces the variable references in the values of an object and
+// keeps track of the substituted fields and undefined variables.
+type substituter struct {
+	vars      map[string]string
+	fields    []string
+	undefined []string
+}
+
+func (s *substituter) substitute(path []string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v[k] = s.substitute(append(path, fieldPathKey(k)), v[k])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = s.substitute(append(path, fmt.Sprintf("[%d]", i)), v[i])
+		}
+		return v
+	case string:
+		return s.substituteString(path, v)
+	default:
+		return v
+	}
+}
+
+func (s *substituter) substituteString(path []string, value string) string {
+	substituted := false
+	result := variableRef.ReplaceAllStringFunc(value, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		name := variableRef.FindStringSubmatch(ref)[1]
+		v, found := s.vars[name]
+		if !found {
+			s.undefined = append(s.undefined, name)
+			return ref
+		}
+		substituted = true
+		return v
+	})
+	if substituted {
+		s.fields = append(s.fields, strings.Join(path, ""))
+	}
+	return result
+}
+
+// fieldPathKey formats the key of a map field as an element of a field path.
+func fieldPathKey(k string) string {
+	if strings.ContainsAny(k, ".[]") {
+		return fmt.Sprintf("[%q]", k)
+	}
+	return "." + k
+}
+
+// UndefinedVariableErrorCode is the error code for a reference to a variable
+// which is not defined for the cluster.
+const UndefinedVariableErrorCode = "1072"
+
+var undefinedVariableErrorBase = status.NewErrorBuilder(UndefinedVariableErrorCode)
+
+// UndefinedVariableError reports that an object refers to a variable which is
+// not defined for the cluster.
+func UndefinedVariableError(obj ast.FileObject, name string) status.Error {
+	return undefinedVariableErrorBase.
+		Sprintf("The variable %q is not defined for this cluster. Variables are "+
+			"%q, %q followed by a label of the Cluster object with the same name as "+
+			"the cluster, or %q followed by a key of the cluster variables ConfigMap. "+
+			"To use the literal text, escape the reference as \"$${%s}\".",
+			name, ClusterNameVariable, ClusterLabelVariablePrefix, ConfigMapVariablePrefix, name).
+		BuildWithResources(obj)
+}
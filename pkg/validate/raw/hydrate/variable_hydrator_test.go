@@ -0,0 +1,152 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+func configMapWithData(data map[string]interface{}, opts ...core.MetaMutator) ast.FileObject {
+	obj := fake.ConfigMap(opts...)
+	obj.Object["data"] = data
+	return obj
+}
+
+func TestVariables(t *testing.T) {
+	testCases := []struct {
+		name     string
+		objs     *objects.Raw
+		want     []ast.FileObject
+		wantErrs status.MultiError
+	}{
+		{
+			name: "Substitute cluster name, cluster labels and ConfigMap variables",
+			objs: &objects.Raw{
+				ClusterName: "prod",
+				Variables:   map[string]string{"tier": "gold"},
+				Objects: []ast.FileObject{
+					fake.Cluster(core.Name("prod"), core.Label("region", "us-east1")),
+					configMapWithData(map[string]interface{}{
+						"endpoint": "https://${cluster.name}.${cluster.labels.region}.example.com",
+						"tier":     "${vars.tier}",
+						"port":     "8080",
+					}, core.Label("region", "${cluster.labels.region}")),
+				},
+			},
+			want: []ast.FileObject{
+				fake.Cluster(core.Name("prod"), core.Label("region", "us-east1")),
+				configMapWithData(map[string]interface{}{
+					"endpoint": "https://prod.us-east1.example.com",
+					"tier":     "gold",
+					"port":     "8080",
+				}, core.Label("region", "us-east1"),
+					core.Annotation(metadata.SubstitutedFieldsAnnotationKey, ".data.endpoint,.data.tier,.metadata.labels.region")),
+			},
+		},
+		{
+			name: "Keep escaped references and other variables",
+			objs: &objects.Raw{
+				ClusterName: "prod",
+				Objects: []ast.FileObject{
+					configMapWithData(map[string]interface{}{
+						"script": "echo ${HOME} $${cluster.name}",
+					}),
+				},
+			},
+			want: []ast.FileObject{
+				configMapWithData(map[string]interface{}{
+					"script": "echo ${HOME} ${cluster.name}",
+				}),
+			},
+		},
+		{
+			name: "Error if a variable is undefined",
+			objs: &objects.Raw{
+				Objects: []ast.FileObject{
+					configMapWithData(map[string]interface{}{
+						"cluster": "${cluster.name}",
+					}),
+				},
+			},
+			want: []ast.FileObject{
+				configMapWithData(map[string]interface{}{
+					"cluster": "${cluster.name}",
+				}),
+			},
+			wantErrs: UndefinedVariableError(fake.ConfigMap(), ClusterNameVariable),
+		},
+		{
+			name: "Skip objects not selected for the cluster",
+			objs: &objects.Raw{
+				ClusterName: prodClusterName,
+				Objects: []ast.FileObject{
+					prodCluster,
+					devCluster,
+					devSelector,
+					configMapWithData(map[string]interface{}{
+						"region": "${cluster.labels.region}",
+					}, core.Name("dev"), withDevLegacyClusterSelector),
+					configMapWithData(map[string]interface{}{
+						"cluster": "${cluster.name}",
+					}, core.Name("inline-dev"), withDevInlineMatchLabels),
+					configMapWithData(map[string]interface{}{
+						"cluster": "${cluster.name}",
+					}, core.Name("inline-prod"), withProdInlineMatchLabels),
+				},
+			},
+			want: []ast.FileObject{
+				prodCluster,
+				devCluster,
+				devSelector,
+				configMapWithData(map[string]interface{}{
+					"region": "${cluster.labels.region}",
+				}, core.Name("dev"), withDevLegacyClusterSelector),
+				configMapWithData(map[string]interface{}{
+					"cluster": "${cluster.name}",
+				}, core.Name("inline-dev"), withDevInlineMatchLabels),
+				configMapWithData(map[string]interface{}{
+					"cluster": prodClusterName,
+				}, core.Name("inline-prod"), withProdInlineMatchLabels,
+					core.Annotation(metadata.SubstitutedFieldsAnnotationKey, ".data.cluster")),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := Variables(tc.objs)
+			if !errors.Is(errs, tc.wantErrs) {
+				t.Errorf("Got Variables() error %v, want %v", errs, tc.wantErrs)
+			}
+			if errs == nil {
+				if errs = SubstitutedFields(tc.objs); errs != nil {
+					t.Errorf("Got SubstitutedFields() error %v, want nil", errs)
+				}
+			}
+			if diff := cmp.Diff(tc.want, tc.objs.Objects, ast.CompareFileObject); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
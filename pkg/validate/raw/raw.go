@@ -25,6 +25,14 @@ import (
 // hierarchical repo against the given Raw objects. Note that this will modify
 // the Raw objects in-place.
 func Hierarchical(objs *objects.Raw) status.MultiError {
+	// Variables are substituted first so that the objects are validated as
+	// they will be applied to the cluster. The objects which are not selected
+	// for the cluster are left as they are, until ClusterSelectors filters
+	// them out.
+	if errs := hydrate.Variables(objs); errs != nil {
+		return errs
+	}
+
 	var errs status.MultiError
 	// Note that the ordering here and in all other collections of validators is
 	// somewhat arbitrary. We always run all validators in a collection before
@@ -44,6 +52,11 @@ func Hierarchical(objs *objects.Raw) status.MultiError {
 		objects.VisitAllRaw(validate.Directory),
 		objects.VisitAllRaw(validate.HNCLabels),
 		objects.VisitAllRaw(validate.ManagementAnnotation),
+		objects.VisitAllRaw(validate.IgnoreDifferences),
+		objects.VisitAllRaw(validate.SecretFrom),
+		objects.VisitAllRaw(validate.DeletionPolicy),
+		objects.VisitAllRaw(validate.Adoption),
+		objects.VisitAllRaw(validate.DelegationForHierarchical),
 		objects.VisitAllRaw(validate.IllegalCRD),
 		objects.VisitAllRaw(validate.CRDName),
 		objects.VisitAllRaw(validate.RootSync),
@@ -61,20 +74,26 @@ func Hierarchical(objs *objects.Raw) status.MultiError {
 		return errs
 	}
 
-	// First we annotate all objects with their declared fields. It is crucial
-	// that we do this step before any other hydration so that we capture the
-	// object exactly as it is declared in Git. Next we set missing namespaces on
+	// First we declare the objects needed by the RepoSyncs which delegate
+	// namespaces, so that they are hydrated like the declared objects. Then we
+	// strip the fields which Config Sync must ignore, and annotate all objects
+	// with their declared fields. It is crucial that we do this step before any
+	// other hydration so that we capture the object exactly as it is declared
+	// in Git. Next we set missing namespaces on
 	// objects in namespace directories since cluster selection relies on
 	// namespace if a namespace gets filtered out. Then we perform cluster
 	// selection so that we can filter out irrelevant objects before trying to
 	// modify them.
 	hydrators := []objects.RawVisitor{
+		hydrate.Delegation,
+		hydrate.IgnoreDifferences,
 		hydrate.DeclaredFields,
 		hydrate.DeclaredVersion,
 		hydrate.ObjectNamespaces,
 		hydrate.ClusterSelectors,
 		hydrate.ClusterName,
 		hydrate.Filepath,
+		hydrate.SubstitutedFields,
 		hydrate.HNCDepth,
 		hydrate.PreventDeletion,
 	}
@@ -88,8 +107,12 @@ func Hierarchical(objs *objects.Raw) status.MultiError {
 // repo against the given Raw objects. Note that this will modify the Raw
 // objects in-place.
 func Unstructured(objs *objects.Raw) status.MultiError {
+	// See the note about variables and ordering above in Hierarchical().
+	if errs := hydrate.Variables(objs); errs != nil {
+		return errs
+	}
+
 	var errs status.MultiError
-	// See the note about ordering above in Hierarchical().
 	validators := []objects.RawVisitor{
 		objects.VisitAllRaw(validate.Annotations),
 		objects.VisitAllRaw(validate.Labels),
@@ -98,6 +121,11 @@ func Unstructured(objs *objects.Raw) status.MultiError {
 		objects.VisitAllRaw(validate.Name),
 		objects.VisitAllRaw(validate.Namespace),
 		objects.VisitAllRaw(validate.ManagementAnnotation),
+		objects.VisitAllRaw(validate.IgnoreDifferences),
+		objects.VisitAllRaw(validate.SecretFrom),
+		objects.VisitAllRaw(validate.DeletionPolicy),
+		objects.VisitAllRaw(validate.Adoption),
+		objects.VisitAllRaw(validate.DelegationForUnstructured),
 		objects.VisitAllRaw(validate.IllegalCRD),
 		objects.VisitAllRaw(validate.CRDName),
 		objects.VisitAllRaw(validate.RootSync),
@@ -114,16 +142,19 @@ func Unstructured(objs *objects.Raw) status.MultiError {
 		return errs
 	}
 
-	// First we annotate all objects with their declared fields. It is crucial
-	// that we do this step before any other hydration so that we capture the
-	// object exactly as it is declared in Git. Then we perform cluster selection
+	// First we strip the fields which Config Sync must ignore, and then annotate
+	// all objects with their declared fields. It is crucial that we do this step
+	// before any other hydration so that we capture the object exactly as it is
+	// declared in Git. Then we perform cluster selection
 	// so that we can filter out irrelevant objects before trying to modify them.
 	hydrators := []objects.RawVisitor{
+		hydrate.IgnoreDifferences,
 		hydrate.DeclaredFields,
 		hydrate.DeclaredVersion,
 		hydrate.ClusterSelectors,
 		hydrate.ClusterName,
 		hydrate.Filepath,
+		hydrate.SubstitutedFields,
 		hydrate.PreventDeletion,
 	}
 	for _, hydrator := range hydrators {
@@ -0,0 +1,52 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Adoption verifies that the adoption annotation of the given object, if
+// present, is either never, ifUnmanaged or always.
+func Adoption(obj ast.FileObject) status.Error {
+	value, found := obj.GetAnnotations()[metadata.AdoptionAnnotationKey]
+	if !found {
+		return nil
+	}
+	switch metadata.AdoptionPolicy(value) {
+	case metadata.AdoptionPolicyNever, metadata.AdoptionPolicyIfUnmanaged, metadata.AdoptionPolicyAlways:
+		return nil
+	default:
+		return InvalidAdoptionAnnotationError(&obj)
+	}
+}
+
+// InvalidAdoptionCode is the error code for an invalid adoption annotation.
+const InvalidAdoptionCode = "1075"
+
+var invalidAdoptionBuilder = status.NewErrorBuilder(InvalidAdoptionCode)
+
+// InvalidAdoptionAnnotationError reports that an object declares an adoption
+// annotation with an unknown value.
+func InvalidAdoptionAnnotationError(o client.Object) status.Error {
+	return invalidAdoptionBuilder.
+		Sprintf("Config has invalid annotation %s=%q. The value must be one of %q, %q or %q.",
+			metadata.AdoptionAnnotationKey, o.GetAnnotations()[metadata.AdoptionAnnotationKey],
+			metadata.AdoptionPolicyNever, metadata.AdoptionPolicyIfUnmanaged, metadata.AdoptionPolicyAlways).
+		BuildWithResources(o)
+}
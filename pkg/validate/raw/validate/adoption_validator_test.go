@@ -0,0 +1,68 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestAdoption(t *testing.T) {
+	role := func(opts ...core.MetaMutator) ast.FileObject {
+		return fake.FileObject(fake.RoleObject(opts...), "namespaces/foo/role.yaml")
+	}
+	testCases := []struct {
+		name    string
+		obj     ast.FileObject
+		wantErr status.Error
+	}{
+		{
+			name: "no annotation",
+			obj:  role(),
+		},
+		{
+			name: "never",
+			obj:  role(core.Annotation(metadata.AdoptionAnnotationKey, "never")),
+		},
+		{
+			name: "ifUnmanaged",
+			obj:  role(core.Annotation(metadata.AdoptionAnnotationKey, "ifUnmanaged")),
+		},
+		{
+			name: "always",
+			obj:  role(core.Annotation(metadata.AdoptionAnnotationKey, "always")),
+		},
+		{
+			name:    "unknown value",
+			obj:     role(core.Annotation(metadata.AdoptionAnnotationKey, "IfUnmanaged")),
+			wantErr: InvalidAdoptionAnnotationError(role()),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Adoption(tc.obj)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("got Adoption() error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/util/fieldmanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConflictPolicySpec validates the spec.conflictPolicy field of a RootSync or
+// RepoSync.
+func ConflictPolicySpec(policy *v1beta1.ConflictPolicy, rs client.Object) status.Error {
+	if _, err := fieldmanager.NewConflictPolicy(policy); err != nil {
+		kind := rs.GetObjectKind().GroupVersionKind().Kind
+		return invalidSyncBuilder.
+			Sprintf("%ss must specify a valid spec.conflictPolicy: %v", kind, err).
+			BuildWithResources(rs)
+	}
+	return nil
+}
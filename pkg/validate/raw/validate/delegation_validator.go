@@ -0,0 +1,100 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/reposync"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DelegationForHierarchical verifies that the delegation annotations of the
+// given object, if present, are declared on a RepoSync and hold a valid
+// ClusterRole name and secret reference.
+func DelegationForHierarchical(obj ast.FileObject) status.Error {
+	annotations := obj.GetAnnotations()
+	role, hasRole := annotations[metadata.DelegateClusterRoleAnnotationKey]
+	secretFrom, hasSecretFrom := annotations[metadata.DelegateSecretFromAnnotationKey]
+	if !hasRole && !hasSecretFrom {
+		return nil
+	}
+	if obj.GetObjectKind().GroupVersionKind().GroupKind() != kinds.RepoSyncV1Beta1().GroupKind() {
+		return InvalidDelegationAnnotationError(&obj, errors.New("only RepoSyncs may delegate namespaces"))
+	}
+	if hasRole {
+		if !isValidClusterRoleName(role) {
+			return InvalidDelegationAnnotationError(&obj,
+				fmt.Errorf("%s=%q is not a valid ClusterRole name", metadata.DelegateClusterRoleAnnotationKey, role))
+		}
+	}
+	if hasSecretFrom {
+		ref, err := declared.ParseSecretReference(secretFrom)
+		if err != nil {
+			return InvalidDelegationAnnotationError(&obj, err)
+		}
+		if ref.Path == "" && ref.Namespace == "" {
+			return InvalidDelegationAnnotationError(&obj,
+				fmt.Errorf("%s=%q must reference a Secret with its namespace", metadata.DelegateSecretFromAnnotationKey, secretFrom))
+		}
+		if reposync.AuthSecretName(obj.Unstructured) == "" {
+			return InvalidDelegationAnnotationError(&obj,
+				fmt.Errorf("%s is set, but the RepoSync does not reference an authentication Secret", metadata.DelegateSecretFromAnnotationKey))
+		}
+	}
+	return nil
+}
+
+// isValidClusterRoleName returns true if the name is a valid path segment,
+// which is the only requirement on the names of RBAC objects.
+func isValidClusterRoleName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/%")
+}
+
+// DelegationForUnstructured verifies that the given object does not declare
+// the delegation annotations, which are only supported in hierarchical
+// repositories.
+func DelegationForUnstructured(obj ast.FileObject) status.Error {
+	for _, key := range []string{metadata.DelegateClusterRoleAnnotationKey, metadata.DelegateSecretFromAnnotationKey} {
+		if _, found := obj.GetAnnotations()[key]; found {
+			return InvalidDelegationAnnotationError(&obj,
+				fmt.Errorf("%s is only supported in hierarchical repositories", key))
+		}
+	}
+	return nil
+}
+
+// InvalidDelegationCode is the error code for an invalid delegation
+// annotation.
+const InvalidDelegationCode = "1076"
+
+var invalidDelegationBuilder = status.NewErrorBuilder(InvalidDelegationCode)
+
+// InvalidDelegationAnnotationError reports that an object declares a
+// delegation annotation which can not be hydrated.
+func InvalidDelegationAnnotationError(o client.Object, err error) status.Error {
+	return invalidDelegationBuilder.
+		Sprintf("Config has invalid delegation annotations: %v. The %s and %s annotations must be declared "+
+			"on a RepoSync in a hierarchical repository.",
+			err, metadata.DelegateClusterRoleAnnotationKey, metadata.DelegateSecretFromAnnotationKey).
+		BuildWithResources(o)
+}
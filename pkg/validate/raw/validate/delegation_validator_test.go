@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestDelegation(t *testing.T) {
+	repoSync := func(secretName string, opts ...core.MetaMutator) ast.FileObject {
+		rs := fake.RepoSyncObjectV1Beta1("", "team-x", opts...)
+		rs.Spec.Git = &v1beta1.Git{
+			Repo: "https://github.com/test/abc",
+			Auth: "ssh",
+		}
+		if secretName != "" {
+			rs.Spec.Git.SecretRef = &v1beta1.SecretReference{Name: secretName}
+		}
+		return fake.FileObject(rs, "namespaces/team-x/rs.yaml")
+	}
+	testCases := []struct {
+		name                string
+		obj                 ast.FileObject
+		wantErr             status.Error
+		wantUnstructuredErr status.Error
+	}{
+		{
+			name: "no annotations",
+			obj:  repoSync("git-creds"),
+		},
+		{
+			name:                "ClusterRole",
+			obj:                 repoSync("git-creds", core.Annotation(metadata.DelegateClusterRoleAnnotationKey, "edit")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+		},
+		{
+			name:                "ClusterRole with a colon",
+			obj:                 repoSync("git-creds", core.Annotation(metadata.DelegateClusterRoleAnnotationKey, "team:edit")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+		},
+		{
+			name:                "invalid ClusterRole",
+			obj:                 repoSync("git-creds", core.Annotation(metadata.DelegateClusterRoleAnnotationKey, "team/edit")),
+			wantErr:             InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+		},
+		{
+			name:                "Secret in another namespace",
+			obj:                 repoSync("git-creds", core.Annotation(metadata.DelegateSecretFromAnnotationKey, "secret:config-management-system/creds")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+		},
+		{
+			name:                "Secret without namespace",
+			obj:                 repoSync("git-creds", core.Annotation(metadata.DelegateSecretFromAnnotationKey, "secret:creds")),
+			wantErr:             InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+		},
+		{
+			name:                "RepoSync without authentication Secret",
+			obj:                 repoSync("", core.Annotation(metadata.DelegateSecretFromAnnotationKey, "secret:config-management-system/creds")),
+			wantErr:             InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(repoSync(""), errors.New("")),
+		},
+		{
+			name:                "not a RepoSync",
+			obj:                 fake.ConfigMap(core.Annotation(metadata.DelegateClusterRoleAnnotationKey, "edit")),
+			wantErr:             InvalidDelegationAnnotationError(fake.ConfigMap(), errors.New("")),
+			wantUnstructuredErr: InvalidDelegationAnnotationError(fake.ConfigMap(), errors.New("")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DelegationForHierarchical(tc.obj)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("got DelegationForHierarchical() error %v, want %v", err, tc.wantErr)
+			}
+			err = DelegationForUnstructured(tc.obj)
+			if !errors.Is(err, tc.wantUnstructuredErr) {
+				t.Errorf("got DelegationForUnstructured() error %v, want %v", err, tc.wantUnstructuredErr)
+			}
+		})
+	}
+}
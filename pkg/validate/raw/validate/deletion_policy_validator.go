@@ -0,0 +1,53 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeletionPolicy verifies that the deletion-policy annotation of the given
+// object, if present, is either orphan or delete.
+func DeletionPolicy(obj ast.FileObject) status.Error {
+	value, found := obj.GetAnnotations()[metadata.DeletionPolicyAnnotationKey]
+	if !found {
+		return nil
+	}
+	switch metadata.DeletionPolicy(value) {
+	case metadata.DeletionPolicyOrphan, metadata.DeletionPolicyDelete:
+		return nil
+	default:
+		return InvalidDeletionPolicyAnnotationError(&obj)
+	}
+}
+
+// InvalidDeletionPolicyCode is the error code for an invalid deletion-policy
+// annotation.
+const InvalidDeletionPolicyCode = "1074"
+
+var invalidDeletionPolicyBuilder = status.NewErrorBuilder(InvalidDeletionPolicyCode)
+
+// InvalidDeletionPolicyAnnotationError reports that an object declares a
+// deletion-policy annotation with an unknown value.
+func InvalidDeletionPolicyAnnotationError(o client.Object) status.Error {
+	return invalidDeletionPolicyBuilder.
+		Sprintf("Config has invalid annotation %s=%q. The value must be either %q or %q.",
+			metadata.DeletionPolicyAnnotationKey, o.GetAnnotations()[metadata.DeletionPolicyAnnotationKey],
+			metadata.DeletionPolicyOrphan, metadata.DeletionPolicyDelete).
+		BuildWithResources(o)
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestDeletionPolicy(t *testing.T) {
+	pvc := func(opts ...core.MetaMutator) ast.FileObject {
+		return fake.FileObject(fake.PersistentVolumeObject(opts...), "cluster/pv.yaml")
+	}
+	testCases := []struct {
+		name    string
+		obj     ast.FileObject
+		wantErr status.Error
+	}{
+		{
+			name: "no annotation",
+			obj:  pvc(),
+		},
+		{
+			name: "orphan",
+			obj:  pvc(core.Annotation(metadata.DeletionPolicyAnnotationKey, "orphan")),
+		},
+		{
+			name: "delete",
+			obj:  pvc(core.Annotation(metadata.DeletionPolicyAnnotationKey, "delete")),
+		},
+		{
+			name:    "unknown value",
+			obj:     pvc(core.Annotation(metadata.DeletionPolicyAnnotationKey, "Orphan")),
+			wantErr: InvalidDeletionPolicyAnnotationError(pvc()),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DeletionPolicy(tc.obj)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("got DeletionPolicy() error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
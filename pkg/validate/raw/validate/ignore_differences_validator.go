@@ -0,0 +1,60 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IgnoreDifferences verifies that the ignore-differences annotation of the
+// given object, if present, only lists valid field paths.
+func IgnoreDifferences(obj ast.FileObject) status.Error {
+	if _, err := declared.IgnoredFieldsFromAnnotation(&obj); err != nil {
+		return InvalidIgnoreDifferencesAnnotationError(&obj, err)
+	}
+	return nil
+}
+
+// InvalidIgnoreDifferencesCode is the error code for an invalid
+// ignore-differences annotation.
+const InvalidIgnoreDifferencesCode = "1070"
+
+var invalidIgnoreDifferencesBuilder = status.NewErrorBuilder(InvalidIgnoreDifferencesCode)
+
+// InvalidIgnoreDifferencesAnnotationError reports that an object declares an
+// ignore-differences annotation which can not be parsed.
+func InvalidIgnoreDifferencesAnnotationError(o client.Object, err error) status.Error {
+	return invalidIgnoreDifferencesBuilder.
+		Sprintf("Config has invalid annotation %s=%q: %v. The value must be a comma separated list of JSON pointers or JSONPath expressions.",
+			metadata.IgnoreDifferencesAnnotationKey, o.GetAnnotations()[metadata.IgnoreDifferencesAnnotationKey], err).
+		BuildWithResources(o)
+}
+
+// IgnoreDifferencesSpec validates the spec.ignoreDifferences field of a
+// RootSync or RepoSync.
+func IgnoreDifferencesSpec(diffs []v1beta1.IgnoreDifference, rs client.Object) status.Error {
+	if _, err := declared.NewIgnoreRules(diffs); err != nil {
+		kind := rs.GetObjectKind().GroupVersionKind().Kind
+		return invalidSyncBuilder.
+			Sprintf("%ss must specify valid spec.ignoreDifferences: %v", kind, err).
+			BuildWithResources(rs)
+	}
+	return nil
+}
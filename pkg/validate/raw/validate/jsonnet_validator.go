@@ -0,0 +1,42 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"path"
+	"strings"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// JsonnetSpec validates the spec.jsonnet field of a RootSync or RepoSync.
+// The library paths must stay inside the sync directory.
+func JsonnetSpec(config *v1beta1.JsonnetConfig, rs client.Object) status.Error {
+	if config == nil {
+		return nil
+	}
+	kind := rs.GetObjectKind().GroupVersionKind().Kind
+	for _, lib := range config.LibPaths {
+		clean := path.Clean(lib)
+		if lib == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return invalidSyncBuilder.
+				Sprintf("%ss must specify spec.jsonnet.libPaths relative to the sync directory, got %q", kind, lib).
+				BuildWithResources(rs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"strings"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KustomizeSpec validates the spec.kustomize field of a RootSync or RepoSync.
+// The allowed remote URLs must be non-empty prefixes without whitespace.
+func KustomizeSpec(config *v1beta1.KustomizeConfig, rs client.Object) status.Error {
+	if config == nil {
+		return nil
+	}
+	kind := rs.GetObjectKind().GroupVersionKind().Kind
+	for _, u := range config.AllowedRemoteURLs {
+		if u == "" || strings.ContainsAny(u, " \t\n") {
+			return invalidSyncBuilder.
+				Sprintf("%ss must specify spec.kustomize.allowedRemoteURLs as URL prefixes, got %q", kind, u).
+				BuildWithResources(rs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceScopeSpec validates the spec.namespaces field of a RepoSync.
+// The names must be valid Namespace names other than the
+// config-management-system Namespace, and the selector must be valid.
+func NamespaceScopeSpec(scope *v1beta1.NamespaceScope, rs client.Object) status.Error {
+	if scope == nil {
+		return nil
+	}
+	kind := rs.GetObjectKind().GroupVersionKind().Kind
+	for _, name := range scope.Names {
+		if name == configsync.ControllerNamespace {
+			return invalidSyncBuilder.
+				Sprintf("%ss must not include the %s namespace in spec.namespaces.names", kind, configsync.ControllerNamespace).
+				BuildWithResources(rs)
+		}
+		if errs := validation.IsDNS1123Label(name); errs != nil {
+			return invalidSyncBuilder.
+				Sprintf("%ss must specify valid namespace names in spec.namespaces.names, got %q: %s", kind, name, strings.Join(errs, ", ")).
+				BuildWithResources(rs)
+		}
+	}
+	if scope.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(scope.Selector); err != nil {
+			return invalidSyncBuilder.
+				Sprintf("%ss must specify a valid spec.namespaces.selector: %v", kind, err).
+				BuildWithResources(rs)
+		}
+	}
+	return nil
+}
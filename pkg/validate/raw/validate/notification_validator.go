@@ -0,0 +1,64 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"net/url"
+
+	"kpt.dev/configsync/pkg/api/configsync/v1beta1"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var notificationEvents = map[string]bool{
+	v1beta1.NotificationSyncSucceeded: true,
+	v1beta1.NotificationSyncFailed:    true,
+	v1beta1.NotificationStalled:       true,
+	v1beta1.NotificationConflict:      true,
+	v1beta1.NotificationRollback:      true,
+}
+
+// NotificationSpec validates the spec.notifications field of a RootSync or
+// RepoSync. The endpoints must be absolute HTTP or HTTPS URLs, and filter
+// known sync results.
+func NotificationSpec(config *v1beta1.NotificationConfig, rs client.Object) status.Error {
+	if config == nil {
+		return nil
+	}
+	kind := rs.GetObjectKind().GroupVersionKind().Kind
+	for _, e := range config.Endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalidSyncBuilder.
+				Sprintf("%ss must specify spec.notifications.endpoints.url as an HTTP or HTTPS URL, got %q", kind, e.URL).
+				BuildWithResources(rs)
+		}
+		for _, event := range e.Events {
+			if !notificationEvents[event] {
+				return invalidSyncBuilder.
+					Sprintf("%ss must specify spec.notifications.endpoints.events among %s, %s, %s, %s and %s, got %q", kind,
+						v1beta1.NotificationSyncSucceeded, v1beta1.NotificationSyncFailed, v1beta1.NotificationStalled,
+						v1beta1.NotificationConflict, v1beta1.NotificationRollback, event).
+					BuildWithResources(rs)
+			}
+		}
+	}
+	if config.SecretRef != nil && config.SecretRef.Name == "" {
+		return invalidSyncBuilder.
+			Sprintf("%ss must specify spec.notifications.secretRef.name when spec.notifications.secretRef is set", kind).
+			BuildWithResources(rs)
+	}
+	return nil
+}
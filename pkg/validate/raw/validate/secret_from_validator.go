@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kpt.dev/configsync/pkg/declared"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecretFrom verifies that the secret-from annotation of the given object, if
+// present, is declared on a Secret and holds a valid secret reference.
+func SecretFrom(obj ast.FileObject) status.Error {
+	value, found := obj.GetAnnotations()[metadata.SecretFromAnnotationKey]
+	if !found {
+		return nil
+	}
+	if obj.GetObjectKind().GroupVersionKind().GroupKind() != kinds.Secret().GroupKind() {
+		return InvalidSecretFromAnnotationError(&obj, errors.New("only Secrets may reference secret data"))
+	}
+	if _, err := declared.ParseSecretReference(value); err != nil {
+		return InvalidSecretFromAnnotationError(&obj, err)
+	}
+	return nil
+}
+
+// SecretFromNamespaces verifies that the secret-from annotation of the given
+// object declared in a Namespace repo, if present, only references Secrets in
+// the given namespaces the namespace reconciler syncs. References to mounted
+// files are rejected, since only the root reconciler mounts a secret volume.
+func SecretFromNamespaces(obj ast.FileObject, namespaces ...string) status.Error {
+	value, found := obj.GetAnnotations()[metadata.SecretFromAnnotationKey]
+	if !found {
+		return nil
+	}
+	ref, err := declared.ParseSecretReference(value)
+	if err != nil {
+		// SecretFrom reports the invalid reference.
+		return nil
+	}
+	if ref.Path != "" {
+		return InvalidSecretFromAnnotationError(&obj, errors.New("only RootSyncs may reference mounted files"))
+	}
+	if ref.Namespace == "" {
+		// The Secret is in the namespace of the declared Secret.
+		return nil
+	}
+	for _, ns := range namespaces {
+		if ref.Namespace == ns {
+			return nil
+		}
+	}
+	return InvalidSecretFromAnnotationError(&obj,
+		fmt.Errorf("the referenced Secret must be in one of the namespaces synced by the RepoSync: %s", strings.Join(namespaces, ", ")))
+}
+
+// InvalidSecretFromCode is the error code for an invalid secret-from
+// annotation.
+const InvalidSecretFromCode = "1073"
+
+var invalidSecretFromBuilder = status.NewErrorBuilder(InvalidSecretFromCode)
+
+// InvalidSecretFromAnnotationError reports that an object declares a
+// secret-from annotation which can not be resolved.
+func InvalidSecretFromAnnotationError(o client.Object, err error) status.Error {
+	return invalidSecretFromBuilder.
+		Sprintf("Config has invalid annotation %s=%q: %v. The annotation must be declared on a Secret, "+
+			"and the value must be either secret:[<namespace>/]<name> or file:<path>.",
+			metadata.SecretFromAnnotationKey, o.GetAnnotations()[metadata.SecretFromAnnotationKey], err).
+		BuildWithResources(o)
+}
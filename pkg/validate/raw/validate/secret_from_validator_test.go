@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/testing/fake"
+)
+
+func TestSecretFrom(t *testing.T) {
+	secret := func(opts ...core.MetaMutator) ast.FileObject {
+		return fake.FileObject(fake.SecretObject("creds", opts...), "namespaces/foo/secret.yaml")
+	}
+	testCases := []struct {
+		name    string
+		obj     ast.FileObject
+		wantErr status.Error
+	}{
+		{
+			name: "no annotation",
+			obj:  secret(),
+		},
+		{
+			name: "Secret in the same namespace",
+			obj:  secret(core.Annotation(metadata.SecretFromAnnotationKey, "secret:creds")),
+		},
+		{
+			name: "Secret in another namespace",
+			obj:  secret(core.Annotation(metadata.SecretFromAnnotationKey, "secret:vault/creds")),
+		},
+		{
+			name: "mounted file",
+			obj:  secret(core.Annotation(metadata.SecretFromAnnotationKey, "file:db/password")),
+		},
+		{
+			name:    "file outside of the secrets directory",
+			obj:     secret(core.Annotation(metadata.SecretFromAnnotationKey, "file:../token")),
+			wantErr: InvalidSecretFromAnnotationError(secret(), errors.New("")),
+		},
+		{
+			name:    "unknown reference",
+			obj:     secret(core.Annotation(metadata.SecretFromAnnotationKey, "vault:creds")),
+			wantErr: InvalidSecretFromAnnotationError(secret(), errors.New("")),
+		},
+		{
+			name:    "not a Secret",
+			obj:     fake.ConfigMap(core.Annotation(metadata.SecretFromAnnotationKey, "secret:creds")),
+			wantErr: InvalidSecretFromAnnotationError(fake.ConfigMap(), errors.New("")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SecretFrom(tc.obj)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("got SecretFrom() error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecretFromNamespaces(t *testing.T) {
+	secret := func(opts ...core.MetaMutator) ast.FileObject {
+		return fake.FileObject(fake.SecretObject("creds", append(opts, core.Namespace("foo"))...), "secret.yaml")
+	}
+	testCases := []struct {
+		name    string
+		obj     ast.FileObject
+		wantErr status.Error
+	}{
+		{
+			name: "no annotation",
+			obj:  secret(),
+		},
+		{
+			name: "Secret in the same namespace",
+			obj:  secret(core.Annotation(metadata.SecretFromAnnotationKey, "secret:creds")),
+		},
+		{
+			name: "Secret in the namespace of the RepoSync",
+			obj:  secret(core.Annotation(metadata.SecretFromAnnotationKey, "secret:foo/creds")),
+		},
+		{
+			name: "Secret in another namespace of the RepoSync",
+			obj:  secret(core.Annotation(metadata.SecretFromAnnotationKey, "secret:bar/creds")),
+		},
+		{
+			name:    "Secret in a namespace out of scope",
+			obj:     secret(core.Annotation(metadata.SecretFromAnnotationKey, "secret:vault/creds")),
+			wantErr: InvalidSecretFromAnnotationError(secret(), errors.New("")),
+		},
+		{
+			name:    "mounted file",
+			obj:     secret(core.Annotation(metadata.SecretFromAnnotationKey, "file:db/password")),
+			wantErr: InvalidSecretFromAnnotationError(secret(), errors.New("")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SecretFromNamespaces(tc.obj, "foo", "bar")
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("got SecretFromNamespaces() error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hydrate
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kpt.dev/configsync/pkg/api/configsync"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast"
+	"kpt.dev/configsync/pkg/importer/analyzer/ast/node"
+	"kpt.dev/configsync/pkg/kinds"
+	"kpt.dev/configsync/pkg/metadata"
+	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/validate/objects"
+)
+
+// Delegation hydrates the given Tree objects by binding the RoleBindings
+// declared for the RepoSyncs which delegate namespaces to the reconciler of
+// the RepoSync in each namespace they were copied into.
+func Delegation(objs *objects.Tree) status.MultiError {
+	if objs.Tree == nil {
+		return nil
+	}
+	return visitDelegation(objs.Tree)
+}
+
+func visitDelegation(n *ast.TreeNode) status.MultiError {
+	if n.Type != node.Namespace {
+		var errs status.MultiError
+		for _, c := range n.Children {
+			errs = status.Append(errs, visitDelegation(c))
+		}
+		return errs
+	}
+
+	var errs status.MultiError
+	for _, o := range n.Objects {
+		rsName, found := o.GetAnnotations()[metadata.DelegatedByAnnotationKey]
+		if !found || o.GetObjectKind().GroupVersionKind().GroupKind() != kinds.RoleBinding().GroupKind() {
+			continue
+		}
+		subjects := []interface{}{
+			map[string]interface{}{
+				"kind":      kinds.ServiceAccount().Kind,
+				"name":      core.NsReconcilerName(n.Name(), rsName),
+				"namespace": configsync.ControllerNamespace,
+			},
+		}
+		if err := unstructured.SetNestedSlice(o.Object, subjects, "subjects"); err != nil {
+			errs = status.Append(errs, status.ResourceWrap(err, "failed to bind the delegated RoleBinding", &o))
+		}
+	}
+	return errs
+}
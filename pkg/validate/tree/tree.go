@@ -40,10 +40,12 @@ func Hierarchical(objs *objects.Tree) status.MultiError {
 	}
 
 	// We perform inheritance first so that we copy all abstract objects into
-	// their potential namespaces, and then we perform namespace selection to
+	// their potential namespaces, and bind the delegated RoleBindings to the
+	// reconciler of each namespace. Then we perform namespace selection to
 	// filter out the copies which are not selected.
 	hydrators := []objects.TreeVisitor{
 		hydrate.Inheritance,
+		hydrate.Delegation,
 		hydrate.NamespaceSelectors,
 	}
 	for _, hydrator := range hydrators {
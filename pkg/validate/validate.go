@@ -15,11 +15,15 @@
 package validate
 
 import (
+	"context"
+
+	"go.opencensus.io/trace"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"kpt.dev/configsync/pkg/declared"
 	"kpt.dev/configsync/pkg/importer/analyzer/ast"
 	"kpt.dev/configsync/pkg/importer/filesystem/cmpath"
 	"kpt.dev/configsync/pkg/status"
+	"kpt.dev/configsync/pkg/tracing"
 	"kpt.dev/configsync/pkg/util/discovery"
 	"kpt.dev/configsync/pkg/validate/final"
 	"kpt.dev/configsync/pkg/validate/objects"
@@ -71,11 +75,17 @@ type Options struct {
 	// Visitors is a list of optional visitor functions which can be used to
 	// inject additional validation or hydration steps on the final objects.
 	Visitors []VisitorFunc
+	// IgnoreRules selects the fields of declared objects which are owned by
+	// another controller, and so are stripped before they are applied.
+	IgnoreRules declared.IgnoreRules
+	// Variables are the values of the cluster variables ConfigMap, which are
+	// substituted for the `${vars.<key>}` references in the declared objects.
+	Variables map[string]string
 }
 
 // Hierarchical validates and hydrates the given FileObjects from a structured,
-// hierarchical repo.
-func Hierarchical(objs []ast.FileObject, opts Options) ([]ast.FileObject, status.MultiError) {
+// hierarchical repo. Each stage is traced as a child of the span in ctx.
+func Hierarchical(ctx context.Context, objs []ast.FileObject, opts Options) ([]ast.FileObject, status.MultiError) {
 	// First we perform initial validation which includes:
 	//   - checking for illegal metadata or resource kinds
 	//   - checking for illegal or invalid directories, namespaces, or names
@@ -92,11 +102,15 @@ func Hierarchical(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 		BuildScoper:       opts.BuildScoper,
 		Converter:         opts.Converter,
 		AllowUnknownKinds: opts.AllowUnknownKinds,
+		IgnoreRules:       opts.IgnoreRules,
+		Variables:         opts.Variables,
 	}
 
 	// nonBlockingErrs tracks the errors which do not block the apply stage
 	var nonBlockingErrs status.MultiError
-	if errs := raw.Hierarchical(rawObjects); errs != nil {
+	if errs := stage(ctx, "raw", func() status.MultiError {
+		return raw.Hierarchical(rawObjects)
+	}); errs != nil {
 		if status.HasBlockingErrors(errs) {
 			return nil, errs
 		}
@@ -107,13 +121,16 @@ func Hierarchical(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 	// and before the next round of validation on them which includes:
 	//   - checking for namespaces being specified on cluster-scoped objects
 	//   - checking for namespace selectors on cluster-scoped objects
-	scopedObjects, scopeErrs := rawObjects.Scoped()
-	if status.HasBlockingErrors(scopeErrs) {
-		return nil, status.Append(nonBlockingErrs, scopeErrs)
-	}
-	nonBlockingErrs = status.Append(nonBlockingErrs, scopeErrs)
-
-	if errs := scoped.Hierarchical(scopedObjects); errs != nil {
+	var scopedObjects *objects.Scoped
+	if errs := stage(ctx, "scoped", func() status.MultiError {
+		var scopeErrs status.MultiError
+		scopedObjects, scopeErrs = rawObjects.Scoped()
+		if status.HasBlockingErrors(scopeErrs) {
+			return scopeErrs
+		}
+		nonBlockingErrs = status.Append(nonBlockingErrs, scopeErrs)
+		return scoped.Hierarchical(scopedObjects)
+	}); errs != nil {
 		return nil, status.Append(nonBlockingErrs, errs)
 	}
 
@@ -125,11 +142,15 @@ func Hierarchical(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 	// We also perform hydration which includes:
 	//   - copying "abstract" resources down into child namespaces and filtering
 	//     based upon their namespace selector
-	treeObjects, errs := objects.BuildTree(scopedObjects)
-	if errs != nil {
-		return nil, status.Append(nonBlockingErrs, errs)
-	}
-	if errs = tree.Hierarchical(treeObjects); errs != nil {
+	var treeObjects *objects.Tree
+	if errs := stage(ctx, "tree", func() status.MultiError {
+		var errs status.MultiError
+		treeObjects, errs = objects.BuildTree(scopedObjects)
+		if errs != nil {
+			return errs
+		}
+		return tree.Hierarchical(treeObjects)
+	}); errs != nil {
 		return nil, status.Append(nonBlockingErrs, errs)
 	}
 
@@ -139,23 +160,22 @@ func Hierarchical(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 	//   - checking for resources with duplicate GKNNs
 	//   - checking for managed resources in unmanaged namespaces
 	finalObjects := treeObjects.Objects()
-	if errs = final.Validation(finalObjects); errs != nil {
+	if errs := stage(ctx, "final", func() status.MultiError {
+		return final.Validation(finalObjects)
+	}); errs != nil {
 		return nil, status.Append(nonBlockingErrs, errs)
 	}
 
-	for _, visitor := range opts.Visitors {
-		finalObjects, errs = visitor(finalObjects)
-		if errs != nil {
-			return nil, status.Append(nonBlockingErrs, errs)
-		}
+	finalObjects, errs := visit(ctx, finalObjects, opts.Visitors)
+	if errs != nil {
+		return nil, status.Append(nonBlockingErrs, errs)
 	}
-
 	return finalObjects, nonBlockingErrs
 }
 
 // Unstructured validates and hydrates the given FileObjects from an
-// unstructured repo.
-func Unstructured(objs []ast.FileObject, opts Options) ([]ast.FileObject, status.MultiError) {
+// unstructured repo. Each stage is traced as a child of the span in ctx.
+func Unstructured(ctx context.Context, objs []ast.FileObject, opts Options) ([]ast.FileObject, status.MultiError) {
 	// First we perform initial validation which includes:
 	//   - checking for illegal metadata or resource kinds
 	//   - checking for illegal or invalid namespaces or names
@@ -172,11 +192,15 @@ func Unstructured(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 		BuildScoper:       opts.BuildScoper,
 		Converter:         opts.Converter,
 		AllowUnknownKinds: opts.AllowUnknownKinds,
+		IgnoreRules:       opts.IgnoreRules,
+		Variables:         opts.Variables,
 	}
 
 	// nonBlockingErrs tracks the errors which do not block the apply stage
 	var nonBlockingErrs status.MultiError
-	if errs := raw.Unstructured(rawObjects); errs != nil {
+	if errs := stage(ctx, "raw", func() status.MultiError {
+		return raw.Unstructured(rawObjects)
+	}); errs != nil {
 		if status.HasBlockingErrors(errs) {
 			return nil, errs
 		}
@@ -190,15 +214,18 @@ func Unstructured(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 	// We also perform the next round of hydration which includes:
 	//   - copy "abstract" resources into zero or more namespaces based upon their
 	//     namespace selector
-	scopedObjects, scopeErrs := rawObjects.Scoped()
-	if status.HasBlockingErrors(scopeErrs) {
-		return nil, status.Append(nonBlockingErrs, scopeErrs)
-	}
-	nonBlockingErrs = status.Append(nonBlockingErrs, scopeErrs)
-
-	scopedObjects.DefaultNamespace = opts.DefaultNamespace
-	scopedObjects.IsNamespaceReconciler = opts.IsNamespaceReconciler
-	if errs := scoped.Unstructured(scopedObjects); errs != nil {
+	var scopedObjects *objects.Scoped
+	if errs := stage(ctx, "scoped", func() status.MultiError {
+		var scopeErrs status.MultiError
+		scopedObjects, scopeErrs = rawObjects.Scoped()
+		if status.HasBlockingErrors(scopeErrs) {
+			return scopeErrs
+		}
+		nonBlockingErrs = status.Append(nonBlockingErrs, scopeErrs)
+		scopedObjects.DefaultNamespace = opts.DefaultNamespace
+		scopedObjects.IsNamespaceReconciler = opts.IsNamespaceReconciler
+		return scoped.Unstructured(scopedObjects)
+	}); errs != nil {
 		return nil, status.Append(nonBlockingErrs, errs)
 	}
 
@@ -208,17 +235,38 @@ func Unstructured(objs []ast.FileObject, opts Options) ([]ast.FileObject, status
 	//   - checking for resources with duplicate GKNNs
 	//   - checking for managed resources in unmanaged namespaces
 	finalObjects := scopedObjects.Objects()
-	if errs := final.Validation(finalObjects); errs != nil {
+	if errs := stage(ctx, "final", func() status.MultiError {
+		return final.Validation(finalObjects)
+	}); errs != nil {
 		return nil, status.Append(nonBlockingErrs, errs)
 	}
 
-	for _, visitor := range opts.Visitors {
-		var errs status.MultiError
-		finalObjects, errs = visitor(finalObjects)
-		if errs != nil {
-			return nil, status.Append(nonBlockingErrs, errs)
-		}
+	finalObjects, errs := visit(ctx, finalObjects, opts.Visitors)
+	if errs != nil {
+		return nil, status.Append(nonBlockingErrs, errs)
 	}
-
 	return finalObjects, nonBlockingErrs
 }
+
+// visit runs the visitors on the final objects in the "visitors" stage.
+func visit(ctx context.Context, objs []ast.FileObject, visitors []VisitorFunc) ([]ast.FileObject, status.MultiError) {
+	errs := stage(ctx, "visitors", func() status.MultiError {
+		for _, visitor := range visitors {
+			var errs status.MultiError
+			objs, errs = visitor(objs)
+			if errs != nil {
+				return errs
+			}
+		}
+		return nil
+	})
+	return objs, errs
+}
+
+// stage runs a validation stage in a trace span named after it.
+func stage(ctx context.Context, name string, validate func() status.MultiError) status.MultiError {
+	_, span := trace.StartSpan(ctx, "validate."+name)
+	errs := validate()
+	tracing.EndSpan(span, errs)
+	return errs
+}
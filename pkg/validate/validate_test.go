@@ -15,6 +15,7 @@
 package validate
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -949,7 +950,7 @@ func TestHierarchical(t *testing.T) {
 			tc.options.PolicyDir = cmpath.RelativeSlash(dir)
 			tc.options.Converter = converter
 
-			got, errs := Hierarchical(tc.objs, tc.options)
+			got, errs := Hierarchical(context.Background(), tc.objs, tc.options)
 			if !errors.Is(errs, tc.wantErrs) {
 				t.Errorf("got Hierarchical() error %v; want %v", errs, tc.wantErrs)
 			}
@@ -1293,7 +1294,7 @@ func TestUnstructured(t *testing.T) {
 			tc.options.PolicyDir = cmpath.RelativeSlash(dir)
 			tc.options.Converter = converter
 
-			got, errs := Unstructured(tc.objs, tc.options)
+			got, errs := Unstructured(context.Background(), tc.objs, tc.options)
 			if !errors.Is(errs, tc.wantErrs) {
 				t.Errorf("got Unstructured() error %v; want %v", errs, tc.wantErrs)
 			}
@@ -1303,3 +1304,83 @@ func TestUnstructured(t *testing.T) {
 		})
 	}
 }
+
+func TestHierarchicalDelegation(t *testing.T) {
+	delegating := func(opts ...core.MetaMutator) ast.FileObject {
+		opts = append(opts,
+			core.Annotation(csmetadata.DelegateClusterRoleAnnotationKey, "edit"),
+			core.Annotation(csmetadata.DelegateSecretFromAnnotationKey, "secret:config-management-system/team-x-git-creds"))
+		rs := validRepoSync("", "team-x", "namespaces/team-x/rs.yaml", opts...)
+		if err := unstructured.SetNestedField(rs.Object, "ssh", "spec", "git", "auth"); err != nil {
+			t.Fatal(err)
+		}
+		if err := unstructured.SetNestedField(rs.Object, "git-creds", "spec", "git", "secretRef", "name"); err != nil {
+			t.Fatal(err)
+		}
+		return rs
+	}
+	objs := []ast.FileObject{
+		fake.Repo(),
+		fake.Namespace("namespaces/team-x/frontend"),
+		fake.Namespace("namespaces/team-x/backend/api", core.Label("env", "prod")),
+		fake.Namespace("namespaces/other"),
+		delegating(),
+	}
+
+	converter, err := openapitest.ValueConverterForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := discoverytest.Client(discoverytest.CRDsToAPIGroupResources(nil))
+	got, errs := Hierarchical(context.Background(), objs, Options{
+		BuildScoper: discovery.ScoperBuilder(dc),
+		PolicyDir:   cmpath.RelativeSlash(dir),
+		Converter:   converter,
+	})
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	gotSubjects := make(map[string]interface{})
+	gotSecrets := make(map[string]string)
+	for _, obj := range got {
+		switch obj.GetObjectKind().GroupVersionKind() {
+		case kinds.RoleBinding():
+			if obj.GetName() != "configsync.gke.io:delegation:team-x" {
+				t.Errorf("got RoleBinding %q, want configsync.gke.io:delegation:team-x", obj.GetName())
+			}
+			subjects, _, _ := unstructured.NestedSlice(obj.Object, "subjects")
+			gotSubjects[obj.GetNamespace()] = subjects
+		case kinds.Secret():
+			if obj.GetName() != "git-creds" {
+				t.Errorf("got Secret %q, want git-creds", obj.GetName())
+			}
+			gotSecrets[obj.GetNamespace()] = obj.GetAnnotations()[csmetadata.SecretFromAnnotationKey]
+			if _, found := obj.GetAnnotations()[csmetadata.DeclaredFieldsKey]; !found {
+				t.Errorf("got Secret without the %s annotation", csmetadata.DeclaredFieldsKey)
+			}
+		}
+	}
+
+	subject := func(name string) []interface{} {
+		return []interface{}{map[string]interface{}{
+			"kind":      "ServiceAccount",
+			"name":      name,
+			"namespace": "config-management-system",
+		}}
+	}
+	wantSubjects := map[string]interface{}{
+		"frontend": subject("ns-reconciler-frontend-team-x-6"),
+		"api":      subject("ns-reconciler-api-team-x-6"),
+	}
+	if diff := cmp.Diff(wantSubjects, gotSubjects); diff != "" {
+		t.Error(diff)
+	}
+	wantSecrets := map[string]string{
+		"frontend": "secret:config-management-system/team-x-git-creds",
+		"api":      "secret:config-management-system/team-x-git-creds",
+	}
+	if diff := cmp.Diff(wantSecrets, gotSecrets); diff != "" {
+		t.Error(diff)
+	}
+}
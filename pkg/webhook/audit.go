@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"kpt.dev/configsync/pkg/core"
+	"kpt.dev/configsync/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// AuditEventReason is the reason of the Events recorded on objects which were
+// modified in a way that the webhook would deny in enforcement mode.
+const AuditEventReason = "ManagedResourceModified"
+
+// AuditEvent is the structured audit log entry for an admission request which
+// the webhook admitted only because it runs in audit-only mode.
+type AuditEvent struct {
+	User      string   `json:"user"`
+	Operation string   `json:"operation"`
+	Group     string   `json:"group,omitempty"`
+	Version   string   `json:"version"`
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Reason    string   `json:"reason"`
+	Message   string   `json:"message"`
+	Fields    []string `json:"fields,omitempty"`
+}
+
+// reject denies the request, or in audit-only mode admits it and records who
+// modified which fields of the object as an Event, an audit log entry and a
+// metric.
+func (v *Validator) reject(ctx context.Context, obj client.Object, username string, op admissionv1.Operation, reason metav1.StatusReason, fields *fieldpath.Set, message string) admission.Response {
+	if !v.auditOnly {
+		klog.Error(message)
+		return deny(reason, message)
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	event := AuditEvent{
+		User:      username,
+		Operation: string(op),
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Reason:    string(reason),
+		Message:   message,
+	}
+	if fields != nil {
+		fields.Iterate(func(p fieldpath.Path) {
+			event.Fields = append(event.Fields, p.String())
+		})
+	}
+	if entry, err := json.Marshal(event); err != nil {
+		klog.Errorf("Failed to encode audit event for %q: %v", core.GKNN(obj), err)
+	} else {
+		klog.Infof("audit: %s", entry)
+	}
+	if v.recorder != nil {
+		v.recorder.Eventf(obj, corev1.EventTypeWarning, AuditEventReason, "%s (admitted in audit-only mode)", message)
+	}
+	metrics.RecordAdmissionAuditEvent(ctx, username, gvk, string(op))
+	return allow()
+}
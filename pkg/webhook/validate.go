@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"kpt.dev/configsync/pkg/core"
 	"kpt.dev/configsync/pkg/declared"
@@ -38,11 +39,17 @@ import (
 )
 
 // AddValidator adds the admission webhook validator to the passed manager.
-func AddValidator(mgr manager.Manager) error {
+// In audit-only mode, requests which would be denied are admitted and
+// recorded instead.
+func AddValidator(mgr manager.Manager, auditOnly bool) error {
 	handler, err := handler(mgr.GetConfig())
 	if err != nil {
 		return err
 	}
+	if auditOnly {
+		handler.auditOnly = true
+		handler.recorder = mgr.GetEventRecorderFor(configuration.ShortName)
+	}
 	mgr.GetWebhookServer().Register(configuration.ServingPath, &webhook.Admission{
 		Handler: handler,
 	})
@@ -53,6 +60,11 @@ func AddValidator(mgr manager.Manager) error {
 // requests and admits or denies them.
 type Validator struct {
 	differ *ObjectDiffer
+	// auditOnly admits requests which would otherwise be denied, and records
+	// them instead.
+	auditOnly bool
+	// recorder records Events on the objects modified in audit-only mode.
+	recorder record.EventRecorder
 }
 
 var _ admission.Handler = &Validator{}
@@ -67,11 +79,11 @@ func handler(cfg *rest.Config) (*Validator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Validator{&ObjectDiffer{vc}}, nil
+	return &Validator{differ: &ObjectDiffer{vc}}, nil
 }
 
 // Handle implements admission.Handler
-func (v *Validator) Handle(_ context.Context, req admission.Request) admission.Response {
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
 	// An admission request for a sub-resource (such as a Scale) will not include
 	// the full parent for us to validate until the admission chain is fixed:
 	// https://github.com/kubernetes/enhancements/pull/1600
@@ -111,39 +123,39 @@ func (v *Validator) Handle(_ context.Context, req admission.Request) admission.R
 	username := req.UserInfo.Username
 	switch req.Operation {
 	case admissionv1.Create:
-		return v.handleCreate(newObj, username)
+		return v.handleCreate(ctx, newObj, username)
 	case admissionv1.Delete:
-		return v.handleDelete(oldObj, username)
+		return v.handleDelete(ctx, oldObj, username)
 	case admissionv1.Update:
-		return v.handleUpdate(oldObj, newObj, username)
+		return v.handleUpdate(ctx, oldObj, newObj, username)
 	default:
 		klog.Errorf("Unsupported operation: %v from %s", req.Operation, username)
 		return allow()
 	}
 }
 
-func (v *Validator) handleCreate(newObj client.Object, username string) admission.Response {
+func (v *Validator) handleCreate(ctx context.Context, newObj client.Object, username string) admission.Response {
 	if differ.ManagedByConfigSync(newObj) {
-		klog.Errorf("%s is not authorized to create managed resource %q", username, core.GKNN(newObj))
-		return deny(metav1.StatusReasonUnauthorized, fmt.Sprintf("%s is not authorized to create managed resource %q", username, core.GKNN(newObj)))
+		return v.reject(ctx, newObj, username, admissionv1.Create, metav1.StatusReasonUnauthorized, nil,
+			fmt.Sprintf("%s is not authorized to create managed resource %q", username, core.GKNN(newObj)))
 	}
 	return allow()
 }
 
-func (v *Validator) handleDelete(oldObj client.Object, username string) admission.Response {
+func (v *Validator) handleDelete(ctx context.Context, oldObj client.Object, username string) admission.Response {
 	// This means a delete request was previously made and accepted, but removal of the API object is not yet complete.
 	// See http://b/199235728#comment16 for more details.
 	if oldObj.GetDeletionTimestamp() != nil {
 		return allow()
 	}
 	if differ.ManagedByConfigSync(oldObj) {
-		klog.Errorf("%s is not authorized to delete managed resource %q", username, core.GKNN(oldObj))
-		return deny(metav1.StatusReasonUnauthorized, fmt.Sprintf("%s is not authorized to delete managed resource %q", username, core.GKNN(oldObj)))
+		return v.reject(ctx, oldObj, username, admissionv1.Delete, metav1.StatusReasonUnauthorized, nil,
+			fmt.Sprintf("%s is not authorized to delete managed resource %q", username, core.GKNN(oldObj)))
 	}
 	return allow()
 }
 
-func (v *Validator) handleUpdate(oldObj, newObj client.Object, username string) admission.Response {
+func (v *Validator) handleUpdate(ctx context.Context, oldObj, newObj client.Object, username string) admission.Response {
 	if !differ.ManagedByConfigSync(oldObj) && !differ.ManagedByConfigSync(newObj) {
 		// Both oldObj and newObj are not managed by Config Sync.
 		// The webhook should be configured to only intercept resources which are
@@ -162,8 +174,8 @@ func (v *Validator) handleUpdate(oldObj, newObj client.Object, username string)
 	// If the diff set includes any ConfigSync labels or annotations, reject the
 	// request immediately.
 	if csSet := ConfigSyncMetadata(diffSet); !csSet.Empty() {
-		klog.Errorf("%s cannot modify Config Sync metadata of object %q: %s", username, core.GKNN(oldObj), csSet.String())
-		return deny(metav1.StatusReasonForbidden, fmt.Sprintf("%s cannot modify Config Sync metadata of object %q: %s", username, core.GKNN(oldObj), csSet.String()))
+		return v.reject(ctx, oldObj, username, admissionv1.Update, metav1.StatusReasonForbidden, csSet,
+			fmt.Sprintf("%s cannot modify Config Sync metadata of object %q: %s", username, core.GKNN(oldObj), csSet.String()))
 	}
 
 	if oldObj.GetAnnotations()[csmetadata.LifecycleMutationAnnotation] == csmetadata.IgnoreMutation {
@@ -184,8 +196,8 @@ func (v *Validator) handleUpdate(oldObj, newObj client.Object, username string)
 	// request. Otherwise allow it.
 	invalidSet := diffSet.Intersection(declaredSet)
 	if !invalidSet.Empty() {
-		klog.Errorf("%s cannot modify fields of object %q managed by Config Sync: %s", username, core.GKNN(oldObj), invalidSet.String())
-		return deny(metav1.StatusReasonForbidden, fmt.Sprintf("%s cannot modify fields of object %q managed by Config Sync: %s", username, core.GKNN(oldObj), invalidSet.String()))
+		return v.reject(ctx, oldObj, username, admissionv1.Update, metav1.StatusReasonForbidden, invalidSet,
+			fmt.Sprintf("%s cannot modify fields of object %q managed by Config Sync: %s", username, core.GKNN(oldObj), invalidSet.String()))
 	}
 	return allow()
 }
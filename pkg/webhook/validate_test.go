@@ -17,6 +17,7 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	admissionv1 "k8s.io/api/admission/v1"
@@ -25,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"kpt.dev/configsync/pkg/api/configsync"
 	"kpt.dev/configsync/pkg/applier"
 	"kpt.dev/configsync/pkg/core"
@@ -715,3 +717,57 @@ func request(oldObj, newObj client.Object) admission.Request {
 		},
 	}
 }
+
+func TestValidator_HandleAuditOnly(t *testing.T) {
+	declaredFields := `{"f:metadata":{"f:labels":{"f:app.kubernetes.io/managed-by":{}},"f:annotations":{"f:configmanagement.gke.io/managed":{}}},"f:rules":{}}`
+	role := func(verb string) client.Object {
+		return fake.RoleObject(
+			core.Name("hello"),
+			core.Namespace("world"),
+			core.Label(csmetadata.ManagedByKey, csmetadata.ManagedByValue),
+			core.Annotation(csmetadata.ResourceManagementKey, csmetadata.ResourceManagementEnabled),
+			core.Annotation(csmetadata.ResourceIDKey, "rbac.authorization.k8s.io_role_world_hello"),
+			setRules([]rbacv1.PolicyRule{
+				{
+					APIGroups: []string{""},
+					Resources: []string{"pods"},
+					Verbs:     []string{verb},
+				},
+			}),
+			core.Annotation(csmetadata.DeclaredFieldsKey, declaredFields),
+		)
+	}
+
+	v := validatorForTest(t)
+	v.auditOnly = true
+	recorder := record.NewFakeRecorder(10)
+	v.recorder = recorder
+
+	req := request(role("get"), role("*"))
+	req.UserInfo = bob()
+	resp := v.Handle(context.Background(), req)
+	if !resp.Allowed {
+		t.Errorf("got Handle() response denied %q, want allowed in audit-only mode", resp.Result.Reason)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, AuditEventReason) || !strings.Contains(event, "bob@acme.com") {
+			t.Errorf("got event %q, want a %s event for bob@acme.com", event, AuditEventReason)
+		}
+	default:
+		t.Error("got no event, want an audit event")
+	}
+
+	// Requests which are allowed in enforcement mode are not audited.
+	req = request(role("get"), role("get"))
+	req.UserInfo = bob()
+	if resp := v.Handle(context.Background(), req); !resp.Allowed {
+		t.Errorf("got Handle() response denied %q, want allowed", resp.Result.Reason)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("got event %q, want no event", event)
+	default:
+	}
+}